@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"incident-ai/models"
+	"incident-ai/telemetry"
+	"log"
+	"strings"
+)
+
+// errAborted is the cancellation cause of an incident aborted by an operator
+var errAborted = errors.New("aborted by operator")
+
+// errIncidentTimeout is the cancellation cause of an incident that ran past -incident-timeout
+var errIncidentTimeout = errors.New("incident timeout exceeded")
+
+// errNotProcessing is returned when aborting an incident that is not being processed
+var errNotProcessing = errors.New("incident is not being processed")
+
+// beginProcessing derives a context for one incident's processing that Abort can cancel
+// and that expires after incidentTimeout, if set. Work done for several incidents at once,
+// like a batched analysis, passes all their IDs, and aborting any of them cancels it. The
+// returned function must be called when processing ends.
+func (o *Orchestrator) beginProcessing(ctx context.Context, ids ...string) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	stop := func() {}
+	if o.incidentTimeout > 0 {
+		ctx, stop = context.WithTimeoutCause(ctx, o.incidentTimeout,
+			fmt.Errorf("%w (%v)", errIncidentTimeout, o.incidentTimeout))
+	}
+
+	o.processingMu.Lock()
+	if o.processing == nil {
+		o.processing = make(map[string]context.CancelCauseFunc)
+	}
+	for _, id := range ids {
+		o.processing[id] = cancel
+	}
+	o.processingMu.Unlock()
+
+	return ctx, func() {
+		o.processingMu.Lock()
+		for _, id := range ids {
+			delete(o.processing, id)
+		}
+		o.processingMu.Unlock()
+		stop()
+		cancel(nil)
+	}
+}
+
+// Abort cancels the processing of an in-progress incident. Remediation halts at the next
+// step boundary (or immediately for steps that honor cancellation, like AI calls and
+// restart commands) and the incident is marked ABORTED.
+func (o *Orchestrator) Abort(id, by string) error {
+	o.processingMu.Lock()
+	cancel, ok := o.processing[id]
+	o.processingMu.Unlock()
+
+	if !ok {
+		return errNotProcessing
+	}
+
+	cause := errAborted
+	if by != "" {
+		cause = fmt.Errorf("%w %s", errAborted, by)
+	}
+
+	log.Printf("[SYSTEM] 🛑 Abort requested for incident %s\n", id)
+	cancel(cause)
+	return nil
+}
+
+// abortedAt reports whether the incident's processing was aborted or timed out, and if so
+// marks it ABORTED or FAILED and notifies. step names what was being done, for the log and
+// notification.
+func (o *Orchestrator) abortedAt(ctx context.Context, incident *models.Incident, step string) bool {
+	cause := context.Cause(ctx)
+	if errors.Is(cause, errIncidentTimeout) {
+		o.timedOut(ctx, incident, step, cause)
+		return true
+	}
+	if !errors.Is(cause, errAborted) {
+		return false
+	}
+
+	incident.Status = models.StatusAborted
+	incident.FailureReason = fmt.Sprintf("%v during %s", cause, step)
+	if err := o.store.StoreIncident(incident); err != nil {
+		telemetry.Logf(ctx, "[MEMORY] Warning: failed to store incident: %v\n", err)
+	}
+
+	o.notify(context.Background(), incident,
+		fmt.Sprintf("%s incident aborted", incident.Type),
+		fmt.Sprintf("Processing was %s.\nThe service may be partially remediated; check it by hand.\n", incident.FailureReason))
+
+	log.Println("\n" + strings.Repeat("=", 70))
+	telemetry.Logf(ctx, "[SYSTEM] 🛑 INCIDENT ABORTED (%s)\n", incident.FailureReason)
+	log.Println(strings.Repeat("=", 70) + "\n")
+	return true
+}
+
+// timedOut marks an incident that ran past -incident-timeout FAILED and notifies. The
+// timeout counts as a failed resolution, toward escalation like any other.
+func (o *Orchestrator) timedOut(ctx context.Context, incident *models.Incident, step string, cause error) {
+	incident.Status = models.StatusFailed
+	incident.FailureReason = fmt.Sprintf("%v during %s", cause, step)
+	if err := o.store.StoreIncident(incident); err != nil {
+		telemetry.Logf(ctx, "[MEMORY] Warning: failed to store incident: %v\n", err)
+	}
+	o.recordOutcome(ctx, incident, false)
+
+	o.notify(context.Background(), incident,
+		fmt.Sprintf("%s incident timed out", incident.Type),
+		fmt.Sprintf("Processing stopped: %s.\nThe service may be partially remediated; check it by hand.\n", incident.FailureReason))
+
+	log.Println("\n" + strings.Repeat("=", 70))
+	telemetry.Logf(ctx, "[SYSTEM] ⏱️  INCIDENT TIMED OUT (%s)\n", incident.FailureReason)
+	log.Println(strings.Repeat("=", 70) + "\n")
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"incident-ai/models"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stallingExecutor is a fakeExecutor whose fixes run until their context is cancelled,
+// signalling started when one begins
+type stallingExecutor struct {
+	fakeExecutor
+	started chan struct{}
+}
+
+func newStallingExecutor() *stallingExecutor {
+	return &stallingExecutor{started: make(chan struct{}, 1)}
+}
+
+func (e *stallingExecutor) ExecuteFix(ctx context.Context, incident *models.Incident, aiResponse *models.AIResponse) (*models.Resolution, error) {
+	e.started <- struct{}{}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestAbortMidRemediation(t *testing.T) {
+	o := newTestOrchestrator(t)
+	executor := newStallingExecutor()
+	o.executor = executor
+	notifier := o.notifier.(*recordingNotifier)
+	api := NewAPIServer("0", o)
+
+	incident := newTestIncident("a", models.ConfigError, "config invalid")
+	if recorder := serveAPI(api, http.MethodPost, "/incidents/a/abort"); recorder.Code != http.StatusConflict {
+		t.Errorf("abort before processing = %d, want %d", recorder.Code, http.StatusConflict)
+	}
+
+	processed := make(chan error, 1)
+	go func() { processed <- o.processIncident(context.Background(), incident) }()
+
+	select {
+	case <-executor.started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("remediation never started")
+	}
+	if recorder := serveAPI(api, http.MethodPost, "/incidents/a/abort?by=alice"); recorder.Code != http.StatusAccepted {
+		t.Fatalf("abort mid-remediation = %d %s, want %d", recorder.Code, recorder.Body, http.StatusAccepted)
+	}
+
+	select {
+	case err := <-processed:
+		if err != nil {
+			t.Fatalf("processIncident: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("processing didn't stop after the abort")
+	}
+
+	if incident.Status != models.StatusAborted || incident.FailureReason != "aborted by operator alice during remediation" {
+		t.Errorf("incident is %s (%q), want ABORTED during remediation", incident.Status, incident.FailureReason)
+	}
+	if stored, _ := o.store.GetIncident(incident.ID); stored.Status != models.StatusAborted {
+		t.Errorf("stored status = %s, want ABORTED", stored.Status)
+	}
+	if msg := notifier.next(t, time.Second); !strings.Contains(msg.Title, "aborted") {
+		t.Errorf("notification %q, want the abort", msg.Title)
+	}
+	if err := o.Abort(incident.ID, "alice"); !errors.Is(err, errNotProcessing) {
+		t.Errorf("Abort after processing = %v, want %v", err, errNotProcessing)
+	}
+}
+
+func TestIncidentTimeoutCutsOffSlowRemediation(t *testing.T) {
+	o := newTestOrchestrator(t)
+	o.incidentTimeout = 100 * time.Millisecond
+	executor := newStallingExecutor()
+	o.executor = executor
+	notifier := o.notifier.(*recordingNotifier)
+
+	incident := newTestIncident("a", models.ConfigError, "config invalid")
+	start := time.Now()
+	if err := o.processIncident(context.Background(), incident); err != nil {
+		t.Fatalf("processIncident: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("processing took %v, want it cut off at the %v budget", elapsed, o.incidentTimeout)
+	}
+	if incident.Status != models.StatusFailed || !strings.Contains(incident.FailureReason, "incident timeout exceeded (100ms) during remediation") {
+		t.Errorf("incident is %s (%q), want FAILED on the timeout during remediation", incident.Status, incident.FailureReason)
+	}
+	if msg := notifier.next(t, time.Second); !strings.Contains(msg.Title, "timed out") {
+		t.Errorf("notification %q, want the timeout", msg.Title)
+	}
+	if streak := o.store.FailureStreak(models.ConfigError); streak != 1 {
+		t.Errorf("failure streak = %d, want the timeout counted as a failed resolution", streak)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"incident-ai/models"
+	"log"
+	"time"
+)
+
+// Acknowledge records that someone has taken ownership of an incident. If the
+// incident is not resolved within the acknowledgment expiry, ownership is released
+// and the incident is re-notified so someone else can pick it up.
+func (o *Orchestrator) Acknowledge(id, by string) (*models.Incident, error) {
+	incident, err := o.store.AcknowledgeIncident(id, by)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[SYSTEM] 🙋 Incident %s acknowledged by %s\n", id, by)
+
+	if o.ackExpiry > 0 {
+		o.scheduleAckExpiry(id, *incident.AcknowledgedAt)
+	}
+
+	return incident, nil
+}
+
+// scheduleAckExpiry starts (or restarts) the expiry timer for an incident's acknowledgment
+func (o *Orchestrator) scheduleAckExpiry(id string, ackedAt time.Time) {
+	o.ackMu.Lock()
+	defer o.ackMu.Unlock()
+
+	if o.ackTimers == nil {
+		o.ackTimers = make(map[string]*time.Timer)
+	}
+
+	if timer, exists := o.ackTimers[id]; exists {
+		timer.Stop()
+	}
+
+	o.ackTimers[id] = time.AfterFunc(o.ackExpiry, func() {
+		o.expireAck(id, ackedAt)
+	})
+}
+
+// expireAck releases an acknowledgment that outlived the expiry without the incident being resolved
+func (o *Orchestrator) expireAck(id string, ackedAt time.Time) {
+	o.ackMu.Lock()
+	delete(o.ackTimers, id)
+	o.ackMu.Unlock()
+
+	owner, err := o.store.ClearAcknowledgment(id, ackedAt)
+	if err != nil {
+		log.Printf("[SYSTEM] Warning: failed to expire acknowledgment for %s: %v\n", id, err)
+		return
+	}
+	if owner == "" {
+		return
+	}
+
+	incident, err := o.store.GetIncident(id)
+	if err != nil {
+		return
+	}
+
+	log.Printf("[SYSTEM] ⏰ Acknowledgment by %s expired for incident %s - incident is unowned again\n", owner, id)
+	o.notify(context.Background(), incident,
+		fmt.Sprintf("%s incident unowned - acknowledgment expired", incident.Type),
+		fmt.Sprintf("Acknowledged by %s but not resolved within %v.\nStatus: %s\nPlease reassign.", owner, o.ackExpiry, incident.Status))
+}
+
+// stopAckTimers cancels all pending acknowledgment expiries
+func (o *Orchestrator) stopAckTimers() {
+	o.ackMu.Lock()
+	defer o.ackMu.Unlock()
+
+	for id, timer := range o.ackTimers {
+		timer.Stop()
+		delete(o.ackTimers, id)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"incident-ai/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAckExpiry(t *testing.T) {
+	const expiry = 30 * time.Millisecond
+
+	tests := []struct {
+		name      string
+		owners    []string // acknowledged by each in turn
+		resolve   bool     // resolve the incident before the acknowledgment expires
+		wantOwner string   // named in the re-notification ("" = none sent)
+	}{
+		{name: "unresolved incident is re-notified", owners: []string{"alice"}, wantOwner: "alice"},
+		{name: "newer acknowledgment restarts the expiry", owners: []string{"alice", "bob"}, wantOwner: "bob"},
+		{name: "resolved incident keeps its owner", owners: []string{"alice"}, resolve: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOrchestrator(t)
+			o.ackExpiry = expiry
+			t.Cleanup(o.stopAckTimers)
+			notifier := o.notifier.(*recordingNotifier)
+
+			incident := newTestIncident("a", models.ServiceDown, "health check timed out")
+			incident.Status = models.StatusFixing
+			if err := o.store.StoreIncident(incident); err != nil {
+				t.Fatalf("StoreIncident: %v", err)
+			}
+
+			for _, owner := range tt.owners {
+				if _, err := o.Acknowledge("a", owner); err != nil {
+					t.Fatalf("Acknowledge(%s): %v", owner, err)
+				}
+			}
+			if tt.resolve {
+				if err := o.store.UpdateIncidentStatus("a", models.StatusResolved); err != nil {
+					t.Fatalf("UpdateIncidentStatus: %v", err)
+				}
+			}
+
+			if tt.wantOwner == "" {
+				notifier.none(t, 5*expiry)
+				stored, _ := o.store.GetIncident("a")
+				if stored.AcknowledgedBy != tt.owners[len(tt.owners)-1] {
+					t.Errorf("AcknowledgedBy = %q after expiry, want it kept", stored.AcknowledgedBy)
+				}
+				return
+			}
+
+			msg := notifier.next(t, 2*time.Second)
+			if msg.IncidentID != "a" || !strings.Contains(msg.Title, "acknowledgment expired") {
+				t.Errorf("notification = %s %q, want the expiry of incident a", msg.IncidentID, msg.Title)
+			}
+			if !strings.Contains(msg.Body, "Acknowledged by "+tt.wantOwner) {
+				t.Errorf("notification body %q doesn't name %s", msg.Body, tt.wantOwner)
+			}
+			notifier.none(t, 3*expiry)
+
+			stored, err := o.store.GetIncident("a")
+			if err != nil {
+				t.Fatalf("GetIncident: %v", err)
+			}
+			if stored.AcknowledgedBy != "" || stored.AcknowledgedAt != nil {
+				t.Errorf("acknowledgment by %q at %v not cleared on expiry", stored.AcknowledgedBy, stored.AcknowledgedAt)
+			}
+		})
+	}
+}
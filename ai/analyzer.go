@@ -3,74 +3,287 @@ package ai
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"incident-ai/models"
-	"log"
+	"incident-ai/telemetry"
+	"math"
+	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	openai "github.com/sashabaranov/go-openai"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Analyzer uses AI to analyze incidents and suggest fixes
 type Analyzer struct {
-	client *openai.Client
-	model  string
+	client       *openai.Client
+	clientConfig openai.ClientConfig
+	complete     completionFunc // sends a chat completion request (default: the OpenAI client)
+	apiKey       string
+	keys         *keyRing // rotation across several API keys (nil = apiKey only)
+	model        string
+	promptMode   PromptMode
+	temperature  float32
+	maxTokens    int
+	timeout      time.Duration // bound on each analysis call (0 = only the caller's context)
+	seed         *int          // sent for reproducible sampling (nil = none)
+	serviceURL   string        // the monitored service's address, shown in prompts
+
+	fingerprintMu   sync.Mutex
+	lastFingerprint string // system_fingerprint of the previous response, to notice backend changes
+
+	lenient           bool
+	defaultConfidence float64
+
+	dailyTokenBudget int              // 0 = unlimited
+	budget           *tokenBudget     // nil when unlimited
+	now              func() time.Time // clock for budget windows
+
+	apiKeys     []APIKey      // keys to rotate across, set by WithAPIKeys
+	keyCooldown time.Duration // how long a rejected key is skipped
+
+	provider   providerHealth     // outcome of recent provider calls
+	normalizer ResponseNormalizer // provider-specific cleanup applied before parsing
+}
+
+const (
+	// defaultTemperature keeps responses focused and close to deterministic
+	defaultTemperature = 0.3
+	// defaultMaxTokens leaves ample room for the JSON response, including code fixes
+	defaultMaxTokens = 1000
+	// defaultAnalysisTimeout bounds a hanging model call so incident handling can fall back
+	defaultAnalysisTimeout = 30 * time.Second
+)
+
+// ErrAnalysisTimeout is returned by AnalyzeIncident when the AI doesn't answer within the
+// analyzer's timeout
+var ErrAnalysisTimeout = errors.New("AI analysis timed out")
+
+// completionFunc sends a chat completion request
+type completionFunc func(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error)
+
+// defaultRestartSteps are used by lenient parsing when a restart fix arrives without steps
+var defaultRestartSteps = []string{
+	"Stop the service",
+	"Restart the service process",
+	"Verify health check passes",
 }
 
 // NewAnalyzer creates a new AI analyzer
-func NewAnalyzer(apiKey string) *Analyzer {
-	client := openai.NewClient(apiKey)
-	return &Analyzer{
-		client: client,
-		model:  openai.GPT3Dot5Turbo, // Using GPT-3.5-turbo (free tier compatible)
+func NewAnalyzer(apiKey string, opts ...Option) *Analyzer {
+	a := &Analyzer{
+		clientConfig: openai.DefaultConfig(apiKey),
+		apiKey:       apiKey,
+		model:        openai.GPT3Dot5Turbo, // Using GPT-3.5-turbo (free tier compatible)
+		promptMode:   PromptModeSeparate,
+		temperature:  defaultTemperature,
+		maxTokens:    defaultMaxTokens,
+		timeout:      defaultAnalysisTimeout,
+		now:          time.Now,
+		normalizer:   NormalizeOpenAI,
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	if a.dailyTokenBudget > 0 {
+		a.budget = newTokenBudget(a.dailyTokenBudget, a.now)
+	}
+	if len(a.apiKeys) > 0 {
+		a.keys = newKeyRing(a.apiKeys, a.keyCooldown, a.now)
+	}
+
+	// Tag analysis requests with the incident's correlation ID
+	httpClient := http.Client{}
+	if a.clientConfig.HTTPClient != nil {
+		httpClient = *a.clientConfig.HTTPClient
+	}
+	httpClient.Transport = telemetry.NewCorrelationTransport(httpClient.Transport)
+	if a.keys != nil {
+		httpClient.Transport = &keyTransport{base: httpClient.Transport}
 	}
+	a.clientConfig.HTTPClient = &httpClient
+
+	a.client = openai.NewClientWithConfig(a.clientConfig)
+	if a.complete == nil {
+		a.complete = a.client.CreateChatCompletion
+	}
+	return a
 }
 
-// AnalyzeIncident sends incident details to OpenAI and gets back a fix
-func (a *Analyzer) AnalyzeIncident(ctx context.Context, incident *models.Incident) (*models.AIResponse, error) {
-	log.Printf("[AI] Analyzing incident: %s (Type: %s)\n", incident.ID, incident.Type)
+// AnalyzeIncident sends incident details to OpenAI and gets back a fix. Fixes already
+// tried for this incident are passed as previousAttempts so the model proposes something else.
+func (a *Analyzer) AnalyzeIncident(ctx context.Context, incident *models.Incident, previousAttempts []models.Resolution) (aiResponse *models.AIResponse, err error) {
+	telemetry.Logf(ctx, "[AI] Analyzing incident: %s (Type: %s)\n", incident.ID, incident.Type)
+
+	ctx, span := telemetry.Tracer().Start(ctx, "analysis", trace.WithAttributes(
+		attribute.String("analysis.source", "ai"),
+		attribute.String("ai.model", a.model),
+	))
+	defer func() {
+		if aiResponse != nil {
+			span.SetAttributes(
+				attribute.String("fix.type", aiResponse.FixType),
+				attribute.Float64("ai.confidence", aiResponse.Confidence),
+			)
+		}
+		telemetry.End(span, err)
+	}()
 
-	prompt := a.buildPrompt(incident)
+	if a.budget != nil {
+		if err := a.budget.check(); err != nil {
+			return nil, err
+		}
+	}
 
-	resp, err := a.client.CreateChatCompletion(
-		ctx,
+	messages, err := a.buildMessages(incident, previousAttempts)
+	if err != nil {
+		return nil, err
+	}
+
+	content, fingerprint, err := a.chat(ctx, messages, a.maxTokens)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the JSON response
+	aiResponse, err = a.parseResponse(ctx, content, a.normalizer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse AI response: %w", err)
+	}
+	aiResponse.SystemFingerprint = fingerprint
+
+	telemetry.Logf(ctx, "[AI] Diagnosis: %s\n", aiResponse.Diagnosis)
+	telemetry.Logf(ctx, "[AI] Fix Type: %s\n", aiResponse.FixType)
+
+	return aiResponse, nil
+}
+
+// chat sends messages to the model and returns the reply's content and the backend's
+// system fingerprint. The call is bounded by the analyzer's timeout and rotates API keys
+// when several are configured; its token usage is recorded on the span in ctx and counted
+// against the daily budget.
+func (a *Analyzer) chat(ctx context.Context, messages []openai.ChatCompletionMessage, maxTokens int) (string, string, error) {
+	// The timeout applies to this call only, independent of the long-lived caller context
+	callCtx := ctx
+	if a.timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, a.timeout)
+		defer cancel()
+	}
+
+	complete := a.complete
+	if a.keys != nil {
+		complete = a.completeWithKeys
+	}
+	resp, err := complete(
+		callCtx,
 		openai.ChatCompletionRequest{
-			Model: a.model,
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleSystem,
-					Content: a.getSystemPrompt(),
-				},
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: prompt,
-				},
-			},
-			Temperature: 0.3, // Lower temperature for more focused/deterministic responses
+			Model:       a.model,
+			Messages:    messages,
+			Temperature: a.requestTemperature(),
+			MaxTokens:   maxTokens,
+			Seed:        a.seed,
 		},
 	)
 
+	a.provider.record(a.now(), err)
 	if err != nil {
-		return nil, fmt.Errorf("OpenAI API error: %w", err)
+		if ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+			return "", "", fmt.Errorf("%w after %v", ErrAnalysisTimeout, a.timeout)
+		}
+		return "", "", fmt.Errorf("OpenAI API error: %w", err)
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.Int("ai.prompt_tokens", resp.Usage.PromptTokens),
+		attribute.Int("ai.completion_tokens", resp.Usage.CompletionTokens),
+		attribute.Int("ai.total_tokens", resp.Usage.TotalTokens),
+		attribute.String("ai.system_fingerprint", resp.SystemFingerprint),
+	)
+	a.checkFingerprint(ctx, resp.SystemFingerprint)
+
+	if a.budget != nil && a.budget.record(resp.Usage.TotalTokens) {
+		telemetry.Logf(ctx, "[AI] 💰 Daily token budget of %d tokens exhausted, AI analysis disabled until midnight\n", a.dailyTokenBudget)
 	}
 
 	if len(resp.Choices) == 0 {
-		return nil, fmt.Errorf("no response from OpenAI")
+		return "", "", fmt.Errorf("no response from OpenAI")
 	}
 
-	content := resp.Choices[0].Message.Content
-	log.Printf("[AI] Received response from OpenAI\n")
+	telemetry.Logf(ctx, "[AI] Received response from OpenAI\n")
+	return resp.Choices[0].Message.Content, resp.SystemFingerprint, nil
+}
 
-	// Parse the JSON response
-	aiResponse, err := a.parseResponse(content)
+// checkFingerprint logs the backend's system fingerprint, warning when it differs from the
+// previous response's: with a fixed seed, outputs are only reproducible on the same backend
+func (a *Analyzer) checkFingerprint(ctx context.Context, fingerprint string) {
+	if fingerprint == "" {
+		return
+	}
+
+	a.fingerprintMu.Lock()
+	previous := a.lastFingerprint
+	a.lastFingerprint = fingerprint
+	a.fingerprintMu.Unlock()
+
+	switch {
+	case previous == "":
+		telemetry.Logf(ctx, "[AI] Model backend fingerprint: %s\n", fingerprint)
+	case previous != fingerprint:
+		telemetry.Logf(ctx, "[AI] ⚠️  Model backend changed: fingerprint %s -> %s\n", previous, fingerprint)
+	}
+}
+
+// requestTemperature returns the temperature to send. The client drops a zero temperature
+// from the request (so the API would use its default of 1), so zero is sent as the
+// smallest non-zero value instead.
+func (a *Analyzer) requestTemperature() float32 {
+	if a.temperature == 0 {
+		return math.SmallestNonzeroFloat32
+	}
+	return a.temperature
+}
+
+// BudgetStatus returns daily token budget usage, or nil when no budget is set
+func (a *Analyzer) BudgetStatus() map[string]interface{} {
+	if a.budget == nil {
+		return nil
+	}
+	return a.budget.status()
+}
+
+// buildMessages assembles the chat messages according to the configured prompt mode
+func (a *Analyzer) buildMessages(incident *models.Incident, previousAttempts []models.Resolution) ([]openai.ChatCompletionMessage, error) {
+	prompt, err := a.buildPrompt(incident, previousAttempts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse AI response: %w", err)
+		return nil, err
 	}
 
-	log.Printf("[AI] Diagnosis: %s\n", aiResponse.Diagnosis)
-	log.Printf("[AI] Fix Type: %s\n", aiResponse.FixType)
+	if a.promptMode == PromptModeCombined {
+		return []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: a.getSystemPrompt() + "\n\n" + prompt,
+			},
+		}, nil
+	}
 
-	return aiResponse, nil
+	return []openai.ChatCompletionMessage{
+		{
+			Role:    openai.ChatMessageRoleSystem,
+			Content: a.getSystemPrompt(),
+		},
+		{
+			Role:    openai.ChatMessageRoleUser,
+			Content: prompt,
+		},
+	}, nil
 }
 
 func (a *Analyzer) getSystemPrompt() string {
@@ -88,92 +301,63 @@ You must respond ONLY with valid JSON in this exact format:
   "fix_type": "restart|config|code",
   "fix_steps": ["Step 1", "Step 2", ...],
   "code": "Any Go code needed (only if fix_type is code)",
-  "confidence": 0.95
+  "config_changes": {"config_key": "correct value"},
+  "confidence": 0.95,
+  "corrected_type": "Optional - only if the detected incident type is wrong",
+  "root_cause_category": "resource|config|dependency|code-bug|external",
+  "recommendations": ["Optional preventive measure", ...]
 }
 
 Rules:
 - fix_type must be one of: "restart", "config", "code"
 - For restart: service just needs to be restarted
-- For config: configuration needs to be corrected (provide correct values in fix_steps)
+- For config: configuration needs to be corrected (put every key to set and its correct value in config_changes, using the service's config keys, and describe the changes in fix_steps)
 - For code: actual code changes needed (provide Go code in "code" field)
+- corrected_type, if given, must be one of: "SERVICE_DOWN", "CONFIG_ERROR", "RESOURCE_EXHAUSTION", "DEPENDENCY_FAILURE", "FLAPPING", "DEGRADED"; omit it when the detected type is right
+- root_cause_category must be one of: "resource", "config", "dependency", "code-bug", "external" ("external" means a cause outside the service and its dependencies, e.g. the network or the host)
+- recommendations are optional preventive measures that would stop this kind of incident recurring (e.g. "add a retry budget", "set a memory limit"); they are reviewed by operators later and never applied, so keep remediation in fix_steps
 - Be concise but complete
 - Only respond with JSON, no additional text`
 }
 
-func (a *Analyzer) buildPrompt(incident *models.Incident) string {
-	var sb strings.Builder
-
-	sb.WriteString("# INCIDENT ANALYSIS REQUEST\n\n")
-	sb.WriteString("## Service Information\n")
-	sb.WriteString("- Service Type: HTTP REST API\n")
-	sb.WriteString("- Language: Go\n")
-	sb.WriteString("- Port: 8080\n\n")
-
-	sb.WriteString("## Incident Details\n")
-	sb.WriteString(fmt.Sprintf("- Incident ID: %s\n", incident.ID))
-	sb.WriteString(fmt.Sprintf("- Type: %s\n", incident.Type))
-	sb.WriteString(fmt.Sprintf("- Detected At: %s\n\n", incident.DetectedAt.Format("2006-01-02 15:04:05")))
-
-	sb.WriteString("## Symptoms\n")
-	if len(incident.Symptoms) > 0 {
-		for i, symptom := range incident.Symptoms {
-			sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, symptom))
-		}
-	} else {
-		sb.WriteString("No specific symptoms recorded\n")
+// buildPrompt renders the prompt template registered for the incident's type
+func (a *Analyzer) buildPrompt(incident *models.Incident, previousAttempts []models.Resolution) (string, error) {
+	prompt, err := renderPrompt(incident, a.serviceURL, previousAttempts)
+	if err != nil {
+		return "", fmt.Errorf("failed to render %s prompt: %w", incident.Type, err)
 	}
-	sb.WriteString("\n")
-
-	sb.WriteString("## Recent Logs\n")
-	if len(incident.Logs) > 0 {
-		sb.WriteString("```\n")
-		for _, log := range incident.Logs {
-			sb.WriteString(log + "\n")
-		}
-		sb.WriteString("```\n")
-	} else {
-		sb.WriteString("No recent logs available\n")
-	}
-	sb.WriteString("\n")
-
-	sb.WriteString("## Current Configuration\n")
-	sb.WriteString("```json\n")
-	sb.WriteString("{\n")
-	sb.WriteString(`  "database_url": "localhost:5432",` + "\n")
-	sb.WriteString(`  "timeout": "30s",` + "\n")
-	sb.WriteString(`  "max_retries": "3"` + "\n")
-	sb.WriteString("}\n")
-	sb.WriteString("```\n\n")
-
-	sb.WriteString("## Your Task\n")
-	sb.WriteString("Analyze this incident and provide a JSON response with:\n")
-	sb.WriteString("1. Root cause diagnosis\n")
-	sb.WriteString("2. Fix type (restart/config/code)\n")
-	sb.WriteString("3. Detailed fix steps\n")
-	sb.WriteString("4. Any code needed\n")
-	sb.WriteString("5. Your confidence level (0-1)\n\n")
-
-	sb.WriteString("Respond ONLY with valid JSON. No markdown, no explanations outside the JSON.")
-
-	return sb.String()
+	return prompt, nil
 }
 
-func (a *Analyzer) parseResponse(content string) (*models.AIResponse, error) {
-	// Clean up the response - remove markdown code blocks if present
-	content = strings.TrimSpace(content)
-	content = strings.TrimPrefix(content, "```json")
-	content = strings.TrimPrefix(content, "```")
-	content = strings.TrimSuffix(content, "```")
-	content = strings.TrimSpace(content)
+// parseResponse normalizes the content with the provider's normalizer, then unmarshals
+// and validates it
+func (a *Analyzer) parseResponse(ctx context.Context, content string, normalize ResponseNormalizer) (*models.AIResponse, error) {
+	content, err := normalize(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to normalize response: %w", err)
+	}
 
-	var response models.AIResponse
+	var response analysisJSON
 	if err := json.Unmarshal([]byte(content), &response); err != nil {
 		// Log the problematic content for debugging
-		log.Printf("[AI] Failed to parse response: %s\n", content)
+		telemetry.Logf(ctx, "[AI] Failed to parse response: %s\n", content)
 		return nil, fmt.Errorf("JSON parsing error: %w", err)
 	}
 
-	// Validate the response
+	return a.validateResponse(ctx, &response)
+}
+
+// analysisJSON is an analysis as the model returns it. Confidence is decoded separately so
+// a missing confidence can be told from an explicit 0.
+type analysisJSON struct {
+	models.AIResponse
+	Confidence *float64 `json:"confidence"`
+}
+
+// validateResponse checks a parsed response's required fields and cleans up the optional
+// ones, filling in defaults when lenient
+func (a *Analyzer) validateResponse(ctx context.Context, analysis *analysisJSON) (*models.AIResponse, error) {
+	response := &analysis.AIResponse
 	if response.Diagnosis == "" {
 		return nil, fmt.Errorf("missing diagnosis in AI response")
 	}
@@ -188,15 +372,102 @@ func (a *Analyzer) parseResponse(content string) (*models.AIResponse, error) {
 	}
 
 	if len(response.FixSteps) == 0 {
-		return nil, fmt.Errorf("missing fix_steps in AI response")
+		if !a.lenient || response.FixType != "restart" {
+			return nil, fmt.Errorf("missing fix_steps in AI response")
+		}
+		telemetry.Logln(ctx, "[AI] ⚠️  Response missing fix_steps - using default restart steps")
+		response.FixSteps = append([]string(nil), defaultRestartSteps...)
+	}
+
+	if response.CorrectedType != "" && !response.CorrectedType.IsValid() {
+		telemetry.Logf(ctx, "[AI] ⚠️  Ignoring unknown corrected_type: %s\n", response.CorrectedType)
+		response.CorrectedType = ""
+	}
+
+	if response.RootCauseCategory != "" && !response.RootCauseCategory.IsValid() {
+		telemetry.Logf(ctx, "[AI] ⚠️  Ignoring unknown root_cause_category: %s\n", response.RootCauseCategory)
+		response.RootCauseCategory = ""
+	}
+
+	response.Recommendations = cleanRecommendations(response.Recommendations)
+	response.ConfigChanges = cleanConfigChanges(ctx, response.FixType, response.ConfigChanges)
+
+	switch {
+	case analysis.Confidence != nil:
+		response.Confidence = *analysis.Confidence
+	case a.lenient:
+		telemetry.Logf(ctx, "[AI] ⚠️  Response missing confidence - defaulting to %.2f\n", a.defaultConfidence)
+		response.Confidence = a.defaultConfidence
+	}
+
+	return response, nil
+}
+
+// cleanRecommendations trims recommendations and drops blank ones. Recommendations are
+// optional, so an empty result is not an error.
+func cleanRecommendations(recommendations []string) []string {
+	var cleaned []string
+	for _, r := range recommendations {
+		if r = strings.TrimSpace(r); r != "" {
+			cleaned = append(cleaned, r)
+		}
+	}
+	return cleaned
+}
+
+// cleanConfigChanges trims config keys and drops blank ones. Only config fixes change config,
+// so changes suggested with any other fix type are dropped.
+func cleanConfigChanges(ctx context.Context, fixType string, changes map[string]string) map[string]string {
+	if len(changes) == 0 {
+		return nil
+	}
+	if fixType != "config" {
+		telemetry.Logf(ctx, "[AI] ⚠️  Ignoring config_changes on a %s fix\n", fixType)
+		return nil
+	}
+
+	cleaned := make(map[string]string, len(changes))
+	for key, value := range changes {
+		if key = strings.TrimSpace(key); key != "" {
+			cleaned[key] = strings.TrimSpace(value)
+		}
+	}
+	if len(cleaned) == 0 {
+		return nil
+	}
+	return cleaned
+}
+
+// GetQuickAnalysis provides a simpler, faster analysis (useful for testing). lastFix, when
+// given, is the fix that last resolved an incident of this type; its steps are proposed
+// instead of the canned ones, keeping the canned diagnosis. confidence, when positive,
+// replaces the canned confidence, e.g. with one calibrated against past outcomes.
+func (a *Analyzer) GetQuickAnalysis(incident *models.Incident, lastFix *models.Resolution, confidence float64) *models.AIResponse {
+	response := cannedAnalysis(incident)
+	if confidence > 0 {
+		response.Confidence = confidence
+	}
+	if lastFix == nil {
+		return response
 	}
 
-	return &response, nil
+	response.Diagnosis += " (reusing the fix that last resolved this incident type)"
+	response.FixType = lastFix.FixType
+	response.FixSteps = append([]string(nil), lastFix.Steps...)
+	response.Code = lastFix.Code
+	response.ConfigChanges = nil
+	if lastFix.FixType == "config" {
+		// Fixes resolved before structured changes existed only record what they applied
+		response.ConfigChanges = lastFix.ConfigChanges
+		if len(response.ConfigChanges) == 0 {
+			response.ConfigChanges = lastFix.AppliedConfig
+		}
+	}
+	return response
 }
 
-// GetQuickAnalysis provides a simpler, faster analysis (useful for testing)
-func (a *Analyzer) GetQuickAnalysis(incident *models.Incident) *models.AIResponse {
-	// Fallback analysis based on incident type
+// cannedAnalysis returns the rule-based analysis for an incident's type
+func cannedAnalysis(incident *models.Incident) *models.AIResponse {
 	switch incident.Type {
 	case models.ServiceDown:
 		return &models.AIResponse{
@@ -207,7 +478,8 @@ func (a *Analyzer) GetQuickAnalysis(incident *models.Incident) *models.AIRespons
 				"Restart the service process",
 				"Verify health check passes",
 			},
-			Confidence: 0.9,
+			Confidence:        0.9,
+			RootCauseCategory: models.CauseCodeBug,
 		}
 
 	case models.ConfigError:
@@ -219,7 +491,9 @@ func (a *Analyzer) GetQuickAnalysis(incident *models.Incident) *models.AIRespons
 				"Reset timeout to '30s'",
 				"Restart service to apply changes",
 			},
-			Confidence: 0.85,
+			ConfigChanges:     map[string]string{"database_url": "localhost:5432", "timeout": "30s"},
+			Confidence:        0.85,
+			RootCauseCategory: models.CauseConfig,
 		}
 
 	case models.DependencyFailure:
@@ -231,10 +505,40 @@ func (a *Analyzer) GetQuickAnalysis(incident *models.Incident) *models.AIRespons
 				"Verify database is running",
 				"Restart service to reconnect",
 			},
-			Confidence: 0.8,
+			Confidence:        0.8,
+			RootCauseCategory: models.CauseDependency,
 		}
 
 	case models.ResourceExhaustion:
+		switch resourceKind(incident) {
+		case resourceMemory:
+			return &models.AIResponse{
+				Diagnosis: "Memory exhausted - likely a leak or unbounded request backlog, which a restart alone won't fix",
+				FixType:   "config",
+				FixSteps: []string{
+					"Reset max_retries to '3' to stop retries piling up in memory",
+					"Reset timeout to '30s' so stalled requests release their memory",
+					"Restart service to free memory and apply changes",
+				},
+				ConfigChanges:     map[string]string{"max_retries": "3", "timeout": "30s"},
+				Confidence:        0.7,
+				RootCauseCategory: models.CauseResource,
+			}
+
+		case resourcePort:
+			return &models.AIResponse{
+				Diagnosis: "Service port is blocked or already in use",
+				FixType:   "restart",
+				FixSteps: []string{
+					"Stop the service to release its listener",
+					"Wait for the blocked port to be freed",
+					"Restart service on the cleared port",
+				},
+				Confidence:        0.8,
+				RootCauseCategory: models.CauseResource,
+			}
+		}
+
 		return &models.AIResponse{
 			Diagnosis: "System resources exhausted (port blocked or memory full)",
 			FixType:   "restart",
@@ -243,7 +547,31 @@ func (a *Analyzer) GetQuickAnalysis(incident *models.Incident) *models.AIRespons
 				"Clear any blocked resources",
 				"Restart service on clean port",
 			},
-			Confidence: 0.75,
+			Confidence:        0.75,
+			RootCauseCategory: models.CauseResource,
+		}
+
+	case models.Flapping:
+		return &models.AIResponse{
+			Diagnosis: "Service is repeatedly toggling between healthy and unhealthy",
+			FixType:   "restart",
+			FixSteps: []string{
+				"Stop the service to break the flapping cycle",
+				"Restart the service process",
+				"Monitor health checks for stability",
+			},
+			Confidence: 0.6,
+		}
+
+	case models.Degraded:
+		return &models.AIResponse{
+			Diagnosis: "Service is serving but reports degraded health",
+			FixType:   "restart",
+			FixSteps: []string{
+				"Restart the service to clear the impaired state",
+				"Verify health check reports healthy rather than degraded",
+			},
+			Confidence: 0.6,
 		}
 
 	default:
@@ -258,3 +586,44 @@ func (a *Analyzer) GetQuickAnalysis(incident *models.Incident) *models.AIRespons
 		}
 	}
 }
+
+// resource is the kind of resource a resource exhaustion incident ran out of
+type resource int
+
+const (
+	resourceUnknown resource = iota
+	resourceMemory
+	resourcePort
+)
+
+var (
+	memorySignals = []string{"memory", "heap", "oomkilled"}
+	portSignals   = []string{"port blocked", "port in use", "address already in use", "bind:"}
+)
+
+// resourceKind inspects an incident's symptoms and logs to tell memory exhaustion from a
+// blocked port. Evidence of both, or neither, is reported as unknown.
+func resourceKind(incident *models.Incident) resource {
+	text := strings.ToLower(strings.Join(append(append([]string{}, incident.Symptoms...), incident.Logs...), "\n"))
+
+	memory := containsAny(text, memorySignals)
+	port := containsAny(text, portSignals)
+
+	switch {
+	case memory && !port:
+		return resourceMemory
+	case port && !memory:
+		return resourcePort
+	default:
+		return resourceUnknown
+	}
+}
+
+func containsAny(s string, substrs []string) bool {
+	for _, substr := range substrs {
+		if strings.Contains(s, substr) {
+			return true
+		}
+	}
+	return false
+}
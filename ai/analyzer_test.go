@@ -0,0 +1,547 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"incident-ai/models"
+	"maps"
+	"strings"
+	"testing"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// cannedResponse is a valid analysis as the model would return it
+const cannedResponse = `{
+  "diagnosis": "Connection pool exhausted by leaked connections",
+  "fix_type": "config",
+  "fix_steps": ["Set max_connections to 200", "Restart the service"],
+  "config_changes": {"max_connections": "200"},
+  "confidence": 0.87,
+  "root_cause_category": "resource",
+  "recommendations": ["  Alert on pool usage  ", ""]
+}`
+
+// testIncident returns an incident with enough detail to fill every prompt section
+func testIncident() *models.Incident {
+	return &models.Incident{
+		ID:            "incident-1",
+		Type:          models.ResourceExhaustion,
+		Severity:      models.SeverityHigh,
+		DetectedAt:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Symptoms:      []string{"Health check returned status code: 503", "Connection pool at 100%"},
+		Logs:          []string{"ERROR: too many connections", "WARN: pool exhausted"},
+		ServiceConfig: map[string]string{"max_connections": "10", "timeout": "30s"},
+	}
+}
+
+func TestAnalyzeIncidentWithCompletionFunc(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     error
+		wantErr string
+	}{
+		{name: "canned response", content: cannedResponse},
+		{name: "code fence and prose", content: "Here you go:\n```json\n" + cannedResponse + "\n```"},
+		{name: "missing diagnosis", content: `{"fix_type": "restart", "fix_steps": ["Restart"]}`, wantErr: "missing diagnosis"},
+		{name: "invalid fix type", content: `{"diagnosis": "x", "fix_type": "reboot", "fix_steps": ["Reboot"]}`, wantErr: "invalid fix_type"},
+		{name: "completion error", err: errors.New("backend down"), wantErr: "backend down"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got openai.ChatCompletionRequest
+			analyzer := NewAnalyzer("test-key",
+				WithServiceURL("http://orders:8080"),
+				WithCompletionFunc(func(ctx context.Context, req openai.ChatCompletionRequest) (string, error) {
+					got = req
+					return tt.content, tt.err
+				}))
+
+			response, err := analyzer.AnalyzeIncident(context.Background(), testIncident(), nil)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AnalyzeIncident: %v", err)
+			}
+
+			if response.FixType != "config" || response.Confidence != 0.87 || response.ConfigChanges["max_connections"] != "200" {
+				t.Errorf("response = %+v", response)
+			}
+			if len(response.Recommendations) != 1 || response.Recommendations[0] != "Alert on pool usage" {
+				t.Errorf("recommendations = %q, want the blank one dropped and the other trimmed", response.Recommendations)
+			}
+
+			// The injected func gets the request the OpenAI call would have made
+			if len(got.Messages) != 2 || got.Messages[0].Role != openai.ChatMessageRoleSystem {
+				t.Fatalf("messages = %+v, want a system and a user message", got.Messages)
+			}
+			prompt := got.Messages[1].Content
+			for _, want := range []string{"incident-1", "RESOURCE_EXHAUSTION", "Connection pool at 100%", "max_connections", "http://orders:8080"} {
+				if !strings.Contains(prompt, want) {
+					t.Errorf("prompt doesn't mention %q:\n%s", want, prompt)
+				}
+			}
+		})
+	}
+}
+
+func TestLenientParsing(t *testing.T) {
+	tests := []struct {
+		name           string
+		lenient        bool
+		content        string
+		wantErr        string
+		wantStep       []string
+		wantConfidence float64
+	}{
+		{
+			name:    "strict: restart without steps",
+			content: `{"diagnosis": "crashed", "fix_type": "restart", "confidence": 0.8}`,
+			wantErr: "missing fix_steps",
+		},
+		{
+			name:           "lenient: restart without steps",
+			lenient:        true,
+			content:        `{"diagnosis": "crashed", "fix_type": "restart", "confidence": 0.8}`,
+			wantStep:       defaultRestartSteps,
+			wantConfidence: 0.8,
+		},
+		{
+			name:    "lenient: config fix without steps",
+			lenient: true,
+			content: `{"diagnosis": "bad timeout", "fix_type": "config", "confidence": 0.8}`,
+			wantErr: "missing fix_steps",
+		},
+		{
+			name:     "strict: no confidence",
+			content:  `{"diagnosis": "crashed", "fix_type": "restart", "fix_steps": ["Restart"]}`,
+			wantStep: []string{"Restart"},
+		},
+		{
+			name:           "lenient: no confidence",
+			lenient:        true,
+			content:        `{"diagnosis": "crashed", "fix_type": "restart", "fix_steps": ["Restart"]}`,
+			wantStep:       []string{"Restart"},
+			wantConfidence: 0.6,
+		},
+		{
+			name:     "lenient: explicit zero confidence",
+			lenient:  true,
+			content:  `{"diagnosis": "crashed", "fix_type": "restart", "fix_steps": ["Restart"], "confidence": 0}`,
+			wantStep: []string{"Restart"},
+		},
+		{
+			name:    "lenient: no diagnosis",
+			lenient: true,
+			content: `{"fix_type": "restart", "fix_steps": ["Restart"]}`,
+			wantErr: "missing diagnosis",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []Option{WithCompletionFunc(func(ctx context.Context, req openai.ChatCompletionRequest) (string, error) {
+				return tt.content, nil
+			})}
+			if tt.lenient {
+				opts = append(opts, WithLenientParsing(0.6))
+			}
+			analyzer := NewAnalyzer("test-key", opts...)
+
+			response, err := analyzer.AnalyzeIncident(context.Background(), testIncident(), nil)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AnalyzeIncident: %v", err)
+			}
+			if strings.Join(response.FixSteps, "|") != strings.Join(tt.wantStep, "|") || response.Confidence != tt.wantConfidence {
+				t.Errorf("steps %q, confidence %v; want %q, %v", response.FixSteps, response.Confidence, tt.wantStep, tt.wantConfidence)
+			}
+		})
+	}
+}
+
+func TestPromptModes(t *testing.T) {
+	tests := []struct {
+		mode      PromptMode
+		wantRoles []string
+	}{
+		{mode: PromptModeSeparate, wantRoles: []string{openai.ChatMessageRoleSystem, openai.ChatMessageRoleUser}},
+		{mode: PromptModeCombined, wantRoles: []string{openai.ChatMessageRoleUser}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			if mode, err := ParsePromptMode(string(tt.mode)); err != nil || mode != tt.mode {
+				t.Fatalf("ParsePromptMode(%q) = %q, %v", tt.mode, mode, err)
+			}
+
+			var got openai.ChatCompletionRequest
+			analyzer := NewAnalyzer("test-key", WithPromptMode(tt.mode),
+				WithCompletionFunc(func(ctx context.Context, req openai.ChatCompletionRequest) (string, error) {
+					got = req
+					return cannedResponse, nil
+				}))
+			if _, err := analyzer.AnalyzeIncident(context.Background(), testIncident(), nil); err != nil {
+				t.Fatalf("AnalyzeIncident: %v", err)
+			}
+
+			var roles []string
+			for _, message := range got.Messages {
+				roles = append(roles, message.Role)
+			}
+			if strings.Join(roles, ",") != strings.Join(tt.wantRoles, ",") {
+				t.Fatalf("message roles = %v, want %v", roles, tt.wantRoles)
+			}
+
+			// Either way the model gets the instructions and then the incident
+			content := got.Messages[0].Content
+			if len(got.Messages) > 1 {
+				content += "\n\n" + got.Messages[1].Content
+			}
+			if !strings.HasPrefix(content, analyzer.getSystemPrompt()) || !strings.Contains(content, "incident-1") {
+				t.Errorf("messages don't hold the system prompt followed by the incident prompt:\n%s", content)
+			}
+		})
+	}
+
+	if _, err := ParsePromptMode("system-only"); err == nil {
+		t.Error("ParsePromptMode accepted an unknown mode")
+	}
+}
+
+func TestAnalysisTimeout(t *testing.T) {
+	const timeout = 50 * time.Millisecond
+
+	// The model never answers; only the call's own context ends it
+	analyzer := NewAnalyzer("test-key", WithAnalysisTimeout(timeout),
+		WithCompletionFunc(func(ctx context.Context, req openai.ChatCompletionRequest) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}))
+
+	start := time.Now()
+	_, err := analyzer.AnalyzeIncident(context.Background(), testIncident(), nil)
+	if !errors.Is(err, ErrAnalysisTimeout) {
+		t.Fatalf("error = %v, want %v", err, ErrAnalysisTimeout)
+	}
+	if elapsed := time.Since(start); elapsed > 10*timeout {
+		t.Errorf("analysis gave up after %v, want about %v", elapsed, timeout)
+	}
+
+	// The caller's own cancellation isn't reported as the analysis timing out
+	ctx, cancel := context.WithTimeout(context.Background(), timeout/5)
+	defer cancel()
+	if _, err := analyzer.AnalyzeIncident(ctx, testIncident(), nil); err == nil || errors.Is(err, ErrAnalysisTimeout) {
+		t.Errorf("error after the caller's deadline = %v, want a plain cancellation", err)
+	}
+}
+
+func BenchmarkBuildPromptAndParse(b *testing.B) {
+	analyzer := NewAnalyzer("test-key")
+	incident := testIncident()
+	previous := []models.Resolution{{FixType: "restart", Steps: []string{"Restart the service"}}}
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := analyzer.buildPrompt(incident, previous); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := analyzer.parseResponse(ctx, cannedResponse, analyzer.normalizer); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestCorrectedType(t *testing.T) {
+	tests := []struct {
+		name      string
+		corrected string
+		want      models.IncidentType
+	}{
+		{name: "known type", corrected: "CONFIG_ERROR", want: models.ConfigError},
+		{name: "unknown type ignored", corrected: "COSMIC_RAY", want: ""},
+		{name: "omitted", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := `{"diagnosis": "Bad database URL", "fix_type": "config", "fix_steps": ["Fix the URL"], "confidence": 0.8}`
+			if tt.corrected != "" {
+				content = strings.Replace(content, `"confidence"`, `"corrected_type": "`+tt.corrected+`", "confidence"`, 1)
+			}
+			analyzer := NewAnalyzer("test-key", WithCompletionFunc(func(ctx context.Context, req openai.ChatCompletionRequest) (string, error) {
+				return content, nil
+			}))
+
+			response, err := analyzer.AnalyzeIncident(context.Background(), testIncident(), nil)
+			if err != nil {
+				t.Fatalf("AnalyzeIncident: %v", err)
+			}
+			if response.CorrectedType != tt.want {
+				t.Errorf("corrected type = %q, want %q", response.CorrectedType, tt.want)
+			}
+		})
+	}
+}
+
+func TestRootCauseCategory(t *testing.T) {
+	tests := []struct {
+		name     string
+		category string
+		want     models.RootCauseCategory
+	}{
+		{name: "known category", category: "dependency", want: models.CauseDependency},
+		{name: "hyphenated category", category: "code-bug", want: models.CauseCodeBug},
+		{name: "unknown category ignored", category: "gremlins", want: ""},
+		{name: "omitted", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var systemPrompt string
+			content := `{"diagnosis": "Database unreachable", "fix_type": "restart", "fix_steps": ["Restart the service"], "confidence": 0.8}`
+			if tt.category != "" {
+				content = strings.Replace(content, `"confidence"`, `"root_cause_category": "`+tt.category+`", "confidence"`, 1)
+			}
+			analyzer := NewAnalyzer("test-key", WithCompletionFunc(func(ctx context.Context, req openai.ChatCompletionRequest) (string, error) {
+				systemPrompt = req.Messages[0].Content
+				return content, nil
+			}))
+
+			response, err := analyzer.AnalyzeIncident(context.Background(), testIncident(), nil)
+			if err != nil {
+				t.Fatalf("AnalyzeIncident: %v", err)
+			}
+			if response.RootCauseCategory != tt.want {
+				t.Errorf("root cause category = %q, want %q", response.RootCauseCategory, tt.want)
+			}
+			for _, category := range models.RootCauseCategories {
+				if !strings.Contains(systemPrompt, `"`+string(category)+`"`) {
+					t.Errorf("system prompt doesn't offer the %s category", category)
+				}
+			}
+		})
+	}
+}
+
+func TestRecommendationsOptional(t *testing.T) {
+	var systemPrompt string
+	analyzer := NewAnalyzer("test-key", WithCompletionFunc(func(ctx context.Context, req openai.ChatCompletionRequest) (string, error) {
+		systemPrompt = req.Messages[0].Content
+		return `{"diagnosis": "Database unreachable", "fix_type": "restart", "fix_steps": ["Restart the service"], "confidence": 0.8}`, nil
+	}))
+
+	response, err := analyzer.AnalyzeIncident(context.Background(), testIncident(), nil)
+	if err != nil {
+		t.Fatalf("AnalyzeIncident without recommendations: %v", err)
+	}
+	if response.Recommendations != nil {
+		t.Errorf("recommendations = %q, want none", response.Recommendations)
+	}
+	if !strings.Contains(systemPrompt, `"recommendations"`) || !strings.Contains(systemPrompt, "never applied") {
+		t.Errorf("system prompt doesn't ask for recommendations that are never applied:\n%s", systemPrompt)
+	}
+}
+
+func TestPreviousAttemptsInPrompt(t *testing.T) {
+	failed := models.Resolution{
+		FixType:     "restart",
+		Description: "Service process crashed",
+		Steps:       []string{"Restart the orders service"},
+	}
+
+	for _, attempts := range [][]models.Resolution{nil, {failed}} {
+		var prompt string
+		analyzer := NewAnalyzer("test-key", WithCompletionFunc(func(ctx context.Context, req openai.ChatCompletionRequest) (string, error) {
+			prompt = req.Messages[len(req.Messages)-1].Content
+			return cannedResponse, nil
+		}))
+		if _, err := analyzer.AnalyzeIncident(context.Background(), testIncident(), attempts); err != nil {
+			t.Fatalf("AnalyzeIncident: %v", err)
+		}
+
+		hasSection := strings.Contains(prompt, "Previously Attempted Fixes")
+		if len(attempts) == 0 {
+			if hasSection {
+				t.Errorf("prompt without previous attempts lists some:\n%s", prompt)
+			}
+			continue
+		}
+		for _, want := range []string{"already tried for this incident and FAILED", "1. Fix type: restart", "Diagnosis: Service process crashed", "- Restart the orders service"} {
+			if !hasSection || !strings.Contains(prompt, want) {
+				t.Errorf("prompt doesn't contain %q:\n%s", want, prompt)
+			}
+		}
+	}
+}
+
+func TestQuickAnalysisOfResourceExhaustion(t *testing.T) {
+	tests := []struct {
+		name     string
+		symptoms []string
+		logs     []string
+		wantFix  string
+		wantStep string // expected in the first fix step
+	}{
+		{
+			name:     "memory",
+			symptoms: []string{"Health check returned status code: 503", "Memory usage at 98%"},
+			logs:     []string{"ERROR: heap allocation failed"},
+			wantFix:  "config",
+			wantStep: "max_retries",
+		},
+		{
+			name:     "port blocked",
+			symptoms: []string{"Health check failed"},
+			logs:     []string{"listen tcp :8080: bind: address already in use"},
+			wantFix:  "restart",
+			wantStep: "release its listener",
+		},
+		{
+			name:     "both",
+			symptoms: []string{"Memory usage at 98%", "Port in use"},
+			wantFix:  "restart",
+			wantStep: "Stop the service",
+		},
+		{
+			name:     "neither",
+			symptoms: []string{"Health check returned status code: 503"},
+			wantFix:  "restart",
+			wantStep: "Stop the service",
+		},
+	}
+
+	analyzer := NewAnalyzer("test-key")
+	for _, tt := range tests {
+		incident := &models.Incident{ID: "incident-1", Type: models.ResourceExhaustion, Symptoms: tt.symptoms, Logs: tt.logs}
+		response := analyzer.GetQuickAnalysis(incident, nil, 0)
+
+		if response.FixType != tt.wantFix || !strings.Contains(response.FixSteps[0], tt.wantStep) {
+			t.Errorf("%s: %s fix %q, want a %s fix starting with %q", tt.name, response.FixType, response.FixSteps, tt.wantFix, tt.wantStep)
+		}
+		if tt.wantFix == "config" && response.ConfigChanges["max_retries"] != "3" {
+			t.Errorf("%s: config changes = %v, want max_retries reset", tt.name, response.ConfigChanges)
+		}
+		if response.RootCauseCategory != models.CauseResource {
+			t.Errorf("%s: root cause = %q, want %q", tt.name, response.RootCauseCategory, models.CauseResource)
+		}
+	}
+}
+
+func TestQuickAnalysisReusesLastFix(t *testing.T) {
+	tests := []struct {
+		name        string
+		incident    models.IncidentType
+		lastFix     *models.Resolution
+		wantFix     string
+		wantStep    string
+		wantChanges map[string]string
+	}{
+		{
+			name:     "no history",
+			incident: models.ServiceDown,
+			wantFix:  "restart",
+			wantStep: "Stop the service if it's still partially running",
+		},
+		{
+			name:     "restart that worked",
+			incident: models.ServiceDown,
+			lastFix:  &models.Resolution{FixType: "restart", Steps: []string{"Drain traffic", "Restart the service"}, Success: true},
+			wantFix:  "restart",
+			wantStep: "Drain traffic",
+		},
+		{
+			name:        "config fix",
+			incident:    models.ConfigError,
+			lastFix:     &models.Resolution{FixType: "config", Steps: []string{"Set timeout to 60s"}, ConfigChanges: map[string]string{"timeout": "60s"}, Success: true},
+			wantFix:     "config",
+			wantStep:    "Set timeout to 60s",
+			wantChanges: map[string]string{"timeout": "60s"},
+		},
+		{
+			name:        "config fix recording only what it applied",
+			incident:    models.ConfigError,
+			lastFix:     &models.Resolution{FixType: "config", Steps: []string{"Fix the database URL"}, AppliedConfig: map[string]string{"database_url": "db:5432"}, Success: true},
+			wantFix:     "config",
+			wantStep:    "Fix the database URL",
+			wantChanges: map[string]string{"database_url": "db:5432"},
+		},
+		{
+			name:     "restart for a config incident",
+			incident: models.ConfigError,
+			lastFix:  &models.Resolution{FixType: "restart", Steps: []string{"Restart the service"}, Success: true},
+			wantFix:  "restart",
+			wantStep: "Restart the service",
+		},
+	}
+
+	analyzer := NewAnalyzer("test-key")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			incident := &models.Incident{ID: "incident-1", Type: tt.incident}
+			canned := analyzer.GetQuickAnalysis(incident, nil, 0)
+			response := analyzer.GetQuickAnalysis(incident, tt.lastFix, 0)
+
+			if response.FixType != tt.wantFix || response.FixSteps[0] != tt.wantStep {
+				t.Errorf("%s fix %q, want a %s fix starting with %q", response.FixType, response.FixSteps, tt.wantFix, tt.wantStep)
+			}
+			if !maps.Equal(response.ConfigChanges, tt.wantChanges) {
+				t.Errorf("config changes = %v, want %v", response.ConfigChanges, tt.wantChanges)
+			}
+			if tt.lastFix == nil {
+				return
+			}
+
+			// The canned diagnosis and confidence are kept, noting where the steps came from
+			if !strings.HasPrefix(response.Diagnosis, canned.Diagnosis) || !strings.Contains(response.Diagnosis, "last resolved") {
+				t.Errorf("diagnosis = %q, want the canned one noting the reused fix", response.Diagnosis)
+			}
+			if response.Confidence != canned.Confidence {
+				t.Errorf("confidence = %v, want the canned %v", response.Confidence, canned.Confidence)
+			}
+
+			// The response doesn't share the stored fix's steps
+			response.FixSteps[0] = "changed"
+			if tt.lastFix.Steps[0] != tt.wantStep {
+				t.Error("changing the proposed steps changed the last fix")
+			}
+		})
+	}
+}
+
+func TestQuickAnalysisCalibratedConfidence(t *testing.T) {
+	analyzer := NewAnalyzer("test-key")
+	incident := &models.Incident{ID: "incident-1", Type: models.ServiceDown}
+	lastFix := &models.Resolution{FixType: "restart", Steps: []string{"Restart the service"}, Success: true}
+	canned := analyzer.GetQuickAnalysis(incident, nil, 0).Confidence
+
+	tests := []struct {
+		name       string
+		lastFix    *models.Resolution
+		confidence float64
+		want       float64
+	}{
+		{name: "uncalibrated", want: canned},
+		{name: "calibrated", confidence: 0.6, want: 0.6},
+		{name: "calibrated with history", lastFix: lastFix, confidence: 0.6, want: 0.6},
+	}
+
+	for _, tt := range tests {
+		if got := analyzer.GetQuickAnalysis(incident, tt.lastFix, tt.confidence).Confidence; got != tt.want {
+			t.Errorf("%s: confidence = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
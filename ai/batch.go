@@ -0,0 +1,176 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"incident-ai/models"
+	"incident-ai/telemetry"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// batchInstructions replace each incident's own task section when several incidents are
+// analyzed in one request
+const batchInstructions = `## Your Task
+The incidents above were detected on the same service at about the same time and may share a cause.
+Analyze each one and respond with a JSON object holding one analysis per incident:
+{
+  "analyses": [
+    {"incident_id": "ID of the incident this analysis is for", "diagnosis": "...", "fix_type": "...", ...},
+    ...
+  ]
+}
+
+Each analysis has the incident_id plus every field of the single-incident format. Give exactly one analysis per incident.
+
+Respond ONLY with valid JSON. No markdown, no explanations outside the JSON.`
+
+// batchAnalysis is one incident's entry in a batched response
+type batchAnalysis struct {
+	IncidentID string `json:"incident_id"`
+	analysisJSON
+}
+
+// AnalyzeIncidents analyzes several incidents in one request, saving a round-trip per
+// incident when related incidents are queued together. The responses are in the order of
+// incidents; an incident the model returned no valid analysis for gets nil, so the caller
+// can analyze it on its own. The error is only set when the request as a whole failed.
+func (a *Analyzer) AnalyzeIncidents(ctx context.Context, incidents []*models.Incident) (responses []*models.AIResponse, err error) {
+	if len(incidents) == 1 {
+		response, err := a.AnalyzeIncident(ctx, incidents[0], nil)
+		if err != nil {
+			return nil, err
+		}
+		return []*models.AIResponse{response}, nil
+	}
+
+	ids := make([]string, len(incidents))
+	for i, incident := range incidents {
+		ids[i] = incident.ID
+	}
+	telemetry.Logf(ctx, "[AI] Analyzing %d incidents in one request: %s\n", len(incidents), strings.Join(ids, ", "))
+
+	ctx, span := telemetry.Tracer().Start(ctx, "analysis", trace.WithAttributes(
+		attribute.String("analysis.source", "ai"),
+		attribute.String("ai.model", a.model),
+		attribute.Int("analysis.batch_size", len(incidents)),
+	))
+	defer func() { telemetry.End(span, err) }()
+
+	if a.budget != nil {
+		if err := a.budget.check(); err != nil {
+			return nil, err
+		}
+	}
+
+	messages, err := a.buildBatchMessages(incidents)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every incident needs room for a full answer
+	content, fingerprint, err := a.chat(ctx, messages, a.maxTokens*len(incidents))
+	if err != nil {
+		return nil, err
+	}
+
+	analyses, err := parseBatchResponse(content, a.normalizer)
+	if err != nil {
+		telemetry.Logf(ctx, "[AI] Failed to parse batched response: %s\n", content)
+		return nil, fmt.Errorf("failed to parse batched AI response: %w", err)
+	}
+
+	index := make(map[string]int, len(incidents))
+	for i, incident := range incidents {
+		index[incident.ID] = i
+	}
+
+	responses = make([]*models.AIResponse, len(incidents))
+	for j := range analyses {
+		// The response points into the analysis, so each must be its own element
+		analysis := &analyses[j]
+		i, ok := index[analysis.IncidentID]
+		switch {
+		case !ok:
+			telemetry.Logf(ctx, "[AI] ⚠️  Ignoring analysis for unknown incident %q\n", analysis.IncidentID)
+			continue
+		case responses[i] != nil:
+			telemetry.Logf(ctx, "[AI] ⚠️  Ignoring second analysis for incident %s\n", analysis.IncidentID)
+			continue
+		}
+
+		validated, err := a.validateResponse(ctx, &analysis.analysisJSON)
+		if err != nil {
+			telemetry.Logf(ctx, "[AI] ⚠️  Invalid analysis for incident %s: %v\n", analysis.IncidentID, err)
+			continue
+		}
+		validated.SystemFingerprint = fingerprint
+		responses[i] = validated
+	}
+
+	for i, response := range responses {
+		if response == nil {
+			telemetry.Logf(ctx, "[AI] ⚠️  Batched response has no valid analysis for incident %s\n", incidents[i].ID)
+		}
+	}
+	return responses, nil
+}
+
+// buildBatchMessages renders every incident's prompt, without its own task section, and
+// asks for one analysis per incident
+func (a *Analyzer) buildBatchMessages(incidents []*models.Incident) ([]openai.ChatCompletionMessage, error) {
+	var sb strings.Builder
+	for i, incident := range incidents {
+		prompt, err := a.buildPrompt(incident, nil)
+		if err != nil {
+			return nil, err
+		}
+		prompt, _, _ = strings.Cut(prompt, "## Your Task")
+
+		fmt.Fprintf(&sb, "# INCIDENT %d OF %d\n\n%s\n", i+1, len(incidents), strings.TrimSpace(prompt))
+		sb.WriteString("\n---\n\n")
+	}
+	sb.WriteString(batchInstructions)
+
+	if a.promptMode == PromptModeCombined {
+		return []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleUser, Content: a.getSystemPrompt() + "\n\n" + sb.String()},
+		}, nil
+	}
+	return []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: a.getSystemPrompt()},
+		{Role: openai.ChatMessageRoleUser, Content: sb.String()},
+	}, nil
+}
+
+// parseBatchResponse reads the analyses out of a batched response: an object with an
+// "analyses" array, as asked for, or a bare array, which models sometimes return instead
+func parseBatchResponse(content string, normalize ResponseNormalizer) ([]batchAnalysis, error) {
+	if trimmed := trimCodeFence(content); strings.HasPrefix(trimmed, "[") {
+		var analyses []batchAnalysis
+		if err := json.Unmarshal([]byte(trimmed), &analyses); err != nil {
+			return nil, fmt.Errorf("JSON parsing error: %w", err)
+		}
+		return analyses, nil
+	}
+
+	normalized, err := normalize(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to normalize response: %w", err)
+	}
+
+	var batch struct {
+		Analyses []batchAnalysis `json:"analyses"`
+	}
+	if err := json.Unmarshal([]byte(normalized), &batch); err != nil {
+		return nil, fmt.Errorf("JSON parsing error: %w", err)
+	}
+	if len(batch.Analyses) == 0 {
+		return nil, fmt.Errorf("no analyses in response")
+	}
+	return batch.Analyses, nil
+}
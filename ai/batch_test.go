@@ -0,0 +1,104 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"incident-ai/models"
+	"strings"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// batchEntry is one analysis of a batched response, for incident id
+func batchEntry(id, diagnosis string) string {
+	return fmt.Sprintf(`{"incident_id": %q, "diagnosis": %q, "fix_type": "restart", "fix_steps": ["Restart the service"], "confidence": 0.8}`, id, diagnosis)
+}
+
+func TestAnalyzeIncidentsMapsResponsesToIncidents(t *testing.T) {
+	incidents := []*models.Incident{testIncident(), testIncident(), testIncident()}
+	for i, incident := range incidents {
+		incident.ID = fmt.Sprintf("incident-%d", i+1)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    []string // diagnosis per incident, "" for none
+		wantErr bool
+	}{
+		{
+			name: "out of order",
+			content: `{"analyses": [` + batchEntry("incident-3", "third") + `, ` + batchEntry("incident-1", "first") + `, ` +
+				batchEntry("incident-2", "second") + `]}`,
+			want: []string{"first", "second", "third"},
+		},
+		{
+			name:    "bare array in a code fence",
+			content: "```json\n[" + batchEntry("incident-2", "second") + ", " + batchEntry("incident-1", "first") + "]\n```",
+			want:    []string{"first", "second", ""},
+		},
+		{
+			name: "unknown, repeated and invalid entries skipped",
+			content: `{"analyses": [` + batchEntry("incident-9", "unknown") + `, ` + batchEntry("incident-1", "first") + `, ` +
+				batchEntry("incident-1", "again") + `, {"incident_id": "incident-2", "fix_type": "restart"}, ` +
+				batchEntry("incident-3", "third") + `]}`,
+			want: []string{"first", "", "third"},
+		},
+		{name: "no analyses", content: `{"analyses": []}`, wantErr: true},
+		{name: "not JSON", content: "I can't help with that", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var prompt string
+			analyzer := NewAnalyzer("test-key", WithCompletionFunc(func(ctx context.Context, req openai.ChatCompletionRequest) (string, error) {
+				prompt = req.Messages[len(req.Messages)-1].Content
+				return tt.content, nil
+			}))
+
+			responses, err := analyzer.AnalyzeIncidents(context.Background(), incidents)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("responses = %v, want an error", responses)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AnalyzeIncidents: %v", err)
+			}
+
+			got := make([]string, len(responses))
+			for i, response := range responses {
+				if response != nil {
+					got[i] = response.Diagnosis
+				}
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("diagnoses = %q, want %q", got, tt.want)
+			}
+
+			// One prompt covers every incident, with a single task section
+			for i, incident := range incidents {
+				if !strings.Contains(prompt, fmt.Sprintf("# INCIDENT %d OF 3", i+1)) || !strings.Contains(prompt, incident.ID) {
+					t.Errorf("prompt is missing incident %s", incident.ID)
+				}
+			}
+			if n := strings.Count(prompt, "## Your Task"); n != 1 {
+				t.Errorf("prompt has %d task sections, want 1", n)
+			}
+		})
+	}
+}
+
+func TestAnalyzeIncidentsSingle(t *testing.T) {
+	analyzer := NewAnalyzer("test-key", WithCompletionFunc(func(ctx context.Context, req openai.ChatCompletionRequest) (string, error) {
+		return cannedResponse, nil
+	}))
+
+	// A batch of one is an ordinary analysis
+	responses, err := analyzer.AnalyzeIncidents(context.Background(), []*models.Incident{testIncident()})
+	if err != nil || len(responses) != 1 || responses[0].FixType != "config" {
+		t.Errorf("responses = %v, %v; want the single-incident analysis", responses, err)
+	}
+}
@@ -0,0 +1,91 @@
+package ai
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BudgetExceededError is returned by AnalyzeIncident when the daily token budget is spent
+type BudgetExceededError struct {
+	Used    int
+	Limit   int
+	ResetAt time.Time
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("daily token budget exhausted (%d/%d tokens used, resets at %s)",
+		e.Used, e.Limit, e.ResetAt.Format(time.RFC3339))
+}
+
+// tokenBudget tracks AI token usage against a daily cap that resets at local midnight
+type tokenBudget struct {
+	mu          sync.Mutex
+	limit       int
+	used        int
+	windowStart time.Time
+	now         func() time.Time
+}
+
+func newTokenBudget(limit int, now func() time.Time) *tokenBudget {
+	return &tokenBudget{
+		limit:       limit,
+		windowStart: startOfDay(now()),
+		now:         now,
+	}
+}
+
+// startOfDay returns local midnight at the start of t's day
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// resetIfNewDay starts a fresh window once midnight has passed. Caller must hold mu.
+func (b *tokenBudget) resetIfNewDay() {
+	today := startOfDay(b.now())
+	if today.After(b.windowStart) {
+		b.windowStart = today
+		b.used = 0
+	}
+}
+
+// check returns a BudgetExceededError if the budget for the current day is spent
+func (b *tokenBudget) check() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.resetIfNewDay()
+	if b.used >= b.limit {
+		return &BudgetExceededError{
+			Used:    b.used,
+			Limit:   b.limit,
+			ResetAt: b.windowStart.AddDate(0, 0, 1),
+		}
+	}
+	return nil
+}
+
+// record adds used tokens, reporting whether this call exhausted the budget
+func (b *tokenBudget) record(tokens int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.resetIfNewDay()
+	wasUnder := b.used < b.limit
+	b.used += tokens
+	return wasUnder && b.used >= b.limit
+}
+
+// status returns current budget usage
+func (b *tokenBudget) status() map[string]interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.resetIfNewDay()
+	return map[string]interface{}{
+		"limit":     b.limit,
+		"used":      b.used,
+		"resets_at": b.windowStart.AddDate(0, 0, 1).Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,62 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDailyTokenBudget(t *testing.T) {
+	provider := &fakeProvider{rejected: map[string]bool{}}
+	server := httptest.NewServer(provider)
+	defer server.Close()
+
+	// Each analysis uses 20 tokens, so the third one exhausts a 50 token budget
+	now := time.Date(2026, 1, 1, 22, 0, 0, 0, time.Local)
+	analyzer := NewAnalyzer("test-key",
+		WithBaseURL(server.URL),
+		WithDailyTokenBudget(50),
+		WithClock(func() time.Time { return now }))
+
+	analyze := func() error {
+		_, err := analyzer.AnalyzeIncident(context.Background(), testIncident(), nil)
+		return err
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := analyze(); err != nil {
+			t.Fatalf("analysis %d within the budget: %v", i+1, err)
+		}
+	}
+
+	provider.take()
+	midnight := time.Date(2026, 1, 2, 0, 0, 0, 0, time.Local)
+	var budgetErr *BudgetExceededError
+	if err := analyze(); !errors.As(err, &budgetErr) {
+		t.Fatalf("analysis over the budget = %v, want a BudgetExceededError", err)
+	}
+	if budgetErr.Used != 60 || budgetErr.Limit != 50 || !budgetErr.ResetAt.Equal(midnight) {
+		t.Errorf("budget error = %+v, want 60/50 tokens used until %v", budgetErr, midnight)
+	}
+	if used := provider.take(); used != "" {
+		t.Errorf("the AI was called over the budget (keys %s)", used)
+	}
+	if status := analyzer.BudgetStatus(); status["used"] != 60 || status["limit"] != 50 {
+		t.Errorf("budget status = %v, want 60 of 50 tokens used", status)
+	}
+
+	// The budget resets at midnight
+	now = midnight.Add(time.Minute)
+	if err := analyze(); err != nil {
+		t.Fatalf("analysis after midnight: %v", err)
+	}
+	if status := analyzer.BudgetStatus(); status["used"] != 20 {
+		t.Errorf("budget status after midnight = %v, want only the new analysis counted", status)
+	}
+
+	if status := NewAnalyzer("test-key").BudgetStatus(); status != nil {
+		t.Errorf("budget status without a budget = %v, want nil", status)
+	}
+}
@@ -0,0 +1,50 @@
+package ai
+
+// extractJSONObject returns the first balanced {...} object found in content,
+// so JSON surrounded by prose or code fences can still be parsed. Braces inside
+// JSON strings are ignored. If no complete object is found, content is returned unchanged.
+func extractJSONObject(content string) string {
+	start := -1
+	depth := 0
+	inString := false
+	escaped := false
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			// Quotes only matter once we're inside an object; prose may contain stray quotes
+			if start >= 0 {
+				inString = true
+			}
+		case '{':
+			if start < 0 {
+				start = i
+			}
+			depth++
+		case '}':
+			if start < 0 {
+				continue
+			}
+			depth--
+			if depth == 0 {
+				return content[start : i+1]
+			}
+		}
+	}
+
+	return content
+}
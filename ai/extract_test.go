@@ -0,0 +1,62 @@
+package ai
+
+import (
+	"context"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+func TestExtractJSONObject(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "bare object", content: `{"a": 1}`, want: `{"a": 1}`},
+		{name: "prose before", content: `Here is my analysis: {"a": 1}`, want: `{"a": 1}`},
+		{name: "prose after", content: `{"a": 1} Let me know if you need more detail.`, want: `{"a": 1}`},
+		{name: "code fence", content: "```json\n{\"a\": 1}\n```", want: `{"a": 1}`},
+		{name: "nested objects", content: `Result: {"a": {"b": {}}, "c": 2} done`, want: `{"a": {"b": {}}, "c": 2}`},
+		{name: "braces in strings", content: `{"steps": ["set {x} to }"]} trailing }`, want: `{"steps": ["set {x} to }"]}`},
+		{name: "escaped quotes", content: `{"diagnosis": "the \"}\" key"} end`, want: `{"diagnosis": "the \"}\" key"}`},
+		{name: "quotes in prose", content: `The "best" fix: {"a": 1}`, want: `{"a": 1}`},
+		{name: "first of two objects", content: `{"a": 1} or {"b": 2}`, want: `{"a": 1}`},
+		{name: "stray closing brace first", content: `} oops {"a": 1}`, want: `{"a": 1}`},
+		{name: "no object", content: "I can't diagnose this incident.", want: "I can't diagnose this incident."},
+		{name: "unbalanced", content: `{"a": {"b": 1}`, want: `{"a": {"b": 1}`},
+	}
+
+	for _, tt := range tests {
+		if got := extractJSONObject(tt.content); got != tt.want {
+			t.Errorf("%s: extractJSONObject(%q) = %q, want %q", tt.name, tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzeIncidentParsesEmbeddedJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "preceded by prose", content: "Based on the logs, the pool is exhausted.\n" + cannedResponse},
+		{name: "followed by prose", content: cannedResponse + "\nThis should resolve the {connection} issue."},
+		{name: "inside a code fence", content: "```json\n" + cannedResponse + "\n```\nApply the steps in order."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := NewAnalyzer("test-key", WithCompletionFunc(func(ctx context.Context, req openai.ChatCompletionRequest) (string, error) {
+				return tt.content, nil
+			}))
+
+			response, err := analyzer.AnalyzeIncident(context.Background(), testIncident(), nil)
+			if err != nil {
+				t.Fatalf("AnalyzeIncident: %v", err)
+			}
+			if response.Diagnosis != "Connection pool exhausted by leaked connections" || response.FixType != "config" {
+				t.Errorf("response = %+v, want the embedded analysis", response)
+			}
+		})
+	}
+}
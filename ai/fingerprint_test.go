@@ -0,0 +1,67 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSeedAndSystemFingerprint(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	fingerprints := []string{"fp_a1", "fp_a1", "fp_b2"}
+	var seeds []interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&req)
+		seeds = append(seeds, req["seed"])
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":                 "chatcmpl-test",
+			"object":             "chat.completion",
+			"system_fingerprint": fingerprints[len(seeds)-1],
+			"choices":            []map[string]interface{}{{"index": 0, "message": map[string]string{"role": "assistant", "content": cannedResponse}}},
+			"usage":              map[string]int{"prompt_tokens": 10, "completion_tokens": 10, "total_tokens": 20},
+		})
+	}))
+	defer server.Close()
+
+	analyzer := NewAnalyzer("test-key", WithBaseURL(server.URL+"/v1"), WithSeed(42))
+	for i, want := range fingerprints {
+		response, err := analyzer.AnalyzeIncident(context.Background(), testIncident(), nil)
+		if err != nil {
+			t.Fatalf("AnalyzeIncident: %v", err)
+		}
+		if response.SystemFingerprint != want {
+			t.Errorf("analysis %d fingerprint = %q, want %q", i+1, response.SystemFingerprint, want)
+		}
+	}
+
+	for i, seed := range seeds {
+		if seed != 42.0 {
+			t.Errorf("request %d seed = %v, want 42", i+1, seed)
+		}
+	}
+	logs := buf.String()
+	if strings.Count(logs, "fingerprint: fp_a1") != 1 || !strings.Contains(logs, "Model backend changed: fingerprint fp_a1 -> fp_b2") {
+		t.Errorf("logs don't report the first fingerprint once and then the change:\n%s", logs)
+	}
+
+	// Without a seed, none is sent
+	seeds, fingerprints = nil, []string{""}
+	if _, err := NewAnalyzer("test-key", WithBaseURL(server.URL+"/v1")).AnalyzeIncident(context.Background(), testIncident(), nil); err != nil {
+		t.Fatalf("AnalyzeIncident: %v", err)
+	}
+	if seeds[0] != nil {
+		t.Errorf("seed = %v sent without WithSeed", seeds[0])
+	}
+}
@@ -0,0 +1,111 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// ValidateAPIKey checks the API key, or every rotated key, looks usable. OpenAI keys
+// start with "sk-"; keys for Azure or a custom base URL have no fixed format, so only
+// their presence is checked.
+func (a *Analyzer) ValidateAPIKey() error {
+	if len(a.apiKeys) == 0 {
+		return a.validateKey(a.apiKey)
+	}
+	for _, key := range a.apiKeys {
+		if err := a.validateKey(key.Key); err != nil {
+			return fmt.Errorf("key %s: %w", maskKey(key.Key), err)
+		}
+	}
+	return nil
+}
+
+func (a *Analyzer) validateKey(apiKey string) error {
+	key := strings.TrimSpace(apiKey)
+	if key == "" {
+		return fmt.Errorf("no API key configured")
+	}
+	if key != apiKey {
+		return fmt.Errorf("API key has leading or trailing whitespace")
+	}
+	if a.usesOpenAI() && !strings.HasPrefix(key, "sk-") {
+		return fmt.Errorf("API key does not look like an OpenAI key (expected an sk- prefix)")
+	}
+	return nil
+}
+
+// CheckProvider verifies the AI endpoint is reachable and accepts the API key
+func (a *Analyzer) CheckProvider(ctx context.Context) error {
+	if _, err := a.client.ListModels(ctx); err != nil {
+		return fmt.Errorf("AI endpoint %s unreachable: %w", a.clientConfig.BaseURL, err)
+	}
+	return nil
+}
+
+// usesOpenAI reports whether requests go to the public OpenAI API
+func (a *Analyzer) usesOpenAI() bool {
+	return a.clientConfig.APIType == openai.APITypeOpenAI &&
+		a.clientConfig.BaseURL == openai.DefaultConfig("").BaseURL
+}
+
+// providerHealth tracks the outcome of recent calls to the AI provider
+type providerHealth struct {
+	mu                  sync.Mutex
+	lastCallAt          time.Time
+	lastSuccessAt       time.Time
+	lastError           string
+	consecutiveFailures int
+}
+
+// record notes the outcome of a provider call made at now
+func (h *providerHealth) record(now time.Time, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastCallAt = now
+	if err != nil {
+		h.lastError = err.Error()
+		h.consecutiveFailures++
+		return
+	}
+	h.lastSuccessAt = now
+	h.lastError = ""
+	h.consecutiveFailures = 0
+}
+
+// ProviderHealth reports the AI provider's health based on recent calls: "unknown" before
+// the first call, "healthy" if the last call succeeded and "failing" otherwise. With
+// several API keys, each key's health is listed under "keys".
+func (a *Analyzer) ProviderHealth() map[string]interface{} {
+	h := &a.provider
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	status := map[string]interface{}{
+		"status":               "unknown",
+		"consecutive_failures": h.consecutiveFailures,
+	}
+	if a.keys != nil {
+		status["keys"] = a.keys.status()
+	}
+	if h.lastCallAt.IsZero() {
+		return status
+	}
+
+	status["last_call_at"] = h.lastCallAt
+	if !h.lastSuccessAt.IsZero() {
+		status["last_success_at"] = h.lastSuccessAt
+	}
+	if h.consecutiveFailures > 0 {
+		status["status"] = "failing"
+		status["last_error"] = h.lastError
+	} else {
+		status["status"] = "healthy"
+	}
+	return status
+}
@@ -0,0 +1,242 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"incident-ai/telemetry"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// defaultKeyCooldown is how long a key that returned an auth or quota error is skipped
+const defaultKeyCooldown = 5 * time.Minute
+
+// ErrNoAvailableKey is returned by AnalyzeIncident when every API key is cooling down
+// after auth or quota errors
+var ErrNoAvailableKey = errors.New("all API keys are cooling down")
+
+// APIKey is one of several API keys requests are spread across
+type APIKey struct {
+	Key    string
+	Weight int // share of requests relative to the other keys
+}
+
+// ParseAPIKeys parses a comma-separated list of API keys, each optionally followed by
+// "=weight", e.g. "sk-a=3,sk-b". Keys without a weight get weight 1.
+func ParseAPIKeys(s string) ([]APIKey, error) {
+	var keys []APIKey
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		key, weight := entry, 1
+		if k, w, ok := strings.Cut(entry, "="); ok {
+			n, err := strconv.Atoi(strings.TrimSpace(w))
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid weight %q for key %s (must be a positive integer)", w, maskKey(k))
+			}
+			key, weight = strings.TrimSpace(k), n
+		}
+		if key == "" {
+			return nil, fmt.Errorf("empty API key in %q", entry)
+		}
+		keys = append(keys, APIKey{Key: key, Weight: weight})
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no API keys given")
+	}
+	return keys, nil
+}
+
+// maskKey hides all but the last four characters of a key, for logs and status output
+func maskKey(key string) string {
+	if len(key) <= 4 {
+		return "****"
+	}
+	return "****" + key[len(key)-4:]
+}
+
+// keyState is an API key's rotation and health state
+type keyState struct {
+	APIKey
+	current       int       // smooth weighted round-robin counter
+	coolingUntil  time.Time // skipped until then after an auth or quota error
+	lastError     string
+	failures      int // auth or quota errors since the key last succeeded
+	lastSuccessAt time.Time
+}
+
+// keyRing rotates requests across API keys by smooth weighted round-robin, skipping keys
+// that are cooling down after auth or quota errors
+type keyRing struct {
+	mu       sync.Mutex
+	keys     []*keyState
+	cooldown time.Duration
+	now      func() time.Time
+}
+
+func newKeyRing(keys []APIKey, cooldown time.Duration, now func() time.Time) *keyRing {
+	r := &keyRing{cooldown: cooldown, now: now}
+	for _, key := range keys {
+		r.keys = append(r.keys, &keyState{APIKey: key})
+	}
+	return r
+}
+
+// next picks the key for the next request, or returns ErrNoAvailableKey when every key is
+// cooling down. Among the available keys, each gets requests in proportion to its weight,
+// interleaved rather than in bursts.
+func (r *keyRing) next() (*keyState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	var picked *keyState
+	total := 0
+	for _, k := range r.keys {
+		if now.Before(k.coolingUntil) {
+			continue
+		}
+		k.current += k.Weight
+		total += k.Weight
+		if picked == nil || k.current > picked.current {
+			picked = k
+		}
+	}
+	if picked == nil {
+		return nil, ErrNoAvailableKey
+	}
+
+	picked.current -= total
+	return picked, nil
+}
+
+// record notes the outcome of a request made with key, putting the key on cooldown if the
+// provider rejected it. It reports whether the key was put on cooldown.
+func (r *keyRing) record(key *keyState, err error) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err == nil {
+		key.failures = 0
+		key.lastError = ""
+		key.lastSuccessAt = r.now()
+		return false
+	}
+	if !isKeyError(err) {
+		return false
+	}
+
+	key.failures++
+	key.lastError = err.Error()
+	key.coolingUntil = r.now().Add(r.cooldown)
+	key.current = 0
+	return true
+}
+
+// status returns each key's weight and health, with the keys masked
+func (r *keyRing) status() []map[string]interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	statuses := make([]map[string]interface{}, 0, len(r.keys))
+	for _, k := range r.keys {
+		status := map[string]interface{}{
+			"key":    maskKey(k.Key),
+			"weight": k.Weight,
+			"status": "available",
+		}
+		if now.Before(k.coolingUntil) {
+			status["status"] = "cooling_down"
+			status["cooling_until"] = k.coolingUntil
+		}
+		if k.failures > 0 {
+			status["failures"] = k.failures
+			status["last_error"] = k.lastError
+		}
+		if !k.lastSuccessAt.IsZero() {
+			status["last_success_at"] = k.lastSuccessAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// isKeyError reports whether err is the provider rejecting the key itself: invalid or
+// revoked (401), not permitted (403) or out of quota or rate limited (429)
+func isKeyError(err error) bool {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return isKeyStatus(apiErr.HTTPStatusCode)
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return isKeyStatus(reqErr.HTTPStatusCode)
+	}
+	return false
+}
+
+func isKeyStatus(code int) bool {
+	return code == http.StatusUnauthorized || code == http.StatusForbidden || code == http.StatusTooManyRequests
+}
+
+type apiKeyContextKey struct{}
+
+// withAPIKey returns a context whose requests authenticate with key
+func withAPIKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, key)
+}
+
+// keyTransport replaces the client's API key on requests whose context carries another one
+type keyTransport struct {
+	base http.RoundTripper
+}
+
+func (t *keyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key, _ := req.Context().Value(apiKeyContextKey{}).(string)
+	if key == "" {
+		return t.base.RoundTrip(req)
+	}
+
+	// A RoundTripper must not modify the caller's request
+	req = req.Clone(req.Context())
+	if req.Header.Get("api-key") != "" {
+		req.Header.Set("api-key", key) // Azure
+	} else {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// completeWithKeys sends a completion request with the next API key, moving on to the
+// following key when the provider rejects one, until a key is accepted or none is left
+func (a *Analyzer) completeWithKeys(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	var lastErr error
+	for range a.keys.keys {
+		key, err := a.keys.next()
+		if err != nil {
+			if lastErr != nil {
+				return openai.ChatCompletionResponse{}, fmt.Errorf("%w (last error: %w)", err, lastErr)
+			}
+			return openai.ChatCompletionResponse{}, err
+		}
+
+		resp, err := a.complete(withAPIKey(ctx, key.Key), req)
+		if !a.keys.record(key, err) {
+			return resp, err
+		}
+		telemetry.Logf(ctx, "[AI] 🔑 API key %s rejected (%v), skipping it for %v\n", maskKey(key.Key), err, a.keys.cooldown)
+		lastErr = err
+	}
+	return openai.ChatCompletionResponse{}, lastErr
+}
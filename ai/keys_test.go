@@ -0,0 +1,155 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeProvider is an OpenAI-compatible endpoint that rejects some API keys
+type fakeProvider struct {
+	mu       sync.Mutex
+	rejected map[string]bool // keys answered with 401
+	used     []string        // keys of the requests received, in order
+}
+
+func (p *fakeProvider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	p.mu.Lock()
+	p.used = append(p.used, key)
+	rejected := p.rejected[key]
+	p.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if rejected {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error": {"message": "Incorrect API key provided", "type": "invalid_request_error"}}`)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":      "chatcmpl-test",
+		"object":  "chat.completion",
+		"choices": []map[string]interface{}{{"index": 0, "message": map[string]string{"role": "assistant", "content": cannedResponse}}},
+		"usage":   map[string]int{"prompt_tokens": 10, "completion_tokens": 10, "total_tokens": 20},
+	})
+}
+
+// take returns and forgets the keys used since the last call
+func (p *fakeProvider) take() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	used := strings.Join(p.used, " ")
+	p.used = nil
+	return used
+}
+
+func (p *fakeProvider) reject(key string, rejected bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rejected[key] = rejected
+}
+
+func TestFailingKeyCoolsDownAndRecovers(t *testing.T) {
+	provider := &fakeProvider{rejected: map[string]bool{"key-a": true}}
+	server := httptest.NewServer(provider)
+	defer server.Close()
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	analyzer := NewAnalyzer("unused",
+		WithBaseURL(server.URL),
+		WithClock(func() time.Time { return now }),
+		WithAPIKeys([]APIKey{{Key: "key-a", Weight: 1}, {Key: "key-b", Weight: 1}}, time.Minute))
+
+	analyze := func() error {
+		_, err := analyzer.AnalyzeIncident(context.Background(), testIncident(), nil)
+		return err
+	}
+
+	// The rejected key is skipped and the request retried with the next one
+	if err := analyze(); err != nil {
+		t.Fatalf("AnalyzeIncident: %v", err)
+	}
+	if used := provider.take(); used != "key-a key-b" {
+		t.Fatalf("keys used = %s, want key-a rejected then key-b", used)
+	}
+
+	// While cooling down, the key isn't tried at all
+	for i := 0; i < 3; i++ {
+		if err := analyze(); err != nil {
+			t.Fatalf("AnalyzeIncident: %v", err)
+		}
+	}
+	if used := provider.take(); used != "key-b key-b key-b" {
+		t.Errorf("keys used during the cooldown = %s, want only key-b", used)
+	}
+
+	// Once the cooldown is over, the key is back in rotation
+	provider.reject("key-a", false)
+	now = now.Add(time.Minute + time.Second)
+	for i := 0; i < 2; i++ {
+		if err := analyze(); err != nil {
+			t.Fatalf("AnalyzeIncident: %v", err)
+		}
+	}
+	if used := provider.take(); !strings.Contains(used, "key-a") {
+		t.Errorf("keys used after the cooldown = %s, want key-a again", used)
+	}
+
+	// With every key rejected, the analysis fails instead of looping
+	provider.reject("key-a", true)
+	provider.reject("key-b", true)
+	now = now.Add(2 * time.Minute)
+	if err := analyze(); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("error = %v, want the last key's 401", err)
+	}
+	if err := analyze(); err == nil || !strings.Contains(err.Error(), ErrNoAvailableKey.Error()) {
+		t.Errorf("error = %v, want %v", err, ErrNoAvailableKey)
+	}
+}
+
+func TestKeyRingWeights(t *testing.T) {
+	ring := newKeyRing([]APIKey{{Key: "a", Weight: 3}, {Key: "b", Weight: 1}}, time.Minute, time.Now)
+
+	var picks []string
+	for i := 0; i < 8; i++ {
+		key, err := ring.next()
+		if err != nil {
+			t.Fatalf("next: %v", err)
+		}
+		picks = append(picks, key.Key)
+	}
+
+	// Smooth weighted round-robin interleaves the lighter key instead of bunching it
+	if got := strings.Join(picks, ""); got != "aabaaaba" {
+		t.Errorf("picks = %s, want aabaaaba", got)
+	}
+}
+
+func TestParseAPIKeys(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "sk-a=3, sk-b", want: "[{sk-a 3} {sk-b 1}]"},
+		{in: "sk-a", want: "[{sk-a 1}]"},
+		{in: "sk-a=0", wantErr: true},
+		{in: "sk-a=x", wantErr: true},
+		{in: "=2", wantErr: true},
+		{in: " , ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		keys, err := ParseAPIKeys(tt.in)
+		if (err != nil) != tt.wantErr || (err == nil && fmt.Sprint(keys) != tt.want) {
+			t.Errorf("ParseAPIKeys(%q) = %v, %v; want %s, error %v", tt.in, keys, err, tt.want, tt.wantErr)
+		}
+	}
+}
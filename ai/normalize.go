@@ -0,0 +1,23 @@
+package ai
+
+import "strings"
+
+// ResponseNormalizer turns a provider's raw completion content into the JSON object
+// parseResponse unmarshals. Each provider supplies one for its own formatting quirks,
+// e.g. a wrapper object around the answer.
+type ResponseNormalizer func(content string) (string, error)
+
+// NormalizeOpenAI handles OpenAI-style responses: markdown code fences are stripped and
+// the JSON object is pulled out of any surrounding prose
+func NormalizeOpenAI(content string) (string, error) {
+	return extractJSONObject(trimCodeFence(content)), nil
+}
+
+// trimCodeFence strips a markdown code fence around content, if any
+func trimCodeFence(content string) string {
+	content = strings.TrimSpace(content)
+	content = strings.TrimPrefix(content, "```json")
+	content = strings.TrimPrefix(content, "```")
+	content = strings.TrimSuffix(content, "```")
+	return strings.TrimSpace(content)
+}
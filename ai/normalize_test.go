@@ -0,0 +1,75 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+func TestNormalizeOpenAI(t *testing.T) {
+	for _, content := range []string{
+		cannedResponse,
+		"```json\n" + cannedResponse + "\n```",
+		"```\n" + cannedResponse + "\n```",
+		"Here is my analysis:\n" + cannedResponse + "\nLet me know if it helps.",
+	} {
+		normalized, err := NormalizeOpenAI(content)
+		if err != nil || normalized != cannedResponse {
+			t.Errorf("NormalizeOpenAI(%q) = %q, %v; want the bare JSON object", content, normalized, err)
+		}
+	}
+}
+
+// unwrapText is the normalizer of a provider that returns its answer as a JSON string
+// under "text"
+func unwrapText(content string) (string, error) {
+	var wrapper struct {
+		Text *string `json:"text"`
+	}
+	if err := json.Unmarshal([]byte(content), &wrapper); err != nil || wrapper.Text == nil {
+		return "", errors.New("response isn't wrapped in a text field")
+	}
+	return *wrapper.Text, nil
+}
+
+func TestCustomNormalizer(t *testing.T) {
+	wrapped, _ := json.Marshal(map[string]string{"text": cannedResponse})
+
+	tests := []struct {
+		name       string
+		normalizer ResponseNormalizer
+		content    string
+		wantErr    string
+	}{
+		{name: "provider wrapper", normalizer: unwrapText, content: string(wrapped)},
+		{name: "wrapper missing", normalizer: unwrapText, content: cannedResponse, wantErr: "failed to normalize response: response isn't wrapped in a text field"},
+		{name: "OpenAI default can't see through the wrapper", content: string(wrapped), wantErr: "failed to parse AI response"},
+		{name: "nil keeps the OpenAI default", normalizer: nil, content: "```json\n" + cannedResponse + "\n```"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := NewAnalyzer("test-key", WithResponseNormalizer(tt.normalizer), WithCompletionFunc(func(ctx context.Context, req openai.ChatCompletionRequest) (string, error) {
+				return tt.content, nil
+			}))
+
+			response, err := analyzer.AnalyzeIncident(context.Background(), testIncident(), nil)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("AnalyzeIncident = %+v, %v; want an error containing %q", response, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AnalyzeIncident: %v", err)
+			}
+			if response.Diagnosis != "Connection pool exhausted by leaked connections" || response.FixType != "config" {
+				t.Errorf("response = %+v, want the wrapped analysis", response)
+			}
+		})
+	}
+}
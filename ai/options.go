@@ -0,0 +1,186 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// Option configures an Analyzer
+type Option func(*Analyzer)
+
+// PromptMode controls how the system and user prompts are assembled into chat messages
+type PromptMode string
+
+const (
+	// PromptModeSeparate sends the system prompt and the incident prompt as separate messages
+	PromptModeSeparate PromptMode = "separate"
+	// PromptModeCombined sends a single user message, for providers that ignore or reject system messages
+	PromptModeCombined PromptMode = "combined"
+)
+
+// ParsePromptMode validates a prompt mode name
+func ParsePromptMode(s string) (PromptMode, error) {
+	switch PromptMode(s) {
+	case PromptModeSeparate, PromptModeCombined:
+		return PromptMode(s), nil
+	default:
+		return "", fmt.Errorf("unknown prompt mode %q (valid: %s, %s)", s, PromptModeSeparate, PromptModeCombined)
+	}
+}
+
+// WithPromptMode sets how prompts are assembled into messages
+func WithPromptMode(mode PromptMode) Option {
+	return func(a *Analyzer) {
+		a.promptMode = mode
+	}
+}
+
+// WithLenientParsing fills defaults for missing optional fields instead of rejecting the response.
+// A restart fix without steps gets the standard restart steps, and a missing confidence
+// is replaced with defaultConfidence.
+func WithLenientParsing(defaultConfidence float64) Option {
+	return func(a *Analyzer) {
+		a.lenient = true
+		a.defaultConfidence = defaultConfidence
+	}
+}
+
+// WithBaseURL sends requests to a custom OpenAI-compatible endpoint, e.g. a corporate proxy.
+// An empty URL keeps the default endpoint.
+func WithBaseURL(baseURL string) Option {
+	return func(a *Analyzer) {
+		if baseURL != "" {
+			a.clientConfig.BaseURL = baseURL
+		}
+	}
+}
+
+// WithAzure targets an Azure OpenAI resource. The deployment replaces the model name
+// in requests; an empty apiVersion keeps the client library's default.
+func WithAzure(endpoint, deployment, apiVersion string) Option {
+	return func(a *Analyzer) {
+		config := openai.DefaultAzureConfig(a.apiKey, endpoint)
+		if apiVersion != "" {
+			config.APIVersion = apiVersion
+		}
+		if deployment != "" {
+			config.AzureModelMapperFunc = func(model string) string {
+				return deployment
+			}
+		}
+		config.HTTPClient = a.clientConfig.HTTPClient
+		a.clientConfig = config
+	}
+}
+
+// WithResponseNormalizer replaces the OpenAI response normalization, for providers that
+// format their answers differently. A nil normalizer keeps the default.
+func WithResponseNormalizer(normalize ResponseNormalizer) Option {
+	return func(a *Analyzer) {
+		if normalize != nil {
+			a.normalizer = normalize
+		}
+	}
+}
+
+// WithDailyTokenBudget caps the tokens spent on AI analysis per day. Once the cap is hit,
+// AnalyzeIncident returns a *BudgetExceededError until the budget resets at local midnight.
+// A limit of 0 disables the cap.
+func WithDailyTokenBudget(limit int) Option {
+	return func(a *Analyzer) {
+		a.dailyTokenBudget = limit
+	}
+}
+
+// WithClock overrides the clock used for budget windows and API key cooldowns
+func WithClock(now func() time.Time) Option {
+	return func(a *Analyzer) {
+		a.now = now
+	}
+}
+
+// WithTemperature sets the sampling temperature for analysis requests. Use 0 for
+// reproducible, deterministic responses.
+func WithTemperature(temperature float32) Option {
+	return func(a *Analyzer) {
+		a.temperature = temperature
+	}
+}
+
+// WithSeed sends a fixed seed with every analysis request so the model samples
+// reproducibly, e.g. for prompt regression tests. Reproducibility also depends on the
+// backend, whose fingerprint is logged and recorded on each response.
+func WithSeed(seed int) Option {
+	return func(a *Analyzer) {
+		a.seed = &seed
+	}
+}
+
+// WithMaxTokens caps the length of the AI's response. A value of 0 leaves it to the API.
+func WithMaxTokens(maxTokens int) Option {
+	return func(a *Analyzer) {
+		a.maxTokens = maxTokens
+	}
+}
+
+// WithServiceURL names the monitored service's address in prompts. Without it the
+// prompt leaves the address out.
+func WithServiceURL(serviceURL string) Option {
+	return func(a *Analyzer) {
+		a.serviceURL = serviceURL
+	}
+}
+
+// CompletionFunc answers a chat completion request with the raw content of the model's reply
+type CompletionFunc func(ctx context.Context, req openai.ChatCompletionRequest) (string, error)
+
+// WithCompletionFunc replaces the OpenAI call with complete, e.g. a canned response for
+// exercising prompt building and response parsing without a network. Token usage and
+// the backend fingerprint are not reported for injected completions. A nil func keeps the
+// OpenAI call.
+func WithCompletionFunc(complete CompletionFunc) Option {
+	return func(a *Analyzer) {
+		if complete == nil {
+			return
+		}
+		a.complete = func(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			content, err := complete(ctx, req)
+			if err != nil {
+				return openai.ChatCompletionResponse{}, err
+			}
+			return openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{
+					{Message: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: content}},
+				},
+			}, nil
+		}
+	}
+}
+
+// WithAPIKeys spreads requests across several API keys by weighted round-robin. A key the
+// provider rejects with an auth or quota error is skipped for cooldown (0 = 5 minutes),
+// and the request is retried with the next key. Replaces the key passed to NewAnalyzer
+// for analysis requests.
+func WithAPIKeys(keys []APIKey, cooldown time.Duration) Option {
+	return func(a *Analyzer) {
+		a.apiKeys = append([]APIKey(nil), keys...)
+		a.keyCooldown = cooldown
+		if cooldown <= 0 {
+			a.keyCooldown = defaultKeyCooldown
+		}
+	}
+}
+
+// WithAnalysisTimeout bounds each AI analysis call. A call that takes longer fails with
+// ErrAnalysisTimeout so the caller can fall back. A timeout of 0 leaves the call bounded
+// only by the caller's context.
+func WithAnalysisTimeout(timeout time.Duration) Option {
+	return func(a *Analyzer) {
+		if timeout >= 0 {
+			a.timeout = timeout
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package ai
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+func TestCustomEndpoints(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       func(serverURL string) []Option
+		wantPath   string
+		wantQuery  string
+		authHeader string
+		wantAuth   string
+	}{
+		{
+			name:       "proxy base URL",
+			opts:       func(serverURL string) []Option { return []Option{WithBaseURL(serverURL + "/proxy/v1")} },
+			wantPath:   "/proxy/v1/chat/completions",
+			authHeader: "Authorization",
+			wantAuth:   "Bearer test-key",
+		},
+		{
+			name: "Azure deployment",
+			opts: func(serverURL string) []Option {
+				return []Option{WithAzure(serverURL, "incident-gpt", "2024-06-01")}
+			},
+			wantPath:   "/openai/deployments/incident-gpt/chat/completions",
+			wantQuery:  "api-version=2024-06-01",
+			authHeader: "api-key",
+			wantAuth:   "test-key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received *http.Request
+			provider := &fakeProvider{rejected: map[string]bool{}}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received = r
+				provider.ServeHTTP(w, r)
+			}))
+			defer server.Close()
+
+			analyzer := NewAnalyzer("test-key", tt.opts(server.URL)...)
+			if _, err := analyzer.AnalyzeIncident(context.Background(), testIncident(), nil); err != nil {
+				t.Fatalf("AnalyzeIncident: %v", err)
+			}
+
+			if received == nil {
+				t.Fatal("no request reached the custom endpoint")
+			}
+			if received.URL.Path != tt.wantPath || received.URL.RawQuery != tt.wantQuery {
+				t.Errorf("request to %s?%s, want %s?%s", received.URL.Path, received.URL.RawQuery, tt.wantPath, tt.wantQuery)
+			}
+			if got := received.Header.Get(tt.authHeader); got != tt.wantAuth {
+				t.Errorf("%s header = %q, want %q", tt.authHeader, got, tt.wantAuth)
+			}
+		})
+	}
+}
+
+func TestEmptyBaseURLKeepsDefault(t *testing.T) {
+	analyzer := NewAnalyzer("test-key", WithBaseURL(""))
+	if want := openai.DefaultConfig("").BaseURL; analyzer.clientConfig.BaseURL != want {
+		t.Errorf("base URL = %s, want the default %s", analyzer.clientConfig.BaseURL, want)
+	}
+}
+
+func TestSamplingSettingsReachRequest(t *testing.T) {
+	tests := []struct {
+		name            string
+		opts            []Option
+		wantTemperature float32
+		wantMaxTokens   int
+	}{
+		{name: "defaults", wantTemperature: defaultTemperature, wantMaxTokens: defaultMaxTokens},
+		{name: "configured", opts: []Option{WithTemperature(0.7), WithMaxTokens(2500)}, wantTemperature: 0.7, wantMaxTokens: 2500},
+		// The client omits a zero temperature, which the API would take as 1
+		{name: "deterministic", opts: []Option{WithTemperature(0)}, wantTemperature: math.SmallestNonzeroFloat32, wantMaxTokens: defaultMaxTokens},
+		{name: "no token cap", opts: []Option{WithMaxTokens(0)}, wantTemperature: defaultTemperature, wantMaxTokens: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got openai.ChatCompletionRequest
+			opts := append(tt.opts, WithCompletionFunc(func(ctx context.Context, req openai.ChatCompletionRequest) (string, error) {
+				got = req
+				return cannedResponse, nil
+			}))
+			if _, err := NewAnalyzer("test-key", opts...).AnalyzeIncident(context.Background(), testIncident(), nil); err != nil {
+				t.Fatalf("AnalyzeIncident: %v", err)
+			}
+
+			if got.Temperature != tt.wantTemperature || got.MaxTokens != tt.wantMaxTokens {
+				t.Errorf("request temperature %v, max tokens %d; want %v, %d", got.Temperature, got.MaxTokens, tt.wantTemperature, tt.wantMaxTokens)
+			}
+		})
+	}
+}
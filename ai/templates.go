@@ -0,0 +1,209 @@
+package ai
+
+import (
+	"fmt"
+	"incident-ai/models"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// promptData is the data available to prompt templates
+type promptData struct {
+	Incident         *models.Incident
+	ServiceURL       string   // where the monitored service is reached ("" = not configured)
+	ProblemLogs      []string // the WARN and ERROR lines of the incident's logs, oldest first
+	Metadata         []configEntry
+	Config           []configEntry
+	PreviousAttempts []models.Resolution
+}
+
+// configEntry is one service config or metadata value, kept in a slice so templates render in a stable order
+type configEntry struct {
+	Key   string
+	Value string
+}
+
+// basePromptTemplate lays out every prompt. Each incident type fills in the "focus"
+// block with the guidance and context that matter most for that kind of failure.
+const basePromptTemplate = `# INCIDENT ANALYSIS REQUEST
+
+## Service Information
+- Service Type: HTTP REST API
+- Language: Go
+{{if .ServiceURL}}- Address: {{.ServiceURL}}
+{{end}}{{range .Metadata}}- {{.Key}}: {{.Value}}
+{{end}}
+## Incident Details
+- Incident ID: {{.Incident.ID}}
+- Type: {{.Incident.Type}}
+- Detected At: {{.Incident.DetectedAt.Format "2006-01-02 15:04:05"}}
+- Impact Score: {{printf "%.2f" .Incident.ImpactScore}} (fraction of sampled API requests failing)
+
+## Symptoms
+{{range $i, $s := .Incident.Symptoms}}{{inc $i}}. {{$s}}
+{{else}}No specific symptoms recorded
+{{end}}
+{{if .Incident.TriggerLog}}## Triggering Log Entry
+This is the log line the service wrote when the fault occurred:
+` + "```" + `
+{{.Incident.TriggerLog}}
+` + "```" + `
+
+{{end}}## Recent Logs
+{{if .Incident.Logs}}{{if .ProblemLogs}}Warnings and errors:
+` + "```" + `
+{{range .ProblemLogs}}{{.}}
+{{end}}` + "```" + `
+
+All logs, oldest first:
+{{end}}` + "```" + `
+{{range .Incident.Logs}}{{.}}
+{{end}}` + "```" + `
+{{else}}No recent logs available
+{{end}}
+{{template "focus" .}}{{if .PreviousAttempts}}## Previously Attempted Fixes
+These fixes were already tried for this incident and FAILED. Propose a different approach.
+{{range $i, $a := .PreviousAttempts}}{{inc $i}}. Fix type: {{$a.FixType}}
+{{if $a.Description}}   Diagnosis: {{$a.Description}}
+{{end}}{{range $a.Steps}}   - {{.}}
+{{end}}{{end}}
+{{end}}## Your Task
+Analyze this incident and provide a JSON response with:
+1. Root cause diagnosis
+2. Fix type (restart/config/code)
+3. Detailed fix steps
+4. Any code needed
+5. Your confidence level (0-1)
+
+Respond ONLY with valid JSON. No markdown, no explanations outside the JSON.`
+
+// configSection renders the service config captured at detection
+const configSection = `{{define "config"}}## Current Configuration
+{{if .Config}}` + "```" + `
+{{range .Config}}{{.Key}} = {{.Value}}
+{{end}}` + "```" + `
+{{else}}Configuration was not available at detection time
+{{end}}
+{{end}}`
+
+// defaultFocus is used for incident types without a dedicated template
+const defaultFocus = `{{define "focus"}}{{template "config" .}}{{end}}`
+
+// focusTemplates holds the type-specific part of each prompt
+var focusTemplates = map[models.IncidentType]string{
+	models.ServiceDown: `{{define "focus"}}## Analysis Focus
+The service process is down or not responding. Determine whether it crashed, was stopped,
+or is hung, and whether a restart is enough or the crash will recur.
+
+{{template "config" .}}{{end}}`,
+
+	models.ConfigError: `{{define "focus"}}## Analysis Focus
+The service configuration is suspected to be invalid. Compare each value below against what
+a healthy service needs and name the exact keys to change and the values to set.
+
+{{template "config" .}}{{end}}`,
+
+	models.ResourceExhaustion: `{{define "focus"}}## Analysis Focus
+The service has run out of a resource. Use the logs to tell memory pressure apart from a
+blocked or already-bound port: memory issues usually need config limits lowered, port
+conflicts usually need a restart.
+
+{{with .Incident.Profile}}## Runtime Profile
+Captured from the service's /debug/pprof at detection:
+- Goroutines: {{.Goroutines}}
+- Heap in use: {{mib .HeapInUseBytes}}
+{{if .TopAllocations}}- Top allocation sites by heap in use:
+{{range .TopAllocations}}  - {{.Function}}: {{mib .InUseBytes}}
+{{end}}{{end}}
+{{end}}{{template "config" .}}{{end}}`,
+
+	models.DependencyFailure: `{{define "focus"}}## Analysis Focus
+A downstream dependency is unreachable. Concentrate on connectivity: check the dependency
+host and port in the config below, DNS resolution, timeouts and retry settings. Restarting
+this service will not help if the dependency address itself is wrong.
+
+{{template "config" .}}{{end}}`,
+
+	models.Flapping: `{{define "focus"}}## Analysis Focus
+The service is flapping between healthy and unhealthy. Look for intermittent causes such as
+tight timeouts, retry storms or an unstable dependency rather than a single hard failure.
+
+{{template "config" .}}{{end}}`,
+
+	models.Degraded: `{{define "focus"}}## Analysis Focus
+The service still answers requests but reports a degraded health state. Look for partial
+failures and elevated latency, and prefer the least disruptive fix that restores full health.
+
+{{template "config" .}}{{end}}`,
+}
+
+// promptFuncs are the helper functions available to prompt templates
+var promptFuncs = template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+	"mib": func(bytes int64) string { return fmt.Sprintf("%.1f MiB", float64(bytes)/(1<<20)) },
+}
+
+// defaultPromptTemplate is the fallback for incident types without a dedicated template
+var defaultPromptTemplate = mustPromptTemplate("default", defaultFocus)
+
+// promptTemplates is the registry of prompt templates keyed by incident type
+var promptTemplates = buildPromptTemplates()
+
+func buildPromptTemplates() map[models.IncidentType]*template.Template {
+	templates := make(map[models.IncidentType]*template.Template, len(focusTemplates))
+	for incidentType, focus := range focusTemplates {
+		templates[incidentType] = mustPromptTemplate(string(incidentType), focus)
+	}
+	return templates
+}
+
+// mustPromptTemplate combines the base layout with a focus block. The templates are
+// compiled into the binary, so a parse error is a programming error.
+func mustPromptTemplate(name, focus string) *template.Template {
+	return template.Must(template.New(name).Funcs(promptFuncs).Parse(basePromptTemplate + configSection + focus))
+}
+
+// promptTemplate returns the template for an incident type, falling back to the default
+func promptTemplate(incidentType models.IncidentType) *template.Template {
+	if tmpl, ok := promptTemplates[incidentType]; ok {
+		return tmpl
+	}
+	return defaultPromptTemplate
+}
+
+// sortedConfig converts a config or metadata map into entries sorted by key
+func sortedConfig(config map[string]string) []configEntry {
+	entries := make([]configEntry, 0, len(config))
+	for key, value := range config {
+		entries = append(entries, configEntry{Key: key, Value: value})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries
+}
+
+// problemLogs picks the warning and error lines out of an incident's logs, keeping their
+// order, so the prompt can point at them without losing the timeline
+func problemLogs(logs []string) []string {
+	var problems []string
+	for _, line := range logs {
+		if models.LogLineLevel(line).Rank() >= models.LogWarn.Rank() {
+			problems = append(problems, line)
+		}
+	}
+	return problems
+}
+
+// renderPrompt executes the incident type's template
+func renderPrompt(incident *models.Incident, serviceURL string, previousAttempts []models.Resolution) (string, error) {
+	var sb strings.Builder
+	err := promptTemplate(incident.Type).Execute(&sb, promptData{
+		Incident:         incident,
+		ServiceURL:       serviceURL,
+		ProblemLogs:      problemLogs(incident.Logs),
+		Metadata:         sortedConfig(incident.Metadata),
+		Config:           sortedConfig(incident.ServiceConfig),
+		PreviousAttempts: previousAttempts,
+	})
+	return sb.String(), err
+}
@@ -0,0 +1,121 @@
+package ai
+
+import (
+	"incident-ai/models"
+	"strings"
+	"testing"
+)
+
+func TestPromptTemplatePerType(t *testing.T) {
+	tests := []struct {
+		incidentType models.IncidentType
+		wantFocus    string // "" = the default template, without a focus section
+	}{
+		{models.ServiceDown, "crashed, was stopped"},
+		{models.ConfigError, "name the exact keys to change"},
+		{models.ResourceExhaustion, "memory pressure apart from a\nblocked or already-bound port"},
+		{models.DependencyFailure, "Concentrate on connectivity"},
+		{models.Flapping, "flapping between healthy and unhealthy"},
+		{models.Degraded, "degraded health state"},
+		{"UNKNOWN_TYPE", ""},
+	}
+
+	for _, tt := range tests {
+		incident := testIncident()
+		incident.Type = tt.incidentType
+
+		prompt, err := renderPrompt(incident, "http://orders:8080", nil)
+		if err != nil {
+			t.Fatalf("%s: renderPrompt: %v", tt.incidentType, err)
+		}
+
+		if tt.wantFocus == "" {
+			if strings.Contains(prompt, "## Analysis Focus") {
+				t.Errorf("%s: default prompt has a focus section:\n%s", tt.incidentType, prompt)
+			}
+		} else if !strings.Contains(prompt, "## Analysis Focus\n") || !strings.Contains(prompt, tt.wantFocus) {
+			t.Errorf("%s: prompt lacks its focus %q:\n%s", tt.incidentType, tt.wantFocus, prompt)
+		}
+
+		// Every template carries the live config and logs
+		for _, want := range []string{"- Type: " + string(tt.incidentType), "- Address: http://orders:8080", "max_connections = 10\ntimeout = 30s", "ERROR: too many connections"} {
+			if !strings.Contains(prompt, want) {
+				t.Errorf("%s: prompt doesn't contain %q:\n%s", tt.incidentType, want, prompt)
+			}
+		}
+	}
+}
+
+func TestPromptWithoutConfig(t *testing.T) {
+	incident := testIncident()
+	incident.ServiceConfig = nil
+
+	prompt, err := renderPrompt(incident, "", nil)
+	if err != nil {
+		t.Fatalf("renderPrompt: %v", err)
+	}
+	if !strings.Contains(prompt, "Configuration was not available at detection time") || strings.Contains(prompt, "- Address:") {
+		t.Errorf("prompt without config or address:\n%s", prompt)
+	}
+}
+
+func TestPromptTriggerLog(t *testing.T) {
+	incident := testIncident()
+	line := "[09:02:00] ERROR Configuration corrupted - invalid values detected [incident fault-2]"
+
+	for _, triggerLog := range []string{"", line} {
+		incident.TriggerLog = triggerLog
+		prompt, err := renderPrompt(incident, "", nil)
+		if err != nil {
+			t.Fatalf("renderPrompt: %v", err)
+		}
+
+		section := "## Triggering Log Entry\nThis is the log line the service wrote when the fault occurred:\n```\n" + line + "\n```"
+		if got := strings.Contains(prompt, section); got != (triggerLog != "") {
+			t.Errorf("trigger log %q: prompt has the triggering log section = %v:\n%s", triggerLog, got, prompt)
+		}
+	}
+}
+
+func TestPromptServiceMetadata(t *testing.T) {
+	incident := testIncident()
+	incident.Metadata = map[string]string{"tier": "1", "team": "payments"}
+
+	prompt, err := renderPrompt(incident, "", nil)
+	if err != nil {
+		t.Fatalf("renderPrompt: %v", err)
+	}
+	if !strings.Contains(prompt, "- Language: Go\n- team: payments\n- tier: 1\n\n## Incident Details") {
+		t.Errorf("prompt doesn't list the metadata under Service Information, sorted:\n%s", prompt)
+	}
+
+	incident.Metadata = nil
+	prompt, _ = renderPrompt(incident, "", nil)
+	if !strings.Contains(prompt, "- Language: Go\n\n## Incident Details") {
+		t.Errorf("prompt without metadata:\n%s", prompt)
+	}
+}
+
+func TestPromptRuntimeProfile(t *testing.T) {
+	incident := testIncident()
+	incident.Profile = &models.ProfileSummary{
+		Goroutines:     1234,
+		HeapInUseBytes: 3 << 20,
+		TopAllocations: []models.AllocationSite{{Function: "main.leakCache", InUseBytes: 2 << 20}},
+	}
+
+	prompt, err := renderPrompt(incident, "", nil)
+	if err != nil {
+		t.Fatalf("renderPrompt: %v", err)
+	}
+	section := "## Runtime Profile\nCaptured from the service's /debug/pprof at detection:\n- Goroutines: 1234\n- Heap in use: 3.0 MiB\n" +
+		"- Top allocation sites by heap in use:\n  - main.leakCache: 2.0 MiB\n"
+	if !strings.Contains(prompt, section) {
+		t.Errorf("prompt lacks the runtime profile section %q:\n%s", section, prompt)
+	}
+
+	incident.Profile = nil
+	if prompt, _ := renderPrompt(incident, "", nil); strings.Contains(prompt, "## Runtime Profile") {
+		t.Errorf("prompt without a profile has a runtime profile section:\n%s", prompt)
+	}
+}
@@ -0,0 +1,569 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"incident-ai/buildinfo"
+	"incident-ai/memory"
+	"incident-ai/models"
+	"incident-ai/monitor"
+	"incident-ai/telemetry"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// budgetReporter is implemented by analyzers that track a daily token budget
+type budgetReporter interface {
+	BudgetStatus() map[string]interface{}
+}
+
+// APIServer exposes the orchestrator's control and status endpoints
+type APIServer struct {
+	port   string
+	orch   *Orchestrator
+	server *http.Server
+}
+
+// NewAPIServer creates a new API server for the orchestrator
+func NewAPIServer(port string, orch *Orchestrator) *APIServer {
+	return &APIServer{
+		port: port,
+		orch: orch,
+	}
+}
+
+// Start starts serving the API in the background
+func (s *APIServer) Start() error {
+	mux := http.NewServeMux()
+
+	// Maintenance mode toggle and scheduled windows
+	mux.HandleFunc("/maintenance", s.handleMaintenance)
+
+	// Monitoring status (health history, flap rate)
+	mux.HandleFunc("/status", s.handleStatus)
+
+	// Return backed-off health probes to the normal interval
+	mux.HandleFunc("/monitor/reset", s.handleMonitorReset)
+
+	// Incidents pushed by external alerting sources
+	mux.HandleFunc("/alerts", s.handleAlerts)
+
+	// Live operational metrics
+	mux.HandleFunc("/ops", s.handleOps)
+
+	// Store summary
+	mux.HandleFunc("/summary", s.handleSummary)
+
+	// Incident counts over time, for charts
+	mux.HandleFunc("/timeseries", s.handleTimeSeries)
+
+	// Per-type resolution breakdown and learned fix success rates
+	mux.HandleFunc("/fix-effectiveness", s.handleFixEffectiveness)
+
+	// Learned fix history per incident type, best first
+	mux.HandleFunc("/fixes", s.handleFixes)
+
+	// Per-type failure streaks that escalate incidents instead of remediating them
+	mux.HandleFunc("/failure-streaks", s.handleFailureStreaks)
+
+	// Re-send webhook notifications that were dead-lettered after exhausting retries
+	mux.HandleFunc("/notifications/replay", s.handleReplayNotifications)
+
+	// Build info and readiness
+	mux.HandleFunc("/version", s.handleVersion)
+	mux.HandleFunc("/ready", s.handleReady)
+
+	// Incident listing, lookup and actions
+	mux.HandleFunc("/incidents", s.handleIncidents)
+	mux.HandleFunc("/incidents/", s.handleIncident)
+
+	// Bind before returning, so a port that is already in use fails Start
+	listener, err := net.Listen("tcp", ":"+s.port)
+	if err != nil {
+		return fmt.Errorf("failed to listen on port %s: %w", s.port, err)
+	}
+
+	s.server = &http.Server{
+		Addr:    listener.Addr().String(),
+		Handler: mux,
+	}
+	server := s.server
+
+	go func() {
+		log.Printf("[API] Listening on port %s\n", s.port)
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("[API] Error: %v\n", err)
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops the API server
+func (s *APIServer) Stop() error {
+	if s.server == nil {
+		return nil
+	}
+	return s.server.Close()
+}
+
+func (s *APIServer) handleMaintenance(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		// Report current state below
+
+	case http.MethodPost:
+		enabled, err := strconv.ParseBool(r.URL.Query().Get("enabled"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "enabled must be true or false")
+			return
+		}
+		s.orch.detector.SetMaintenanceMode(enabled)
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("method %s not allowed", r.Method))
+		return
+	}
+
+	manual, windows := s.orch.detector.MaintenanceStatus()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"active":  s.orch.detector.InMaintenance(),
+		"manual":  manual,
+		"windows": windows,
+		"time":    time.Now().Format(time.RFC3339),
+	})
+}
+
+// handleMonitorReset returns the detector to its normal probe cadence, e.g. after the
+// service was fixed by hand while probes were backed off
+func (s *APIServer) handleMonitorReset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("method %s not allowed", r.Method))
+		return
+	}
+
+	s.orch.detector.ResetBackoff()
+	writeJSON(w, http.StatusOK, s.orch.detector.BackoffStatus())
+}
+
+// maxAlertBytes bounds the body of a pushed alert
+const maxAlertBytes = 1 << 20
+
+// handleAlerts raises an incident for an alert pushed by an external source. The alert's
+// X-Correlation-ID header, if any, becomes the incident's correlation ID.
+func (s *APIServer) handleAlerts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("method %s not allowed", r.Method))
+		return
+	}
+
+	var alert monitor.Alert
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAlertBytes)).Decode(&alert); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid alert: %v", err))
+		return
+	}
+
+	incident, err := s.orch.detector.ReceiveAlert(alert, r.Header.Get(telemetry.CorrelationHeader))
+	switch {
+	case errors.Is(err, monitor.ErrAlertSuppressed):
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	case errors.Is(err, monitor.ErrAlertDropped):
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set(telemetry.CorrelationHeader, incident.CorrelationID)
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{
+		"incident_id":    incident.ID,
+		"type":           incident.Type,
+		"severity":       incident.Severity,
+		"correlation_id": incident.CorrelationID,
+	})
+}
+
+func (s *APIServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("method %s not allowed", r.Method))
+		return
+	}
+
+	status := map[string]interface{}{
+		"monitor": s.orch.detector.Status(),
+	}
+	if reporter, ok := s.orch.analyzer.(budgetReporter); ok {
+		if budget := reporter.BudgetStatus(); budget != nil {
+			status["ai_budget"] = budget
+		}
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (s *APIServer) handleOps(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("method %s not allowed", r.Method))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.orch.OpsSnapshot())
+}
+
+func (s *APIServer) handleSummary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("method %s not allowed", r.Method))
+		return
+	}
+
+	store, ok := s.tenantStore(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, store.Summary())
+}
+
+// handleTimeSeries serves incident counts per time bucket, e.g. /timeseries?bucket=day&by=type.
+// bucket is hour (the default), day or a duration such as 15m; by is type, status or empty.
+func (s *APIServer) handleTimeSeries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("method %s not allowed", r.Method))
+		return
+	}
+
+	bucket, err := parseBucket(r.URL.Query().Get("bucket"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	store, ok := s.tenantStore(w, r)
+	if !ok {
+		return
+	}
+
+	series, err := store.CountByTimeBucket(bucket, r.URL.Query().Get("by"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, series)
+}
+
+// parseBucket parses a time series bucket width: hour, day or a duration
+func parseBucket(s string) (time.Duration, error) {
+	switch s {
+	case "", "hour":
+		return time.Hour, nil
+	case "day":
+		return 24 * time.Hour, nil
+	}
+
+	bucket, err := time.ParseDuration(s)
+	if err != nil || bucket <= 0 {
+		return 0, fmt.Errorf("invalid bucket %q: must be hour, day or a positive duration", s)
+	}
+	return bucket, nil
+}
+
+func (s *APIServer) handleFixEffectiveness(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("method %s not allowed", r.Method))
+		return
+	}
+
+	store, ok := s.tenantStore(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, store.FixEffectivenessReport())
+}
+
+func (s *APIServer) handleFixes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("method %s not allowed", r.Method))
+		return
+	}
+
+	store, ok := s.tenantStore(w, r)
+	if !ok {
+		return
+	}
+
+	if name := r.URL.Query().Get("type"); name != "" {
+		incidentType, ok := models.NormalizeIncidentType(name)
+		if !ok {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown incident type %q", name))
+			return
+		}
+		writeJSON(w, http.StatusOK, store.FixHistory(incidentType))
+		return
+	}
+	writeJSON(w, http.StatusOK, store.AllFixHistory())
+}
+
+func (s *APIServer) handleFailureStreaks(w http.ResponseWriter, r *http.Request) {
+	var store *memory.Store
+	switch r.Method {
+	case http.MethodGet:
+		var ok bool
+		if store, ok = s.tenantStore(w, r); !ok {
+			return
+		}
+
+	case http.MethodDelete:
+		if !s.ownTenant(w, r) {
+			return
+		}
+		store = s.orch.store
+
+		name := r.URL.Query().Get("type")
+		incidentType, ok := models.NormalizeIncidentType(name)
+		if !ok {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown incident type %q", name))
+			return
+		}
+		if err := store.ResetFailureStreak(incidentType); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("method %s not allowed", r.Method))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"escalate_after": s.orch.escalateAfter,
+		"streaks":        store.FailureStreaks(),
+	})
+}
+
+func (s *APIServer) handleVersion(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, buildinfo.Get())
+}
+
+// handleReplayNotifications re-sends dead-lettered webhook notifications
+func (s *APIServer) handleReplayNotifications(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("method %s not allowed", r.Method))
+		return
+	}
+	if s.orch.webhook == nil {
+		writeError(w, http.StatusNotFound, "no webhook configured")
+		return
+	}
+
+	delivered, err := s.orch.webhook.ReplayDeadLetters(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"delivered": delivered})
+}
+
+// handleReady reports ready once the orchestrator is initialized and monitoring has started
+func (s *APIServer) handleReady(w http.ResponseWriter, r *http.Request) {
+	if !s.orch.ready.Load() {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]bool{"ready": false})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]bool{"ready": true})
+}
+
+func (s *APIServer) handleIncidents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("method %s not allowed", r.Method))
+		return
+	}
+
+	store, ok := s.tenantStore(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, store.GetAllIncidents())
+}
+
+// handleIncident serves /incidents/{id} and /incidents/{id}/{action}
+func (s *APIServer) handleIncident(w http.ResponseWriter, r *http.Request) {
+	id, action := splitIncidentPath(r.URL.Path)
+	if id == "" {
+		writeError(w, http.StatusNotFound, "incident ID required")
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		store, ok := s.tenantStore(w, r)
+		if !ok {
+			return
+		}
+		incident, err := store.GetIncident(id)
+		if err != nil {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, incident)
+
+	case action == "postmortem" && r.Method == http.MethodGet:
+		store, ok := s.tenantStore(w, r)
+		if !ok {
+			return
+		}
+		postmortem, err := s.orch.GeneratePostmortem(store, id)
+		if err != nil {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, postmortem)
+
+	case action == "ack" && r.Method == http.MethodPost:
+		if !s.ownTenant(w, r) {
+			return
+		}
+		by := r.URL.Query().Get("by")
+		if by == "" {
+			writeError(w, http.StatusBadRequest, "by is required")
+			return
+		}
+		incident, err := s.orch.Acknowledge(id, by)
+		if err != nil {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, incident)
+
+	case action == "abort" && r.Method == http.MethodPost:
+		if !s.ownTenant(w, r) {
+			return
+		}
+		if err := s.orch.Abort(id, r.URL.Query().Get("by")); err != nil {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusAccepted, map[string]string{"id": id, "status": "aborting"})
+
+	case action == "reanalyze" && r.Method == http.MethodPost:
+		if !s.ownTenant(w, r) {
+			return
+		}
+		if _, err := s.orch.store.GetIncident(id); err != nil {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		incident, err := s.orch.Reanalyze(r.Context(), id)
+		if errors.Is(err, errAIDisabled) || errors.Is(err, errStillProcessing) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusBadGateway, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, incident)
+
+	default:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no route for %s %s", r.Method, r.URL.Path))
+	}
+}
+
+// tenantStore returns the store named by the request's tenant parameter, or the
+// orchestrator's own store without one. Another tenant's store is read from its file on
+// every request, as the orchestrator handling it keeps changing it, and is read-only. A
+// tenant the API doesn't serve is answered with 404.
+func (s *APIServer) tenantStore(w http.ResponseWriter, r *http.Request) (*memory.Store, bool) {
+	tenant, ok := requestTenant(r)
+	if !ok || tenant == s.orch.store.Tenant() {
+		return s.orch.store, true
+	}
+
+	if !s.orch.tenants[tenant] {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("tenant %q is not served", tenant))
+		return nil, false
+	}
+	store, err := s.orch.store.View(tenant)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	return store, true
+}
+
+// ownTenant checks that a request changing incidents, failure streaks or their handling,
+// which only this orchestrator does for its own tenant, doesn't name another tenant. Such
+// a request is answered with 403.
+func (s *APIServer) ownTenant(w http.ResponseWriter, r *http.Request) bool {
+	tenant, ok := requestTenant(r)
+	if ok && tenant != s.orch.store.Tenant() {
+		writeError(w, http.StatusForbidden, fmt.Sprintf("tenant %q is read-only: it is handled by another orchestrator", tenant))
+		return false
+	}
+	return true
+}
+
+// requestTenant returns the tenant named by the request's tenant parameter, if any, where
+// "default" names the default tenant
+func requestTenant(r *http.Request) (string, bool) {
+	tenant, ok := r.URL.Query()["tenant"]
+	if !ok {
+		return "", false
+	}
+	return tenantName(tenant[0]), true
+}
+
+// tenantName maps "default" to the default tenant
+func tenantName(name string) string {
+	if name == "default" {
+		return memory.DefaultTenant
+	}
+	return name
+}
+
+// servedTenants resolves the tenants the API serves: the orchestrator's own and the
+// comma-separated tenants in spec, where "default" names the default tenant. Each is
+// checked to be readable.
+func servedTenants(own *memory.Store, spec string) (map[string]bool, error) {
+	tenants := map[string]bool{own.Tenant(): true}
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		tenant := tenantName(name)
+		if _, err := own.View(tenant); err != nil {
+			return nil, err
+		}
+		tenants[tenant] = true
+	}
+	return tenants, nil
+}
+
+// splitIncidentPath extracts the incident ID and optional action from /incidents/{id}[/{action}]
+func splitIncidentPath(path string) (string, string) {
+	parts := strings.SplitN(strings.Trim(strings.TrimPrefix(path, "/incidents/"), "/"), "/", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
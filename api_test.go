@@ -0,0 +1,445 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"incident-ai/buildinfo"
+	"incident-ai/memory"
+	"incident-ai/models"
+	"incident-ai/monitor"
+	"incident-ai/telemetry"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serveAPI sends a request to the API's incident endpoints and returns the response
+func serveAPI(s *APIServer, method, target string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(method, target, nil)
+	if path := request.URL.Path; path == "/incidents" {
+		s.handleIncidents(recorder, request)
+	} else {
+		s.handleIncident(recorder, request)
+	}
+	return recorder
+}
+
+// decodeIncident decodes an incident from a successful API response
+func decodeIncident(t *testing.T, recorder *httptest.ResponseRecorder) *models.Incident {
+	t.Helper()
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", recorder.Code, http.StatusOK, recorder.Body)
+	}
+	var incident models.Incident
+	if err := json.NewDecoder(recorder.Body).Decode(&incident); err != nil {
+		t.Fatalf("decoding incident: %v", err)
+	}
+	return &incident
+}
+
+func TestReanalyzeKeepsFirstDiagnosis(t *testing.T) {
+	o := newTestOrchestrator(t)
+	analyzer := o.analyzer.(*fakeAnalyzer)
+	api := NewAPIServer("0", o)
+
+	incident := newTestIncident("a", models.ServiceDown, "health check timed out")
+	if err := o.processIncident(context.Background(), incident); err != nil {
+		t.Fatalf("processIncident: %v", err)
+	}
+
+	revisions := []string{"Connection pool exhausted after the deploy", "Memory leak in the request handler"}
+	for i, diagnosis := range revisions {
+		analyzer.response.Diagnosis = diagnosis
+		analyzer.response.RootCauseCategory = "capacity"
+
+		reanalyzed := decodeIncident(t, serveAPI(api, http.MethodPost, "/incidents/a/reanalyze"))
+		if len(reanalyzed.DiagnosisRevisions) != i+1 {
+			t.Fatalf("%d diagnosis revisions after re-analysis %d, want %d", len(reanalyzed.DiagnosisRevisions), i+1, i+1)
+		}
+	}
+
+	stored := decodeIncident(t, serveAPI(api, http.MethodGet, "/incidents/a"))
+	if stored.Diagnosis != "AI diagnosis" || stored.Status != models.StatusResolved {
+		t.Errorf("stored diagnosis = %q, status %s; want the original diagnosis, resolved", stored.Diagnosis, stored.Status)
+	}
+	for i, revision := range stored.DiagnosisRevisions {
+		if revision.Diagnosis != revisions[i] || revision.RootCauseCategory != "capacity" {
+			t.Errorf("revision %d = %q (%s), want %q", i+1, revision.Diagnosis, revision.RootCauseCategory, revisions[i])
+		}
+		if revision.RecommendedFix == nil || revision.RecommendedFix.FixType != "restart" {
+			t.Errorf("revision %d recommends %+v, want the re-analysis' restart fix", i+1, revision.RecommendedFix)
+		}
+	}
+	if len(stored.DiagnosisRevisions) != len(revisions) {
+		t.Errorf("%d diagnosis revisions stored, want %d", len(stored.DiagnosisRevisions), len(revisions))
+	}
+}
+
+func TestReanalyzeRejected(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		setup      func(t *testing.T, o *Orchestrator)
+		wantStatus int
+		wantError  string
+	}{
+		{name: "unknown incident", target: "/incidents/missing/reanalyze", wantStatus: http.StatusNotFound, wantError: "not found"},
+		{name: "other tenant", target: "/incidents/a/reanalyze?tenant=other", wantStatus: http.StatusForbidden, wantError: "read-only"},
+		{
+			name:       "AI disabled",
+			target:     "/incidents/a/reanalyze",
+			setup:      func(t *testing.T, o *Orchestrator) { o.useAI = false },
+			wantStatus: http.StatusConflict,
+			wantError:  errAIDisabled.Error(),
+		},
+		{
+			name:   "still processing",
+			target: "/incidents/a/reanalyze",
+			setup: func(t *testing.T, o *Orchestrator) {
+				_, done := o.beginProcessing(context.Background(), "a")
+				t.Cleanup(done)
+			},
+			wantStatus: http.StatusConflict,
+			wantError:  errStillProcessing.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOrchestrator(t)
+			if err := o.store.StoreIncident(newTestIncident("a", models.ServiceDown, "health check timed out")); err != nil {
+				t.Fatalf("StoreIncident: %v", err)
+			}
+			if tt.setup != nil {
+				tt.setup(t, o)
+			}
+
+			recorder := serveAPI(NewAPIServer("0", o), http.MethodPost, tt.target)
+			if recorder.Code != tt.wantStatus || !strings.Contains(recorder.Body.String(), tt.wantError) {
+				t.Errorf("response = %d %s, want %d containing %q", recorder.Code, recorder.Body, tt.wantStatus, tt.wantError)
+			}
+			if analyzed := o.analyzer.(*fakeAnalyzer).analyzed; len(analyzed) != 0 {
+				t.Errorf("AI called for %v, want no re-analysis", analyzed)
+			}
+		})
+	}
+}
+
+func TestIncidentAPIShowsStepResults(t *testing.T) {
+	o := newTestOrchestrator(t)
+	incident := newTestIncident("a", models.ConfigError, "database_url is invalid")
+	incident.Status = models.StatusFailed
+	incident.Resolution = &models.Resolution{
+		FixType: "config",
+		Steps:   []string{"Restore database_url to localhost:5432", "Tune the connection pool"},
+		StepResults: []models.StepResult{
+			{Step: "Restore database_url to localhost:5432", Applied: true, Action: "set database_url=localhost:5432"},
+			{Step: "Tune the connection pool", Error: "no config change recognized in step"},
+		},
+	}
+	if err := o.store.StoreIncident(incident); err != nil {
+		t.Fatalf("StoreIncident: %v", err)
+	}
+
+	recorder := serveAPI(NewAPIServer("0", o), http.MethodGet, "/incidents/a")
+	var body struct {
+		Resolution struct {
+			StepResults []map[string]interface{} `json:"step_results"`
+		} `json:"resolution"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+		t.Fatalf("decoding incident: %v", err)
+	}
+
+	steps := body.Resolution.StepResults
+	if len(steps) != 2 {
+		t.Fatalf("%d step results served, want 2", len(steps))
+	}
+	if steps[0]["applied"] != true || steps[0]["action"] != "set database_url=localhost:5432" {
+		t.Errorf("first step served as %v, want it applied", steps[0])
+	}
+	if steps[1]["applied"] != false || steps[1]["error"] != "no config change recognized in step" {
+		t.Errorf("second step served as %v, want it unparseable", steps[1])
+	}
+}
+
+func TestAPIServesConfiguredTenants(t *testing.T) {
+	o := newTestOrchestrator(t)
+
+	// This orchestrator handles team-a. The root store and team-b's store stand in for
+	// the orchestrators handling the default tenant and team-b, which own their files.
+	root := o.store
+	own, err := root.Namespace("team-a")
+	if err != nil {
+		t.Fatalf("Namespace(team-a): %v", err)
+	}
+	other, err := root.Namespace("team-b")
+	if err != nil {
+		t.Fatalf("Namespace(team-b): %v", err)
+	}
+	o.store = own
+	if o.tenants, err = servedTenants(own, "team-b, default"); err != nil {
+		t.Fatalf("servedTenants: %v", err)
+	}
+
+	for store, incident := range map[*memory.Store]*models.Incident{
+		own:   newTestIncident("a", models.ServiceDown, "health check timed out"),
+		other: newTestIncident("b", models.ConfigError, "database_url is invalid"),
+		root:  newTestIncident("d", models.ResourceExhaustion, "memory usage at 95%"),
+	} {
+		if err := store.StoreIncident(incident); err != nil {
+			t.Fatalf("StoreIncident(%s): %v", incident.ID, err)
+		}
+	}
+	api := NewAPIServer("0", o)
+
+	tests := []struct {
+		method     string
+		target     string
+		wantStatus int
+		wantBody   string
+	}{
+		{http.MethodGet, "/incidents/a", http.StatusOK, `"id":"a"`},
+		{http.MethodGet, "/incidents/a?tenant=team-a", http.StatusOK, `"id":"a"`},
+		{http.MethodGet, "/incidents/b", http.StatusNotFound, "not found"},
+		{http.MethodGet, "/incidents/b?tenant=team-b", http.StatusOK, `"id":"b"`},
+		{http.MethodGet, "/incidents?tenant=team-b", http.StatusOK, `"id":"b"`},
+		{http.MethodGet, "/incidents/b/postmortem?tenant=team-b", http.StatusOK, "b"},
+		{http.MethodGet, "/incidents/d?tenant=default", http.StatusOK, `"id":"d"`},
+		{http.MethodGet, "/incidents/d?tenant=", http.StatusOK, `"id":"d"`},
+		{http.MethodGet, "/incidents?tenant=team-c", http.StatusNotFound, `tenant \"team-c\" is not served`},
+		{http.MethodGet, "/incidents/a?tenant=team-c", http.StatusNotFound, `tenant \"team-c\" is not served`},
+		{http.MethodPost, "/incidents/b/ack?by=alice&tenant=team-b", http.StatusForbidden, "handled by another orchestrator"},
+		{http.MethodPost, "/incidents/b/abort?tenant=team-b", http.StatusForbidden, "handled by another orchestrator"},
+		{http.MethodPost, "/incidents/b/reanalyze?tenant=team-b", http.StatusForbidden, "handled by another orchestrator"},
+		{http.MethodPost, "/incidents/d/reanalyze?tenant=default", http.StatusForbidden, "handled by another orchestrator"},
+		{http.MethodPost, "/incidents/a/ack?by=alice", http.StatusOK, `"acknowledged_by":"alice"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			recorder := serveAPI(api, tt.method, tt.target)
+			if recorder.Code != tt.wantStatus || !strings.Contains(recorder.Body.String(), tt.wantBody) {
+				t.Errorf("response = %d %s, want %d containing %s", recorder.Code, recorder.Body, tt.wantStatus, tt.wantBody)
+			}
+		})
+	}
+
+	recorder := httptest.NewRecorder()
+	api.handleFailureStreaks(recorder, httptest.NewRequest(http.MethodDelete, "/failure-streaks?type=crash&tenant=team-b", nil))
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf("resetting team-b's failure streak = %d %s, want %d", recorder.Code, recorder.Body, http.StatusForbidden)
+	}
+
+	// Incidents team-b's orchestrator stores later are served too
+	if err := other.StoreIncident(newTestIncident("b2", models.ServiceDown, "connection refused")); err != nil {
+		t.Fatalf("StoreIncident(b2): %v", err)
+	}
+	if stored := decodeIncident(t, serveAPI(api, http.MethodGet, "/incidents/b2?tenant=team-b")); stored.ID != "b2" {
+		t.Errorf("served %s, want b2", stored.ID)
+	}
+}
+
+func TestAPIServerStartOnUsedPort(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	defer listener.Close()
+	port := strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+
+	api := NewAPIServer(port, newTestOrchestrator(t))
+	if err := api.Start(); err == nil {
+		api.Stop()
+		t.Fatalf("Start on port %s in use succeeded, want an error", port)
+	}
+}
+
+func TestMonitorResetEndpoint(t *testing.T) {
+	o := newTestOrchestrator(t)
+	o.detector = monitor.NewIncidentDetector("http://127.0.0.1:1", time.Second, monitor.WithProbeBackoff(time.Minute))
+	api := NewAPIServer("0", o)
+
+	recorder := httptest.NewRecorder()
+	api.handleMonitorReset(recorder, httptest.NewRequest(http.MethodPost, "/monitor/reset", nil))
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), `"current_interval":"1s"`) {
+		t.Errorf("POST /monitor/reset = %d %s, want the check interval restored", recorder.Code, recorder.Body)
+	}
+
+	recorder = httptest.NewRecorder()
+	api.handleMonitorReset(recorder, httptest.NewRequest(http.MethodGet, "/monitor/reset", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /monitor/reset = %d, want %d", recorder.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestReadyAndVersionEndpoints(t *testing.T) {
+	api := NewAPIServer("0", newTestOrchestrator(t))
+
+	ready := func() *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		api.handleReady(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		return recorder
+	}
+	if recorder := ready(); recorder.Code != http.StatusServiceUnavailable || !strings.Contains(recorder.Body.String(), `"ready":false`) {
+		t.Errorf("/ready while starting = %d %s, want not ready", recorder.Code, recorder.Body)
+	}
+	api.orch.ready.Store(true)
+	if recorder := ready(); recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), `"ready":true`) {
+		t.Errorf("/ready once started = %d %s, want ready", recorder.Code, recorder.Body)
+	}
+
+	recorder := httptest.NewRecorder()
+	api.handleVersion(recorder, httptest.NewRequest(http.MethodGet, "/version", nil))
+	var info buildinfo.Info
+	if err := json.NewDecoder(recorder.Body).Decode(&info); err != nil || info != buildinfo.Get() {
+		t.Errorf("/version = %+v, %v; want %+v", info, err, buildinfo.Get())
+	}
+}
+
+func TestAlertsEndpoint(t *testing.T) {
+	o := newTestOrchestrator(t)
+	o.detector = monitor.NewIncidentDetector("http://127.0.0.1:1", time.Second)
+	api := NewAPIServer("0", o)
+
+	tests := []struct {
+		name          string
+		method        string
+		body          string
+		correlationID string
+		wantCode      int
+		wantType      models.IncidentType
+	}{
+		{name: "alias with correlation ID", method: http.MethodPost, body: `{"type": "crash", "source": "alertmanager"}`, correlationID: "am-7", wantCode: http.StatusAccepted, wantType: models.ServiceDown},
+		{name: "generated correlation ID", method: http.MethodPost, body: `{"type": "Config-Error", "severity": "medium"}`, wantCode: http.StatusAccepted, wantType: models.ConfigError},
+		{name: "unknown type", method: http.MethodPost, body: `{"type": "gremlins"}`, wantCode: http.StatusBadRequest},
+		{name: "not JSON", method: http.MethodPost, body: `type=crash`, wantCode: http.StatusBadRequest},
+		{name: "GET", method: http.MethodGet, wantCode: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/alerts", strings.NewReader(tt.body))
+			if tt.correlationID != "" {
+				req.Header.Set(telemetry.CorrelationHeader, tt.correlationID)
+			}
+			recorder := httptest.NewRecorder()
+			api.handleAlerts(recorder, req)
+
+			if recorder.Code != tt.wantCode {
+				t.Fatalf("%s /alerts = %d %s, want %d", tt.method, recorder.Code, recorder.Body, tt.wantCode)
+			}
+			if tt.wantType == "" {
+				select {
+				case incident := <-o.detector.GetIncidentChannel():
+					t.Errorf("rejected alert raised incident %s", incident.ID)
+				default:
+				}
+				return
+			}
+
+			var got struct {
+				IncidentID    string              `json:"incident_id"`
+				Type          models.IncidentType `json:"type"`
+				CorrelationID string              `json:"correlation_id"`
+			}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if got.Type != tt.wantType {
+				t.Errorf("type = %s, want %s", got.Type, tt.wantType)
+			}
+			if tt.correlationID != "" && got.CorrelationID != tt.correlationID {
+				t.Errorf("correlation ID = %q, want the inbound %q", got.CorrelationID, tt.correlationID)
+			}
+			if header := recorder.Header().Get(telemetry.CorrelationHeader); header == "" || header != got.CorrelationID {
+				t.Errorf("%s header = %q, want %q", telemetry.CorrelationHeader, header, got.CorrelationID)
+			}
+
+			// The alert is queued for the orchestrator like a detected incident
+			select {
+			case incident := <-o.detector.GetIncidentChannel():
+				if incident.ID != got.IncidentID || incident.CorrelationID != got.CorrelationID {
+					t.Errorf("queued incident %s (%s), want %s (%s)", incident.ID, incident.CorrelationID, got.IncidentID, got.CorrelationID)
+				}
+			case <-time.After(time.Second):
+				t.Error("alert queued no incident")
+			}
+		})
+	}
+}
+
+func TestTimeSeriesEndpoint(t *testing.T) {
+	o := newTestOrchestrator(t)
+	api := NewAPIServer("0", o)
+
+	day := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	for i, at := range []time.Time{day.Add(time.Hour), day.Add(2 * time.Hour), day.Add(50 * time.Hour)} {
+		incident := newTestIncident(strconv.Itoa(i), models.ServiceDown, "health check timed out")
+		incident.DetectedAt = at
+		if err := o.store.StoreIncident(incident); err != nil {
+			t.Fatalf("StoreIncident: %v", err)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	api.handleTimeSeries(recorder, httptest.NewRequest(http.MethodGet, "/timeseries?bucket=day&by=type", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", recorder.Code, recorder.Body)
+	}
+	var series []memory.TimeBucket
+	if err := json.NewDecoder(recorder.Body).Decode(&series); err != nil {
+		t.Fatalf("decoding series: %v", err)
+	}
+	totals := make([]int, len(series))
+	for i, point := range series {
+		totals[i] = point.Counts[string(models.ServiceDown)]
+	}
+	if len(series) != 3 || !series[0].Start.Equal(day) || !slices.Equal(totals, []int{2, 0, 1}) {
+		t.Errorf("daily series = %+v, want 2, 0 and 1 SERVICE_DOWN incidents from %v", series, day)
+	}
+
+	for _, query := range []string{"bucket=fortnight", "bucket=-1h", "by=severity", "bucket=1s"} {
+		recorder := httptest.NewRecorder()
+		api.handleTimeSeries(recorder, httptest.NewRequest(http.MethodGet, "/timeseries?"+query, nil))
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", query, recorder.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestFixEffectivenessEndpoint(t *testing.T) {
+	o := newTestOrchestrator(t)
+	api := NewAPIServer("0", o)
+
+	incident := newTestIncident("a", models.ServiceDown, "health check timed out")
+	if err := o.processIncident(context.Background(), incident); err != nil {
+		t.Fatalf("processIncident: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	api.handleFixEffectiveness(recorder, httptest.NewRequest(http.MethodGet, "/fix-effectiveness", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", recorder.Code, recorder.Body)
+	}
+	var report []memory.FixEffectiveness
+	if err := json.NewDecoder(recorder.Body).Decode(&report); err != nil {
+		t.Fatalf("decoding report: %v", err)
+	}
+	if len(report) != 1 || report[0].ResolvedByAI != 1 || report[0].LearnedFix == nil || report[0].LearnedFix.Successes != 1 {
+		t.Errorf("report = %+v, want one SERVICE_DOWN incident resolved by AI and its learned fix", report)
+	}
+
+	recorder = httptest.NewRecorder()
+	api.handleFixEffectiveness(recorder, httptest.NewRequest(http.MethodPost, "/fix-effectiveness", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST status = %d, want %d", recorder.Code, http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"incident-ai/memory"
+	"incident-ai/models"
+	"incident-ai/telemetry"
+)
+
+// pendingDiagnosis is the placeholder diagnosis of an incident stored at detection whose
+// processing hasn't started
+const pendingDiagnosis = "Pending analysis"
+
+// acceptIncidents stores each detected incident as soon as it is raised, with a pending
+// analysis placeholder, and queues it for processing. Incidents waiting behind the one
+// being handled can then be seen through the API.
+func (o *Orchestrator) acceptIncidents(ctx context.Context, incidents <-chan *models.Incident) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case incident := <-incidents:
+			if !o.accept(ctx, incident) {
+				continue
+			}
+			select {
+			case o.accepted <- incident:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// accept stores a detected incident awaiting processing. It reports false for an incident
+// that is the same problem as an open one, which is only counted as another occurrence.
+func (o *Orchestrator) accept(ctx context.Context, incident *models.Incident) bool {
+	ctx = telemetry.WithCorrelationID(ctx, incident.CorrelationID)
+
+	incident.Diagnosis = pendingDiagnosis
+	err := o.store.StoreIncident(incident)
+	if errors.Is(err, memory.ErrDuplicateIncident) {
+		telemetry.Logf(ctx, "[MEMORY] 🔁 %v, not handling it again\n", err)
+		return false
+	}
+	if err != nil {
+		telemetry.Logf(ctx, "[MEMORY] Warning: failed to store incident: %v\n", err)
+	}
+
+	telemetry.Logf(ctx, "[SYSTEM] 📥 Stored %s incident %s, analysis pending\n", incident.Type, incident.ID)
+	return true
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"incident-ai/models"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// gatedAnalyzer holds each AI analysis until released, announcing the incident it started
+type gatedAnalyzer struct {
+	*fakeAnalyzer
+	started chan string
+	release chan struct{}
+}
+
+func (a *gatedAnalyzer) AnalyzeIncident(ctx context.Context, incident *models.Incident, previousAttempts []models.Resolution) (*models.AIResponse, error) {
+	a.started <- incident.ID
+	<-a.release
+	return a.fakeAnalyzer.AnalyzeIncident(ctx, incident, previousAttempts)
+}
+
+func TestIncidentAnalyzingVisibleThroughAPI(t *testing.T) {
+	o := newTestOrchestrator(t)
+	analyzer := &gatedAnalyzer{fakeAnalyzer: o.analyzer.(*fakeAnalyzer), started: make(chan string), release: make(chan struct{})}
+	o.analyzer = analyzer
+	o.accepted = make(chan *models.Incident, 2)
+	api := NewAPIServer("0", o)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	detected := make(chan *models.Incident)
+	go o.acceptIncidents(ctx, detected)
+	detected <- newTestIncident("a", models.ServiceDown, "health check timed out")
+	detected <- newTestIncident("b", models.ConfigError, "database_url is invalid")
+	queued := []*models.Incident{<-o.accepted, <-o.accepted}
+
+	// Both are stored at detection, before processing starts
+	for _, id := range []string{"a", "b"} {
+		stored := decodeIncident(t, serveAPI(api, http.MethodGet, "/incidents/"+id))
+		if stored.Status != models.StatusDetected || stored.Diagnosis != pendingDiagnosis {
+			t.Errorf("incident %s served as %s %q before processing, want %s %q",
+				id, stored.Status, stored.Diagnosis, models.StatusDetected, pendingDiagnosis)
+		}
+	}
+
+	processed := make(chan struct{})
+	go func() {
+		defer close(processed)
+		for _, incident := range queued {
+			if err := o.processIncident(ctx, incident); err != nil {
+				t.Errorf("processIncident(%s): %v", incident.ID, err)
+			}
+		}
+	}()
+
+	for i, id := range []string{"a", "b"} {
+		select {
+		case started := <-analyzer.started:
+			if started != id {
+				t.Fatalf("analysis started for %s, want %s", started, id)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("analysis of %s never started", id)
+		}
+
+		stored := decodeIncident(t, serveAPI(api, http.MethodGet, "/incidents/"+id))
+		if stored.Status != models.StatusAnalyzing || stored.Diagnosis != "" {
+			t.Errorf("incident %s served as %s %q during analysis, want %s without a diagnosis",
+				id, stored.Status, stored.Diagnosis, models.StatusAnalyzing)
+		}
+		if i == 0 {
+			waiting := decodeIncident(t, serveAPI(api, http.MethodGet, "/incidents/b"))
+			if waiting.Status != models.StatusDetected || waiting.Diagnosis != pendingDiagnosis {
+				t.Errorf("incident b served as %s %q while a is analyzed, want it still pending", waiting.Status, waiting.Diagnosis)
+			}
+		}
+
+		analyzer.release <- struct{}{}
+	}
+	<-processed
+
+	for _, id := range []string{"a", "b"} {
+		stored := decodeIncident(t, serveAPI(api, http.MethodGet, "/incidents/"+id))
+		if stored.Status != models.StatusResolved || stored.Diagnosis != "AI diagnosis" {
+			t.Errorf("incident %s served as %s %q once handled, want %s with the AI's diagnosis",
+				id, stored.Status, stored.Diagnosis, models.StatusResolved)
+		}
+	}
+}
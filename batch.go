@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"incident-ai/models"
+	"incident-ai/telemetry"
+	"strings"
+)
+
+// maxAnalysisBatch is how many queued incidents are analyzed in one AI call at most
+const maxAnalysisBatch = 5
+
+// batchAnalyzer analyzes several incidents in one call, returning a response per incident
+// in order, nil for any it couldn't analyze
+type batchAnalyzer interface {
+	AnalyzeIncidents(ctx context.Context, incidents []*models.Incident) ([]*models.AIResponse, error)
+}
+
+// takeQueued returns first followed by the incidents queued behind it, up to
+// maxAnalysisBatch. Those detected within batchWindow of first that will need AI analysis
+// are analyzed together in one call, and processIncident uses those analyses instead of
+// calling the AI for each. Likely duplicates are left out, since they are only counted
+// against the incident they repeat. Without batching, only first is returned.
+func (o *Orchestrator) takeQueued(ctx context.Context, first *models.Incident, queue <-chan *models.Incident) []*models.Incident {
+	incidents := []*models.Incident{first}
+	batcher, ok := o.analyzer.(batchAnalyzer)
+	if o.batchWindow <= 0 || !o.useAI || !ok {
+		return incidents
+	}
+
+drain:
+	for len(incidents) < maxAnalysisBatch {
+		select {
+		case incident := <-queue:
+			incidents = append(incidents, incident)
+		default:
+			break drain
+		}
+	}
+
+	var batch []*models.Incident
+	for i, incident := range incidents {
+		if o.batchable(incident, first) && !o.store.LikelyDuplicate(incident, incidents[:i]...) {
+			batch = append(batch, incident)
+		}
+	}
+	if len(batch) < 2 {
+		return incidents
+	}
+
+	// The prompts carry the same inventory metadata as when analyzed one at a time
+	ids := make([]string, len(batch))
+	for i, incident := range batch {
+		o.enrich(telemetry.WithCorrelationID(ctx, incident.CorrelationID), incident)
+		ids[i] = incident.ID
+	}
+
+	// The call is bounded like processing: -incident-timeout caps it and aborting any of the
+	// incidents cancels it, after which they are analyzed one at a time
+	ctx, done := o.beginProcessing(telemetry.WithCorrelationID(ctx, first.CorrelationID), ids...)
+	defer done()
+
+	telemetry.Logf(ctx, "[AI] 📦 %d related incidents queued, analyzing them in one call: %s\n", len(batch), strings.Join(ids, ", "))
+	responses, err := batcher.AnalyzeIncidents(ctx, batch)
+	if err != nil {
+		if cause := context.Cause(ctx); cause != nil {
+			err = cause
+		}
+		telemetry.Logf(ctx, "[AI] ❌ Batched analysis failed, analyzing the incidents one at a time: %v\n", err)
+		return incidents
+	}
+
+	o.batchMu.Lock()
+	defer o.batchMu.Unlock()
+	if o.batched == nil {
+		o.batched = make(map[string]*models.AIResponse)
+	}
+	for i, response := range responses {
+		if response != nil {
+			o.batched[batch[i].ID] = response
+		}
+	}
+	return incidents
+}
+
+// batchable reports whether an incident belongs in first's batch: detected within the
+// batch window of it, and headed for AI analysis rather than a learned fix or runbook
+func (o *Orchestrator) batchable(incident, first *models.Incident) bool {
+	gap := incident.DetectedAt.Sub(first.DetectedAt)
+	if gap > o.batchWindow || gap < -o.batchWindow {
+		return false
+	}
+
+	if o.store.HasLearnedFix(incident.Type) {
+		return false
+	}
+	_, hasRunbook := o.runbooks.Lookup(incident.Type)
+	return !hasRunbook || o.runbookDiagnosis
+}
+
+// takeBatchedAnalysis returns and forgets the analysis a batched call made for an incident, if any
+func (o *Orchestrator) takeBatchedAnalysis(id string) *models.AIResponse {
+	o.batchMu.Lock()
+	defer o.batchMu.Unlock()
+
+	response := o.batched[id]
+	delete(o.batched, id)
+	return response
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"incident-ai/memory"
+	"incident-ai/models"
+	"incident-ai/telemetry"
+	"maps"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// staticEnricher answers every inventory lookup with the same metadata
+type staticEnricher map[string]string
+
+func (e staticEnricher) Lookup(ctx context.Context, service string) (map[string]string, error) {
+	return maps.Clone(e), nil
+}
+
+// queueOf returns a channel holding incidents, as the detector's queue would
+func queueOf(incidents ...*models.Incident) chan *models.Incident {
+	queue := make(chan *models.Incident, len(incidents))
+	for _, incident := range incidents {
+		queue <- incident
+	}
+	return queue
+}
+
+func TestTakeQueuedBatchesRelatedIncidents(t *testing.T) {
+	o := newTestOrchestrator(t, memory.WithDedupWindow(time.Minute))
+	o.batchWindow = time.Minute
+	o.enricher = staticEnricher{"team": "payments"}
+	o.serviceName = "target-service"
+
+	type call struct {
+		ids           []string
+		metadata      []map[string]string
+		correlationID string
+	}
+	var calls []call
+	o.analyzer = &fakeAnalyzer{batch: func(ctx context.Context, incidents []*models.Incident) ([]*models.AIResponse, error) {
+		c := call{correlationID: telemetry.CorrelationID(ctx)}
+		responses := make([]*models.AIResponse, len(incidents))
+		for i, incident := range incidents {
+			c.ids = append(c.ids, incident.ID)
+			c.metadata = append(c.metadata, incident.Metadata)
+			responses[i] = &models.AIResponse{Diagnosis: "batched " + incident.ID, FixType: "restart"}
+		}
+		calls = append(calls, c)
+		return responses, nil
+	}}
+
+	first := newTestIncident("a", models.ConfigError, "max_connections is 0")
+	repeat := newTestIncident("b", models.ConfigError, "max_connections is 0")
+	repeat.DetectedAt = first.DetectedAt.Add(time.Second)
+	other := newTestIncident("c", models.ServiceDown, "health check timed out")
+	other.DetectedAt = first.DetectedAt.Add(2 * time.Second)
+
+	incidents := o.takeQueued(context.Background(), first, queueOf(repeat, other))
+
+	var ids []string
+	for _, incident := range incidents {
+		ids = append(ids, incident.ID)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("takeQueued returned %v, want %v", ids, want)
+	}
+
+	if len(calls) != 1 {
+		t.Fatalf("AnalyzeIncidents called %d times, want 1", len(calls))
+	}
+	if want := []string{"a", "c"}; !reflect.DeepEqual(calls[0].ids, want) {
+		t.Errorf("batch = %v, want %v without the duplicate", calls[0].ids, want)
+	}
+	for i, metadata := range calls[0].metadata {
+		if metadata["team"] != "payments" {
+			t.Errorf("batched incident %s analyzed without its inventory metadata: %v", calls[0].ids[i], metadata)
+		}
+	}
+	if calls[0].correlationID != first.CorrelationID {
+		t.Errorf("batch call correlation ID = %q, want the first incident's %q", calls[0].correlationID, first.CorrelationID)
+	}
+
+	for id, want := range map[string]string{"a": "batched a", "b": "", "c": "batched c"} {
+		var got string
+		if response := o.takeBatchedAnalysis(id); response != nil {
+			got = response.Diagnosis
+		}
+		if got != want {
+			t.Errorf("batched analysis of %s = %q, want %q", id, got, want)
+		}
+	}
+}
+
+func TestTakeQueuedBoundsBatchedCall(t *testing.T) {
+	tests := []struct {
+		name      string
+		timeout   time.Duration
+		abort     string // incident aborted once the call started
+		wantCause error
+	}{
+		{name: "incident timeout", timeout: 20 * time.Millisecond, wantCause: errIncidentTimeout},
+		{name: "first incident aborted", abort: "a", wantCause: errAborted},
+		{name: "other incident aborted", abort: "b", wantCause: errAborted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOrchestrator(t)
+			o.batchWindow = time.Minute
+			o.incidentTimeout = tt.timeout
+
+			started := make(chan struct{})
+			var cause error
+			o.analyzer = &fakeAnalyzer{batch: func(ctx context.Context, incidents []*models.Incident) ([]*models.AIResponse, error) {
+				close(started)
+				select {
+				case <-ctx.Done():
+					cause = context.Cause(ctx)
+					return nil, ctx.Err()
+				case <-time.After(5 * time.Second):
+					return nil, errors.New("batched call was not cancelled")
+				}
+			}}
+
+			if tt.abort != "" {
+				go func() {
+					<-started
+					if err := o.Abort(tt.abort, "test"); err != nil {
+						t.Errorf("Abort(%s): %v", tt.abort, err)
+					}
+				}()
+			}
+
+			first := newTestIncident("a", models.ConfigError, "max_connections is 0")
+			second := newTestIncident("b", models.ServiceDown, "health check timed out")
+			incidents := o.takeQueued(context.Background(), first, queueOf(second))
+
+			if len(incidents) != 2 {
+				t.Errorf("takeQueued returned %d incidents, want both", len(incidents))
+			}
+			if !errors.Is(cause, tt.wantCause) {
+				t.Errorf("batched call cancelled with %v, want %v", cause, tt.wantCause)
+			}
+			for _, id := range []string{"a", "b"} {
+				if o.takeBatchedAnalysis(id) != nil {
+					t.Errorf("incident %s has a batched analysis from a cancelled call", id)
+				}
+				if err := o.Abort(id, ""); !errors.Is(err, errNotProcessing) {
+					t.Errorf("Abort(%s) after the call = %v, want %v", id, err, errNotProcessing)
+				}
+			}
+		})
+	}
+}
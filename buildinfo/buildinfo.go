@@ -0,0 +1,30 @@
+package buildinfo
+
+import "runtime"
+
+// Build metadata, set at link time:
+//
+//	go build -ldflags "-X incident-ai/buildinfo.Version=v1.2.0 -X incident-ai/buildinfo.Commit=$(git rev-parse --short HEAD) -X incident-ai/buildinfo.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildDate = ""
+)
+
+// Info describes the running build
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build info of the running binary
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"incident-ai/ai"
+	"incident-ai/monitor"
+	"incident-ai/remediation"
+	"incident-ai/service"
+	"incident-ai/telemetry"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// logBuffer collects log output written from several goroutines
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *logBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestCorrelationIDAcrossPipeline(t *testing.T) {
+	var logs logBuffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	// The monitored service is down, though it has finished starting up
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"healthy": false, "message": "down"}`))
+	}))
+	defer down.Close()
+
+	// The AI provider answers with a restart, recording the correlation header it was sent
+	aiHeaders := make(chan string, 1)
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		aiHeaders <- r.Header.Get(telemetry.CorrelationHeader)
+		content := `{"diagnosis": "Process crashed", "fix_type": "restart", "fix_steps": ["Restart the service"], "confidence": 0.9}`
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":      "chatcmpl-test",
+			"object":  "chat.completion",
+			"choices": []map[string]interface{}{{"index": 0, "message": map[string]string{"role": "assistant", "content": content}}},
+			"usage":   map[string]int{"prompt_tokens": 10, "completion_tokens": 10, "total_tokens": 20},
+		})
+	}))
+	defer provider.Close()
+
+	ts, err := service.NewTargetService("0", service.WithAccessLog(false))
+	if err != nil {
+		t.Fatalf("NewTargetService: %v", err)
+	}
+	t.Cleanup(func() { ts.Stop() })
+
+	o := newTestOrchestrator(t)
+	o.analyzer = ai.NewAnalyzer("test-key", ai.WithBaseURL(provider.URL+"/v1"))
+	o.executor = remediation.NewExecutor(ts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	detector := monitor.NewIncidentDetector(down.URL, 10*time.Millisecond, monitor.WithImpactSampling(0, 0))
+	detector.Start(ctx)
+	defer detector.Stop()
+
+	var id string
+	select {
+	case incident := <-detector.GetIncidentChannel():
+		detector.Stop()
+		id = incident.CorrelationID
+		if id == "" {
+			t.Fatal("incident detected without a correlation ID")
+		}
+		if err := o.processIncident(context.Background(), incident); err != nil {
+			t.Fatalf("processIncident: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no incident detected")
+	}
+
+	if header := <-aiHeaders; header != id {
+		t.Errorf("AI request carried correlation header %q, want %q", header, id)
+	}
+
+	// Every analyzer and executor line is about this incident, and so carries its ID, as
+	// do the detector's lines about raising it
+	tagged := make(map[string]int)
+	for _, line := range strings.Split(logs.String(), "\n") {
+		for _, component := range []string{"[MONITOR]", "[AI]", "[REMEDIATION]"} {
+			if !strings.Contains(line, component) {
+				continue
+			}
+			if strings.Contains(line, "["+id+"] ") {
+				tagged[component]++
+			} else if component != "[MONITOR]" {
+				t.Errorf("%s line without the correlation ID: %s", component, line)
+			}
+		}
+	}
+	for _, component := range []string{"[MONITOR]", "[AI]", "[REMEDIATION]"} {
+		if tagged[component] == 0 {
+			t.Errorf("no %s line carries correlation ID %s:\n%s", component, id, logs.String())
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"incident-ai/models"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultDemoWait is how long the demo waits for resolution when a scenario doesn't say
+const defaultDemoWait = 15 * time.Second
+
+// DemoScenario is one incident the demo triggers
+type DemoScenario struct {
+	Name string        `yaml:"name"`
+	Type string        `yaml:"type"` // trigger type or alias, e.g. crash or CONFIG_ERROR
+	Wait time.Duration `yaml:"wait"` // time allowed for resolution before the next scenario
+}
+
+// defaultDemoScenarios is the demo run without -demo-file
+func defaultDemoScenarios() []DemoScenario {
+	return []DemoScenario{
+		{"Service Crash", "crash", defaultDemoWait},
+		{"Config Error", "config", defaultDemoWait},
+		{"Service Crash (cached)", "crash", defaultDemoWait},
+		{"Dependency Failure", "dependency", defaultDemoWait},
+	}
+}
+
+// LoadDemoScenarios reads demo scenarios from a YAML or JSON file, run in the order given, e.g.
+//
+//	[{"name": "Service Crash", "type": "crash", "wait": "15s"}]
+//
+// A scenario without a name is named after its type, and one without a wait gets 15s.
+func LoadDemoScenarios(path string) ([]DemoScenario, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// YAML is a superset of JSON, so one parser reads both
+	var scenarios []DemoScenario
+	if err := yaml.Unmarshal(raw, &scenarios); err != nil {
+		return nil, fmt.Errorf("failed to parse demo file: %w", err)
+	}
+	if len(scenarios) == 0 {
+		return nil, fmt.Errorf("demo file has no scenarios")
+	}
+
+	for i := range scenarios {
+		scenario := &scenarios[i]
+		incidentType, ok := models.NormalizeIncidentType(scenario.Type)
+		if !ok || incidentType == models.Flapping {
+			return nil, fmt.Errorf("scenario %d: incident type %q cannot be triggered", i+1, scenario.Type)
+		}
+		if scenario.Wait < 0 {
+			return nil, fmt.Errorf("scenario %d: wait must not be negative", i+1)
+		}
+		if scenario.Wait == 0 {
+			scenario.Wait = defaultDemoWait
+		}
+		if scenario.Name == "" {
+			scenario.Name = string(incidentType)
+		}
+	}
+
+	return scenarios, nil
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"incident-ai/service"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// triggerRecorder stands in for the target service's trigger endpoint, reporting each
+// request on triggered
+type triggerRecorder struct {
+	triggered chan string
+}
+
+func (r *triggerRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.triggered <- req.URL.Query().Get("type")
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+}
+
+func TestDemoStopsWhenCancelled(t *testing.T) {
+	ts, err := service.NewTargetService("0", service.WithAccessLog(false))
+	if err != nil {
+		t.Fatalf("NewTargetService: %v", err)
+	}
+	t.Cleanup(func() { ts.Stop() })
+	if err := ts.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	recorder := &triggerRecorder{triggered: make(chan string, 4)}
+	scenarios := []DemoScenario{
+		{Name: "Service crash", Type: "crash", Wait: time.Minute},
+		{Name: "Config error", Type: "config", Wait: time.Minute},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runDemo(ctx, ts, &http.Client{Transport: recorder}, scenarios)
+	}()
+
+	// Cancel while the first scenario waits for resolution
+	select {
+	case trigger := <-recorder.triggered:
+		if trigger != "crash" {
+			t.Fatalf("first trigger = %q, want crash", trigger)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("demo never triggered the first scenario")
+	}
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("demo still running a second after cancellation")
+	}
+
+	// Teardown stops the service; the demo must not start it again or trigger anything more
+	ts.Stop()
+	time.Sleep(100 * time.Millisecond)
+	if ts.IsRunning() {
+		t.Error("service running again after the demo was cancelled and it was stopped")
+	}
+	select {
+	case trigger := <-recorder.triggered:
+		t.Errorf("demo triggered %q after cancellation", trigger)
+	default:
+	}
+}
+
+func TestDemoCancelledBeforeStart(t *testing.T) {
+	ts, err := service.NewTargetService("0", service.WithAccessLog(false))
+	if err != nil {
+		t.Fatalf("NewTargetService: %v", err)
+	}
+	t.Cleanup(func() { ts.Stop() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	runDemo(ctx, ts, &http.Client{Transport: &triggerRecorder{triggered: make(chan string, 1)}}, defaultDemoScenarios())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("demo took %v to return after cancellation during its start delay", elapsed)
+	}
+	if ts.IsRunning() {
+		t.Error("demo started the service after cancellation")
+	}
+}
+
+func TestLoadDemoScenarios(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{name: "YAML", file: "demo.yaml", content: "- name: Outage\n  type: service-down\n  wait: 20s\n- type: OOM\n"},
+		{name: "JSON", file: "demo.json", content: `[{"name": "Outage", "type": "service-down", "wait": "20s"}, {"type": "OOM"}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scenarios, err := LoadDemoScenarios(writeDemoFile(t, tt.file, tt.content))
+			if err != nil {
+				t.Fatalf("LoadDemoScenarios: %v", err)
+			}
+			want := []DemoScenario{
+				{Name: "Outage", Type: "service-down", Wait: 20 * time.Second},
+				{Name: "RESOURCE_EXHAUSTION", Type: "OOM", Wait: defaultDemoWait},
+			}
+			if !slices.Equal(scenarios, want) {
+				t.Errorf("scenarios = %+v, want %+v", scenarios, want)
+			}
+		})
+	}
+}
+
+func TestLoadDemoScenariosRejectsBadFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "not YAML", content: "- name: [", wantErr: "failed to parse demo file"},
+		{name: "no scenarios", content: "[]", wantErr: "no scenarios"},
+		{name: "unknown type", content: `[{"type": "meltdown"}]`, wantErr: `scenario 1: incident type "meltdown"`},
+		{name: "flapping can't be triggered", content: `[{"type": "crash"}, {"type": "flapping"}]`, wantErr: "scenario 2"},
+		{name: "negative wait", content: `[{"type": "crash", "wait": "-1s"}]`, wantErr: "wait must not be negative"},
+	}
+
+	for _, tt := range tests {
+		if _, err := LoadDemoScenarios(writeDemoFile(t, "demo.yaml", tt.content)); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+	if _, err := LoadDemoScenarios(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("missing demo file loaded")
+	}
+}
+
+func TestDemoRunsScenariosInOrder(t *testing.T) {
+	ts, err := service.NewTargetService("0", service.WithAccessLog(false))
+	if err != nil {
+		t.Fatalf("NewTargetService: %v", err)
+	}
+	t.Cleanup(func() { ts.Stop() })
+
+	scenarios, err := LoadDemoScenarios(writeDemoFile(t, "demo.yaml",
+		"- {name: Bad config, type: config, wait: 10ms}\n- {name: Slow, type: degraded, wait: 10ms}\n- {name: Outage, type: crash, wait: 10ms}\n"))
+	if err != nil {
+		t.Fatalf("LoadDemoScenarios: %v", err)
+	}
+
+	recorder := &triggerRecorder{triggered: make(chan string, len(scenarios)+1)}
+	runDemo(context.Background(), ts, &http.Client{Transport: recorder}, scenarios)
+	close(recorder.triggered)
+
+	var triggered []string
+	for trigger := range recorder.triggered {
+		triggered = append(triggered, trigger)
+	}
+	if want := []string{"config", "degraded", "crash"}; !slices.Equal(triggered, want) {
+		t.Errorf("demo triggered %q, want the file's scenarios in order %q", triggered, want)
+	}
+}
+
+// writeDemoFile writes a demo scenario file and returns its path
+func writeDemoFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("writing demo file: %v", err)
+	}
+	return path
+}
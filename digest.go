@@ -0,0 +1,314 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"incident-ai/memory"
+	"incident-ai/models"
+	"incident-ai/notify"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxDigestFailures is how many failed incidents a digest lists by name
+const maxDigestFailures = 5
+
+// digestAliases are the named schedules accepted by parseDigestSchedule
+var digestAliases = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+}
+
+// digestSchedule decides when activity digests are sent: either every interval, or at the
+// times matching a cron expression in the local time zone
+type digestSchedule struct {
+	spec     string
+	interval time.Duration // send every interval (0 = use the cron fields)
+
+	// Cron fields as bitsets of the values they allow
+	minutes, hours, days, months, weekdays uint64
+	anyDay, anyWeekday                     bool // the field was "*", which matters for how days match
+}
+
+// parseDigestSchedule parses an interval like "6h", a named schedule (@hourly, @daily,
+// @midnight, @weekly) or a five-field cron expression like "0 9 * * 1-5" (minute, hour,
+// day of month, month, day of week). Cron fields accept *, numbers, ranges, lists and
+// steps like */15.
+func parseDigestSchedule(s string) (*digestSchedule, error) {
+	spec := strings.TrimSpace(s)
+	if interval, err := time.ParseDuration(spec); err == nil {
+		if interval <= 0 {
+			return nil, fmt.Errorf("interval must be positive, got %v", interval)
+		}
+		return &digestSchedule{spec: spec, interval: interval}, nil
+	}
+
+	expr := spec
+	if alias, ok := digestAliases[spec]; ok {
+		expr = alias
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q (want an interval like 24h, @hourly, @daily, @weekly or a cron expression like \"0 9 * * *\")", s)
+	}
+
+	schedule := &digestSchedule{
+		spec:       spec,
+		anyDay:     fields[2] == "*",
+		anyWeekday: fields[4] == "*",
+	}
+	var err error
+	if schedule.minutes, err = parseCronField(fields[0], "minute", 0, 59); err != nil {
+		return nil, err
+	}
+	if schedule.hours, err = parseCronField(fields[1], "hour", 0, 23); err != nil {
+		return nil, err
+	}
+	if schedule.days, err = parseCronField(fields[2], "day of month", 1, 31); err != nil {
+		return nil, err
+	}
+	if schedule.months, err = parseCronField(fields[3], "month", 1, 12); err != nil {
+		return nil, err
+	}
+	if schedule.weekdays, err = parseCronField(fields[4], "day of week", 0, 7); err != nil {
+		return nil, err
+	}
+	// Both 0 and 7 are Sunday
+	if schedule.weekdays&(1<<7) != 0 {
+		schedule.weekdays |= 1
+	}
+
+	if schedule.next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("schedule %q never matches a date", s)
+	}
+	return schedule, nil
+}
+
+// parseCronField parses one comma-separated cron field into a bitset of the values it allows
+func parseCronField(field, name string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step %q in %s field %q", stepStr, name, field)
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			from, to, _ := strings.Cut(rng, "-")
+			var err error
+			if lo, err = cronValue(from, name, min, max); err != nil {
+				return 0, err
+			}
+			if hi, err = cronValue(to, name, min, max); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range %q in %s field", rng, name)
+			}
+		default:
+			n, err := cronValue(rng, name, min, max)
+			if err != nil {
+				return 0, err
+			}
+			lo = n
+			// A single value only runs to the end of the field when given a step, e.g. 5/15
+			if !hasStep {
+				hi = n
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// cronValue parses a single cron field value, checking it is in range
+func cronValue(s, name string, min, max int) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < min || n > max {
+		return 0, fmt.Errorf("invalid %s %q (must be %d-%d)", name, s, min, max)
+	}
+	return n, nil
+}
+
+// next returns the first time after after that the schedule fires, or the zero time if
+// a cron expression matches no date in the next five years
+func (s *digestSchedule) next(after time.Time) time.Time {
+	if s.interval > 0 {
+		return after.Add(s.interval)
+	}
+
+	loc := after.Location()
+	t := time.Date(after.Year(), after.Month(), after.Day(), after.Hour(), after.Minute()+1, 0, 0, loc)
+	for limit := t.AddDate(5, 0, 0); t.Before(limit); {
+		switch {
+		case s.months&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case s.hours&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case s.minutes&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches reports whether t's date matches the day fields. As in cron, when both the
+// day of month and the day of week are restricted, a date matching either one fires.
+func (s *digestSchedule) dayMatches(t time.Time) bool {
+	day := s.days&(1<<uint(t.Day())) != 0
+	weekday := s.weekdays&(1<<uint(t.Weekday())) != 0
+	if !s.anyDay && !s.anyWeekday {
+		return day || weekday
+	}
+	return day && weekday
+}
+
+func (s *digestSchedule) String() string {
+	return s.spec
+}
+
+// runDigests sends a digest of incident activity through the notifier each time the
+// schedule fires, covering the time since the previous digest, until ctx is cancelled
+func (o *Orchestrator) runDigests(ctx context.Context, schedule *digestSchedule) {
+	if schedule == nil || o.notifier == nil {
+		return
+	}
+
+	since := time.Now()
+	for {
+		next := schedule.next(time.Now())
+		if next.IsZero() || !sleepContext(ctx, time.Until(next)) {
+			return
+		}
+
+		until := time.Now()
+		o.sendDigest(ctx, since, until)
+		since = until
+	}
+}
+
+// sendDigest sends a digest of the incidents detected between since and until
+func (o *Orchestrator) sendDigest(ctx context.Context, since, until time.Time) {
+	title, body := buildDigest(o.store, since, until)
+	if err := o.notifier.Notify(ctx, notify.Message{Title: title, Body: body}); err != nil {
+		log.Printf("[NOTIFY] Warning: failed to send incident digest: %v\n", err)
+		return
+	}
+	log.Printf("[SYSTEM] 📰 Sent incident digest for %s - %s\n", since.Format("2006-01-02 15:04"), until.Format("2006-01-02 15:04"))
+}
+
+// buildDigest summarizes the incidents detected between since and until: how many of each
+// type, how many were resolved, which failed, and the store's all-time totals
+func buildDigest(store *memory.Store, since, until time.Time) (string, string) {
+	var incidents []*models.Incident
+	for _, incident := range store.GetAllIncidents() {
+		if !incident.DetectedAt.Before(since) && incident.DetectedAt.Before(until) {
+			incidents = append(incidents, incident)
+		}
+	}
+	sort.Slice(incidents, func(i, j int) bool {
+		return incidents[i].DetectedAt.Before(incidents[j].DetectedAt)
+	})
+
+	byType := make(map[string]int)
+	var resolved, open int
+	var failures []*models.Incident
+	for _, incident := range incidents {
+		byType[string(incident.Type)]++
+		switch {
+		case incident.Status == models.StatusResolved:
+			resolved++
+		case incident.Status == models.StatusFailed:
+			failures = append(failures, incident)
+		case !incident.Status.IsTerminal():
+			open++
+		}
+	}
+
+	title := fmt.Sprintf("Incident digest: %d incident(s) since %s", len(incidents), since.Format("2006-01-02 15:04"))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Period: %s - %s (%v)\n", since.Format("2006-01-02 15:04"), until.Format("2006-01-02 15:04"), until.Sub(since).Round(time.Second))
+
+	if len(incidents) == 0 {
+		b.WriteString("No incidents detected.\n")
+	} else {
+		fmt.Fprintf(&b, "Incidents: %d (%s)\n", len(incidents), formatTypeCounts(byType))
+		if finished := resolved + len(failures); finished > 0 {
+			fmt.Fprintf(&b, "Resolution rate: %d of %d resolved (%.0f%%)\n", resolved, finished, float64(resolved)/float64(finished)*100)
+		}
+		if open > 0 {
+			fmt.Fprintf(&b, "Still in progress: %d\n", open)
+		}
+		if len(failures) > 0 {
+			fmt.Fprintf(&b, "Failed: %d\n", len(failures))
+			for i, incident := range failures {
+				if i == maxDigestFailures {
+					fmt.Fprintf(&b, "  ... and %d more\n", len(failures)-maxDigestFailures)
+					break
+				}
+				fmt.Fprintf(&b, "  - %s %s at %s: %s\n", incident.ID, incident.Type, incident.DetectedAt.Format("15:04"), failureSummary(incident))
+			}
+		}
+	}
+
+	summary := store.Summary()
+	fmt.Fprintf(&b, "All time: %d incidents, %d resolved, %d failed, %d learned fixes\n",
+		summary.TotalIncidents, summary.Resolved, summary.Failed, summary.LearnedFixes)
+
+	return title, b.String()
+}
+
+// formatTypeCounts lists incident counts by type, most frequent first
+func formatTypeCounts(byType map[string]int) string {
+	types := make([]string, 0, len(byType))
+	for incidentType := range byType {
+		types = append(types, incidentType)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if byType[types[i]] != byType[types[j]] {
+			return byType[types[i]] > byType[types[j]]
+		}
+		return types[i] < types[j]
+	})
+
+	parts := make([]string, len(types))
+	for i, incidentType := range types {
+		parts[i] = fmt.Sprintf("%s %d", incidentType, byType[incidentType])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// failureSummary says briefly why an incident failed
+func failureSummary(incident *models.Incident) string {
+	switch {
+	case incident.FailureReason != "":
+		return incident.FailureReason
+	case incident.Resolution != nil:
+		return fmt.Sprintf("%s fix did not resolve it", incident.Resolution.FixType)
+	case incident.Diagnosis != "":
+		return incident.Diagnosis
+	default:
+		return "no fix found"
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"incident-ai/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDigestSchedule(t *testing.T) {
+	// A Friday morning
+	from := time.Date(2026, 10, 16, 10, 7, 30, 0, time.Local)
+
+	tests := []struct {
+		spec     string
+		wantNext time.Time
+		wantErr  bool
+	}{
+		{spec: "6h", wantNext: from.Add(6 * time.Hour)},
+		{spec: "@hourly", wantNext: time.Date(2026, 10, 16, 11, 0, 0, 0, time.Local)},
+		{spec: "@daily", wantNext: time.Date(2026, 10, 17, 0, 0, 0, 0, time.Local)},
+		{spec: "@weekly", wantNext: time.Date(2026, 10, 18, 0, 0, 0, 0, time.Local)},
+		{spec: "*/15 * * * *", wantNext: time.Date(2026, 10, 16, 10, 15, 0, 0, time.Local)},
+		{spec: "0 9 * * 1-5", wantNext: time.Date(2026, 10, 19, 9, 0, 0, 0, time.Local)},
+		{spec: "30 8,17 * * *", wantNext: time.Date(2026, 10, 16, 17, 30, 0, 0, time.Local)},
+		// Restricting both days fires on either: the 1st, or Sundays
+		{spec: "0 0 1 * 0", wantNext: time.Date(2026, 10, 18, 0, 0, 0, 0, time.Local)},
+		{spec: "0 0 * * 7", wantNext: time.Date(2026, 10, 18, 0, 0, 0, 0, time.Local)},
+		{spec: "-1h", wantErr: true},
+		{spec: "daily", wantErr: true},
+		{spec: "0 25 * * *", wantErr: true},
+		{spec: "*/0 * * * *", wantErr: true},
+		{spec: "0 9 * *", wantErr: true},
+		{spec: "0 0 30 2 *", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := parseDigestSchedule(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseDigestSchedule(%q) = %v, want an error", tt.spec, schedule)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseDigestSchedule(%q): %v", tt.spec, err)
+			}
+			if next := schedule.next(from); !next.Equal(tt.wantNext) {
+				t.Errorf("next after %v = %v, want %v", from, next, tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestBuildDigest(t *testing.T) {
+	since := time.Date(2026, 10, 16, 9, 0, 0, 0, time.Local)
+	until := since.Add(time.Hour)
+
+	o := newTestOrchestrator(t)
+	store := func(id string, incidentType models.IncidentType, at time.Duration, status models.IncidentStatus, change func(*models.Incident)) {
+		incident := newTestIncident(id, incidentType, "symptom of "+id)
+		incident.DetectedAt = since.Add(at)
+		incident.Status = status
+		if change != nil {
+			change(incident)
+		}
+		if err := o.store.StoreIncident(incident); err != nil {
+			t.Fatalf("StoreIncident(%s): %v", id, err)
+		}
+	}
+
+	store("before", models.ServiceDown, -time.Minute, models.StatusResolved, nil)
+	store("a", models.ServiceDown, 1*time.Minute, models.StatusResolved, func(incident *models.Incident) {
+		incident.Resolution = &models.Resolution{FixType: "restart", Steps: []string{"Restart the service"}, Success: true}
+	})
+	store("b", models.ServiceDown, 2*time.Minute, models.StatusFailed, func(incident *models.Incident) {
+		incident.FailureReason = "service failed again during the soak period"
+	})
+	store("c", models.ConfigError, 3*time.Minute, models.StatusFailed, func(incident *models.Incident) {
+		incident.Resolution = &models.Resolution{FixType: "config"}
+	})
+	store("d", models.ConfigError, 4*time.Minute, models.StatusAnalyzing, nil)
+	store("after", models.ServiceDown, time.Hour, models.StatusDetected, nil)
+
+	title, body := buildDigest(o.store, since, until)
+
+	if want := "Incident digest: 4 incident(s) since 2026-10-16 09:00"; title != want {
+		t.Errorf("title = %q, want %q", title, want)
+	}
+	for _, line := range []string{
+		"Period: 2026-10-16 09:00 - 2026-10-16 10:00 (1h0m0s)\n",
+		fmt.Sprintf("Incidents: 4 (%s 2, %s 2)\n", models.ConfigError, models.ServiceDown),
+		"Resolution rate: 1 of 3 resolved (33%)\n",
+		"Still in progress: 1\n",
+		"Failed: 2\n",
+		fmt.Sprintf("  - b %s at 09:02: service failed again during the soak period\n", models.ServiceDown),
+		fmt.Sprintf("  - c %s at 09:03: config fix did not resolve it\n", models.ConfigError),
+		"All time: 6 incidents, 2 resolved, 2 failed, 1 learned fixes\n",
+	} {
+		if !strings.Contains(body, line) {
+			t.Errorf("digest is missing %q:\n%s", line, body)
+		}
+	}
+}
+
+func TestBuildDigestLimitsFailures(t *testing.T) {
+	since := time.Date(2026, 10, 16, 9, 0, 0, 0, time.Local)
+
+	o := newTestOrchestrator(t)
+	_, body := buildDigest(o.store, since, since.Add(time.Hour))
+	if !strings.Contains(body, "No incidents detected.\n") {
+		t.Errorf("empty digest doesn't say so:\n%s", body)
+	}
+
+	for i := 0; i < maxDigestFailures+2; i++ {
+		incident := newTestIncident(fmt.Sprintf("failed-%d", i), models.ServiceDown, fmt.Sprintf("symptom %d", i))
+		incident.DetectedAt = since.Add(time.Duration(i) * time.Minute)
+		incident.Status = models.StatusFailed
+		if err := o.store.StoreIncident(incident); err != nil {
+			t.Fatalf("StoreIncident: %v", err)
+		}
+	}
+
+	_, body = buildDigest(o.store, since, since.Add(time.Hour))
+	if got := strings.Count(body, "  - failed-"); got != maxDigestFailures {
+		t.Errorf("digest lists %d failures, want %d:\n%s", got, maxDigestFailures, body)
+	}
+	if !strings.Contains(body, "  ... and 2 more\n") {
+		t.Errorf("digest doesn't count the failures it leaves out:\n%s", body)
+	}
+}
+
+func TestRunDigestsSendsOnSchedule(t *testing.T) {
+	o := newTestOrchestrator(t)
+	notifier := o.notifier.(*recordingNotifier)
+
+	schedule, err := parseDigestSchedule("200ms")
+	if err != nil {
+		t.Fatalf("parseDigestSchedule: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		o.runDigests(ctx, schedule)
+	}()
+
+	// Stored after the first digest was sent, so only the second one counts it
+	first := notifier.next(t, 2*time.Second)
+	incident := newTestIncident("a", models.ServiceDown, "health check timed out")
+	if err := o.store.StoreIncident(incident); err != nil {
+		t.Fatalf("StoreIncident: %v", err)
+	}
+	second := notifier.next(t, 2*time.Second)
+	cancel()
+	<-stopped
+
+	for _, msg := range []struct {
+		name  string
+		title string
+		body  string
+	}{
+		{"first", first.Title, first.Body},
+		{"second", second.Title, second.Body},
+	} {
+		if !strings.HasPrefix(msg.title, "Incident digest: ") || msg.body == "" {
+			t.Errorf("%s digest = %q %q, want a titled digest", msg.name, msg.title, msg.body)
+		}
+	}
+	if !strings.Contains(first.Body, "No incidents detected.") {
+		t.Errorf("first digest reports incidents before any were stored:\n%s", first.Body)
+	}
+	if !strings.Contains(second.Title, "1 incident(s)") || !strings.Contains(second.Body, fmt.Sprintf("(%s 1)", models.ServiceDown)) {
+		t.Errorf("second digest = %q\n%s\nwant the incident stored during its period", second.Title, second.Body)
+	}
+}
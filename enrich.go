@@ -0,0 +1,25 @@
+package main
+
+import (
+	"context"
+	"incident-ai/models"
+	"incident-ai/telemetry"
+)
+
+// enrich attaches the monitored service's inventory metadata to an incident. Incidents that
+// already carry metadata, e.g. reprocessed ones, keep it; a failed lookup only leaves the
+// incident without.
+func (o *Orchestrator) enrich(ctx context.Context, incident *models.Incident) {
+	if o.enricher == nil || len(incident.Metadata) > 0 {
+		return
+	}
+
+	metadata, err := o.enricher.Lookup(ctx, o.serviceName)
+	if err != nil {
+		telemetry.Logf(ctx, "[INVENTORY] Warning: no metadata for %s: %v\n", o.serviceName, err)
+		return
+	}
+
+	incident.Metadata = metadata
+	telemetry.Logf(ctx, "[INVENTORY] 🏷️  %s: %s\n", o.serviceName, models.FormatMetadata(metadata))
+}
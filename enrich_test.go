@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"incident-ai/inventory"
+	"incident-ai/models"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIncidentEnrichedFromInventory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "inventory.yaml")
+	content := "target-service:\n  team: payments\n  oncall: alice@example.com\n  tier: \"1\"\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("writing inventory file: %v", err)
+	}
+	enricher, err := inventory.LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	inventoried := map[string]string{"team": "payments", "oncall": "alice@example.com", "tier": "1"}
+
+	tests := []struct {
+		name        string
+		serviceName string
+		metadata    map[string]string // the incident's metadata before processing
+		want        map[string]string
+	}{
+		{name: "service in the inventory", serviceName: "target-service", want: inventoried},
+		{name: "service not in the inventory", serviceName: "orders"},
+		{name: "reprocessed incident", serviceName: "target-service", metadata: map[string]string{"team": "search"}, want: map[string]string{"team": "search"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOrchestrator(t)
+			o.enricher = enricher
+			o.serviceName = tt.serviceName
+			o.diagnoseOnly = true
+			notifier := o.notifier.(*recordingNotifier)
+
+			incident := newTestIncident("a", models.ServiceDown, "health check timed out")
+			incident.Metadata = tt.metadata
+			if err := o.processIncident(context.Background(), incident); err != nil {
+				t.Fatalf("processIncident: %v", err)
+			}
+
+			if !maps.Equal(incident.Metadata, tt.want) {
+				t.Errorf("metadata = %v, want %v", incident.Metadata, tt.want)
+			}
+			if stored, _ := o.store.GetIncident("a"); !maps.Equal(stored.Metadata, tt.want) {
+				t.Errorf("stored metadata = %v, want %v", stored.Metadata, tt.want)
+			}
+			if msg := notifier.next(t, time.Second); msg.Incident == nil || !maps.Equal(msg.Incident.Metadata, tt.want) {
+				t.Errorf("notification carries incident %+v, want metadata %v", msg.Incident, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"incident-ai/models"
+	"incident-ai/telemetry"
+)
+
+// remediationSuspended reports whether an incident type has failed enough resolutions in a
+// row that it should go straight to a human instead of being auto-remediated again
+func (o *Orchestrator) remediationSuspended(incidentType models.IncidentType) (int, bool) {
+	if o.escalateAfter <= 0 {
+		return 0, false
+	}
+
+	streak := o.store.FailureStreak(incidentType)
+	return streak, streak >= o.escalateAfter
+}
+
+// recordOutcome extends or resets the incident type's failure streak after a remediation attempt
+func (o *Orchestrator) recordOutcome(ctx context.Context, incident *models.Incident, success bool) {
+	streak, err := o.store.RecordResolutionOutcome(incident.Type, success)
+	if err != nil {
+		telemetry.Logf(ctx, "[MEMORY] Warning: failed to record resolution outcome: %v\n", err)
+	}
+
+	if o.escalateAfter > 0 && streak == o.escalateAfter {
+		telemetry.Logf(ctx, "[SYSTEM] ⚠️  %s incidents failed %d resolutions in a row - further ones will be escalated for manual remediation\n",
+			incident.Type, streak)
+	}
+}
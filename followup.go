@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"incident-ai/models"
+	"incident-ai/telemetry"
+	"log"
+	"strings"
+)
+
+// currentIncidentDetector classifies the monitored service's state right now
+type currentIncidentDetector interface {
+	DetectCurrent() *models.Incident
+}
+
+// followUp re-classifies the service after a fix failed verification. When the service is
+// now failing in a different way - e.g. a restart cleared a crash but revealed a config
+// error - the new problem is enqueued as a follow-up incident that records what caused it.
+// The original incident still failed: it is closed as FAILED, linked to the follow-up,
+// and its fix is not learned. It reports whether that happened; otherwise the caller
+// handles the failure as usual.
+func (o *Orchestrator) followUp(ctx context.Context, incident *models.Incident, resolution *models.Resolution) bool {
+	if o.reclassifier == nil {
+		return false
+	}
+
+	current := o.reclassifier.DetectCurrent()
+	if current == nil || current.Type == incident.Type {
+		return false
+	}
+
+	current.CausedBy = incident.ID
+	current.CorrelationID = incident.CorrelationID
+	current.Symptoms = append(current.Symptoms,
+		fmt.Sprintf("Appeared after a %s fix for %s incident %s", resolution.FixType, incident.Type, incident.ID))
+
+	// The requeue is drained by the goroutine running this, so never block on it
+	select {
+	case o.requeue <- current:
+	default:
+		telemetry.Logf(ctx, "[SYSTEM] Warning: requeue is full, cannot enqueue follow-up %s incident\n", current.Type)
+		return false
+	}
+
+	incident.Status = models.StatusFailed
+	incident.Resolution = resolution
+	incident.FollowUp = current.ID
+	incident.FailureReason = fmt.Sprintf("the service is now failing with a %s incident (%s)", current.Type, current.ID)
+	if err := o.store.StoreIncident(incident); err != nil {
+		telemetry.Logf(ctx, "[MEMORY] Warning: failed to store incident: %v\n", err)
+	}
+	o.recordOutcome(ctx, incident, false)
+
+	o.notify(ctx, incident,
+		fmt.Sprintf("%s incident not resolved, a %s incident followed", incident.Type, current.Type),
+		fmt.Sprintf("The %s fix for the %s incident could not be verified, and the service is now failing with a %s incident (%s), which is being handled as a follow-up.\n",
+			resolution.FixType, incident.Type, current.Type, current.ID))
+
+	log.Println("\n" + strings.Repeat("=", 70))
+	telemetry.Logf(ctx, "[SYSTEM] 🔀 FIX FOR %s NOT VERIFIED - THE SERVICE NOW HAS A %s INCIDENT\n", incident.Type, current.Type)
+	telemetry.Logf(ctx, "[SYSTEM] Follow-up incident %s enqueued\n", current.ID)
+	log.Println(strings.Repeat("=", 70) + "\n")
+	return true
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"incident-ai/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeReclassifier reports current as the service's state
+type fakeReclassifier struct {
+	current *models.Incident
+}
+
+func (r *fakeReclassifier) DetectCurrent() *models.Incident {
+	return r.current
+}
+
+func TestFollowUpOnDifferentIncidentType(t *testing.T) {
+	tests := []struct {
+		name         string
+		current      *models.Incident // the service's state once the fix failed verification
+		wantFollowUp bool
+	}{
+		{name: "different type", current: newTestIncident("b", models.ConfigError, "max_connections is 0"), wantFollowUp: true},
+		{name: "same type", current: newTestIncident("b", models.ServiceDown, "health check timed out")},
+		{name: "nothing detected", current: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOrchestrator(t)
+			o.verifier = &fakeVerifier{healthy: func(int) bool { return false }}
+			o.reclassifier = &fakeReclassifier{current: tt.current}
+			notifier := o.notifier.(*recordingNotifier)
+
+			incident := newTestIncident("a", models.ServiceDown, "health check timed out")
+			if err := o.processIncident(context.Background(), incident); err != nil {
+				t.Fatalf("processIncident: %v", err)
+			}
+
+			if incident.Status != models.StatusFailed {
+				t.Errorf("original status = %s, want %s", incident.Status, models.StatusFailed)
+			}
+			if _, learned := o.store.GetLearnedFix(models.ServiceDown); learned {
+				t.Error("fix that failed verification was learned")
+			}
+
+			if !tt.wantFollowUp {
+				if incident.FollowUp != "" {
+					t.Errorf("FollowUp = %q, want none", incident.FollowUp)
+				}
+				if len(o.requeue) != 0 {
+					t.Errorf("%d incidents requeued, want none", len(o.requeue))
+				}
+				return
+			}
+
+			if incident.FollowUp != "b" {
+				t.Errorf("FollowUp = %q, want b", incident.FollowUp)
+			}
+			if !strings.Contains(incident.FailureReason, string(models.ConfigError)) {
+				t.Errorf("failure reason %q doesn't name the follow-up type", incident.FailureReason)
+			}
+
+			if len(o.requeue) != 1 {
+				t.Fatalf("%d incidents requeued, want the follow-up", len(o.requeue))
+			}
+			followUp := <-o.requeue
+			if followUp.ID != "b" || followUp.CausedBy != "a" || followUp.CorrelationID != incident.CorrelationID {
+				t.Errorf("follow-up %s caused by %q with correlation ID %q, want b caused by a with %q",
+					followUp.ID, followUp.CausedBy, followUp.CorrelationID, incident.CorrelationID)
+			}
+			if last := followUp.Symptoms[len(followUp.Symptoms)-1]; !strings.Contains(last, "after a restart fix") {
+				t.Errorf("follow-up's last symptom = %q, want what caused it", last)
+			}
+
+			msg := notifier.next(t, time.Second)
+			if msg.IncidentID != "a" || !strings.Contains(msg.Title, "a "+string(models.ConfigError)+" incident followed") {
+				t.Errorf("notification = %s %q, want the follow-up announced for a", msg.IncidentID, msg.Title)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrUnknownService is returned when the inventory has no entry for a service
+var ErrUnknownService = errors.New("service not in inventory")
+
+// Enricher looks up ownership and environment metadata for a service, such as its team,
+// on-call contact, environment and tier
+type Enricher interface {
+	Lookup(ctx context.Context, service string) (map[string]string, error)
+}
+
+// FileEnricher serves metadata from an inventory file read at startup
+type FileEnricher struct {
+	services map[string]map[string]string
+}
+
+// LoadFile reads service metadata keyed by service name from a YAML or JSON file, e.g.
+//
+//	target-service:
+//	  team: payments
+//	  oncall: alice@example.com
+//	  environment: production
+//	  tier: "1"
+//
+// Every service needs at least one non-empty value.
+func LoadFile(path string) (*FileEnricher, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var services map[string]map[string]string
+	if err := yaml.Unmarshal(raw, &services); err != nil {
+		return nil, fmt.Errorf("failed to parse inventory file: %w", err)
+	}
+	if len(services) == 0 {
+		return nil, fmt.Errorf("inventory file has no services")
+	}
+
+	e := &FileEnricher{services: make(map[string]map[string]string, len(services))}
+	for service, metadata := range services {
+		cleaned := make(map[string]string, len(metadata))
+		for key, value := range metadata {
+			key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+			if key != "" && value != "" {
+				cleaned[key] = value
+			}
+		}
+		if len(cleaned) == 0 {
+			return nil, fmt.Errorf("invalid inventory entry for %s: no metadata", service)
+		}
+		e.services[service] = cleaned
+	}
+
+	return e, nil
+}
+
+// Lookup returns a copy of the service's metadata
+func (e *FileEnricher) Lookup(ctx context.Context, service string) (map[string]string, error) {
+	metadata, ok := e.services[service]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownService, service)
+	}
+
+	copied := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		copied[key] = value
+	}
+	return copied, nil
+}
+
+// Services returns the names of the services in the inventory, sorted
+func (e *FileEnricher) Services() []string {
+	services := make([]string, 0, len(e.services))
+	for service := range e.services {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+	return services
+}
@@ -0,0 +1,113 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeInventory writes content as an inventory file named name and returns its path
+func writeInventory(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("writing inventory file: %v", err)
+	}
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name: "YAML",
+			file: "inventory.yaml",
+			content: `target-service:
+  team: " payments "
+  oncall: alice@example.com
+  tier: "1"
+  notes: ""
+orders:
+  team: fulfilment
+`,
+		},
+		{
+			name:    "JSON",
+			file:    "inventory.json",
+			content: `{"target-service": {"team": " payments ", "oncall": "alice@example.com", "tier": "1", "notes": ""}, "orders": {"team": "fulfilment"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enricher, err := LoadFile(writeInventory(t, tt.file, tt.content))
+			if err != nil {
+				t.Fatalf("LoadFile: %v", err)
+			}
+
+			if services := enricher.Services(); !slices.Equal(services, []string{"orders", "target-service"}) {
+				t.Errorf("services = %v, want orders and target-service, sorted", services)
+			}
+
+			// Values are trimmed and blank ones dropped
+			metadata, err := enricher.Lookup(context.Background(), "target-service")
+			want := map[string]string{"team": "payments", "oncall": "alice@example.com", "tier": "1"}
+			if err != nil || !maps.Equal(metadata, want) {
+				t.Errorf("Lookup = %v, %v; want %v", metadata, err, want)
+			}
+		})
+	}
+}
+
+func TestLoadFileRejectsBadInventories(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "malformed", content: "target-service: [", wantErr: "failed to parse inventory file"},
+		{name: "not keyed by service", content: "- team: payments", wantErr: "failed to parse inventory file"},
+		{name: "empty", content: "", wantErr: "no services"},
+		{name: "service without metadata", content: "target-service:\n  team: \"\"\n", wantErr: "invalid inventory entry for target-service"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enricher, err := LoadFile(writeInventory(t, "inventory.yaml", tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadFile = %v, %v; want an error containing %q", enricher, err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := LoadFile(filepath.Join(t.TempDir(), "missing.yaml")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("LoadFile of a missing file = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	enricher, err := LoadFile(writeInventory(t, "inventory.yaml", "target-service:\n  team: payments\n"))
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+
+	if _, err := enricher.Lookup(context.Background(), "orders"); !errors.Is(err, ErrUnknownService) {
+		t.Errorf("Lookup of a service not in the inventory = %v, want ErrUnknownService", err)
+	}
+
+	// Callers get their own copy
+	metadata, _ := enricher.Lookup(context.Background(), "target-service")
+	metadata["team"] = "changed"
+	if again, _ := enricher.Lookup(context.Background(), "target-service"); again["team"] != "payments" {
+		t.Errorf("changing looked-up metadata changed the inventory: %v", again)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"incident-ai/models"
+	"incident-ai/telemetry"
+	"log"
+	"strings"
+)
+
+// LowConfidencePolicy controls what happens to an AI fix whose confidence is below the
+// auto-apply threshold
+type LowConfidencePolicy string
+
+const (
+	// LowConfidenceDiagnose records the fix as a recommendation and hands the incident to a human
+	LowConfidenceDiagnose LowConfidencePolicy = "diagnose"
+	// LowConfidenceFail marks the incident FAILED without applying anything
+	LowConfidenceFail LowConfidencePolicy = "fail"
+	// LowConfidenceApply applies the AI fix regardless of its confidence
+	LowConfidenceApply LowConfidencePolicy = "apply-anyway"
+	// LowConfidenceRuleBased discards the AI fix and applies the rule-based analysis instead
+	LowConfidenceRuleBased LowConfidencePolicy = "use-rule-based"
+)
+
+// ParseLowConfidencePolicy validates a low-confidence policy name
+func ParseLowConfidencePolicy(s string) (LowConfidencePolicy, error) {
+	switch LowConfidencePolicy(s) {
+	case LowConfidenceDiagnose, LowConfidenceFail, LowConfidenceApply, LowConfidenceRuleBased:
+		return LowConfidencePolicy(s), nil
+	default:
+		return "", fmt.Errorf("unknown low-confidence policy %q (valid: %s, %s, %s, %s)",
+			s, LowConfidenceDiagnose, LowConfidenceFail, LowConfidenceApply, LowConfidenceRuleBased)
+	}
+}
+
+// belowAutoApply reports whether an AI fix is not confident enough to apply automatically
+func (o *Orchestrator) belowAutoApply(aiResponse *models.AIResponse) bool {
+	return o.autoApplyConfidence > 0 && aiResponse.Confidence < o.autoApplyConfidence
+}
+
+// lowConfidenceReason explains why a fix with the given confidence was not auto-applied
+func (o *Orchestrator) lowConfidenceReason(confidence float64) string {
+	return fmt.Sprintf("AI confidence %.2f is below the auto-apply threshold %.2f", confidence, o.autoApplyConfidence)
+}
+
+// failLowConfidence marks an incident FAILED because its AI fix was not confident enough
+// to apply, and notifies with the fix that was rejected
+func (o *Orchestrator) failLowConfidence(ctx context.Context, incident *models.Incident, aiResponse *models.AIResponse) {
+	incident.Status = models.StatusFailed
+	incident.FailureReason = o.lowConfidenceReason(aiResponse.Confidence)
+	o.store.StoreIncident(incident)
+
+	var body strings.Builder
+	body.WriteString(fmt.Sprintf("No fix applied: %s\n", incident.FailureReason))
+	body.WriteString(fmt.Sprintf("Diagnosis: %s\n", aiResponse.Diagnosis))
+	body.WriteString(fmt.Sprintf("Rejected fix (%s):\n", aiResponse.FixType))
+	for i, step := range aiResponse.FixSteps {
+		body.WriteString(fmt.Sprintf("%d. %s\n", i+1, step))
+	}
+
+	o.notify(ctx, incident, fmt.Sprintf("%s incident failed - AI fix below confidence threshold", incident.Type), body.String())
+
+	log.Println("\n" + strings.Repeat("=", 70))
+	telemetry.Logf(ctx, "[SYSTEM] ❌ INCIDENT FAILED (%s)\n", incident.FailureReason)
+	log.Println(strings.Repeat("=", 70) + "\n")
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"incident-ai/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLowConfidencePolicies(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       LowConfidencePolicy
+		confidence   float64
+		wantStatus   models.IncidentStatus
+		wantApplied  string // the diagnosis of the fix applied, "" = none
+		wantNotified string // the notification title, "" = none
+	}{
+		{name: "fail", policy: LowConfidenceFail, confidence: 0.4, wantStatus: models.StatusFailed, wantNotified: "AI fix below confidence threshold"},
+		{name: "apply anyway", policy: LowConfidenceApply, confidence: 0.4, wantStatus: models.StatusResolved, wantApplied: "AI diagnosis"},
+		{name: "use rule-based", policy: LowConfidenceRuleBased, confidence: 0.4, wantStatus: models.StatusResolved, wantApplied: "rule-based diagnosis"},
+		{name: "diagnose", policy: LowConfidenceDiagnose, confidence: 0.4, wantStatus: models.StatusDiagnosed, wantNotified: "manual remediation required"},
+		{name: "confident enough to apply", policy: LowConfidenceFail, confidence: 0.8, wantStatus: models.StatusResolved, wantApplied: "AI diagnosis"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOrchestrator(t)
+			o.autoApplyConfidence = 0.7
+			o.lowConfidencePolicy = tt.policy
+			o.analyzer.(*fakeAnalyzer).response.Confidence = tt.confidence
+			executor := o.executor.(*fakeExecutor)
+			notifier := o.notifier.(*recordingNotifier)
+
+			incident := newTestIncident("a", models.ServiceDown, "health check timed out")
+			if err := o.processIncident(context.Background(), incident); err != nil {
+				t.Fatalf("processIncident: %v", err)
+			}
+
+			if incident.Status != tt.wantStatus {
+				t.Errorf("incident is %s, want %s", incident.Status, tt.wantStatus)
+			}
+			switch {
+			case tt.wantApplied == "" && len(executor.executed) != 0:
+				t.Errorf("%d fixes applied, want none", len(executor.executed))
+			case tt.wantApplied != "" && (len(executor.executed) != 1 || executor.executed[0].Diagnosis != tt.wantApplied):
+				t.Errorf("fixes applied = %+v, want the one with diagnosis %q", executor.executed, tt.wantApplied)
+			}
+			if tt.wantNotified == "" {
+				notifier.none(t, 10*time.Millisecond)
+				return
+			}
+			msg := notifier.next(t, time.Second)
+			if !strings.Contains(msg.Title, tt.wantNotified) || !strings.Contains(msg.Body, "AI confidence 0.40 is below the auto-apply threshold 0.70") {
+				t.Errorf("notification %q:\n%s\nwant %q with the reason", msg.Title, msg.Body, tt.wantNotified)
+			}
+		})
+	}
+}
+
+func TestParseLowConfidencePolicy(t *testing.T) {
+	for _, name := range []string{"diagnose", "fail", "apply-anyway", "use-rule-based"} {
+		if policy, err := ParseLowConfidencePolicy(name); err != nil || string(policy) != name {
+			t.Errorf("ParseLowConfidencePolicy(%q) = %q, %v", name, policy, err)
+		}
+	}
+	if _, err := ParseLowConfidencePolicy("ignore"); err == nil {
+		t.Error("ParseLowConfidencePolicy accepted an unknown policy")
+	}
+}
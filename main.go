@@ -2,29 +2,43 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"incident-ai/ai"
+	"incident-ai/buildinfo"
+	"incident-ai/inventory"
 	"incident-ai/memory"
 	"incident-ai/models"
 	"incident-ai/monitor"
+	"incident-ai/notify"
 	"incident-ai/remediation"
 	"incident-ai/service"
+	"incident-ai/telemetry"
+	"incident-ai/trace"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
+	"text/template"
 	"time"
 
 	"github.com/joho/godotenv"
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
 const (
-	servicePort    = "8080"
-	checkInterval  = 3 * time.Second
-	memoryFile     = "incident_memory.json"
+	servicePort   = "8080"
+	checkInterval = 3 * time.Second
+	memoryFile    = "incident_memory.json"
 )
 
 func main() {
@@ -33,12 +47,129 @@ func main() {
 
 	// Command line flags
 	apiKey := flag.String("api-key", os.Getenv("OPENAI_API_KEY"), "OpenAI API key (or set OPENAI_API_KEY env var)")
+	apiKeysList := flag.String("api-keys", os.Getenv("OPENAI_API_KEYS"), "Comma-separated API keys to rotate across by weighted round-robin, each optionally followed by =weight, e.g. sk-a=3,sk-b (or set OPENAI_API_KEYS env var); overrides -api-key")
+	apiKeyCooldown := flag.Duration("api-key-cooldown", 5*time.Minute, "How long a key from -api-keys is skipped after an auth or quota error")
 	demo := flag.Bool("demo", false, "Run automated demo scenario")
+	demoFile := flag.String("demo-file", "", "YAML or JSON list of {name, type, wait} scenarios for -demo to trigger in order (empty = built-in scenarios)")
 	useAI := flag.Bool("use-ai", true, "Use OpenAI for analysis (false = use fallback logic)")
+	fallbackHistory := flag.Bool("fallback-history", true, "Have the rule-based fallback propose the fix that last resolved the incident type, when there is one, instead of its canned steps")
+	calibrateFallback := flag.Bool("calibrate-fallback", true, "Replace the rule-based fallback's canned confidence with the incident type's success rate, once it has 3 resolved or failed incidents")
+	diagnoseOnly := flag.Bool("diagnose-only", false, "Analyze incidents and notify with the recommended fix, but never remediate")
+	apiPort := flag.String("api-port", "9090", "Port for the orchestrator API")
+	manageService := flag.Bool("manage-service", true, "Start and stop the built-in target service; false monitors an externally running service at -service-url")
+	serviceURL := flag.String("service-url", "http://localhost:"+servicePort, "Base URL of the monitored service, or unix:///path/to.sock for a service on a Unix socket")
+	logCapacity := flag.Int("log-capacity", 50, "Number of log entries the target service keeps")
+	accessLog := flag.Bool("access-log", true, "Log every request to the target service (method, path, status, duration)")
+	servicePprof := flag.Bool("service-pprof", false, "Serve Go runtime profiles on the target service under /debug/pprof/")
+	captureProfiles := flag.Bool("capture-profiles", false, "Capture goroutine and heap profiles from the service's /debug/pprof/ for resource exhaustion incidents and attach a summary")
+	profileDir := flag.String("profile-dir", "", "Keep the raw profiles captured by -capture-profiles in this directory (empty = summaries only)")
+	serviceConfigFile := flag.String("service-config-file", "", "Back the target service's config with this file (.json, or KEY=VALUE lines otherwise); empty keeps it in memory")
+	openAIBaseURL := flag.String("openai-base-url", "", "Custom OpenAI-compatible base URL, e.g. a proxy (default: api.openai.com)")
+	azureEndpoint := flag.String("azure-endpoint", "", "Azure OpenAI resource endpoint (enables Azure mode)")
+	azureDeployment := flag.String("azure-deployment", "", "Azure OpenAI deployment name")
+	azureAPIVersion := flag.String("azure-api-version", "", "Azure OpenAI API version (default: client library default)")
+	promptMode := flag.String("prompt-mode", string(ai.PromptModeSeparate), "Prompt assembly: separate (system+user messages) or combined (single user message)")
+	lenientParse := flag.Bool("lenient-parse", false, "Fill defaults for missing AI response fields instead of rejecting the response")
+	defaultConfidence := flag.Float64("default-confidence", 0.5, "Confidence assumed when lenient parsing and the AI omits it")
+	temperature := flag.Float64("ai-temperature", 0.3, "Sampling temperature for AI analysis (0 = deterministic)")
+	maxTokens := flag.Int("ai-max-tokens", 1000, "Maximum tokens in an AI analysis response (0 = API default)")
+	aiSeed := flag.Int("ai-seed", -1, "Seed sent with AI requests for reproducible analyses (negative = none)")
+	analysisTimeout := flag.Duration("ai-timeout", 30*time.Second, "Give up on an AI analysis call after this long and use rule-based analysis (0 = no limit)")
+	dailyTokenBudget := flag.Int("daily-token-budget", 0, "Maximum OpenAI tokens spent per day before falling back to rule-based analysis until midnight (0 = unlimited)")
+	flapWindow := flag.Duration("flap-window", 5*time.Minute, "Window of health results used to compute the flap rate")
+	flapThreshold := flag.Float64("flap-threshold", 0, "Health transitions per minute above which a FLAPPING incident is raised (0 = disabled)")
+	impactSamples := flag.Int("impact-samples", 5, "API requests sampled at detection to estimate incident impact (0 = disabled)")
+	impactInterval := flag.Duration("impact-interval", 200*time.Millisecond, "Delay between impact sampling requests")
+	degradedMode := flag.String("degraded-mode", string(monitor.DegradedWarn), "How a degraded health state is handled: ignore, warn (log only) or escalate (raise a DEGRADED incident)")
+	driftChecks := flag.Int("config-drift-checks", 0, "Raise a CONFIG_ERROR incident when the service config drifts from its known-good baseline for this many consecutive checks (0 = disabled)")
+	driftWindow := flag.Duration("config-drift-window", 30*time.Second, "How long config drift must also persist before it is an incident")
+	probeTokenURL := flag.String("probe-token-url", "", "Token endpoint for a service behind a token-auth gateway; probes send its bearer token, refreshed on expiry or a 401 (empty = unauthenticated)")
+	monitorWarmup := flag.Duration("monitor-warmup", 0, "After monitoring starts, probe the service but raise no incidents for this long, giving a slow-starting service time to come up (0 = none)")
+	probeJitter := flag.Float64("probe-jitter", 0, "Vary each wait between health probes by up to ±this fraction of the check interval, e.g. 0.2 for ±20% (0 = disabled)")
+	probeBackoffMax := flag.Duration("probe-backoff-max", 0, "While the service stays down after an incident is raised, double the wait between health probes up to this long; POST /monitor/reset returns to the check interval (0 = no backoff)")
+	probeMethod := flag.String("probe-method", string(monitor.ProbeGET), "HTTP method of health probes: GET (parse the health JSON) or HEAD (judge health by status code alone, transferring no body)")
+	latencyAlpha := flag.Float64("latency-alpha", 0.2, "Smoothing factor (0-1] of the /health latency moving average; higher reacts faster")
+	incidentBuffer := flag.Int("incident-buffer", 10, "Detected incidents that can queue for processing; incidents raised while the queue is full are dropped")
+	batchWindow := flag.Duration("batch-analysis-window", 0, "Analyze queued incidents detected within this window of each other in one AI call, up to 5 at a time (0 = one call per incident)")
+	asyncAnalysis := flag.Bool("async-analysis", false, "Store each detected incident right away with a pending-analysis placeholder, then analyze and remediate it in the background, so incidents queued behind the one being handled show up in the API")
+	healthEndpoints := flag.String("health-endpoints", "", "Comma-separated health URLs probed together as one composite service (default: the target service's /health)")
+	healthAggregation := flag.String("health-aggregation", string(monitor.AggregateAll), "How composite health is computed: all (every endpoint healthy) or majority")
+	healthDependencies := flag.String("health-dependencies", "", "Comma-separated downstream=upstream pairs of -health-endpoints, so a cascading failure is attributed to the upstream service that caused it")
+	correlationWindow := flag.Duration("correlation-window", 5*time.Second, "How long an incident for -health-endpoints waits for dependent services to fail before it is attributed to its root cause")
+	learnedTypeConfidence := flag.Float64("learned-type-confidence", 0, "Minimum confidence for an incident type learned from resolved incidents' symptoms to replace the default SERVICE_DOWN classification, e.g. 0.6 (0 = disabled)")
+	failureTypes := flag.String("failure-types", "", "Incident type per health check failure category (connection, timeout, 5xx, 4xx, unhealthy) when nothing more specific is found, e.g. 5xx=DEGRADED,timeout=RESOURCE_EXHAUSTION")
+	healthCriteria := flag.String("health-criteria", "", "How a health response body is judged healthy: field=value (e.g. status=UP, dotted paths allowed) or a field that must be true (empty = the healthy boolean)")
+	verifyEndpoint := flag.String("verify-endpoint", "", "Functional endpoint that must return 200 after a fix, in addition to the health check, e.g. /api/data")
+	verifyBodyContains := flag.String("verify-body-contains", "", "Substring the -verify-endpoint response body must contain")
+	maintenanceWindows := flag.String("maintenance-windows", "", "Comma-separated RFC3339 start/end pairs during which incidents are suppressed")
+	restartCmd := flag.String("restart-cmd", "", "External command run for restart fixes, e.g. \"systemctl restart my-service\"")
+	restartCmdTimeout := flag.Duration("restart-cmd-timeout", 30*time.Second, "Timeout for the external restart command")
+	restartCmdDir := flag.String("restart-cmd-dir", "", "Working directory for the external restart command")
+	restartCmdMode := flag.String("restart-cmd-mode", string(remediation.CommandReplace), "replace: run the command instead of the in-process restart; append: run it after")
+	soakDuration := flag.Duration("soak-duration", 0, "After verification passes, keep probing this long and only resolve the incident if the service stays healthy (0 = disabled)")
+	soakInterval := flag.Duration("soak-interval", 2*time.Second, "Delay between health probes during the soak period")
+	incidentTimeout := flag.Duration("incident-timeout", 0, "Cap on the time spent processing one incident, from analysis through fix attempts, verification and soak; an incident that runs over is marked FAILED (0 = no limit)")
+	verifyRestarts := flag.Int("verify-restarts", 0, "When verification fails after a fix, restart the service and re-verify up to this many times before declaring failure (0 = disabled)")
+	verifyStrategies := flag.String("verify-strategies", "", "Per-fix-type verification overrides, e.g. \"restart=health,config=health+config,code=manual\"")
+	reconcileMode := flag.String("reconcile-mode", string(ReconcileFail), "What to do at startup with incidents a previous run left in flight: retry or fail")
+	reconcileAfter := flag.Duration("reconcile-after", 1*time.Minute, "Only reconcile in-flight incidents detected at least this long before startup")
+	postmortemTemplate := flag.String("postmortem-template", "", "Go text/template file for GET /incidents/{id}/postmortem (empty = built-in Markdown template)")
+	webhookURL := flag.String("webhook-url", "", "Also post notifications as JSON to this URL (empty = log only)")
+	webhookRetries := flag.Int("webhook-retries", 3, "Times a failed webhook post is retried")
+	webhookBackoff := flag.Duration("webhook-backoff", 1*time.Second, "Wait before the first webhook retry, doubling on each further retry")
+	webhookDeadLetter := flag.String("webhook-dead-letter", "", "Append webhook notifications that still fail after retries to this file for replay (empty = dropped)")
+	notifyThrottle := flag.Duration("notify-throttle", 1*time.Minute, "Coalesce notifications for the same incident within this window, reporting how many were suppressed (0 = disabled)")
+	digestScheduleSpec := flag.String("digest-schedule", "", "Send a digest of incident activity through the notifier on this schedule: an interval like 24h, @hourly, @daily, @weekly or a cron expression like \"0 9 * * 1-5\" in local time (empty = disabled)")
+	compactInterval := flag.Duration("compact-interval", 1*time.Hour, "How often old resolved/failed incidents are pruned from the store (0 = only at shutdown)")
+	retainAge := flag.Duration("retain-age", 7*24*time.Hour, "Prune resolved/failed incidents closed longer ago than this (0 = no age limit)")
+	retainCount := flag.Int("retain-count", 1000, "Keep at most this many resolved/failed incidents, dropping the oldest (0 = no limit)")
+	ackExpiry := flag.Duration("ack-expiry", 30*time.Minute, "How long an acknowledgment lasts before an unresolved incident is released and re-notified (0 = never)")
+	summaryFormat := flag.String("summary-format", "text", "Format of the summary printed at shutdown: text or json (written to stdout)")
+	otelEndpoint := flag.String("otel-endpoint", "", "Export incident lifecycles as OpenTelemetry traces to this OTLP/HTTP endpoint, e.g. localhost:4318 (default: disabled)")
+	otelInsecure := flag.Bool("otel-insecure", true, "Use plain HTTP instead of HTTPS for the OTLP endpoint")
+	recordTrace := flag.String("record-trace", "", "Record incident handling events to this trace file")
+	replayTrace := flag.String("replay-trace", "", "Replay a recorded trace file without a live service or AI, then exit")
+	escalateAfter := flag.Int("escalate-after", 3, "Failed resolutions in a row after which an incident type is diagnosed and escalated to a human instead of auto-remediated (0 = never)")
+	autoApplyConfidence := flag.Float64("auto-apply-confidence", 0, "AI fixes with a lower confidence (0-1) are not applied automatically but handled by -low-confidence-policy (0 = apply all)")
+	lowConfidencePolicy := flag.String("low-confidence-policy", string(LowConfidenceDiagnose), "What to do with AI fixes below -auto-apply-confidence: diagnose (hand to a human), fail, apply-anyway or use-rule-based")
+	recurrenceThreshold := flag.Int("recurrence-threshold", 5, "Incidents of one type within -recurrence-window that mark it a recurring problem and raise severity one level per multiple (0 = disabled)")
+	recurrenceWindow := flag.Duration("recurrence-window", 1*time.Hour, "Window in which same-type incidents count toward -recurrence-threshold")
+	fixHistory := flag.Int("fix-history", 5, "Learned fixes kept per incident type; the best-performing one is reused")
+	dedupWindow := flag.Duration("dedup-window", 30*time.Minute, "Count a new incident as another occurrence of an open incident with the same fingerprint seen within this window instead of handling it again (0 = disabled)")
+	tenant := flag.String("tenant", "", "Keep this orchestrator's incidents and learned fixes in a separate per-tenant store (empty = default store)")
+	apiTenants := flag.String("api-tenants", "", "Other tenants whose stores the API serves with ?tenant=, comma-separated; \"default\" is the default store (empty = only this orchestrator's)")
+	memoryFormat := flag.String("memory-format", string(memory.FormatIndented), "Layout of the memory file: indented (readable) or compact (smaller)")
+	fixesFile := flag.String("fixes-file", "", "YAML file of known-good fixes keyed by incident type, loaded into the store as learned fixes at startup")
+	runbooksFile := flag.String("runbooks-file", "", "YAML file of remediation runbooks keyed by incident type; a type with a runbook is fixed by it instead of learned or AI-generated fixes")
+	runbookDiagnosis := flag.Bool("runbook-diagnosis", false, "Still ask the AI to diagnose incidents handled by a runbook; its suggested fix is ignored")
+	inventoryFile := flag.String("inventory-file", "", "YAML or JSON file of service metadata (team, oncall, environment, tier, ...) keyed by service name, attached to incidents, notifications and AI prompts")
+	serviceName := flag.String("service-name", "target-service", "Name of the monitored service in the -inventory-file")
+	allowedFixTypes := flag.String("allowed-fix-types", "", "Comma-separated fix types that may be applied, e.g. restart,config; other fixes are blocked and handed to a human (empty = all)")
+	allowedConfigKeys := flag.String("allowed-config-keys", "", "Comma-separated config keys fixes may change; changes to other keys are blocked (empty = all)")
+	forbiddenConfigKeys := flag.String("forbidden-config-keys", "", "Comma-separated config keys fixes may never change, e.g. database_url")
+	configFixStrategy := flag.String("config-fix-strategy", "steps", "How config fixes decide what to change: steps (the fix's own changes and steps) or baseline (revert every key that drifted from the config before the incident)")
+	validate := flag.Bool("validate", false, "Validate the configuration (API key, AI endpoint, memory file, ports, intervals), print a report and exit")
 	flag.Parse()
 
 	printBanner()
 
+	if *replayTrace != "" {
+		if err := runReplay(*replayTrace); err != nil {
+			log.Fatalf("[REPLAY] %v", err)
+		}
+		return
+	}
+
+	var apiKeys []ai.APIKey
+	if *apiKeysList != "" {
+		keys, err := ai.ParseAPIKeys(*apiKeysList)
+		if err != nil {
+			log.Fatalf("Invalid -api-keys: %v", err)
+		}
+		apiKeys = keys
+		*apiKey = keys[0].Key
+	}
+
 	// Validate API key if AI is enabled
 	if *useAI && *apiKey == "" {
 		log.Println("⚠️  No OpenAI API key provided. Using fallback analysis mode.")
@@ -49,19 +180,376 @@ func main() {
 	// Initialize components
 	log.Println("\n[SYSTEM] Initializing Incident Response System...")
 
-	targetService := service.NewTargetService(servicePort)
-	analyzer := ai.NewAnalyzer(*apiKey)
-	executor := remediation.NewExecutor(targetService)
-	store := memory.NewStore(memoryFile)
+	// Without a managed service, only monitoring and external restart commands are available
+	var targetService *service.TargetService
+	if *manageService {
+		var err error
+		targetService, err = service.NewTargetService(servicePort,
+			service.WithLogCapacity(*logCapacity),
+			service.WithAccessLog(*accessLog),
+			service.WithConfigFile(*serviceConfigFile),
+			service.WithPprof(*servicePprof),
+		)
+		if err != nil {
+			log.Fatalf("Invalid -service-config-file: %v", err)
+		}
+	} else if *demo {
+		log.Fatal("-demo requires -manage-service")
+	}
+
+	mode, err := ai.ParsePromptMode(*promptMode)
+	if err != nil {
+		log.Fatalf("Invalid -prompt-mode: %v", err)
+	}
+
+	analyzerOpts := []ai.Option{ai.WithPromptMode(mode)}
+	if *azureEndpoint != "" {
+		analyzerOpts = append(analyzerOpts, ai.WithAzure(*azureEndpoint, *azureDeployment, *azureAPIVersion))
+	}
+	analyzerOpts = append(analyzerOpts, ai.WithBaseURL(*openAIBaseURL))
+	if *lenientParse {
+		analyzerOpts = append(analyzerOpts, ai.WithLenientParsing(*defaultConfidence))
+	}
+
+	analyzerOpts = append(analyzerOpts,
+		ai.WithTemperature(float32(*temperature)),
+		ai.WithMaxTokens(*maxTokens),
+		ai.WithAnalysisTimeout(*analysisTimeout),
+		ai.WithServiceURL(*serviceURL),
+		ai.WithDailyTokenBudget(*dailyTokenBudget),
+	)
+	if *aiSeed >= 0 {
+		analyzerOpts = append(analyzerOpts, ai.WithSeed(*aiSeed))
+	}
+	if len(apiKeys) > 0 {
+		analyzerOpts = append(analyzerOpts, ai.WithAPIKeys(apiKeys, *apiKeyCooldown))
+	}
+	analyzer := ai.NewAnalyzer(*apiKey, analyzerOpts...)
+
+	if *validate {
+		cfg := validationConfig{
+			memoryFile:    memoryFile,
+			fixesFile:     *fixesFile,
+			demoFile:      *demoFile,
+			runbooksFile:  *runbooksFile,
+			inventoryFile: *inventoryFile,
+			ports: []namedPort{
+				{"-api-port", *apiPort},
+			},
+			durations: []namedDuration{
+				{"flap-window", *flapWindow, *flapThreshold > 0},
+				{"impact-interval", *impactInterval, false},
+				{"restart-cmd-timeout", *restartCmdTimeout, *restartCmd != ""},
+				{"reconcile-after", *reconcileAfter, false},
+				{"notify-throttle", *notifyThrottle, false},
+				{"webhook-backoff", *webhookBackoff, false},
+				{"ai-timeout", *analysisTimeout, false},
+				{"api-key-cooldown", *apiKeyCooldown, len(apiKeys) > 0},
+				{"config-drift-window", *driftWindow, false},
+				{"compact-interval", *compactInterval, false},
+				{"retain-age", *retainAge, false},
+				{"ack-expiry", *ackExpiry, false},
+				{"soak-duration", *soakDuration, false},
+				{"soak-interval", *soakInterval, *soakDuration > 0},
+				{"incident-timeout", *incidentTimeout, false},
+				{"recurrence-window", *recurrenceWindow, *recurrenceThreshold > 0},
+				{"dedup-window", *dedupWindow, false},
+				{"batch-analysis-window", *batchWindow, false},
+				{"monitor-warmup", *monitorWarmup, false},
+			},
+		}
+		if *useAI {
+			cfg.analyzer = analyzer
+		}
+		if *manageService {
+			cfg.ports = append(cfg.ports, namedPort{"service port", servicePort})
+		}
+		if !runValidation(context.Background(), configChecks(cfg)) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	scenarios := defaultDemoScenarios()
+	if *demoFile != "" {
+		loaded, err := LoadDemoScenarios(*demoFile)
+		if err != nil {
+			log.Fatalf("Invalid -demo-file: %v", err)
+		}
+		scenarios = loaded
+	}
+
+	var runbooks *RunbookRegistry
+	if *runbooksFile != "" {
+		runbooks, err = LoadRunbooks(*runbooksFile)
+		if err != nil {
+			log.Fatalf("Invalid -runbooks-file: %v", err)
+		}
+		log.Printf("[SYSTEM] Loaded runbooks for %s\n", strings.Join(runbooks.Types(), ", "))
+	}
+
+	var enricher inventory.Enricher
+	if *inventoryFile != "" {
+		fileEnricher, err := inventory.LoadFile(*inventoryFile)
+		if err != nil {
+			log.Fatalf("Invalid -inventory-file: %v", err)
+		}
+		if _, err := fileEnricher.Lookup(context.Background(), *serviceName); err != nil {
+			log.Printf("[SYSTEM] Warning: -inventory-file has no entry for -service-name %q (has: %s)\n", *serviceName, strings.Join(fileEnricher.Services(), ", "))
+		}
+		enricher = fileEnricher
+	}
+
+	guardrails, err := remediation.ParseGuardrails(*allowedFixTypes, *allowedConfigKeys, *forbiddenConfigKeys)
+	if err != nil {
+		log.Fatalf("Invalid guardrails: %v", err)
+	}
+	configStrategy, err := remediation.ParseConfigStrategy(*configFixStrategy)
+	if err != nil {
+		log.Fatalf("Invalid -config-fix-strategy: %v", err)
+	}
+	executorOpts := []remediation.Option{
+		remediation.WithGuardrails(guardrails),
+		remediation.WithConfigStrategy(configStrategy),
+	}
+	if fields := strings.Fields(*restartCmd); len(fields) > 0 {
+		cmdMode, err := remediation.ParseCommandMode(*restartCmdMode)
+		if err != nil {
+			log.Fatalf("Invalid -restart-cmd-mode: %v", err)
+		}
+		executorOpts = append(executorOpts, remediation.WithRestartCommand(remediation.CommandConfig{
+			Path:    fields[0],
+			Args:    fields[1:],
+			Dir:     *restartCmdDir,
+			Timeout: *restartCmdTimeout,
+			Mode:    cmdMode,
+		}))
+	}
+
+	executor := remediation.NewExecutor(targetService, executorOpts...)
+
+	format, err := memory.ParseFormat(*memoryFormat)
+	if err != nil {
+		log.Fatalf("Invalid -memory-format: %v", err)
+	}
+	rootStore, err := memory.NewStore(memoryFile, memory.WithFixHistory(*fixHistory), memory.WithFormat(format), memory.WithDedupWindow(*dedupWindow))
+	if err != nil {
+		log.Fatalf("Failed to open the incident store: %v", err)
+	}
+	store, err := rootStore.Namespace(*tenant)
+	if err != nil {
+		log.Fatalf("Invalid -tenant: %v", err)
+	}
+	if *tenant != "" {
+		log.Printf("[MEMORY] Using tenant %q\n", *tenant)
+	}
+	served, err := servedTenants(store, *apiTenants)
+	if err != nil {
+		log.Fatalf("Invalid -api-tenants: %v", err)
+	}
+
+	if *fixesFile != "" {
+		if err := seedFixes(store, *fixesFile); err != nil {
+			log.Fatalf("Invalid -fixes-file: %v", err)
+		}
+	}
+
+	detectorOpts := []monitor.Option{
+		monitor.WithFlapDetection(*flapWindow, *flapThreshold),
+		monitor.WithImpactSampling(*impactSamples, *impactInterval),
+		monitor.WithIncidentBuffer(*incidentBuffer),
+		monitor.WithLatencyAlpha(*latencyAlpha),
+		monitor.WithProbeJitter(*probeJitter),
+		monitor.WithProbeBackoff(*probeBackoffMax),
+		monitor.WithWarmup(*monitorWarmup),
+		monitor.WithConfigDrift(*driftWindow, *driftChecks),
+		monitor.WithVerificationEndpoint(*verifyEndpoint, *verifyBodyContains),
+		monitor.WithProfileCapture(*captureProfiles, *profileDir),
+	}
+	if *captureProfiles && *profileDir != "" {
+		if err := os.MkdirAll(*profileDir, 0755); err != nil {
+			log.Fatalf("Invalid -profile-dir: %v", err)
+		}
+	}
+	if *healthEndpoints != "" {
+		aggregation, err := monitor.ParseAggregationMode(*healthAggregation)
+		if err != nil {
+			log.Fatalf("Invalid -health-aggregation: %v", err)
+		}
+		var endpoints []string
+		for _, endpoint := range strings.Split(*healthEndpoints, ",") {
+			if endpoint = strings.TrimSpace(endpoint); endpoint != "" {
+				endpoints = append(endpoints, endpoint)
+			}
+		}
+		detectorOpts = append(detectorOpts, monitor.WithHealthEndpoints(endpoints, aggregation))
+
+		if *healthDependencies != "" {
+			dependsOn, err := monitor.ParseDependencies(*healthDependencies)
+			if err != nil {
+				log.Fatalf("Invalid -health-dependencies: %v", err)
+			}
+			for downstream, upstreams := range dependsOn {
+				for _, endpoint := range append([]string{downstream}, upstreams...) {
+					if !slices.Contains(endpoints, endpoint) {
+						log.Fatalf("Invalid -health-dependencies: %s is not one of -health-endpoints", endpoint)
+					}
+				}
+			}
+			detectorOpts = append(detectorOpts, monitor.WithDependencies(dependsOn, *correlationWindow))
+		}
+	}
+
+	criteria, err := monitor.ParseHealthCriteria(*healthCriteria)
+	if err != nil {
+		log.Fatalf("Invalid -health-criteria: %v", err)
+	}
+	detectorOpts = append(detectorOpts, monitor.WithHealthCriteria(criteria))
+
+	failureTypeMap, err := monitor.ParseFailureTypes(*failureTypes)
+	if err != nil {
+		log.Fatalf("Invalid -failure-types: %v", err)
+	}
+	detectorOpts = append(detectorOpts, monitor.WithFailureTypes(failureTypeMap))
+
+	if *learnedTypeConfidence < 0 || *learnedTypeConfidence > 1 {
+		log.Fatalf("Invalid -learned-type-confidence %v: must be between 0 and 1", *learnedTypeConfidence)
+	}
+	if *learnedTypeConfidence > 0 {
+		detectorOpts = append(detectorOpts, monitor.WithTypeSuggester(store, *learnedTypeConfidence))
+	}
+
+	if *probeJitter < 0 || *probeJitter >= 1 {
+		log.Fatalf("Invalid -probe-jitter %v: must be at least 0 and less than 1", *probeJitter)
+	}
+
+	degraded, err := monitor.ParseDegradedMode(*degradedMode)
+	if err != nil {
+		log.Fatalf("Invalid -degraded-mode: %v", err)
+	}
+	detectorOpts = append(detectorOpts, monitor.WithDegradedMode(degraded))
+
+	method, err := monitor.ParseProbeMethod(*probeMethod)
+	if err != nil {
+		log.Fatalf("Invalid -probe-method: %v", err)
+	}
+	if method == monitor.ProbeHEAD {
+		// Both are read from the health body, which HEAD probes don't have
+		if criteria.Field != "" {
+			log.Fatalf("Invalid -probe-method: HEAD probes can't be judged by -health-criteria")
+		}
+		if degraded == monitor.DegradedEscalate {
+			log.Fatalf("Invalid -probe-method: HEAD probes can't see degraded health for -degraded-mode=escalate")
+		}
+	}
+	detectorOpts = append(detectorOpts, monitor.WithProbeMethod(method))
+
+	if *probeTokenURL != "" {
+		detectorOpts = append(detectorOpts, monitor.WithTokenProvider(monitor.NewEndpointTokenProvider(*probeTokenURL)))
+	}
+
+	// One connection pool for every request to the service, the demo's triggers included
+	transport := monitor.NewTransport()
+	detectorOpts = append(detectorOpts, monitor.WithTransport(transport))
+
 	detector := monitor.NewIncidentDetector(
-		fmt.Sprintf("http://localhost:%s", servicePort),
+		strings.TrimRight(*serviceURL, "/"),
 		checkInterval,
+		detectorOpts...,
 	)
 
+	windows, err := monitor.ParseMaintenanceWindows(*maintenanceWindows)
+	if err != nil {
+		log.Fatalf("Invalid -maintenance-windows: %v", err)
+	}
+	for _, window := range windows {
+		detector.AddMaintenanceWindow(window)
+	}
+
+	strategies, err := ParseVerificationStrategies(*verifyStrategies)
+	if err != nil {
+		log.Fatalf("Invalid -verify-strategies: %v", err)
+	}
+	if *verifyRestarts < 0 {
+		log.Fatalf("Invalid -verify-restarts %d: must not be negative", *verifyRestarts)
+	}
+
+	reconcile, err := ParseReconcileMode(*reconcileMode)
+	if err != nil {
+		log.Fatalf("Invalid -reconcile-mode: %v", err)
+	}
+
+	lowConfidence, err := ParseLowConfidencePolicy(*lowConfidencePolicy)
+	if err != nil {
+		log.Fatalf("Invalid -low-confidence-policy: %v", err)
+	}
+	if *autoApplyConfidence < 0 || *autoApplyConfidence > 1 {
+		log.Fatalf("Invalid -auto-apply-confidence %v: must be between 0 and 1", *autoApplyConfidence)
+	}
+
+	if *summaryFormat != "text" && *summaryFormat != "json" {
+		log.Fatalf("Invalid -summary-format %q: must be text or json", *summaryFormat)
+	}
+
 	// Start target service
-	log.Println("[SYSTEM] Starting target service...")
-	if err := targetService.Start(); err != nil {
-		log.Fatalf("Failed to start service: %v", err)
+	if *manageService {
+		log.Println("[SYSTEM] Starting target service...")
+		if err := targetService.Start(); err != nil {
+			log.Fatalf("Failed to start service: %v", err)
+		}
+	} else {
+		log.Printf("[SYSTEM] Monitoring externally managed service at %s\n", *serviceURL)
+	}
+
+	if *otelEndpoint != "" {
+		shutdownTracing, err := telemetry.Setup(context.Background(), *otelEndpoint, *otelInsecure)
+		if err != nil {
+			log.Fatalf("Failed to set up OpenTelemetry: %v", err)
+		}
+		defer shutdownTracing(context.Background())
+	}
+
+	var recorder *trace.Recorder
+	if *recordTrace != "" {
+		recorder, err = trace.NewRecorder(*recordTrace)
+		if err != nil {
+			log.Fatalf("Failed to start trace recording: %v", err)
+		}
+		defer recorder.Close()
+		log.Printf("[TRACE] Recording incident handling to %s\n", *recordTrace)
+	}
+
+	var webhook *notify.WebhookNotifier
+	var delivery notify.Notifier = notify.NewLogNotifier()
+	if *webhookURL != "" {
+		if *webhookRetries < 0 {
+			log.Fatalf("Invalid -webhook-retries %d: must not be negative", *webhookRetries)
+		}
+		webhook = notify.NewWebhookNotifier(*webhookURL,
+			notify.WithRetries(*webhookRetries, *webhookBackoff),
+			notify.WithDeadLetterFile(*webhookDeadLetter),
+		)
+		delivery = notify.NewMultiNotifier(delivery, webhook)
+	}
+	notifier := notify.NewThrottledNotifier(delivery, *notifyThrottle)
+
+	var digests *digestSchedule
+	if *digestScheduleSpec != "" {
+		digests, err = parseDigestSchedule(*digestScheduleSpec)
+		if err != nil {
+			log.Fatalf("Invalid -digest-schedule: %v", err)
+		}
+	}
+
+	postmortem, err := ParsePostmortemTemplate(*postmortemTemplate)
+	if err != nil {
+		log.Fatalf("Invalid -postmortem-template: %v", err)
+	}
+
+	// With async analysis, incidents are stored as soon as they are detected
+	var accepted chan *models.Incident
+	if *asyncAnalysis {
+		accepted = make(chan *models.Incident, max(*incidentBuffer, 0))
 	}
 
 	// Create orchestrator
@@ -70,8 +558,56 @@ func main() {
 		detector: detector,
 		analyzer: analyzer,
 		executor: executor,
+		verifier: detector,
 		store:    store,
-		useAI:    *useAI,
+		tenants:  served,
+		notifier: notifier,
+		webhook:  webhook,
+		recorder: recorder,
+
+		postmortemTemplate: postmortem,
+		useAI:              *useAI,
+		fallbackHistory:    *fallbackHistory,
+		calibrateFallback:  *calibrateFallback,
+		requeue:            make(chan *models.Incident, 16),
+		accepted:           accepted,
+
+		batchWindow: *batchWindow,
+
+		diagnoseOnly:  *diagnoseOnly,
+		escalateAfter: *escalateAfter,
+
+		recurrenceWindow:    *recurrenceWindow,
+		recurrenceThreshold: *recurrenceThreshold,
+
+		autoApplyConfidence: *autoApplyConfidence,
+		lowConfidencePolicy: lowConfidence,
+
+		runbooks:         runbooks,
+		runbookDiagnosis: *runbookDiagnosis,
+
+		enricher:    enricher,
+		serviceName: *serviceName,
+
+		stabilizeDelay: 2 * time.Second,
+		verifyInterval: 1 * time.Second,
+		soakDuration:   *soakDuration,
+		soakInterval:   *soakInterval,
+
+		incidentTimeout: *incidentTimeout,
+
+		verifyRestarts: *verifyRestarts,
+		restarter:      executor,
+		reclassifier:   detector,
+
+		verifyStrategies: strategies,
+
+		ackExpiry: *ackExpiry,
+	}
+
+	// Config assertions need the live config of the managed service
+	if targetService != nil {
+		orch.config = targetService
 	}
 
 	// Setup context and signal handling
@@ -81,48 +617,173 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
+	// Incidents detected before this point were left by a previous run
+	reconcileCutoff := time.Now().Add(-*reconcileAfter)
+
 	// Start monitoring
 	detector.Start(ctx)
 
 	// Start incident handler
 	go orch.handleIncidents(ctx)
+	go orch.reconcile(ctx, reconcile, reconcileCutoff)
+	go runCompaction(ctx, store, *compactInterval, *retainAge, *retainCount)
+	if digests != nil {
+		log.Printf("[SYSTEM] 📰 Sending incident digests on schedule %s (first at %s)\n", digests, digests.next(time.Now()).Format("2006-01-02 15:04"))
+		go orch.runDigests(ctx, digests)
+	}
+
+	// Start orchestrator API
+	apiServer := NewAPIServer(*apiPort, orch)
+	if err := apiServer.Start(); err != nil {
+		log.Fatalf("Failed to start API server: %v", err)
+	}
 
-	log.Println("[SYSTEM] ✓ System ready!")
-	log.Printf("[SYSTEM] Service running at: http://localhost:%s\n", servicePort)
+	orch.ready.Store(true)
+	log.Printf("[SYSTEM] ✓ System ready! (version %s, commit %s)\n", buildinfo.Version, buildinfo.Commit)
+	log.Printf("[SYSTEM] Service running at: %s\n", *serviceURL)
 	log.Println("\n" + strings.Repeat("=", 70))
 	printUsageInstructions()
 
-	// Run demo if requested
+	// Run demo if requested; shutdown waits for it so it can't race the teardown
+	demoDone := make(chan struct{})
 	if *demo {
-		go runDemo(targetService)
+		go func() {
+			defer close(demoDone)
+			runDemo(ctx, targetService, &http.Client{Transport: transport, Timeout: 5 * time.Second}, scenarios)
+		}()
+	} else {
+		close(demoDone)
 	}
 
 	// Wait for interrupt
 	<-sigChan
 	log.Println("\n[SYSTEM] Shutting down...")
 
-	cancel()
+	// Stopped before cancelling, while the monitor loop is still there to take the signal
 	detector.Stop()
-	targetService.Stop()
+	cancel()
+	<-demoDone
+	orch.stopAckTimers()
+	notifier.Close()
+	if webhook != nil {
+		webhook.Close()
+	}
+	apiServer.Stop()
+	if *manageService {
+		targetService.Stop()
+	}
+
+	compactStore(store, *retainAge, *retainCount)
 
 	log.Println("[SYSTEM] Printing final summary...")
-	store.PrintSummary()
+	if *summaryFormat == "json" {
+		summary, err := store.SummaryJSON()
+		if err != nil {
+			log.Printf("[MEMORY] Warning: failed to encode summary: %v\n", err)
+		} else {
+			fmt.Println(string(summary))
+		}
+	} else {
+		store.PrintSummary()
+	}
 
 	log.Println("[SYSTEM] Goodbye!")
 }
 
+// incidentAnalyzer diagnoses incidents and proposes fixes
+type incidentAnalyzer interface {
+	AnalyzeIncident(ctx context.Context, incident *models.Incident, previousAttempts []models.Resolution) (*models.AIResponse, error)
+	GetQuickAnalysis(incident *models.Incident, lastFix *models.Resolution, confidence float64) *models.AIResponse
+}
+
+// fixExecutor applies fixes to the monitored service
+type fixExecutor interface {
+	ExecuteFix(ctx context.Context, incident *models.Incident, aiResponse *models.AIResponse) (*models.Resolution, error)
+	ApplyCachedFix(ctx context.Context, incident *models.Incident, cachedResolution *models.Resolution) error
+}
+
+// healthVerifier performs a single post-fix health check
+type healthVerifier interface {
+	VerifyResolution() bool
+}
+
+// serviceRestarter restarts the monitored service outside of a fix
+type serviceRestarter interface {
+	Restart(ctx context.Context) error
+}
+
 // Orchestrator coordinates incident detection and response
 type Orchestrator struct {
 	service  *service.TargetService
 	detector *monitor.IncidentDetector
-	analyzer *ai.Analyzer
-	executor *remediation.Executor
+	analyzer incidentAnalyzer
+	executor fixExecutor
+	verifier healthVerifier
 	store    *memory.Store
-	useAI    bool
+	tenants  map[string]bool // tenants the API serves, this orchestrator's own included
+	notifier notify.Notifier
+	webhook  *notify.WebhookNotifier // nil unless -webhook-url is set
+
+	postmortemTemplate *template.Template // renders GET /incidents/{id}/postmortem
+	recorder           *trace.Recorder    // nil unless -record-trace is set
+	useAI              bool
+	fallbackHistory    bool                  // base rule-based fixes on the fix that last resolved the type
+	calibrateFallback  bool                  // replace canned rule-based confidences with ones from past outcomes
+	requeue            chan *models.Incident // incidents re-enqueued for processing, e.g. by startup reconciliation
+	accepted           chan *models.Incident // incidents stored at detection, awaiting processing (nil = taken straight from the detector)
+
+	batchWindow time.Duration // queued incidents detected within this long of each other are analyzed in one AI call (0 = one call each)
+	batchMu     sync.Mutex
+	batched     map[string]*models.AIResponse // incident ID -> analysis from a batched call, awaiting processing
+
+	diagnoseOnly  bool // analyze and notify, never call the executor
+	escalateAfter int  // failed resolutions in a row after which a type is escalated instead of remediated (0 = never)
+
+	recurrenceWindow    time.Duration // how far back same-type incidents count toward recurrence
+	recurrenceThreshold int           // same-type incidents in the window that make a recurring problem (0 = disabled)
+
+	autoApplyConfidence float64             // AI fixes below this confidence are not applied automatically (0 = apply all)
+	lowConfidencePolicy LowConfidencePolicy // what to do with AI fixes below autoApplyConfidence
+
+	runbooks         *RunbookRegistry // operator runbooks, preferred over learned and AI fixes (nil = none)
+	runbookDiagnosis bool             // still ask the AI to diagnose incidents a runbook fixes
+
+	enricher    inventory.Enricher // looks up the service's ownership metadata for incidents (nil = none)
+	serviceName string             // the monitored service's name in the inventory
+
+	stabilizeDelay time.Duration // wait after a fix before verifying
+	verifyInterval time.Duration // wait between verification health checks
+	soakDuration   time.Duration // keep probing this long after verification passes (0 = no soak)
+	soakInterval   time.Duration // wait between soak probes
+
+	incidentTimeout time.Duration // cap on processing one incident (0 = no limit)
+
+	verifyRestarts int                     // restart-and-reverify attempts after verification fails (0 = none)
+	restarter      serviceRestarter        // restarts the service between verification attempts (nil = no retries)
+	reclassifier   currentIncidentDetector // re-classifies the service when a fix fails verification (nil = never)
+
+	verifyStrategies map[string]VerificationStrategy // fix type -> verification strategy
+	config           configReader                    // live config for config assertions (nil skips them)
+
+	budgetNotifiedUntil time.Time // reset time of the budget window already notified as exhausted
+
+	ackExpiry time.Duration // how long an acknowledgment lasts without resolution (0 = forever)
+	ackMu     sync.Mutex
+	ackTimers map[string]*time.Timer // incident ID -> pending acknowledgment expiry
+
+	ready       atomic.Bool  // set once monitoring and incident handling have started
+	remediating atomic.Int32 // fixes currently being applied
+
+	processingMu sync.Mutex
+	processing   map[string]context.CancelCauseFunc // incident ID -> cancels its processing
 }
 
 func (o *Orchestrator) handleIncidents(ctx context.Context) {
 	incidentChan := o.detector.GetIncidentChannel()
+	if o.accepted != nil {
+		go o.acceptIncidents(ctx, incidentChan)
+		incidentChan = o.accepted
+	}
 
 	for {
 		select {
@@ -130,48 +791,170 @@ func (o *Orchestrator) handleIncidents(ctx context.Context) {
 			return
 
 		case incident := <-incidentChan:
+			for _, incident := range o.takeQueued(ctx, incident, incidentChan) {
+				if err := o.processIncident(ctx, incident); err != nil {
+					telemetry.Logf(ctx, "[SYSTEM] ❌ Failed to process incident: %v\n", err)
+				}
+			}
+
+		case incident := <-o.requeue:
 			if err := o.processIncident(ctx, incident); err != nil {
-				log.Printf("[SYSTEM] ❌ Failed to process incident: %v\n", err)
+				telemetry.Logf(ctx, "[SYSTEM] ❌ Failed to process incident: %v\n", err)
 			}
 		}
 	}
 }
 
 func (o *Orchestrator) processIncident(ctx context.Context, incident *models.Incident) error {
+	// Processing starts now, so an incident accepted at detection is no longer pending
+	if incident.Diagnosis == pendingDiagnosis {
+		incident.Diagnosis = ""
+	}
+
+	// Incidents stored before correlation IDs existed get one when reprocessed
+	if incident.CorrelationID == "" {
+		incident.CorrelationID = telemetry.NewCorrelationID()
+	}
+	ctx = telemetry.WithCorrelationID(ctx, incident.CorrelationID)
+
 	log.Println("\n" + strings.Repeat("=", 70))
-	log.Printf("[DETECTOR] 🚨 Incident Detected: %s\n", incident.Type)
-	log.Printf("[DETECTOR] ID: %s\n", incident.ID)
+	telemetry.Logf(ctx, "[DETECTOR] 🚨 Incident Detected: %s\n", incident.Type)
+	telemetry.Logf(ctx, "[DETECTOR] ID: %s\n", incident.ID)
+	if incident.Severity != "" {
+		telemetry.Logf(ctx, "[DETECTOR] Severity: %s\n", incident.Severity)
+	}
 	log.Println(strings.Repeat("=", 70))
 
-	// Store initial incident
-	if err := o.store.StoreIncident(incident); err != nil {
-		log.Printf("[MEMORY] Warning: failed to store incident: %v\n", err)
+	o.enrich(ctx, incident)
+
+	// The incident span covers detection through outcome
+	ctx, span := telemetry.Tracer().Start(ctx, "incident",
+		oteltrace.WithTimestamp(incident.DetectedAt),
+		oteltrace.WithAttributes(
+			attribute.String("incident.id", incident.ID),
+			attribute.String("incident.type", string(incident.Type)),
+			attribute.String("incident.severity", string(incident.Severity)),
+			attribute.String("incident.correlation_id", incident.CorrelationID),
+		),
+	)
+	_, detection := telemetry.Tracer().Start(ctx, "detection",
+		oteltrace.WithTimestamp(incident.DetectedAt),
+		oteltrace.WithAttributes(
+			attribute.Float64("incident.impact_score", incident.ImpactScore),
+			attribute.Int("incident.symptoms", len(incident.Symptoms)),
+		),
+	)
+	detection.End()
+
+	cachedFix, hasCachedFix := o.store.GetLearnedFix(incident.Type)
+	batched := o.takeBatchedAnalysis(incident.ID)
+	o.record(trace.Event{Kind: trace.EventDetection, IncidentID: incident.ID, Incident: incident, LearnedFix: cachedFix})
+	defer func() {
+		o.record(trace.Event{Kind: trace.EventOutcome, IncidentID: incident.ID, Incident: incident})
+		span.SetAttributes(
+			attribute.String("incident.type", string(incident.Type)),
+			attribute.String("incident.severity", string(incident.Severity)),
+			attribute.Bool("incident.recurring", incident.Recurring),
+			attribute.String("incident.status", string(incident.Status)),
+			attribute.Bool("incident.used_cached_fix", incident.UsedCachedFix),
+		)
+		span.End()
+	}()
+
+	// Operators can abort this incident's processing through the API, and it may run out of time
+	ctx, done := o.beginProcessing(ctx, incident.ID)
+	defer done()
+
+	// Store initial incident, unless it is the same problem as an incident still open
+	if err := o.store.StoreIncident(incident); errors.Is(err, memory.ErrDuplicateIncident) {
+		telemetry.Logf(ctx, "[MEMORY] 🔁 %v, not handling it again\n", err)
+		span.SetAttributes(attribute.String("incident.duplicate_of", incident.DuplicateOf))
+		return nil
+	} else if err != nil {
+		telemetry.Logf(ctx, "[MEMORY] Warning: failed to store incident: %v\n", err)
+	}
+
+	// A type that keeps recurring is a systemic problem, so it gets more attention
+	if o.boostRecurring(ctx, incident) {
+		if err := o.store.StoreIncident(incident); err != nil {
+			telemetry.Logf(ctx, "[MEMORY] Warning: failed to store incident: %v\n", err)
+		}
+	}
+
+	// Types that keep failing remediation are diagnosed and handed to a human instead
+	remediate := !o.diagnoseOnly
+	streak, escalated := o.remediationSuspended(incident.Type)
+	if escalated && remediate {
+		telemetry.Logf(ctx, "[SYSTEM] ⏭️  Skipping auto-remediation: last %d %s resolutions failed\n", streak, incident.Type)
+		remediate = false
+	}
+
+	// Fixes that were tried and failed, passed to the AI so it suggests something else
+	var previousAttempts []models.Resolution
+	if incident.Relapses > 0 && incident.Resolution != nil {
+		previousAttempts = append(previousAttempts, *incident.Resolution)
 	}
 
-	// Check if we have a learned fix
-	if cachedFix, exists := o.store.GetLearnedFix(incident.Type); exists {
-		log.Println("[MEMORY] ⚡ Found learned fix! Applying without AI call...")
+	// An operator runbook takes precedence over learned and AI-generated fixes
+	runbook, hasRunbook := o.runbooks.Lookup(incident.Type)
+	incident.UsedRunbook = hasRunbook
+
+	// Check if we have a learned fix (applying it is remediation, so diagnose-only skips it)
+	if hasCachedFix && remediate && !hasRunbook {
+		telemetry.Logln(ctx, "[MEMORY] ⚡ Found learned fix! Applying without AI call...")
 		incident.UsedCachedFix = true
 
-		if err := o.executor.ApplyCachedFix(incident, cachedFix); err != nil {
-			log.Printf("[REMEDIATION] ❌ Cached fix failed: %v\n", err)
-			log.Println("[REMEDIATION] Falling back to AI analysis...")
+		endRemediation := o.beginRemediation()
+		err := o.executor.ApplyCachedFix(ctx, incident, cachedFix)
+		endRemediation()
+		o.record(trace.Event{Kind: trace.EventCachedFix, IncidentID: incident.ID, Resolution: cachedFix, Error: errString(err)})
+		if o.abortedAt(ctx, incident, "the cached fix") {
+			return nil
+		}
+
+		if err != nil {
+			telemetry.Logf(ctx, "[REMEDIATION] ❌ Cached fix failed: %v\n", err)
+			telemetry.Logln(ctx, "[REMEDIATION] Falling back to AI analysis...")
+			// A fix the guardrails blocked never ran, so it didn't fail
+			if !errors.Is(err, remediation.ErrForbiddenAction) {
+				if err := o.store.RecordFixFailure(incident.Type, cachedFix); err != nil {
+					telemetry.Logf(ctx, "[MEMORY] Warning: failed to record fix failure: %v\n", err)
+				}
+			}
 		} else {
 			// Verify resolution
-			if o.verifyResolution() {
+			result := o.verifyFix(ctx, incident, cachedFix)
+			if o.abortedAt(ctx, incident, "verification") {
+				return nil
+			}
+
+			switch result {
+			case verificationPassed:
 				incident.Status = models.StatusResolved
 				now := time.Now()
 				incident.ResolvedAt = &now
 				incident.Resolution = cachedFix
 				o.store.StoreIncident(incident)
+				o.recordOutcome(ctx, incident, true)
 
-				log.Println("[SYSTEM] ✅ Incident resolved using cached fix!")
-				log.Printf("[SYSTEM] Resolution time: %v\n", time.Since(incident.DetectedAt))
+				telemetry.Logln(ctx, "[SYSTEM] ✅ Incident resolved using cached fix!")
+				telemetry.Logf(ctx, "[SYSTEM] Resolution time: %v\n", time.Since(incident.DetectedAt))
 				return nil
-			} else {
-				log.Println("[VERIFICATION] ❌ Service still unhealthy after cached fix")
+			case verificationManual:
+				o.requestManualVerification(ctx, incident, cachedFix)
+				return nil
+			default:
+				telemetry.Logf(ctx, "[VERIFICATION] ❌ Cached fix could not be verified (%s)\n", result)
+				if err := o.store.RecordFixFailure(incident.Type, cachedFix); err != nil {
+					telemetry.Logf(ctx, "[MEMORY] Warning: failed to record fix failure: %v\n", err)
+				}
+				if result == verificationFailed && o.followUp(ctx, incident, cachedFix) {
+					return nil
+				}
 			}
 		}
+
+		previousAttempts = append(previousAttempts, *cachedFix)
 	}
 
 	// No cached fix or cached fix failed - use AI
@@ -180,85 +963,399 @@ func (o *Orchestrator) processIncident(ctx context.Context, incident *models.Inc
 
 	var aiResponse *models.AIResponse
 	var err error
+	fromAI := false
 
-	if o.useAI {
-		log.Println("[AI] Calling OpenAI for incident analysis...")
-		aiResponse, err = o.analyzer.AnalyzeIncident(ctx, incident)
+	if hasRunbook && !o.runbookDiagnosis {
+		telemetry.Logf(ctx, "[RUNBOOK] 📘 Using the %s runbook, skipping analysis\n", incident.Type)
+		aiResponse = runbook.response(nil)
+	} else if batched != nil && len(previousAttempts) == 0 {
+		// A batch analysis knows nothing of fixes tried since, so it is only used without any
+		telemetry.Logln(ctx, "[AI] 📦 Using the analysis from a batched OpenAI call")
+		aiResponse, fromAI = batched, true
+		o.record(trace.Event{Kind: trace.EventAnalysis, IncidentID: incident.ID, Source: trace.SourceAI, AIResponse: aiResponse})
+	} else if o.useAI {
+		telemetry.Logln(ctx, "[AI] Calling OpenAI for incident analysis...")
+		aiResponse, err = o.analyzer.AnalyzeIncident(ctx, incident, previousAttempts)
+		o.record(trace.Event{Kind: trace.EventAnalysis, IncidentID: incident.ID, Source: trace.SourceAI, AIResponse: aiResponse, Error: errString(err)})
+		fromAI = err == nil
 		if err != nil {
-			log.Printf("[AI] ❌ OpenAI error: %v\n", err)
-			log.Println("[AI] Falling back to rule-based analysis...")
-			aiResponse = o.analyzer.GetQuickAnalysis(incident)
+			var budgetErr *ai.BudgetExceededError
+			if errors.As(err, &budgetErr) {
+				o.budgetExhausted(ctx, incident, budgetErr)
+			} else if errors.Is(err, ai.ErrAnalysisTimeout) {
+				telemetry.Logf(ctx, "[AI] ⏱️  %v\n", err)
+			} else {
+				telemetry.Logf(ctx, "[AI] ❌ OpenAI error: %v\n", err)
+			}
+			telemetry.Logln(ctx, "[AI] Falling back to rule-based analysis...")
+			aiResponse = o.quickAnalysis(ctx, incident, previousAttempts)
 		}
 	} else {
-		log.Println("[AI] Using fallback rule-based analysis...")
-		aiResponse = o.analyzer.GetQuickAnalysis(incident)
+		telemetry.Logln(ctx, "[AI] Using fallback rule-based analysis...")
+		aiResponse = o.quickAnalysis(ctx, incident, previousAttempts)
+	}
+	if o.abortedAt(ctx, incident, "analysis") {
+		return nil
+	}
+
+	// The runbook's fix replaces the suggested one, so the confidence gate doesn't apply
+	if hasRunbook {
+		if o.runbookDiagnosis {
+			telemetry.Logf(ctx, "[RUNBOOK] 📘 Using the %s runbook instead of the suggested fix\n", incident.Type)
+			aiResponse = runbook.response(aiResponse)
+		}
+		fromAI = false
+	}
+
+	// AI fixes too uncertain to apply automatically are handled by the low-confidence policy
+	lowConfidence := remediate && fromAI && o.belowAutoApply(aiResponse)
+	confidence := aiResponse.Confidence
+	if lowConfidence {
+		telemetry.Logf(ctx, "[AI] ⚠️  %s (policy: %s)\n", o.lowConfidenceReason(confidence), o.lowConfidencePolicy)
+		if o.lowConfidencePolicy == LowConfidenceRuleBased {
+			telemetry.Logln(ctx, "[AI] Using rule-based analysis instead...")
+			aiResponse = o.quickAnalysis(ctx, incident, previousAttempts)
+		}
 	}
 
 	incident.Diagnosis = aiResponse.Diagnosis
-	log.Printf("[AI] 📊 Diagnosis: %s\n", aiResponse.Diagnosis)
-	log.Printf("[AI] 🔧 Fix Type: %s\n", aiResponse.FixType)
-	log.Printf("[AI] 📝 Steps: %d\n", len(aiResponse.FixSteps))
+	incident.RootCauseCategory = aiResponse.RootCauseCategory
+	incident.Recommendations = aiResponse.Recommendations
+	incident.SystemFingerprint = aiResponse.SystemFingerprint
+	o.applyTypeCorrection(ctx, incident, aiResponse)
+	telemetry.Logf(ctx, "[AI] 📊 Diagnosis: %s\n", aiResponse.Diagnosis)
+	if aiResponse.RootCauseCategory != "" {
+		telemetry.Logf(ctx, "[AI] 🏷️  Root Cause: %s\n", aiResponse.RootCauseCategory)
+	}
+	telemetry.Logf(ctx, "[AI] 🔧 Fix Type: %s\n", aiResponse.FixType)
+	telemetry.Logf(ctx, "[AI] 📝 Steps: %d\n", len(aiResponse.FixSteps))
+	for _, recommendation := range aiResponse.Recommendations {
+		telemetry.Logf(ctx, "[AI] 💡 Recommendation: %s\n", recommendation)
+	}
+
+	if !remediate {
+		reason := "diagnose-only mode"
+		if escalated {
+			reason = fmt.Sprintf("escalated after %d failed resolutions in a row", streak)
+		}
+		o.completeDiagnosis(ctx, incident, aiResponse, reason)
+		return nil
+	}
+
+	if lowConfidence {
+		switch o.lowConfidencePolicy {
+		case LowConfidenceFail:
+			o.failLowConfidence(ctx, incident, aiResponse)
+			return nil
+		case LowConfidenceDiagnose:
+			o.completeDiagnosis(ctx, incident, aiResponse, o.lowConfidenceReason(confidence))
+			return nil
+		}
+	}
 
 	// Execute fix
 	incident.Status = models.StatusFixing
 	o.store.UpdateIncidentStatus(incident.ID, models.StatusFixing)
 
-	resolution, err := o.executor.ExecuteFix(incident, aiResponse)
+	endRemediation := o.beginRemediation()
+	resolution, err := o.executor.ExecuteFix(ctx, incident, aiResponse)
+	endRemediation()
+	o.record(trace.Event{Kind: trace.EventRemediation, IncidentID: incident.ID, Resolution: resolution, Error: errString(err)})
+	if o.abortedAt(ctx, incident, "remediation") {
+		return nil
+	}
+	if errors.Is(err, remediation.ErrForbiddenAction) {
+		// Blocked fixes are handed to a human like any other fix that may not be applied
+		o.completeDiagnosis(ctx, incident, aiResponse, err.Error())
+		return nil
+	}
 	if err != nil {
 		incident.Status = models.StatusFailed
 		o.store.StoreIncident(incident)
+		o.recordOutcome(ctx, incident, false)
 		return fmt.Errorf("failed to execute fix: %w", err)
 	}
 
 	incident.Resolution = resolution
 
 	// Verify resolution
-	time.Sleep(2 * time.Second) // Give service time to stabilize
+	sleepContext(ctx, o.stabilizeDelay) // Give service time to stabilize
 
-	if o.verifyResolution() {
+	result := o.verifyFix(ctx, incident, resolution)
+	if o.abortedAt(ctx, incident, "verification") {
+		return nil
+	}
+
+	switch result {
+	case verificationPassed:
 		incident.Status = models.StatusResolved
 		now := time.Now()
 		incident.ResolvedAt = &now
 		o.store.StoreIncident(incident)
+		o.recordOutcome(ctx, incident, true)
 
 		log.Println("\n" + strings.Repeat("=", 70))
-		log.Println("[SYSTEM] ✅ INCIDENT RESOLVED!")
-		log.Printf("[SYSTEM] Resolution time: %v\n", time.Since(incident.DetectedAt))
+		telemetry.Logln(ctx, "[SYSTEM] ✅ INCIDENT RESOLVED!")
+		telemetry.Logf(ctx, "[SYSTEM] Resolution time: %v\n", time.Since(incident.DetectedAt))
 		log.Println(strings.Repeat("=", 70) + "\n")
-	} else {
+	case verificationManual:
+		o.requestManualVerification(ctx, incident, resolution)
+	default:
+		if result == verificationFailed && o.followUp(ctx, incident, resolution) {
+			return nil
+		}
+		o.recordOutcome(ctx, incident, false)
+		if result == verificationRelapsed && o.reopen(ctx, incident) {
+			return nil
+		}
+
 		incident.Status = models.StatusFailed
+		if result == verificationRelapsed {
+			incident.FailureReason = fmt.Sprintf("service failed again during the %v soak period", o.soakDuration)
+		}
 		o.store.StoreIncident(incident)
 
 		log.Println("\n" + strings.Repeat("=", 70))
-		log.Println("[SYSTEM] ❌ INCIDENT NOT RESOLVED")
-		log.Println("[SYSTEM] Service still reporting unhealthy after fix attempt")
+		telemetry.Logln(ctx, "[SYSTEM] ❌ INCIDENT NOT RESOLVED")
+		telemetry.Logf(ctx, "[SYSTEM] Fix could not be verified after fix attempt (%s)\n", result)
 		log.Println(strings.Repeat("=", 70) + "\n")
 	}
 
 	return nil
 }
 
-func (o *Orchestrator) verifyResolution() bool {
-	log.Println("[VERIFICATION] Checking service health...")
+// applyTypeCorrection reclassifies the incident when the AI disagrees with detection,
+// so the fix is learned under the right type
+func (o *Orchestrator) applyTypeCorrection(ctx context.Context, incident *models.Incident, aiResponse *models.AIResponse) {
+	corrected := aiResponse.CorrectedType
+	if corrected == "" || corrected == incident.Type {
+		return
+	}
+
+	telemetry.Logf(ctx, "[AI] 🔀 Incident type corrected: %s → %s\n", incident.Type, corrected)
+	if incident.DetectedType == "" {
+		incident.DetectedType = incident.Type
+	}
+	incident.Type = corrected
+
+	if err := o.store.StoreIncident(incident); err != nil {
+		telemetry.Logf(ctx, "[MEMORY] Warning: failed to store incident: %v\n", err)
+	}
+}
+
+// completeDiagnosis records the AI's recommendation without applying it and notifies humans.
+// reason explains why no fix was applied.
+func (o *Orchestrator) completeDiagnosis(ctx context.Context, incident *models.Incident, aiResponse *models.AIResponse, reason string) {
+	incident.RecommendedFix = &models.Resolution{
+		FixType:       aiResponse.FixType,
+		Description:   aiResponse.Diagnosis,
+		Steps:         aiResponse.FixSteps,
+		Code:          aiResponse.Code,
+		ConfigChanges: aiResponse.ConfigChanges,
+		Success:       false,
+	}
+	incident.Status = models.StatusDiagnosed
+	o.store.StoreIncident(incident)
+
+	var body strings.Builder
+	body.WriteString(fmt.Sprintf("No fix applied: %s\n", reason))
+	body.WriteString(fmt.Sprintf("Diagnosis: %s\n", aiResponse.Diagnosis))
+	body.WriteString(fmt.Sprintf("Recommended fix (%s):\n", aiResponse.FixType))
+	for i, step := range aiResponse.FixSteps {
+		body.WriteString(fmt.Sprintf("%d. %s\n", i+1, step))
+	}
+	if len(aiResponse.Recommendations) > 0 {
+		body.WriteString("Preventive recommendations:\n")
+		for _, recommendation := range aiResponse.Recommendations {
+			body.WriteString(fmt.Sprintf("- %s\n", recommendation))
+		}
+	}
+
+	o.notify(ctx, incident, fmt.Sprintf("%s incident diagnosed - manual remediation required", incident.Type), body.String())
+
+	log.Println("\n" + strings.Repeat("=", 70))
+	telemetry.Logf(ctx, "[SYSTEM] 🩺 INCIDENT DIAGNOSED (%s, no fix applied)\n", reason)
+	log.Println(strings.Repeat("=", 70) + "\n")
+}
+
+// budgetExhausted logs a spent AI budget and notifies once per budget window
+func (o *Orchestrator) budgetExhausted(ctx context.Context, incident *models.Incident, budgetErr *ai.BudgetExceededError) {
+	telemetry.Logf(ctx, "[AI] 💰 %v\n", budgetErr)
+
+	if !budgetErr.ResetAt.After(o.budgetNotifiedUntil) {
+		return
+	}
+	o.budgetNotifiedUntil = budgetErr.ResetAt
+
+	o.notify(ctx, incident, "Daily AI token budget exhausted",
+		fmt.Sprintf("%d of %d tokens used. Incidents will use rule-based analysis until %s.\n",
+			budgetErr.Used, budgetErr.Limit, budgetErr.ResetAt.Format(time.RFC3339)))
+}
+
+// beginRemediation marks a fix as being applied until the returned function is called.
+// The detector is told too, so the downtime of a restart the fix makes isn't reported as
+// a new incident.
+func (o *Orchestrator) beginRemediation() (end func()) {
+	o.remediating.Add(1)
+	endDetector := func() {}
+	if o.detector != nil {
+		endDetector = o.detector.BeginRemediation()
+	}
+
+	return func() {
+		endDetector()
+		o.remediating.Add(-1)
+	}
+}
+
+// notify sends a notification about an incident, logging delivery failures
+func (o *Orchestrator) notify(ctx context.Context, incident *models.Incident, title, body string) {
+	if o.notifier == nil {
+		return
+	}
+
+	msg := notify.Message{
+		IncidentID:    incident.ID,
+		CorrelationID: incident.CorrelationID,
+		Title:         title,
+		Body:          body,
+		Incident:      incident,
+	}
+
+	if err := o.notifier.Notify(ctx, msg); err != nil {
+		telemetry.Logf(ctx, "[NOTIFY] Warning: failed to send notification: %v\n", err)
+	}
+}
+
+// quickAnalysis runs the rule-based fallback analysis. With fallbackHistory, it is based
+// on the fix that last resolved the incident's type, unless that fix was already tried.
+func (o *Orchestrator) quickAnalysis(ctx context.Context, incident *models.Incident, previousAttempts []models.Resolution) *models.AIResponse {
+	_, span := telemetry.Tracer().Start(ctx, "analysis", oteltrace.WithAttributes(
+		attribute.String("analysis.source", "fallback"),
+	))
+	defer span.End()
+
+	var lastFix *models.Resolution
+	if o.fallbackHistory {
+		lastFix, _ = o.store.LastSuccessfulFix(incident.Type, previousAttempts...)
+		if lastFix != nil {
+			telemetry.Logf(ctx, "[AI] 📚 Reusing the %s fix that last resolved a %s incident\n", lastFix.FixType, incident.Type)
+		}
+	}
+
+	var confidence float64
+	if o.calibrateFallback {
+		confidence = o.store.CalibratedConfidence(incident.Type)
+		if confidence > 0 {
+			telemetry.Logf(ctx, "[AI] 📐 Calibrated confidence for %s: %.2f, from past outcomes\n", incident.Type, confidence)
+		}
+	}
+
+	aiResponse := o.analyzer.GetQuickAnalysis(incident, lastFix, confidence)
+	span.SetAttributes(
+		attribute.String("fix.type", aiResponse.FixType),
+		attribute.Bool("analysis.from_history", lastFix != nil),
+	)
+	o.record(trace.Event{Kind: trace.EventAnalysis, IncidentID: incident.ID, Source: trace.SourceFallback, AIResponse: aiResponse})
+	return aiResponse
+}
+
+// record writes an event to the trace if recording is enabled
+func (o *Orchestrator) record(event trace.Event) {
+	if err := o.recorder.Record(event); err != nil {
+		log.Printf("[TRACE] Warning: failed to record %s event: %v\n", event.Kind, err)
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func (o *Orchestrator) verifyResolution(ctx context.Context, incident *models.Incident) bool {
+	telemetry.Logln(ctx, "[VERIFICATION] Checking service health...")
 
 	// Multiple checks to ensure stability
 	for i := 0; i < 3; i++ {
-		if i > 0 {
-			time.Sleep(1 * time.Second)
+		if i > 0 && !sleepContext(ctx, o.verifyInterval) {
+			return false
 		}
 
-		if o.detector.VerifyResolution() {
-			log.Printf("[VERIFICATION] ✓ Health check %d/3 passed\n", i+1)
+		healthy := o.verifier.VerifyResolution()
+		o.record(trace.Event{Kind: trace.EventVerification, IncidentID: incident.ID, Healthy: healthy})
+
+		if healthy {
+			telemetry.Logf(ctx, "[VERIFICATION] ✓ Health check %d/3 passed\n", i+1)
 		} else {
-			log.Printf("[VERIFICATION] ✗ Health check %d/3 failed\n", i+1)
+			telemetry.Logf(ctx, "[VERIFICATION] ✗ Health check %d/3 failed\n", i+1)
 			return false
 		}
 	}
 
-	log.Println("[VERIFICATION] ✅ All health checks passed!")
+	telemetry.Logln(ctx, "[VERIFICATION] ✅ All health checks passed!")
 	return true
 }
 
+// runCompaction prunes old incidents from the store every interval until ctx is cancelled
+func runCompaction(ctx context.Context, store *memory.Store, interval, maxAge time.Duration, maxCount int) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			compactStore(store, maxAge, maxCount)
+		}
+	}
+}
+
+// compactStore applies the retention policy, logging what was removed
+func compactStore(store *memory.Store, maxAge time.Duration, maxCount int) {
+	removed, err := store.Compact(maxAge, maxCount)
+	if err != nil {
+		log.Printf("[MEMORY] Warning: compaction failed: %v\n", err)
+		return
+	}
+	if removed > 0 {
+		log.Printf("[MEMORY] 🧹 Compacted store: removed %d old incident(s)\n", removed)
+	}
+}
+
+// seedFixes loads known-good fixes from a fixes file into the store as learned fixes
+func seedFixes(store *memory.Store, path string) error {
+	fixes, err := memory.LoadFixesFile(path)
+	if err != nil {
+		return err
+	}
+
+	types := make([]string, 0, len(fixes))
+	for incidentType := range fixes {
+		types = append(types, string(incidentType))
+	}
+	sort.Strings(types)
+
+	for _, t := range types {
+		incidentType := models.IncidentType(t)
+		if store.HasLearnedFix(incidentType) {
+			log.Printf("[MEMORY] Replacing learned fix for %s with the one from %s\n", incidentType, path)
+		}
+		if err := store.SetLearnedFix(incidentType, fixes[incidentType]); err != nil {
+			return fmt.Errorf("failed to store fix for %s: %w", incidentType, err)
+		}
+	}
+
+	log.Printf("[MEMORY] Seeded %d learned fix(es) from %s\n", len(fixes), path)
+	return nil
+}
+
 func printBanner() {
 	banner := `
 ╔═══════════════════════════════════════════════════════════════════╗
@@ -284,6 +1381,7 @@ func printUsageInstructions() {
    • config     - Configuration becomes corrupted
    • resource   - Resource exhaustion (port/memory)
    • dependency - External dependency failure
+   • degraded   - Service stays up but reports degraded health
 
 2. Watch the system:
    • Automatically detect the incident
@@ -296,51 +1394,77 @@ func printUsageInstructions() {
 4. Check service status:
    curl http://localhost:8080/status
 
-5. Press Ctrl+C to stop and see summary
+5. Toggle maintenance mode (suppresses incidents):
+   curl -X POST "http://localhost:9090/maintenance?enabled=true"
+
+6. List incidents and take ownership of one:
+   curl http://localhost:9090/incidents
+   curl -X POST "http://localhost:9090/incidents/<id>/ack?by=alice"
+
+7. Press Ctrl+C to stop and see summary
 
 ` + strings.Repeat("=", 70) + "\n"
 
 	fmt.Println(instructions)
 }
 
-func runDemo(targetService *service.TargetService) {
-	log.Println("\n[DEMO] Starting automated demo in 5 seconds...")
-	time.Sleep(5 * time.Second)
-
-	incidents := []struct {
-		name     string
-		typeStr  string
-		waitTime time.Duration
-	}{
-		{"Service Crash", "crash", 15 * time.Second},
-		{"Config Error", "config", 15 * time.Second},
-		{"Service Crash (cached)", "crash", 15 * time.Second},
-		{"Dependency Failure", "dependency", 15 * time.Second},
+// runDemo triggers a scripted series of incidents. It returns promptly once ctx is
+// cancelled, so it never touches the service after shutdown has begun.
+func runDemo(ctx context.Context, targetService *service.TargetService, client *http.Client, scenarios []DemoScenario) {
+	telemetry.Logln(ctx, "\n[DEMO] Starting automated demo in 5 seconds...")
+	if !sleepContext(ctx, 5*time.Second) {
+		return
 	}
 
-	for i, inc := range incidents {
-		log.Printf("\n[DEMO] (%d/%d) Triggering: %s\n", i+1, len(incidents), inc.name)
+	for i, scenario := range scenarios {
+		telemetry.Logf(ctx, "\n[DEMO] (%d/%d) Triggering: %s\n", i+1, len(scenarios), scenario.Name)
 
 		// Trigger incident via internal API
 		targetService.Stop()
-		time.Sleep(500 * time.Millisecond)
-		targetService.Start()
-		time.Sleep(1 * time.Second)
+		if !sleepContext(ctx, 500*time.Millisecond) {
+			return
+		}
+		if err := targetService.Start(); err != nil {
+			telemetry.Logf(ctx, "[DEMO] Failed to restart the service, skipping %s: %v\n", scenario.Name, err)
+			continue
+		}
+		if !sleepContext(ctx, 1*time.Second) {
+			return
+		}
 
 		// Trigger the incident
-		client := &http.Client{}
-		url := fmt.Sprintf("http://localhost:%s/trigger-incident?type=%s", servicePort, inc.typeStr)
-		resp, err := client.Get(url)
+		url := fmt.Sprintf("http://localhost:%s/trigger-incident?type=%s", servicePort, url.QueryEscape(scenario.Type))
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 		if err != nil {
-			log.Printf("[DEMO] Failed to trigger incident: %v\n", err)
+			telemetry.Logf(ctx, "[DEMO] Failed to trigger incident: %v\n", err)
+			continue
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			telemetry.Logf(ctx, "[DEMO] Failed to trigger incident: %v\n", err)
 		} else {
 			resp.Body.Close()
 		}
 
 		// Wait for resolution
-		log.Printf("[DEMO] Waiting %v for resolution...\n", inc.waitTime)
-		time.Sleep(inc.waitTime)
+		telemetry.Logf(ctx, "[DEMO] Waiting %v for resolution...\n", scenario.Wait)
+		if !sleepContext(ctx, scenario.Wait) {
+			return
+		}
 	}
 
-	log.Println("\n[DEMO] Demo complete! Press Ctrl+C to see summary.")
+	telemetry.Logln(ctx, "\n[DEMO] Demo complete! Press Ctrl+C to see summary.")
+}
+
+// sleepContext waits for d, returning false if ctx is cancelled first
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
 }
@@ -0,0 +1,500 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"incident-ai/ai"
+	"incident-ai/memory"
+	"incident-ai/models"
+	"incident-ai/notify"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeAnalyzer answers every AI analysis with response, or err if set, recording the
+// incidents it was asked about. Batched calls go to batch, and fail without it.
+type fakeAnalyzer struct {
+	response models.AIResponse
+	err      error
+	batch    func(ctx context.Context, incidents []*models.Incident) ([]*models.AIResponse, error)
+
+	mu       sync.Mutex
+	analyzed []string              // IDs of the incidents AnalyzeIncident was called for
+	previous [][]models.Resolution // the previous attempts passed with each
+}
+
+func (a *fakeAnalyzer) AnalyzeIncident(ctx context.Context, incident *models.Incident, previousAttempts []models.Resolution) (*models.AIResponse, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.analyzed = append(a.analyzed, incident.ID)
+	a.previous = append(a.previous, previousAttempts)
+	if a.err != nil {
+		return nil, a.err
+	}
+	response := a.response
+	return &response, nil
+}
+
+func (a *fakeAnalyzer) GetQuickAnalysis(incident *models.Incident, lastFix *models.Resolution, confidence float64) *models.AIResponse {
+	return &models.AIResponse{
+		Diagnosis:  "rule-based diagnosis",
+		FixType:    "restart",
+		FixSteps:   []string{"Restart the service"},
+		Confidence: 0.5,
+	}
+}
+
+func (a *fakeAnalyzer) AnalyzeIncidents(ctx context.Context, incidents []*models.Incident) ([]*models.AIResponse, error) {
+	if a.batch == nil {
+		return nil, errors.New("batched analysis not supported")
+	}
+	return a.batch(ctx, incidents)
+}
+
+// fakeExecutor applies every fix successfully, recording what it was asked to apply
+type fakeExecutor struct {
+	mu       sync.Mutex
+	executed []*models.AIResponse
+	cached   []*models.Resolution
+}
+
+func (e *fakeExecutor) ExecuteFix(ctx context.Context, incident *models.Incident, aiResponse *models.AIResponse) (*models.Resolution, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.executed = append(e.executed, aiResponse)
+	return &models.Resolution{
+		FixType:       aiResponse.FixType,
+		Description:   aiResponse.Diagnosis,
+		Steps:         aiResponse.FixSteps,
+		Code:          aiResponse.Code,
+		ConfigChanges: aiResponse.ConfigChanges,
+		Success:       true,
+	}, nil
+}
+
+func (e *fakeExecutor) ApplyCachedFix(ctx context.Context, incident *models.Incident, cachedResolution *models.Resolution) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.cached = append(e.cached, cachedResolution)
+	return nil
+}
+
+// fakeVerifier answers health checks with healthy, called with the 1-based number of the
+// check; without it the service is always healthy
+type fakeVerifier struct {
+	healthy func(check int) bool
+	checks  atomic.Int32
+}
+
+func (v *fakeVerifier) VerifyResolution() bool {
+	check := int(v.checks.Add(1))
+	return v.healthy == nil || v.healthy(check)
+}
+
+// recordingNotifier collects the messages sent through it
+type recordingNotifier struct {
+	messages chan notify.Message
+}
+
+func newRecordingNotifier() *recordingNotifier {
+	return &recordingNotifier{messages: make(chan notify.Message, 16)}
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, msg notify.Message) error {
+	n.messages <- msg
+	return nil
+}
+
+// next returns the next message sent, failing the test if none is sent within timeout
+func (n *recordingNotifier) next(t *testing.T, timeout time.Duration) notify.Message {
+	t.Helper()
+
+	select {
+	case msg := <-n.messages:
+		return msg
+	case <-time.After(timeout):
+		t.Fatalf("no notification sent within %v", timeout)
+		return notify.Message{}
+	}
+}
+
+// none fails the test if a message is sent within wait
+func (n *recordingNotifier) none(t *testing.T, wait time.Duration) {
+	t.Helper()
+
+	select {
+	case msg := <-n.messages:
+		t.Fatalf("unexpected notification: %s", msg.Title)
+	case <-time.After(wait):
+	}
+}
+
+// newTestOrchestrator creates an orchestrator that analyzes with AI, applies and verifies
+// fixes through fakes, notifies into a recordingNotifier and stores incidents in a
+// temporary directory. Tests replace the fakes and settings they need.
+func newTestOrchestrator(t *testing.T, opts ...memory.Option) *Orchestrator {
+	t.Helper()
+
+	store, err := memory.NewStore(filepath.Join(t.TempDir(), "incident_memory.json"), opts...)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+
+	return &Orchestrator{
+		analyzer: &fakeAnalyzer{response: models.AIResponse{
+			Diagnosis:  "AI diagnosis",
+			FixType:    "restart",
+			FixSteps:   []string{"Restart the service"},
+			Confidence: 0.9,
+		}},
+		executor: &fakeExecutor{},
+		verifier: &fakeVerifier{},
+		store:    store,
+		notifier: newRecordingNotifier(),
+		useAI:    true,
+		requeue:  make(chan *models.Incident, 10),
+	}
+}
+
+// newTestIncident returns a freshly detected incident
+func newTestIncident(id string, incidentType models.IncidentType, symptoms ...string) *models.Incident {
+	return &models.Incident{
+		ID:            id,
+		Type:          incidentType,
+		Status:        models.StatusDetected,
+		DetectedAt:    time.Now(),
+		Symptoms:      symptoms,
+		CorrelationID: "corr-" + id,
+	}
+}
+
+func TestAnalysisTimeoutFallsBackToRules(t *testing.T) {
+	o := newTestOrchestrator(t)
+	o.analyzer.(*fakeAnalyzer).err = fmt.Errorf("%w after 30s", ai.ErrAnalysisTimeout)
+	executor := o.executor.(*fakeExecutor)
+
+	incident := newTestIncident("a", models.ServiceDown, "health check timed out")
+	if err := o.processIncident(context.Background(), incident); err != nil {
+		t.Fatalf("processIncident: %v", err)
+	}
+
+	if incident.Status != models.StatusResolved {
+		t.Errorf("status = %s, want the rule-based fix to resolve it", incident.Status)
+	}
+	if len(executor.executed) != 1 || executor.executed[0].Diagnosis != "rule-based diagnosis" {
+		t.Errorf("executed %+v, want the rule-based fix", executor.executed)
+	}
+}
+
+func TestBudgetExhaustedFallsBackToRules(t *testing.T) {
+	o := newTestOrchestrator(t)
+	analyzer := o.analyzer.(*fakeAnalyzer)
+	executor := o.executor.(*fakeExecutor)
+	notifier := o.notifier.(*recordingNotifier)
+
+	midnight := time.Now().Truncate(24 * time.Hour).Add(24 * time.Hour)
+	budgetNotices := func() int {
+		notices := 0
+		for {
+			select {
+			case msg := <-notifier.messages:
+				if msg.Title == "Daily AI token budget exhausted" {
+					notices++
+				}
+			default:
+				return notices
+			}
+		}
+	}
+
+	// Every incident in a spent window uses the rules; the window is notified once
+	analyzer.err = &ai.BudgetExceededError{Used: 1200, Limit: 1000, ResetAt: midnight}
+	for i, incidentType := range []models.IncidentType{models.ServiceDown, models.ConfigError} {
+		incident := newTestIncident(fmt.Sprint(i), incidentType, "health check timed out")
+		if err := o.processIncident(context.Background(), incident); err != nil {
+			t.Fatalf("processIncident: %v", err)
+		}
+		if incident.Status != models.StatusResolved || executor.executed[i].Diagnosis != "rule-based diagnosis" {
+			t.Errorf("incident %s %s with %q, want it resolved by the rule-based fix", incident.ID, incident.Status, executor.executed[i].Diagnosis)
+		}
+	}
+	if notices := budgetNotices(); notices != 1 {
+		t.Errorf("%d budget notifications, want 1", notices)
+	}
+
+	// The next window's budget running out is notified again
+	analyzer.err = &ai.BudgetExceededError{Used: 1000, Limit: 1000, ResetAt: midnight.Add(24 * time.Hour)}
+	if err := o.processIncident(context.Background(), newTestIncident("2", models.ResourceExhaustion, "pool exhausted")); err != nil {
+		t.Fatalf("processIncident: %v", err)
+	}
+	if notices := budgetNotices(); notices != 1 {
+		t.Errorf("%d budget notifications in the next window, want 1", notices)
+	}
+}
+
+func TestAICorrectsIncidentType(t *testing.T) {
+	o := newTestOrchestrator(t)
+	analyzer := o.analyzer.(*fakeAnalyzer)
+	analyzer.response.CorrectedType = models.ConfigError
+
+	// Detection saw the service down, but the AI traced it to a bad config
+	incident := newTestIncident("a", models.ServiceDown, "health check timed out")
+	if err := o.processIncident(context.Background(), incident); err != nil {
+		t.Fatalf("processIncident: %v", err)
+	}
+
+	if incident.Type != models.ConfigError || incident.DetectedType != models.ServiceDown {
+		t.Errorf("type %s detected as %s, want CONFIG_ERROR detected as SERVICE_DOWN", incident.Type, incident.DetectedType)
+	}
+	if stored, err := o.store.GetIncident("a"); err != nil || stored.Type != models.ConfigError {
+		t.Errorf("stored incident = %+v, %v; want the corrected type", stored, err)
+	}
+	if !o.store.HasLearnedFix(models.ConfigError) || o.store.HasLearnedFix(models.ServiceDown) {
+		t.Error("fix not learned under the corrected type only")
+	}
+
+	// A correction that agrees with detection changes nothing
+	analyzer.response.CorrectedType = models.ServiceDown
+	incident = newTestIncident("b", models.ServiceDown, "connection refused")
+	if err := o.processIncident(context.Background(), incident); err != nil {
+		t.Fatalf("processIncident: %v", err)
+	}
+	if incident.Type != models.ServiceDown || incident.DetectedType != "" {
+		t.Errorf("type %s detected as %q, want SERVICE_DOWN uncorrected", incident.Type, incident.DetectedType)
+	}
+}
+
+func TestSystemFingerprintRecordedOnIncident(t *testing.T) {
+	o := newTestOrchestrator(t)
+	o.analyzer.(*fakeAnalyzer).response.SystemFingerprint = "fp_a1"
+
+	incident := newTestIncident("a", models.ServiceDown, "health check timed out")
+	if err := o.processIncident(context.Background(), incident); err != nil {
+		t.Fatalf("processIncident: %v", err)
+	}
+	if stored, err := o.store.GetIncident("a"); err != nil || stored.SystemFingerprint != "fp_a1" {
+		t.Errorf("stored incident = %+v, %v; want the AI backend's fingerprint", stored, err)
+	}
+}
+
+func TestFailedCachedFixPassedToAI(t *testing.T) {
+	o := newTestOrchestrator(t)
+	analyzer := o.analyzer.(*fakeAnalyzer)
+	o.verifier = &fakeVerifier{healthy: func(check int) bool { return check > 1 }} // the cached fix doesn't work
+
+	learned := &models.Resolution{FixType: "restart", Steps: []string{"Restart the service"}, Success: true}
+	if err := o.store.SetLearnedFix(models.ServiceDown, learned); err != nil {
+		t.Fatalf("SetLearnedFix: %v", err)
+	}
+
+	incident := newTestIncident("a", models.ServiceDown, "health check timed out")
+	if err := o.processIncident(context.Background(), incident); err != nil {
+		t.Fatalf("processIncident: %v", err)
+	}
+
+	if len(analyzer.previous) != 1 {
+		t.Fatalf("analyzed %d times, want once after the cached fix failed", len(analyzer.previous))
+	}
+	if attempts := analyzer.previous[0]; len(attempts) != 1 || attempts[0].Steps[0] != "Restart the service" {
+		t.Errorf("previous attempts = %+v, want the failed cached fix", attempts)
+	}
+}
+
+func TestSeededFixAppliedWithoutAI(t *testing.T) {
+	o := newTestOrchestrator(t)
+	analyzer := o.analyzer.(*fakeAnalyzer)
+	executor := o.executor.(*fakeExecutor)
+
+	path := filepath.Join(t.TempDir(), "fixes.yaml")
+	content := "SERVICE_DOWN:\n  fix_type: restart\n  steps:\n    - Restart with a clean cache\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("writing fixes file: %v", err)
+	}
+	if err := seedFixes(o.store, path); err != nil {
+		t.Fatalf("seedFixes: %v", err)
+	}
+
+	incident := newTestIncident("a", models.ServiceDown, "health check timed out")
+	if err := o.processIncident(context.Background(), incident); err != nil {
+		t.Fatalf("processIncident: %v", err)
+	}
+
+	if len(executor.cached) != 1 || executor.cached[0].Steps[0] != "Restart with a clean cache" {
+		t.Fatalf("cached fixes applied = %+v, want the seeded fix", executor.cached)
+	}
+	if len(analyzer.analyzed) != 0 || len(executor.executed) != 0 {
+		t.Errorf("AI analyzed %d times and %d fixes ran, want the seeded fix only", len(analyzer.analyzed), len(executor.executed))
+	}
+	if incident.Status != models.StatusResolved {
+		t.Errorf("incident is %s, want RESOLVED", incident.Status)
+	}
+}
+
+func TestFailureStreakEscalatesToManual(t *testing.T) {
+	o := newTestOrchestrator(t)
+	o.escalateAfter = 2
+	o.verifier = &fakeVerifier{healthy: func(check int) bool { return false }}
+	executor := o.executor.(*fakeExecutor)
+	notifier := o.notifier.(*recordingNotifier)
+
+	for _, id := range []string{"a", "b"} {
+		incident := newTestIncident(id, models.ServiceDown, "health check timed out")
+		if err := o.processIncident(context.Background(), incident); err != nil {
+			t.Fatalf("processIncident: %v", err)
+		}
+		if incident.Status != models.StatusFailed {
+			t.Fatalf("incident %s is %s, want FAILED", id, incident.Status)
+		}
+	}
+	if len(executor.executed) != 2 {
+		t.Fatalf("%d fixes ran, want one per failed incident", len(executor.executed))
+	}
+	for len(notifier.messages) > 0 {
+		<-notifier.messages
+	}
+
+	// The third incident of the type is diagnosed and handed to a human
+	incident := newTestIncident("c", models.ServiceDown, "health check timed out")
+	if err := o.processIncident(context.Background(), incident); err != nil {
+		t.Fatalf("processIncident: %v", err)
+	}
+	if len(executor.executed) != 2 || incident.Status != models.StatusDiagnosed {
+		t.Errorf("incident is %s after %d fixes, want DIAGNOSED without another fix", incident.Status, len(executor.executed))
+	}
+	msg := notifier.next(t, time.Second)
+	if !strings.Contains(msg.Body, "No fix applied: escalated after 2 failed resolutions in a row") {
+		t.Errorf("notification body:\n%s\nwant the escalation reason", msg.Body)
+	}
+
+	// Other types are still remediated, and so is this one once a human resets the streak
+	if other := newTestIncident("d", models.ConfigError, "invalid timeout"); o.processIncident(context.Background(), other) != nil || len(executor.executed) != 3 {
+		t.Errorf("%s incident not remediated", models.ConfigError)
+	}
+	if err := o.store.ResetFailureStreak(models.ServiceDown); err != nil {
+		t.Fatalf("ResetFailureStreak: %v", err)
+	}
+	if err := o.processIncident(context.Background(), newTestIncident("e", models.ServiceDown, "health check timed out")); err != nil {
+		t.Fatalf("processIncident: %v", err)
+	}
+	if len(executor.executed) != 4 {
+		t.Errorf("%d fixes ran, want remediation to resume after the reset", len(executor.executed))
+	}
+}
+
+func TestDiagnoseOnlyNeverRemediates(t *testing.T) {
+	for _, learned := range []bool{false, true} {
+		t.Run(fmt.Sprintf("learned fix %t", learned), func(t *testing.T) {
+			o := newTestOrchestrator(t)
+			o.diagnoseOnly = true
+			executor := o.executor.(*fakeExecutor)
+			verifier := o.verifier.(*fakeVerifier)
+			notifier := o.notifier.(*recordingNotifier)
+			if learned {
+				fix := &models.Resolution{FixType: "restart", Steps: []string{"Restart the service"}, Success: true}
+				if err := o.store.SetLearnedFix(models.ServiceDown, fix); err != nil {
+					t.Fatalf("SetLearnedFix: %v", err)
+				}
+			}
+
+			incident := newTestIncident("a", models.ServiceDown, "health check timed out")
+			if err := o.processIncident(context.Background(), incident); err != nil {
+				t.Fatalf("processIncident: %v", err)
+			}
+
+			if len(executor.executed) != 0 || len(executor.cached) != 0 || verifier.checks.Load() != 0 {
+				t.Errorf("executor ran %d fixes and %d cached fixes, %d health checks; want none",
+					len(executor.executed), len(executor.cached), verifier.checks.Load())
+			}
+			if incident.Status != models.StatusDiagnosed || incident.Diagnosis != "AI diagnosis" {
+				t.Errorf("incident is %s with diagnosis %q, want DIAGNOSED with the AI's", incident.Status, incident.Diagnosis)
+			}
+			if incident.Resolution != nil || incident.RecommendedFix == nil || incident.RecommendedFix.Steps[0] != "Restart the service" {
+				t.Errorf("resolution = %+v, recommended fix = %+v; want only the recommendation", incident.Resolution, incident.RecommendedFix)
+			}
+			if stored, _ := o.store.GetIncident("a"); stored.Status != models.StatusDiagnosed {
+				t.Errorf("stored status = %s, want DIAGNOSED", stored.Status)
+			}
+
+			msg := notifier.next(t, time.Second)
+			if !strings.Contains(msg.Title, "diagnosed") || !strings.Contains(msg.Body, "No fix applied: diagnose-only mode") || !strings.Contains(msg.Body, "1. Restart the service") {
+				t.Errorf("notification %q:\n%s\nwant the diagnosis and recommended fix", msg.Title, msg.Body)
+			}
+		})
+	}
+}
+
+func TestFallbackReusesLastSuccessfulFix(t *testing.T) {
+	drain := models.Resolution{FixType: "restart", Steps: []string{"Drain traffic", "Restart the service"}, Success: true}
+
+	tests := []struct {
+		name     string
+		history  bool
+		previous []models.Resolution
+		wantStep string
+	}{
+		{name: "last fix reused", history: true, wantStep: "Drain traffic"},
+		{name: "last fix already tried", history: true, previous: []models.Resolution{drain}, wantStep: "Stop the service if it's still partially running"},
+		{name: "history off", wantStep: "Stop the service if it's still partially running"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOrchestrator(t)
+			o.analyzer = ai.NewAnalyzer("test-key")
+			o.fallbackHistory = tt.history
+
+			resolved := newTestIncident("incident-1", models.ServiceDown, "Health check returned status code: 503")
+			resolved.Status = models.StatusResolved
+			fix := drain
+			resolved.Resolution = &fix
+			if err := o.store.StoreIncident(resolved); err != nil {
+				t.Fatalf("StoreIncident: %v", err)
+			}
+
+			incident := newTestIncident("incident-2", models.ServiceDown, "Connection refused")
+			response := o.quickAnalysis(context.Background(), incident, tt.previous)
+			if response.FixSteps[0] != tt.wantStep {
+				t.Errorf("fallback steps = %q, want them to start with %q", response.FixSteps, tt.wantStep)
+			}
+		})
+	}
+}
+
+func TestFallbackUsesCalibratedConfidence(t *testing.T) {
+	for _, calibrate := range []bool{false, true} {
+		t.Run(fmt.Sprintf("calibrate %t", calibrate), func(t *testing.T) {
+			o := newTestOrchestrator(t)
+			o.analyzer = ai.NewAnalyzer("test-key")
+			o.calibrateFallback = calibrate
+
+			incident := newTestIncident("new", models.ServiceDown, "Connection refused")
+			canned := o.quickAnalysis(context.Background(), incident, nil).Confidence
+
+			// 2 resolved and 2 failed incidents: a (2+1)/(4+2) chance once smoothed
+			for i, status := range []models.IncidentStatus{models.StatusResolved, models.StatusFailed, models.StatusResolved, models.StatusFailed} {
+				past := newTestIncident(fmt.Sprintf("past-%d", i), models.ServiceDown, fmt.Sprintf("symptom %d", i))
+				past.Status = status
+				if err := o.store.StoreIncident(past); err != nil {
+					t.Fatalf("StoreIncident: %v", err)
+				}
+			}
+
+			want := canned
+			if calibrate {
+				want = 0.5
+			}
+			if got := o.quickAnalysis(context.Background(), incident, nil).Confidence; got != want {
+				t.Errorf("fallback confidence = %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package memory
+
+import "incident-ai/models"
+
+// minCalibrationOutcomes is how many resolved or failed incidents of a type are needed
+// before its calibrated confidence is trusted
+const minCalibrationOutcomes = 3
+
+// CalibratedConfidence returns the empirical chance that a fix resolves an incident of this
+// type, from the type's resolved and failed incidents. It is smoothed toward 0.5 (Laplace's
+// rule of succession) so a short run of outcomes can't claim certainty either way. It
+// returns 0 until the type has minCalibrationOutcomes outcomes.
+func (s *Store) CalibratedConfidence(incidentType models.IncidentType) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	resolved, outcomes := 0, 0
+	for _, incident := range s.incidents {
+		if incident.Type != incidentType {
+			continue
+		}
+		switch incident.Status {
+		case models.StatusResolved:
+			resolved++
+			outcomes++
+		case models.StatusFailed:
+			outcomes++
+		}
+	}
+
+	if outcomes < minCalibrationOutcomes {
+		return 0
+	}
+	return float64(resolved+1) / float64(outcomes+2)
+}
@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"fmt"
+	"incident-ai/models"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCalibratedConfidence(t *testing.T) {
+	store := newTestStore(t)
+
+	// Each step adds an incident with the given status and the confidence it leads to
+	steps := []struct {
+		incidentType models.IncidentType
+		status       models.IncidentStatus
+		want         float64
+	}{
+		{models.ServiceDown, models.StatusResolved, 0},
+		{models.ServiceDown, models.StatusResolved, 0},
+		{models.ServiceDown, models.StatusDiagnosed, 0}, // not an outcome
+		{models.ConfigError, models.StatusFailed, 0},    // another type's outcome
+		{models.ServiceDown, models.StatusResolved, 4.0 / 5},
+		{models.ServiceDown, models.StatusFailed, 4.0 / 6},
+		{models.ServiceDown, models.StatusFailed, 4.0 / 7},
+		{models.ServiceDown, models.StatusResolved, 5.0 / 8},
+	}
+
+	for i, step := range steps {
+		incident := &models.Incident{
+			ID:         fmt.Sprintf("incident-%d", i+1),
+			Type:       step.incidentType,
+			Status:     step.status,
+			DetectedAt: time.Now(),
+			Symptoms:   []string{fmt.Sprintf("symptom %d", i+1)},
+		}
+		if err := store.StoreIncident(incident); err != nil {
+			t.Fatalf("StoreIncident: %v", err)
+		}
+
+		if got := store.CalibratedConfidence(models.ServiceDown); math.Abs(got-step.want) > 1e-9 {
+			t.Errorf("after incident %d (%s %s): confidence = %v, want %v", i+1, step.incidentType, step.status, got, step.want)
+		}
+	}
+
+	// Smoothing keeps a perfect record short of certainty
+	for i := 0; i < 3; i++ {
+		resolveWith(t, store, models.Degraded, fmt.Sprintf("fix %d", i))
+	}
+	if got := store.CalibratedConfidence(models.Degraded); got >= 1 {
+		t.Errorf("confidence after 3 successes = %v, want below 1", got)
+	}
+}
@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"incident-ai/models"
+	"log"
+)
+
+// rawStoredData is StoredData with every entry left undecoded, so entries can be decoded
+// one at a time
+type rawStoredData struct {
+	Incidents      map[string]json.RawMessage   `json:"incidents"`
+	Fixes          map[string][]json.RawMessage `json:"fixes"`
+	FailureStreaks map[string]json.RawMessage   `json:"failure_streaks"`
+}
+
+// decodeEntries decodes store data entry by entry. An incident, learned fix or failure
+// streak that doesn't decode, e.g. after a bad manual edit, is logged and skipped instead
+// of failing the whole load. It returns how many entries were skipped.
+func decodeEntries(raw []byte) (StoredData, int, error) {
+	var doc rawStoredData
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return StoredData{}, 0, fmt.Errorf("failed to decode store data: %w", err)
+	}
+
+	data := StoredData{
+		Incidents:      make(map[string]*models.Incident, len(doc.Incidents)),
+		Fixes:          make(map[string][]*models.Resolution, len(doc.Fixes)),
+		FailureStreaks: make(map[string]int, len(doc.FailureStreaks)),
+	}
+	skipped := 0
+
+	for id, entry := range doc.Incidents {
+		var incident models.Incident
+		if err := json.Unmarshal(entry, &incident); err != nil {
+			log.Printf("[MEMORY] ⚠️  Skipping malformed incident %s: %v\n", id, err)
+			skipped++
+			continue
+		}
+		data.Incidents[id] = &incident
+	}
+
+	for incidentType, entries := range doc.Fixes {
+		for i, entry := range entries {
+			var fix models.Resolution
+			if err := json.Unmarshal(entry, &fix); err != nil {
+				log.Printf("[MEMORY] ⚠️  Skipping malformed learned fix %d for %s: %v\n", i+1, incidentType, err)
+				skipped++
+				continue
+			}
+			data.Fixes[incidentType] = append(data.Fixes[incidentType], &fix)
+		}
+	}
+
+	for incidentType, entry := range doc.FailureStreaks {
+		var streak int
+		if err := json.Unmarshal(entry, &streak); err != nil {
+			log.Printf("[MEMORY] ⚠️  Skipping malformed failure streak for %s: %v\n", incidentType, err)
+			skipped++
+			continue
+		}
+		data.FailureStreaks[incidentType] = streak
+	}
+
+	return data, skipped, nil
+}
@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"incident-ai/models"
+	"os"
+	"testing"
+)
+
+// partlyCorruptFile is a current-schema store file with one malformed entry of each kind
+const partlyCorruptFile = `{
+  "schema_version": 3,
+  "incidents": {
+    "good": {"id": "good", "type": "SERVICE_DOWN", "status": "RESOLVED", "detected_at": "2026-09-01T10:00:00Z", "symptoms": [], "logs": []},
+    "bad-time": {"id": "bad-time", "type": "SERVICE_DOWN", "status": "RESOLVED", "detected_at": "yesterday"},
+    "bad-symptoms": {"id": "bad-symptoms", "type": "CONFIG_ERROR", "status": "DETECTED", "detected_at": "2026-09-01T11:00:00Z", "symptoms": "down"}
+  },
+  "fixes": {
+    "SERVICE_DOWN": [
+      {"fix_type": "restart", "steps": ["Restart the service"], "success": true, "successes": 3},
+      {"fix_type": "restart", "steps": "Restart it twice"}
+    ]
+  },
+  "failure_streaks": {"SERVICE_DOWN": 2, "CONFIG_ERROR": "three"}
+}`
+
+func TestLoadSkipsMalformedEntries(t *testing.T) {
+	path := writeStoreFile(t, partlyCorruptFile)
+	store := openTestStore(t, path)
+
+	if incidents := store.GetAllIncidents(); len(incidents) != 1 || incidents[0].ID != "good" {
+		t.Errorf("loaded %d incidents, want only the well-formed one", len(incidents))
+	}
+	if history := store.FixHistory(models.ServiceDown); len(history) != 1 || history[0].Successes != 3 {
+		t.Errorf("fix history = %+v, want only the well-formed fix", history)
+	}
+	if streaks := store.FailureStreaks(); len(streaks) != 1 || streaks[string(models.ServiceDown)] != 2 {
+		t.Errorf("failure streaks = %v, want only SERVICE_DOWN's", streaks)
+	}
+	if stats := store.GetStats(); stats["total_incidents"] != 1 {
+		t.Errorf("stats = %v, want the well-formed incident counted", stats)
+	}
+
+	// The next save drops the skipped entries, so the original is kept for repairing them
+	if backup, err := os.ReadFile(path + ".bak"); err != nil || string(backup) != partlyCorruptFile {
+		t.Fatalf("original file not backed up: %v", err)
+	}
+	if err := store.UpdateIncidentStatus("good", models.StatusResolved); err != nil {
+		t.Fatalf("UpdateIncidentStatus: %v", err)
+	}
+	if reloaded := openTestStore(t, path); len(reloaded.GetAllIncidents()) != 1 {
+		t.Errorf("the saved file doesn't load cleanly")
+	}
+}
+
+func TestLoadWithoutMalformedEntriesMakesNoBackup(t *testing.T) {
+	path := writeStoreFile(t, `{"schema_version": 3, "incidents": {}, "fixes": {}}`)
+	openTestStore(t, path)
+
+	if _, err := os.Stat(path + ".bak"); !os.IsNotExist(err) {
+		t.Errorf("backup written for a well-formed file: %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package memory
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"incident-ai/models"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrDuplicateIncident is returned by StoreIncident when a new incident is the same problem
+// as a recent open one, which is counted as another occurrence instead of stored
+var ErrDuplicateIncident = errors.New("same problem as an open incident")
+
+// numbers matches the parts of a symptom that vary between occurrences of one problem,
+// such as counts, durations and ports
+var numbers = regexp.MustCompile(`\d+(\.\d+)?`)
+
+// Fingerprint identifies the problem behind an incident: its type, its symptoms with
+// numbers masked, and the service config captured at detection. Incidents with the same
+// fingerprint are occurrences of one problem.
+func Fingerprint(incident *models.Incident) string {
+	symptoms := make([]string, 0, len(incident.Symptoms))
+	seen := make(map[string]bool, len(incident.Symptoms))
+	for _, symptom := range incident.Symptoms {
+		symptom = numbers.ReplaceAllString(strings.ToLower(strings.Join(strings.Fields(symptom), " ")), "#")
+		if symptom != "" && !seen[symptom] {
+			seen[symptom] = true
+			symptoms = append(symptoms, symptom)
+		}
+	}
+	sort.Strings(symptoms)
+
+	keys := make([]string, 0, len(incident.ServiceConfig))
+	for key := range incident.ServiceConfig {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	fmt.Fprintf(h, "type:%s\n", incident.Type)
+	for _, symptom := range symptoms {
+		fmt.Fprintf(h, "symptom:%s\n", symptom)
+	}
+	for _, key := range keys {
+		fmt.Fprintf(h, "config:%s=%s\n", key, incident.ServiceConfig[key])
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// WithDedupWindow makes StoreIncident count a new incident as another occurrence of an
+// open incident with the same fingerprint last seen within window, instead of storing it.
+// A window of 0 stores every incident.
+func WithDedupWindow(window time.Duration) Option {
+	return func(s *Store) {
+		s.dedupWindow = window
+	}
+}
+
+// LikelyDuplicate reports whether StoreIncident would count incident as another occurrence
+// of an open incident instead of storing it, if it were stored now after the new incidents
+// in earlier. Nothing is recorded.
+func (s *Store) LikelyDuplicate(incident *models.Incident, earlier ...*models.Incident) bool {
+	if s.dedupWindow <= 0 {
+		return false
+	}
+
+	fingerprint := Fingerprint(incident)
+	for _, other := range earlier {
+		if Fingerprint(other) == fingerprint && incident.DetectedAt.Sub(other.DetectedAt) <= s.dedupWindow {
+			return true
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.openOriginal(fingerprint, incident.DetectedAt) != nil
+}
+
+// recordOccurrence counts incident as another occurrence of the open incident with its
+// fingerprint last seen most recently within the dedup window, returning that incident,
+// or nil if there is none. Caller must hold s.mu.
+func (s *Store) recordOccurrence(incident *models.Incident) *models.Incident {
+	if s.dedupWindow <= 0 {
+		return nil
+	}
+
+	original := s.openOriginal(incident.Fingerprint, incident.DetectedAt)
+	if original == nil {
+		return nil
+	}
+
+	original.Occurrences = max(original.Occurrences, 1) + 1
+	occurredAt := incident.DetectedAt
+	original.LastOccurredAt = &occurredAt
+	incident.DuplicateOf = original.ID
+	return original
+}
+
+// openOriginal returns the open incident with fingerprint last seen most recently within
+// the dedup window before detectedAt, or nil if there is none. Caller must hold s.mu.
+func (s *Store) openOriginal(fingerprint string, detectedAt time.Time) *models.Incident {
+	var original *models.Incident
+	for _, candidate := range s.incidents {
+		if candidate.Fingerprint != fingerprint || !isOpen(candidate.Status) {
+			continue
+		}
+		if detectedAt.Sub(lastOccurrence(candidate)) > s.dedupWindow {
+			continue
+		}
+		if original == nil || lastOccurrence(candidate).After(lastOccurrence(original)) {
+			original = candidate
+		}
+	}
+	return original
+}
+
+// relinkFollowUp points the incident that caused a follow-up at the open incident the
+// follow-up was folded into, since the follow-up itself is never stored. Caller must hold s.mu.
+func (s *Store) relinkFollowUp(followUp, original *models.Incident) {
+	if followUp.CausedBy == "" {
+		return
+	}
+	if cause, ok := s.incidents[followUp.CausedBy]; ok && cause.FollowUp == followUp.ID {
+		cause.FollowUp = original.ID
+	}
+}
+
+// lastOccurrence returns when an incident's problem was last detected
+func lastOccurrence(incident *models.Incident) time.Time {
+	if incident.LastOccurredAt != nil {
+		return *incident.LastOccurredAt
+	}
+	return incident.DetectedAt
+}
+
+// isOpen reports whether an incident still needs attention: it isn't resolved, failed
+// or aborted. Failed incidents aren't open so that another occurrence is handled afresh
+// and counts toward the type's failure streak.
+func isOpen(status models.IncidentStatus) bool {
+	return status != models.StatusResolved && status != models.StatusFailed && status != models.StatusAborted
+}
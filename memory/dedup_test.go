@@ -0,0 +1,133 @@
+package memory
+
+import (
+	"errors"
+	"incident-ai/models"
+	"testing"
+	"time"
+)
+
+func TestFingerprint(t *testing.T) {
+	base := &models.Incident{
+		Type:          models.ResourceExhaustion,
+		Symptoms:      []string{"Memory usage at 91%", "Health check returned status code: 503"},
+		ServiceConfig: map[string]string{"max_connections": "100"},
+	}
+
+	tests := []struct {
+		name   string
+		change func(*models.Incident)
+		same   bool
+	}{
+		{name: "numbers masked", change: func(i *models.Incident) { i.Symptoms[0] = "Memory usage at 97%" }, same: true},
+		{name: "symptom order and case", change: func(i *models.Incident) {
+			i.Symptoms = []string{"health check returned status code: 503", "MEMORY usage  at 91%"}
+		}, same: true},
+		{name: "repeated symptom", change: func(i *models.Incident) { i.Symptoms = append(i.Symptoms, i.Symptoms[0]) }, same: true},
+		{name: "different type", change: func(i *models.Incident) { i.Type = models.ServiceDown }},
+		{name: "different symptom", change: func(i *models.Incident) { i.Symptoms[0] = "Goroutines leaking" }},
+		{name: "different config", change: func(i *models.Incident) { i.ServiceConfig = map[string]string{"max_connections": "10"} }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := *base
+			changed.Symptoms = append([]string(nil), base.Symptoms...)
+			tt.change(&changed)
+
+			if same := Fingerprint(&changed) == Fingerprint(base); same != tt.same {
+				t.Errorf("same fingerprint = %v, want %v", same, tt.same)
+			}
+		})
+	}
+}
+
+func TestStoreIncidentDedup(t *testing.T) {
+	detected := time.Now()
+	original := func() *models.Incident {
+		return &models.Incident{
+			ID:         "original",
+			Type:       models.ServiceDown,
+			Status:     models.StatusAnalyzing,
+			DetectedAt: detected,
+			Symptoms:   []string{"Health check failed after 3 retries"},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		window    time.Duration
+		closed    bool // the original is resolved by the time the new incident arrives
+		change    func(*models.Incident)
+		duplicate bool
+	}{
+		{name: "same problem", window: time.Minute, change: func(i *models.Incident) {
+			i.Symptoms = []string{"Health check failed after 5 retries"}
+		}, duplicate: true},
+		{name: "distinct symptoms", window: time.Minute, change: func(i *models.Incident) {
+			i.Symptoms = []string{"Connection refused"}
+		}},
+		{name: "distinct type", window: time.Minute, change: func(i *models.Incident) { i.Type = models.ConfigError }},
+		{name: "original closed", window: time.Minute, closed: true, change: func(*models.Incident) {}},
+		{name: "outside window", window: time.Minute, change: func(i *models.Incident) {
+			i.DetectedAt = detected.Add(2 * time.Minute)
+		}},
+		{name: "dedup disabled", change: func(*models.Incident) {}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestStore(t, WithDedupWindow(tt.window))
+			first := original()
+			if err := store.StoreIncident(first); err != nil {
+				t.Fatalf("StoreIncident(original): %v", err)
+			}
+			if tt.closed {
+				if err := store.UpdateIncidentStatus(first.ID, models.StatusResolved); err != nil {
+					t.Fatalf("UpdateIncidentStatus: %v", err)
+				}
+			}
+
+			next := original()
+			next.ID = "next"
+			next.DetectedAt = detected.Add(10 * time.Second)
+			tt.change(next)
+
+			err := store.StoreIncident(next)
+			if duplicate := errors.Is(err, ErrDuplicateIncident); duplicate != tt.duplicate {
+				t.Fatalf("StoreIncident error = %v, want duplicate %v", err, tt.duplicate)
+			}
+
+			stored, _ := store.GetIncident(first.ID)
+			_, nextErr := store.GetIncident(next.ID)
+			if tt.duplicate {
+				if stored.Occurrences != 2 || stored.LastOccurredAt == nil || !stored.LastOccurredAt.Equal(next.DetectedAt) {
+					t.Errorf("original occurrences = %d, last = %v; want 2 at %v", stored.Occurrences, stored.LastOccurredAt, next.DetectedAt)
+				}
+				if next.DuplicateOf != first.ID || nextErr == nil {
+					t.Errorf("duplicate_of = %q, stored = %v; want %q and not stored", next.DuplicateOf, nextErr == nil, first.ID)
+				}
+			} else if stored.Occurrences != 1 || nextErr != nil {
+				t.Errorf("original occurrences = %d, next stored = %v; want 1 and stored", stored.Occurrences, nextErr == nil)
+			}
+		})
+	}
+}
+
+func TestLikelyDuplicateOfEarlierQueued(t *testing.T) {
+	store := newTestStore(t, WithDedupWindow(time.Minute))
+	now := time.Now()
+	first := &models.Incident{ID: "a", Type: models.ServiceDown, DetectedAt: now, Symptoms: []string{"down for 3s"}}
+	repeat := &models.Incident{ID: "b", Type: models.ServiceDown, DetectedAt: now.Add(time.Second), Symptoms: []string{"down for 4s"}}
+	other := &models.Incident{ID: "c", Type: models.ConfigError, DetectedAt: now.Add(time.Second), Symptoms: []string{"bad config"}}
+
+	if store.LikelyDuplicate(first) {
+		t.Error("first incident reported as a duplicate of an empty store")
+	}
+	if !store.LikelyDuplicate(repeat, first) {
+		t.Error("repeat of an earlier queued incident not reported as a duplicate")
+	}
+	if store.LikelyDuplicate(other, first, repeat) {
+		t.Error("distinct incident reported as a duplicate")
+	}
+}
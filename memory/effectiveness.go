@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"incident-ai/models"
+	"sort"
+	"time"
+)
+
+// FixEffectiveness reports how incidents of one type were resolved
+type FixEffectiveness struct {
+	IncidentType         string  `json:"incident_type"`
+	Occurrences          int     `json:"occurrences"`
+	Resolved             int     `json:"resolved"`
+	ResolvedByCachedFix  int     `json:"resolved_by_cached_fix"`
+	ResolvedByRunbook    int     `json:"resolved_by_runbook"`
+	ResolvedByAI         int     `json:"resolved_by_ai"`         // by a newly analyzed fix, rule-based fallbacks included
+	AvgResolutionSeconds float64 `json:"avg_resolution_seconds"` // detection to resolution, over resolved incidents
+
+	LearnedFix *LearnedFixEffectiveness `json:"learned_fix,omitempty"` // the fix reused for the next incident, if any
+}
+
+// LearnedFixEffectiveness is the track record of the learned fix currently reused for a type
+type LearnedFixEffectiveness struct {
+	FixType     string  `json:"fix_type"`
+	Successes   int     `json:"successes"`
+	Failures    int     `json:"failures"`
+	SuccessRate float64 `json:"success_rate"`
+}
+
+// FixEffectivenessReport returns, per incident type, how often it occurred, how its
+// incidents were resolved and how long that took, and how well its current learned fix
+// performs. Types are sorted by name; a type with a learned fix but no stored incidents,
+// e.g. a seeded one, is included.
+func (s *Store) FixEffectivenessReport() []FixEffectiveness {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	byType := make(map[string]*FixEffectiveness)
+	entry := func(incidentType string) *FixEffectiveness {
+		e, ok := byType[incidentType]
+		if !ok {
+			e = &FixEffectiveness{IncidentType: incidentType}
+			byType[incidentType] = e
+		}
+		return e
+	}
+
+	resolutionTime := make(map[string]time.Duration)
+	for _, incident := range s.incidents {
+		e := entry(string(incident.Type))
+		e.Occurrences++
+
+		if incident.Status != models.StatusResolved {
+			continue
+		}
+		e.Resolved++
+		switch {
+		case incident.UsedCachedFix:
+			e.ResolvedByCachedFix++
+		case incident.UsedRunbook:
+			e.ResolvedByRunbook++
+		default:
+			e.ResolvedByAI++
+		}
+		resolutionTime[e.IncidentType] += closedAt(incident).Sub(incident.DetectedAt)
+	}
+
+	for incidentType := range s.fixes {
+		best := s.bestFix(models.IncidentType(incidentType))
+		if best == nil {
+			continue
+		}
+		entry(incidentType).LearnedFix = &LearnedFixEffectiveness{
+			FixType:     best.FixType,
+			Successes:   best.Successes,
+			Failures:    best.Failures,
+			SuccessRate: successRate(best),
+		}
+	}
+
+	report := make([]FixEffectiveness, 0, len(byType))
+	for _, e := range byType {
+		if e.Resolved > 0 {
+			e.AvgResolutionSeconds = (resolutionTime[e.IncidentType] / time.Duration(e.Resolved)).Seconds()
+		}
+		report = append(report, *e)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		return report[i].IncidentType < report[j].IncidentType
+	})
+
+	return report
+}
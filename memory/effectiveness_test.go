@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"incident-ai/models"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFixEffectivenessReport(t *testing.T) {
+	store := newTestStore(t)
+	restart := models.Resolution{FixType: "restart", Steps: []string{"Restart the service"}, Success: true}
+	config := models.Resolution{FixType: "config", Steps: []string{"Reset timeout to 30s"}, Success: true}
+
+	detected := time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC)
+	seed := []struct {
+		id           string
+		incidentType models.IncidentType
+		status       models.IncidentStatus
+		resolution   *models.Resolution
+		took         time.Duration
+		cached       bool
+		runbook      bool
+	}{
+		{id: "a", incidentType: models.ServiceDown, status: models.StatusResolved, resolution: &restart, took: time.Minute},
+		{id: "b", incidentType: models.ServiceDown, status: models.StatusResolved, resolution: &restart, took: 30 * time.Second, cached: true},
+		{id: "c", incidentType: models.ServiceDown, status: models.StatusFailed},
+		{id: "d", incidentType: models.ConfigError, status: models.StatusResolved, resolution: &config, took: 2 * time.Minute, runbook: true},
+		{id: "e", incidentType: models.ConfigError, status: models.StatusDiagnosed},
+	}
+	for _, s := range seed {
+		incident := &models.Incident{
+			ID:            s.id,
+			Type:          s.incidentType,
+			Status:        s.status,
+			DetectedAt:    detected,
+			Symptoms:      []string{"incident " + s.id}, // distinct, so none is a duplicate
+			Resolution:    s.resolution,
+			UsedCachedFix: s.cached,
+			UsedRunbook:   s.runbook,
+		}
+		if s.status == models.StatusResolved {
+			resolvedAt := detected.Add(s.took)
+			incident.ResolvedAt = &resolvedAt
+		}
+		if err := store.StoreIncident(incident); err != nil {
+			t.Fatalf("StoreIncident(%s): %v", s.id, err)
+		}
+	}
+	// The learned restart then failed once on reuse
+	if err := store.RecordFixFailure(models.ServiceDown, &restart); err != nil {
+		t.Fatalf("RecordFixFailure: %v", err)
+	}
+	// A seeded fix for a type that hasn't occurred yet
+	if err := store.SetLearnedFix(models.DependencyFailure, &models.Resolution{FixType: "restart", Steps: []string{"Restart the database"}, Success: true}); err != nil {
+		t.Fatalf("SetLearnedFix: %v", err)
+	}
+
+	report := store.FixEffectivenessReport()
+	if len(report) != 3 {
+		t.Fatalf("report has %d types, want 3: %+v", len(report), report)
+	}
+	configError, dependency, serviceDown := report[0], report[1], report[2]
+
+	if configError.IncidentType != "CONFIG_ERROR" || configError.Occurrences != 2 || configError.Resolved != 1 ||
+		configError.ResolvedByRunbook != 1 || configError.ResolvedByCachedFix != 0 || configError.ResolvedByAI != 0 ||
+		configError.AvgResolutionSeconds != 120 {
+		t.Errorf("CONFIG_ERROR = %+v, want 2 occurrences, 1 resolved by runbook in 120s", configError)
+	}
+	if fix := configError.LearnedFix; fix == nil || fix.FixType != "config" || fix.Successes != 1 || fix.Failures != 0 || fix.SuccessRate != 1 {
+		t.Errorf("CONFIG_ERROR learned fix = %+v, want config at 1/1", fix)
+	}
+
+	if dependency.IncidentType != "DEPENDENCY_FAILURE" || dependency.Occurrences != 0 || dependency.LearnedFix == nil || dependency.LearnedFix.FixType != "restart" {
+		t.Errorf("DEPENDENCY_FAILURE = %+v, want the seeded fix with no occurrences", dependency)
+	}
+
+	if serviceDown.IncidentType != "SERVICE_DOWN" || serviceDown.Occurrences != 3 || serviceDown.Resolved != 2 ||
+		serviceDown.ResolvedByCachedFix != 1 || serviceDown.ResolvedByAI != 1 || serviceDown.ResolvedByRunbook != 0 ||
+		serviceDown.AvgResolutionSeconds != 45 {
+		t.Errorf("SERVICE_DOWN = %+v, want 3 occurrences, 1 cached and 1 AI resolution averaging 45s", serviceDown)
+	}
+	if fix := serviceDown.LearnedFix; fix == nil || fix.Successes != 2 || fix.Failures != 1 || math.Abs(fix.SuccessRate-2.0/3) > 1e-9 {
+		t.Errorf("SERVICE_DOWN learned fix = %+v, want restart at 2 of 3", fix)
+	}
+}
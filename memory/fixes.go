@@ -0,0 +1,192 @@
+package memory
+
+import (
+	"incident-ai/models"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultFixHistory is how many learned fixes are kept per incident type unless configured otherwise
+const defaultFixHistory = 5
+
+// fixKey identifies a fix by what it does, so relearning the same fix updates its stats.
+// Config changes are part of it: the same steps setting different values are different fixes.
+func fixKey(fix *models.Resolution) string {
+	key := fix.FixType + "\x00" + strings.Join(fix.Steps, "\x00") + "\x00" + fix.Code
+	changes := make([]string, 0, len(fix.ConfigChanges))
+	for name, value := range fix.ConfigChanges {
+		changes = append(changes, name+"="+value)
+	}
+	sort.Strings(changes)
+	return key + "\x00" + strings.Join(changes, "\x00")
+}
+
+// successRate is the fraction of uses in which the fix resolved the incident
+func successRate(fix *models.Resolution) float64 {
+	attempts := fix.Successes + fix.Failures
+	if attempts == 0 {
+		return 0
+	}
+	return float64(fix.Successes) / float64(attempts)
+}
+
+// betterFix reports whether a should be preferred over b: higher success rate first,
+// then more successes, then the more recently learned
+func betterFix(a, b *models.Resolution) bool {
+	if ra, rb := successRate(a), successRate(b); ra != rb {
+		return ra > rb
+	}
+	if a.Successes != b.Successes {
+		return a.Successes > b.Successes
+	}
+	return learnedAt(a).After(learnedAt(b))
+}
+
+func learnedAt(fix *models.Resolution) time.Time {
+	if fix.LearnedAt == nil {
+		return time.Time{}
+	}
+	return *fix.LearnedAt
+}
+
+// LastSuccessfulFix returns a copy of the fix that most recently resolved an incident of
+// this type, whether or not it became the learned fix. Fixes matching one of exclude, e.g.
+// ones that just failed, are skipped.
+func (s *Store) LastSuccessfulFix(incidentType models.IncidentType, exclude ...models.Resolution) (*models.Resolution, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	excluded := make(map[string]bool, len(exclude))
+	for i := range exclude {
+		excluded[fixKey(&exclude[i])] = true
+	}
+
+	var last *models.Incident
+	for _, incident := range s.incidents {
+		if incident.Type != incidentType || incident.Status != models.StatusResolved {
+			continue
+		}
+		if incident.Resolution == nil || !incident.Resolution.Success || excluded[fixKey(incident.Resolution)] {
+			continue
+		}
+		if last == nil || closedAt(incident).After(closedAt(last)) {
+			last = incident
+		}
+	}
+	if last == nil {
+		return nil, false
+	}
+
+	fix := *last.Resolution
+	return &fix, true
+}
+
+// findFix returns the stored fix matching fix, if any. The caller must hold s.mu.
+func (s *Store) findFix(incidentType models.IncidentType, fix *models.Resolution) *models.Resolution {
+	key := fixKey(fix)
+	for _, stored := range s.fixes[string(incidentType)] {
+		if fixKey(stored) == key {
+			return stored
+		}
+	}
+	return nil
+}
+
+// learnFix records a successful resolution in the type's fix history, counting a success
+// for a fix already known. The caller must hold s.mu.
+func (s *Store) learnFix(incidentType models.IncidentType, resolution *models.Resolution) {
+	if stored := s.findFix(incidentType, resolution); stored != nil {
+		stored.Successes++
+		return
+	}
+
+	now := time.Now()
+	fix := *resolution
+	fix.LearnedAt = &now
+	fix.Successes = 1
+	fix.Failures = 0
+
+	key := string(incidentType)
+	s.fixes[key] = append(s.fixes[key], &fix)
+	s.trimFixes(key)
+	s.stats.learnedFix(key)
+}
+
+// trimFixes drops the worst-performing fixes of a type beyond the history limit, keeping
+// the rest oldest first. The caller must hold s.mu.
+func (s *Store) trimFixes(key string) {
+	history := s.fixes[key]
+	if len(history) <= s.fixHistory {
+		return
+	}
+
+	sort.SliceStable(history, func(i, j int) bool {
+		return betterFix(history[i], history[j])
+	})
+	history = history[:s.fixHistory]
+
+	sort.SliceStable(history, func(i, j int) bool {
+		return learnedAt(history[i]).Before(learnedAt(history[j]))
+	})
+	s.fixes[key] = history
+}
+
+// bestFix returns the best-performing fix for a type, or nil. The caller must hold s.mu.
+func (s *Store) bestFix(incidentType models.IncidentType) *models.Resolution {
+	var best *models.Resolution
+	for _, fix := range s.fixes[string(incidentType)] {
+		if best == nil || betterFix(fix, best) {
+			best = fix
+		}
+	}
+	return best
+}
+
+// RecordFixFailure counts a failed reuse of a learned fix, so a fix that stops working
+// loses out to the type's other fixes
+func (s *Store) RecordFixFailure(incidentType models.IncidentType, fix *models.Resolution) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := s.findFix(incidentType, fix)
+	if stored == nil {
+		return nil
+	}
+
+	stored.Failures++
+	return s.save()
+}
+
+// FixHistory returns copies of the learned fixes for a type, best first
+func (s *Store) FixHistory(incidentType models.IncidentType) []models.Resolution {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.fixHistoryLocked(string(incidentType))
+}
+
+// AllFixHistory returns the learned fix history of every incident type, best first
+func (s *Store) AllFixHistory() map[string][]models.Resolution {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	histories := make(map[string][]models.Resolution, len(s.fixes))
+	for key := range s.fixes {
+		histories[key] = s.fixHistoryLocked(key)
+	}
+	return histories
+}
+
+// fixHistoryLocked copies a type's fix history, best first. The caller must hold s.mu.
+func (s *Store) fixHistoryLocked(key string) []models.Resolution {
+	history := make([]models.Resolution, 0, len(s.fixes[key]))
+	for _, fix := range s.fixes[key] {
+		history = append(history, *fix)
+	}
+
+	sort.SliceStable(history, func(i, j int) bool {
+		return betterFix(&history[i], &history[j])
+	})
+	return history
+}
@@ -0,0 +1,149 @@
+package memory
+
+import (
+	"fmt"
+	"incident-ai/models"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestStore creates an empty store in a temporary directory
+func newTestStore(t *testing.T, opts ...Option) *Store {
+	t.Helper()
+	return openTestStore(t, filepath.Join(t.TempDir(), "incident_memory.json"), opts...)
+}
+
+// openTestStore opens the store at path, failing the test if it can't be loaded
+func openTestStore(t *testing.T, path string, opts ...Option) *Store {
+	t.Helper()
+
+	store, err := NewStore(path, opts...)
+	if err != nil {
+		t.Fatalf("NewStore(%s): %v", path, err)
+	}
+	return store
+}
+
+// resolveWith stores a resolved incident of incidentType that fix resolved
+func resolveWith(t *testing.T, store *Store, incidentType models.IncidentType, fix string) {
+	t.Helper()
+
+	incident := &models.Incident{
+		ID:         fmt.Sprintf("incident-%d", len(store.GetAllIncidents())+1),
+		Type:       incidentType,
+		Status:     models.StatusResolved,
+		DetectedAt: time.Now(),
+		Symptoms:   []string{fix},
+		Resolution: &models.Resolution{FixType: "restart", Steps: []string{fix}, Success: true},
+	}
+	if err := store.StoreIncident(incident); err != nil {
+		t.Fatalf("StoreIncident: %v", err)
+	}
+}
+
+func TestGetLearnedFixSelectsBest(t *testing.T) {
+	tests := []struct {
+		name     string
+		resolved []string // fixes that resolved incidents, in order
+		failed   []string // learned fixes that then failed when reused
+		want     string
+	}{
+		{name: "only fix", resolved: []string{"a"}, want: "a"},
+		{name: "more successes", resolved: []string{"a", "b", "a"}, want: "a"},
+		{name: "newest on a tie", resolved: []string{"a", "b"}, want: "b"},
+		{name: "failure demotes", resolved: []string{"a", "b"}, failed: []string{"b"}, want: "a"},
+		{name: "success rate before successes", resolved: []string{"a", "a", "b"}, failed: []string{"a"}, want: "b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestStore(t)
+			for _, fix := range tt.resolved {
+				resolveWith(t, store, models.ServiceDown, fix)
+				time.Sleep(time.Millisecond) // distinct learn times
+			}
+			for _, fix := range tt.failed {
+				failed := &models.Resolution{FixType: "restart", Steps: []string{fix}}
+				if err := store.RecordFixFailure(models.ServiceDown, failed); err != nil {
+					t.Fatalf("RecordFixFailure: %v", err)
+				}
+			}
+
+			fix, ok := store.GetLearnedFix(models.ServiceDown)
+			if !ok {
+				t.Fatal("no learned fix")
+			}
+			if fix.Steps[0] != tt.want {
+				t.Errorf("learned fix = %q, want %q", fix.Steps[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestFixHistoryRetention(t *testing.T) {
+	store := newTestStore(t, WithFixHistory(2))
+	for _, fix := range []string{"a", "a", "b", "c"} {
+		resolveWith(t, store, models.ServiceDown, fix)
+		time.Sleep(time.Millisecond)
+	}
+	resolveWith(t, store, models.ConfigError, "other type")
+
+	// b is dropped: a has more successes and c is newer
+	history := store.FixHistory(models.ServiceDown)
+	var got []string
+	for _, fix := range history {
+		got = append(got, fmt.Sprintf("%s:%d", fix.Steps[0], fix.Successes))
+	}
+	if fmt.Sprint(got) != "[a:2 c:1]" {
+		t.Errorf("history = %v, want [a:2 c:1] best first", got)
+	}
+	if len(store.FixHistory(models.ConfigError)) != 1 {
+		t.Errorf("another type's history was trimmed with this one")
+	}
+
+	// The history survives a reload
+	reloaded := openTestStore(t, store.filePath, WithFixHistory(2))
+	if fix, ok := reloaded.GetLearnedFix(models.ServiceDown); !ok || fix.Steps[0] != "a" || fix.Successes != 2 {
+		t.Errorf("reloaded learned fix = %+v, want a with 2 successes", fix)
+	}
+}
+
+func TestLastSuccessfulFix(t *testing.T) {
+	store := newTestStore(t)
+	if fix, ok := store.LastSuccessfulFix(models.ServiceDown); ok {
+		t.Fatalf("LastSuccessfulFix on an empty store = %+v, want none", fix)
+	}
+
+	for _, fix := range []string{"a", "a", "b"} {
+		resolveWith(t, store, models.ServiceDown, fix)
+		time.Sleep(time.Millisecond) // distinct detection times
+	}
+	resolveWith(t, store, models.ConfigError, "other type")
+	unresolved := &models.Incident{
+		ID:         "unresolved",
+		Type:       models.ServiceDown,
+		Status:     models.StatusFailed,
+		DetectedAt: time.Now(),
+		Symptoms:   []string{"still down"},
+		Resolution: &models.Resolution{FixType: "restart", Steps: []string{"c"}},
+	}
+	if err := store.StoreIncident(unresolved); err != nil {
+		t.Fatalf("StoreIncident: %v", err)
+	}
+
+	// The newest fix that worked, though a has more successes and is the learned fix
+	if learned, _ := store.GetLearnedFix(models.ServiceDown); learned.Steps[0] != "a" {
+		t.Fatalf("learned fix = %q, want a", learned.Steps[0])
+	}
+	fix, ok := store.LastSuccessfulFix(models.ServiceDown)
+	if !ok || fix.Steps[0] != "b" {
+		t.Fatalf("LastSuccessfulFix = %+v, %v; want b", fix, ok)
+	}
+
+	// A fix already tried is skipped for the one before it
+	fix, ok = store.LastSuccessfulFix(models.ServiceDown, models.Resolution{FixType: "restart", Steps: []string{"b"}})
+	if !ok || fix.Steps[0] != "a" {
+		t.Errorf("LastSuccessfulFix excluding b = %+v, %v; want a", fix, ok)
+	}
+}
@@ -0,0 +1,116 @@
+package memory
+
+import "fmt"
+
+// CurrentSchemaVersion is the schema version written by this build
+const CurrentSchemaVersion = 3
+
+// Migration upgrades raw store data by exactly one schema version
+type Migration func(data map[string]interface{}) error
+
+// migrations maps a schema version to the migration that upgrades it to the next version
+var migrations = map[int]Migration{
+	1: migrateV1ToV2,
+	2: migrateV2ToV3,
+}
+
+// RegisterMigration registers a migration that upgrades data from fromVersion to fromVersion+1
+func RegisterMigration(fromVersion int, migration Migration) {
+	migrations[fromVersion] = migration
+}
+
+// migrate upgrades raw store data to CurrentSchemaVersion
+func migrate(data map[string]interface{}) error {
+	version := schemaVersionOf(data)
+
+	if version > CurrentSchemaVersion {
+		return fmt.Errorf("store schema version %d is newer than supported version %d", version, CurrentSchemaVersion)
+	}
+
+	for version < CurrentSchemaVersion {
+		migration, exists := migrations[version]
+		if !exists {
+			return fmt.Errorf("no migration registered for schema version %d", version)
+		}
+
+		if err := migration(data); err != nil {
+			return fmt.Errorf("migration from schema version %d failed: %w", version, err)
+		}
+
+		version++
+		data["schema_version"] = version
+	}
+
+	return nil
+}
+
+// schemaVersionOf returns the schema version of raw store data.
+// Files written before versioning was introduced carry no version and are treated as v1.
+func schemaVersionOf(data map[string]interface{}) int {
+	if v, ok := data["schema_version"].(float64); ok && v >= 1 {
+		return int(v)
+	}
+	if v, ok := data["schema_version"].(int); ok && v >= 1 {
+		return v
+	}
+	return 1
+}
+
+// migrateV1ToV2 makes sure the top-level maps exist and that every incident
+// carries symptoms and logs as arrays rather than null. An incident that isn't an object
+// is left as it is, to be skipped as malformed when the data is decoded.
+func migrateV1ToV2(data map[string]interface{}) error {
+	if _, ok := data["incidents"].(map[string]interface{}); !ok {
+		data["incidents"] = map[string]interface{}{}
+	}
+	if _, ok := data["fixes"].(map[string]interface{}); !ok {
+		data["fixes"] = map[string]interface{}{}
+	}
+
+	for _, raw := range data["incidents"].(map[string]interface{}) {
+		incident, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, field := range []string{"symptoms", "logs"} {
+			if _, ok := incident[field].([]interface{}); !ok {
+				incident[field] = []interface{}{}
+			}
+		}
+	}
+
+	return nil
+}
+
+// migrateV2ToV3 turns the single learned fix per incident type into a one-entry fix history.
+// The existing fix counts as one success, learned when the file was last updated. A fix
+// that isn't an object becomes a one-entry history too, to be skipped as malformed when the
+// data is decoded.
+func migrateV2ToV3(data map[string]interface{}) error {
+	fixes, ok := data["fixes"].(map[string]interface{})
+	if !ok {
+		data["fixes"] = map[string]interface{}{}
+		return nil
+	}
+
+	for incidentType, raw := range fixes {
+		fix, ok := raw.(map[string]interface{})
+		if !ok {
+			fixes[incidentType] = []interface{}{raw}
+			continue
+		}
+
+		if _, ok := fix["successes"]; !ok {
+			fix["successes"] = 1
+		}
+		if _, ok := fix["learned_at"]; !ok {
+			if updated, ok := data["last_updated"].(string); ok {
+				fix["learned_at"] = updated
+			}
+		}
+
+		fixes[incidentType] = []interface{}{fix}
+	}
+
+	return nil
+}
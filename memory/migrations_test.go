@@ -0,0 +1,160 @@
+package memory
+
+import (
+	"encoding/json"
+	"incident-ai/models"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// v1File is a store file written before schema versioning: no schema_version, one learned
+// fix per incident type, and incidents that may carry null symptoms and logs
+const v1File = `{
+  "incidents": {
+    "incident-1": {
+      "id": "incident-1",
+      "type": "SERVICE_DOWN",
+      "status": "RESOLVED",
+      "detected_at": "2026-09-01T10:00:00Z",
+      "symptoms": null,
+      "resolution": {"fix_type": "restart", "steps": ["Restart the service"], "success": true}
+    }
+  },
+  "fixes": {
+    "SERVICE_DOWN": {"fix_type": "restart", "steps": ["Restart the service"], "success": true}
+  },
+  "last_updated": "2026-09-01T10:05:00Z"
+}`
+
+// writeStoreFile writes content as a store file in a temporary directory and returns its path
+func writeStoreFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "incident_memory.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("writing store file: %v", err)
+	}
+	return path
+}
+
+func TestLoadMigratesV1File(t *testing.T) {
+	path := writeStoreFile(t, v1File)
+	store := openTestStore(t, path)
+
+	incident, err := store.GetIncident("incident-1")
+	if err != nil {
+		t.Fatalf("GetIncident: %v", err)
+	}
+	if incident.Symptoms == nil || len(incident.Symptoms) != 0 {
+		t.Errorf("symptoms = %#v, want an empty list", incident.Symptoms)
+	}
+
+	history := store.FixHistory(models.ServiceDown)
+	if len(history) != 1 {
+		t.Fatalf("%d learned fixes for %s, want the v1 fix as a one-entry history", len(history), models.ServiceDown)
+	}
+	learnedAt := time.Date(2026, 9, 1, 10, 5, 0, 0, time.UTC)
+	if fix := history[0]; fix.Steps[0] != "Restart the service" || fix.Successes != 1 || fix.LearnedAt == nil || !fix.LearnedAt.Equal(learnedAt) {
+		t.Errorf("migrated fix = %+v, want one success learned at %v", fix, learnedAt)
+	}
+
+	// Loading doesn't rewrite the file; the next save writes the current schema
+	if raw, _ := os.ReadFile(path); string(raw) != v1File {
+		t.Errorf("loading rewrote the v1 file:\n%s", raw)
+	}
+	if err := store.UpdateIncidentStatus("incident-1", models.StatusResolved); err != nil {
+		t.Fatalf("UpdateIncidentStatus: %v", err)
+	}
+	var saved struct {
+		SchemaVersion int                          `json:"schema_version"`
+		Fixes         map[string][]json.RawMessage `json:"fixes"`
+	}
+	raw, _ := os.ReadFile(path)
+	if err := json.Unmarshal(raw, &saved); err != nil {
+		t.Fatalf("decoding saved store: %v", err)
+	}
+	if saved.SchemaVersion != CurrentSchemaVersion || len(saved.Fixes[string(models.ServiceDown)]) != 1 {
+		t.Errorf("saved schema v%d with fixes %v, want v%d with a fix history", saved.SchemaVersion, saved.Fixes, CurrentSchemaVersion)
+	}
+}
+
+func TestLoadSkipsMalformedEntriesWhileMigrating(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name: "v1 incident that isn't an object",
+			content: `{
+  "incidents": {
+    "good": {"id": "good", "type": "SERVICE_DOWN", "status": "DETECTED", "detected_at": "2026-09-01T10:00:00Z"},
+    "bad": "not an incident"
+  },
+  "fixes": {"SERVICE_DOWN": {"fix_type": "restart", "steps": ["Restart the service"], "success": true}}
+}`,
+		},
+		{
+			name: "v2 fix that isn't an object",
+			content: `{
+  "schema_version": 2,
+  "incidents": {
+    "good": {"id": "good", "type": "SERVICE_DOWN", "status": "DETECTED", "detected_at": "2026-09-01T10:00:00Z", "symptoms": [], "logs": []}
+  },
+  "fixes": {
+    "SERVICE_DOWN": {"fix_type": "restart", "steps": ["Restart the service"], "success": true},
+    "CONFIG_ERROR": 42
+  }
+}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeStoreFile(t, tt.content)
+			store := openTestStore(t, path)
+
+			if _, err := store.GetIncident("good"); err != nil {
+				t.Errorf("well-formed incident not loaded: %v", err)
+			}
+			if n := len(store.GetAllIncidents()); n != 1 {
+				t.Errorf("%d incidents loaded, want only the well-formed one", n)
+			}
+			if _, ok := store.GetLearnedFix(models.ServiceDown); !ok {
+				t.Errorf("well-formed learned fix not loaded")
+			}
+			if store.HasLearnedFix(models.ConfigError) {
+				t.Errorf("malformed learned fix loaded")
+			}
+			if backup, err := os.ReadFile(path + ".bak"); err != nil || string(backup) != tt.content {
+				t.Errorf("original file not backed up before dropping the malformed entry: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewStoreRefusesUnloadableFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "newer schema", content: `{"schema_version": 99, "incidents": {}, "fixes": {}}`, wantErr: "newer than supported"},
+		{name: "not JSON", content: `{"incidents": {`, wantErr: "failed to decode"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeStoreFile(t, tt.content)
+
+			if store, err := NewStore(path); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("NewStore = %v, %v; want an error containing %q", store, err, tt.wantErr)
+			}
+			if raw, _ := os.ReadFile(path); string(raw) != tt.content {
+				t.Errorf("unloadable file was changed:\n%s", raw)
+			}
+		})
+	}
+}
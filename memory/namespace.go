@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// DefaultTenant is the tenant whose data lives in the store's own file
+const DefaultTenant = ""
+
+// ErrReadOnly is returned when changing a store opened with View
+var ErrReadOnly = errors.New("store is read-only")
+
+// validTenant restricts tenant names to characters that are safe in a file name
+var validTenant = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// namespaces holds the per-tenant stores of a root store
+type namespaces struct {
+	mu     sync.Mutex
+	stores map[string]*Store // tenant -> store
+}
+
+// Namespace returns the store holding the given tenant's incidents, learned fixes and
+// failure streaks, isolated from every other tenant. Each tenant is persisted in its own
+// file next to the root store's, e.g. incident_memory.team-a.json; the default tenant ("")
+// is the root store itself. Repeated calls return the same store, and calling Namespace
+// on a tenant's store resolves against the root.
+//
+// The returned store keeps the tenant's data in memory and overwrites its file on every
+// change, so only the process handling the tenant should use it; see View for reading
+// another process's tenant.
+func (s *Store) Namespace(tenant string) (*Store, error) {
+	root := s.rootStore()
+
+	if tenant == DefaultTenant {
+		return root, nil
+	}
+	if err := checkTenant(tenant); err != nil {
+		return nil, err
+	}
+
+	root.tenants.mu.Lock()
+	defer root.tenants.mu.Unlock()
+
+	if store, ok := root.tenants.stores[tenant]; ok {
+		return store, nil
+	}
+
+	store, err := NewStore(tenantFile(root.filePath, tenant), root.opts...)
+	if err != nil {
+		return nil, err
+	}
+	store.root = root
+	store.tenant = tenant
+
+	if root.tenants.stores == nil {
+		root.tenants.stores = make(map[string]*Store)
+	}
+	root.tenants.stores[tenant] = store
+	return store, nil
+}
+
+// View loads a read-only snapshot of a tenant's store as its file is now, e.g. for serving
+// a tenant handled by another orchestrator process, which keeps rewriting the file. Every
+// call reads the file again. Changes to the snapshot fail with ErrReadOnly. A tenant
+// without a file yet, or of an in-memory store, is viewed as empty.
+func (s *Store) View(tenant string) (*Store, error) {
+	root := s.rootStore()
+
+	file := root.filePath
+	if tenant != DefaultTenant {
+		if err := checkTenant(tenant); err != nil {
+			return nil, err
+		}
+		file = tenantFile(root.filePath, tenant)
+	}
+
+	view := newStore(file, root.opts...)
+	view.root = root
+	view.tenant = tenant
+	view.readOnly = true
+
+	if file == "" {
+		return view, nil
+	}
+	if err := view.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to load tenant %q: %w", tenant, err)
+	}
+	return view, nil
+}
+
+// rootStore returns the store tenant stores are resolved against
+func (s *Store) rootStore() *Store {
+	if s.root != nil {
+		return s.root
+	}
+	return s
+}
+
+// checkTenant validates a tenant name other than the default tenant
+func checkTenant(tenant string) error {
+	if !validTenant.MatchString(tenant) {
+		return fmt.Errorf("invalid tenant %q: use letters, digits, '-' and '_'", tenant)
+	}
+	return nil
+}
+
+// Tenant returns the tenant the store belongs to (DefaultTenant for the root store)
+func (s *Store) Tenant() string {
+	return s.tenant
+}
+
+// tenantFile derives a tenant's file from the root store's, inserting the tenant before
+// the extension. In-memory stores have in-memory tenants.
+func tenantFile(rootFile, tenant string) string {
+	if rootFile == "" {
+		return ""
+	}
+	ext := filepath.Ext(rootFile)
+	return strings.TrimSuffix(rootFile, ext) + "." + tenant + ext
+}
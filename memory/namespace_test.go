@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"errors"
+	"incident-ai/models"
+	"testing"
+	"time"
+)
+
+func TestNamespaceIsolatesTenants(t *testing.T) {
+	root := newTestStore(t)
+	teamA, err := root.Namespace("team-a")
+	if err != nil {
+		t.Fatalf("Namespace(team-a): %v", err)
+	}
+	teamB, err := root.Namespace("team-b")
+	if err != nil {
+		t.Fatalf("Namespace(team-b): %v", err)
+	}
+
+	resolveWith(t, teamA, models.ServiceDown, "Restart the service")
+
+	if again, _ := teamB.Namespace("team-a"); again != teamA {
+		t.Error("Namespace on a tenant store doesn't resolve against the root")
+	}
+	if _, ok := teamB.GetLearnedFix(models.ServiceDown); ok {
+		t.Error("team-b sees team-a's learned fix")
+	}
+	if _, ok := root.GetLearnedFix(models.ServiceDown); ok {
+		t.Error("the default tenant sees team-a's learned fix")
+	}
+	if stats := teamB.GetStats(); stats["total_incidents"] != 0 {
+		t.Errorf("team-b stats = %v, want no incidents", stats)
+	}
+
+	reloaded := openTestStore(t, tenantFile(root.filePath, "team-a"))
+	if fix, ok := reloaded.GetLearnedFix(models.ServiceDown); !ok || fix.Steps[0] != "Restart the service" {
+		t.Errorf("team-a's file holds fix %+v, want its learned fix", fix)
+	}
+
+	for _, tenant := range []string{"../team", "team a", "team.json"} {
+		if _, err := root.Namespace(tenant); err == nil {
+			t.Errorf("Namespace(%q) succeeded, want an invalid tenant error", tenant)
+		}
+	}
+}
+
+func TestViewReadsTenantFileEachTime(t *testing.T) {
+	root := newTestStore(t)
+	teamB, err := root.Namespace("team-b")
+	if err != nil {
+		t.Fatalf("Namespace(team-b): %v", err)
+	}
+
+	empty, err := root.View("team-b")
+	if err != nil {
+		t.Fatalf("View before team-b has a file: %v", err)
+	}
+	if n := len(empty.GetAllIncidents()); n != 0 {
+		t.Errorf("view of a tenant without a file has %d incidents, want none", n)
+	}
+
+	// team-b's own orchestrator keeps storing incidents after the view was taken
+	resolveWith(t, teamB, models.ServiceDown, "Restart the service")
+	view, err := root.View("team-b")
+	if err != nil {
+		t.Fatalf("View: %v", err)
+	}
+	if n := len(view.GetAllIncidents()); n != 1 {
+		t.Errorf("view has %d incidents, want the 1 stored since the last view", n)
+	}
+	if view.Tenant() != "team-b" {
+		t.Errorf("view tenant = %q, want team-b", view.Tenant())
+	}
+
+	incident := &models.Incident{ID: "from-view", Type: models.ConfigError, Status: models.StatusDetected, DetectedAt: time.Now()}
+	if err := view.StoreIncident(incident); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("StoreIncident on a view = %v, want ErrReadOnly", err)
+	}
+
+	reread, err := root.View("team-b")
+	if err != nil {
+		t.Fatalf("View: %v", err)
+	}
+	if _, err := reread.GetIncident("from-view"); err == nil {
+		t.Error("a change to a view was written to team-b's file")
+	}
+}
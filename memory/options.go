@@ -0,0 +1,44 @@
+package memory
+
+import "fmt"
+
+// Option configures a Store
+type Option func(*Store)
+
+// WithFixHistory sets how many learned fixes are kept per incident type. The
+// worst-performing fixes are dropped first; values below 1 keep the default.
+func WithFixHistory(n int) Option {
+	return func(s *Store) {
+		if n > 0 {
+			s.fixHistory = n
+		}
+	}
+}
+
+// Format is the JSON layout of the store file
+type Format string
+
+const (
+	// FormatIndented writes two-space indented JSON, easy to read and diff
+	FormatIndented Format = "indented"
+	// FormatCompact writes JSON without whitespace, for large stores
+	FormatCompact Format = "compact"
+)
+
+// ParseFormat validates a store format name
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case FormatIndented, FormatCompact:
+		return Format(s), nil
+	default:
+		return "", fmt.Errorf("unknown store format %q (valid: %s, %s)", s, FormatIndented, FormatCompact)
+	}
+}
+
+// WithFormat sets how the store file is written. Both formats load the same way, so
+// switching formats only takes effect on the next save.
+func WithFormat(format Format) Option {
+	return func(s *Store) {
+		s.format = format
+	}
+}
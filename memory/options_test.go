@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"encoding/json"
+	"incident-ai/models"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStoreFormatsRoundTrip(t *testing.T) {
+	tests := []struct {
+		format Format
+		reopen Format // format the file is then reopened and rewritten with
+	}{
+		{format: FormatIndented, reopen: FormatCompact},
+		{format: FormatCompact, reopen: FormatIndented},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "incident_memory.json")
+			store := openTestStore(t, path, WithFormat(tt.format))
+			resolveWith(t, store, models.ServiceDown, "Restart the service")
+			resolveWith(t, store, models.ConfigError, "Reset timeout to 30s")
+
+			raw := mustReadStore(t, path)
+			if !json.Valid(raw) {
+				t.Fatalf("%s store file isn't valid JSON:\n%s", tt.format, raw)
+			}
+			if lines := strings.Count(string(raw), "\n"); tt.format == FormatCompact && lines != 1 {
+				t.Errorf("compact store file has %d lines, want 1:\n%s", lines, raw)
+			}
+			if tt.format == FormatIndented && !strings.Contains(string(raw), "\n  \"incidents\"") {
+				t.Errorf("indented store file isn't indented by two spaces:\n%s", raw)
+			}
+
+			// Either layout loads the same, and the next save switches to the new layout
+			reopened := openTestStore(t, path, WithFormat(tt.reopen))
+			for _, want := range store.GetAllIncidents() {
+				got, err := reopened.GetIncident(want.ID)
+				if err != nil {
+					t.Fatalf("GetIncident(%s) after reopening: %v", want.ID, err)
+				}
+				gotJSON, _ := json.Marshal(got)
+				wantJSON, _ := json.Marshal(want)
+				if string(gotJSON) != string(wantJSON) {
+					t.Errorf("incident after reopening = %s, want %s", gotJSON, wantJSON)
+				}
+			}
+			if fix, ok := reopened.GetLearnedFix(models.ConfigError); !ok || fix.Steps[0] != "Reset timeout to 30s" {
+				t.Errorf("learned fix after reopening = %+v, %t", fix, ok)
+			}
+			resolveWith(t, reopened, models.ServiceDown, "Restart the service")
+			rewritten := mustReadStore(t, path)
+			if compact := strings.Count(string(rewritten), "\n") == 1; compact != (tt.reopen == FormatCompact) {
+				t.Errorf("store file rewritten as %s:\n%s", tt.reopen, rewritten)
+			}
+			if len(openTestStore(t, path).GetAllIncidents()) != 3 {
+				t.Error("rewritten store file doesn't load all incidents")
+			}
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, s := range []string{"indented", "compact"} {
+		if format, err := ParseFormat(s); err != nil || string(format) != s {
+			t.Errorf("ParseFormat(%q) = %q, %v", s, format, err)
+		}
+	}
+	for _, s := range []string{"", "pretty", "Compact"} {
+		if _, err := ParseFormat(s); err == nil {
+			t.Errorf("ParseFormat(%q) accepted an unknown format", s)
+		}
+	}
+}
+
+// mustReadStore returns the contents of the store file, failing the test if it can't be read
+func mustReadStore(t *testing.T, path string) []byte {
+	t.Helper()
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading store file: %v", err)
+	}
+	return raw
+}
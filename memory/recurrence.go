@@ -0,0 +1,20 @@
+package memory
+
+import (
+	"incident-ai/models"
+	"time"
+)
+
+// RecurrenceCount returns how many incidents of a type were detected at or after since
+func (s *Store) RecurrenceCount(incidentType models.IncidentType, since time.Time) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	count := 0
+	for _, incident := range s.incidents {
+		if incident.Type == incidentType && !incident.DetectedAt.Before(since) {
+			count++
+		}
+	}
+	return count
+}
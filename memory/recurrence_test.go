@@ -0,0 +1,47 @@
+package memory
+
+import (
+	"fmt"
+	"incident-ai/models"
+	"testing"
+	"time"
+)
+
+func TestRecurrenceCount(t *testing.T) {
+	store := newTestStore(t)
+	now := time.Now()
+
+	detected := []struct {
+		incidentType models.IncidentType
+		ago          time.Duration
+	}{
+		{models.ServiceDown, 5 * time.Minute},
+		{models.ServiceDown, 30 * time.Minute},
+		{models.ServiceDown, 59 * time.Minute},
+		{models.ServiceDown, 2 * time.Hour},
+		{models.ConfigError, 10 * time.Minute},
+	}
+	for i, d := range detected {
+		incident := &models.Incident{ID: fmt.Sprintf("incident-%d", i), Type: d.incidentType, Status: models.StatusResolved, DetectedAt: now.Add(-d.ago)}
+		if err := store.StoreIncident(incident); err != nil {
+			t.Fatalf("StoreIncident: %v", err)
+		}
+	}
+
+	tests := []struct {
+		incidentType models.IncidentType
+		window       time.Duration
+		want         int
+	}{
+		{models.ServiceDown, time.Hour, 3},
+		{models.ServiceDown, 3 * time.Hour, 4},
+		{models.ServiceDown, time.Minute, 0},
+		{models.ConfigError, time.Hour, 1},
+		{models.Flapping, time.Hour, 0},
+	}
+	for _, tt := range tests {
+		if got := store.RecurrenceCount(tt.incidentType, now.Add(-tt.window)); got != tt.want {
+			t.Errorf("%s incidents in the last %v = %d, want %d", tt.incidentType, tt.window, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"fmt"
+	"incident-ai/models"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fixSpec is one learned fix as written in a fixes file
+type fixSpec struct {
+	FixType     string   `yaml:"fix_type"`
+	Description string   `yaml:"description"`
+	Steps       []string `yaml:"steps"`
+	Code        string   `yaml:"code"`
+
+	ConfigChanges map[string]string `yaml:"config_changes"` // config key -> value, replayed exactly
+}
+
+// LoadFixesFile reads known-good fixes keyed by incident type from a YAML file, e.g.
+//
+//	SERVICE_DOWN:
+//	  fix_type: restart
+//	  description: Service crashed
+//	  steps:
+//	    - Restart the service process
+//
+// Every entry is validated; the first malformed one fails the whole file.
+func LoadFixesFile(path string) (map[models.IncidentType]*models.Resolution, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var specs map[string]fixSpec
+	if err := yaml.Unmarshal(raw, &specs); err != nil {
+		return nil, fmt.Errorf("failed to parse fixes file: %w", err)
+	}
+
+	types := make([]string, 0, len(specs))
+	for t := range specs {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	fixes := make(map[models.IncidentType]*models.Resolution, len(specs))
+	for _, t := range types {
+		incidentType := models.IncidentType(t)
+		spec := specs[t]
+		if err := validateFixSpec(incidentType, spec); err != nil {
+			return nil, fmt.Errorf("invalid fix for %s: %w", t, err)
+		}
+
+		fixes[incidentType] = &models.Resolution{
+			FixType:       spec.FixType,
+			Description:   spec.Description,
+			Steps:         spec.Steps,
+			Code:          spec.Code,
+			ConfigChanges: spec.ConfigChanges,
+			Success:       true,
+		}
+	}
+
+	return fixes, nil
+}
+
+// validateFixSpec checks a fix is usable for its incident type
+func validateFixSpec(incidentType models.IncidentType, spec fixSpec) error {
+	if !incidentType.IsValid() {
+		return fmt.Errorf("unknown incident type")
+	}
+
+	switch spec.FixType {
+	case "restart", "config", "code":
+	case "":
+		return fmt.Errorf("missing fix_type")
+	default:
+		return fmt.Errorf("fix_type %q must be one of restart, config, code", spec.FixType)
+	}
+
+	if len(spec.Steps) == 0 {
+		return fmt.Errorf("missing steps")
+	}
+	for i, step := range spec.Steps {
+		if strings.TrimSpace(step) == "" {
+			return fmt.Errorf("step %d is empty", i+1)
+		}
+	}
+
+	if spec.FixType == "code" && strings.TrimSpace(spec.Code) == "" {
+		return fmt.Errorf("code fix has no code")
+	}
+	if spec.FixType != "config" && len(spec.ConfigChanges) > 0 {
+		return fmt.Errorf("config_changes are only allowed on config fixes")
+	}
+
+	return nil
+}
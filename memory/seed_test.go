@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"incident-ai/models"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoadFixesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixes.yaml")
+	content := `SERVICE_DOWN:
+  fix_type: restart
+  description: Service crashed
+  steps:
+    - Restart the service process
+CONFIG_ERROR:
+  fix_type: config
+  steps:
+    - Restore the connection limit
+  config_changes:
+    max_connections: "100"
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("writing fixes file: %v", err)
+	}
+
+	fixes, err := LoadFixesFile(path)
+	if err != nil {
+		t.Fatalf("LoadFixesFile: %v", err)
+	}
+	if len(fixes) != 2 {
+		t.Fatalf("%d fixes loaded, want 2", len(fixes))
+	}
+	if fix := fixes[models.ServiceDown]; fix.FixType != "restart" || fix.Description != "Service crashed" || !fix.Success ||
+		!slices.Equal(fix.Steps, []string{"Restart the service process"}) {
+		t.Errorf("SERVICE_DOWN fix = %+v", fix)
+	}
+	if fix := fixes[models.ConfigError]; fix.FixType != "config" || fix.ConfigChanges["max_connections"] != "100" {
+		t.Errorf("CONFIG_ERROR fix = %+v, want the config change", fix)
+	}
+}
+
+func TestLoadFixesFileRejectsMalformedFixes(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "not YAML", content: "SERVICE_DOWN: [", wantErr: "failed to parse fixes file"},
+		{name: "unknown type", content: "DISK_FULL: {fix_type: restart, steps: [Restart]}", wantErr: "invalid fix for DISK_FULL: unknown incident type"},
+		{name: "missing fix type", content: "SERVICE_DOWN: {steps: [Restart]}", wantErr: "missing fix_type"},
+		{name: "unknown fix type", content: "SERVICE_DOWN: {fix_type: reboot, steps: [Restart]}", wantErr: `fix_type "reboot" must be one of`},
+		{name: "no steps", content: "SERVICE_DOWN: {fix_type: restart}", wantErr: "missing steps"},
+		{name: "empty step", content: "SERVICE_DOWN: {fix_type: restart, steps: [Restart, '  ']}", wantErr: "step 2 is empty"},
+		{name: "code fix without code", content: "CONFIG_ERROR: {fix_type: code, steps: [Patch the handler]}", wantErr: "code fix has no code"},
+		{name: "config changes on a restart", content: "SERVICE_DOWN: {fix_type: restart, steps: [Restart], config_changes: {timeout: 30s}}", wantErr: "config_changes are only allowed on config fixes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "fixes.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("writing fixes file: %v", err)
+			}
+
+			fixes, err := LoadFixesFile(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadFixesFile = %v, %v; want an error containing %q", fixes, err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := LoadFixesFile(filepath.Join(t.TempDir(), "missing.yaml")); !os.IsNotExist(err) {
+		t.Errorf("LoadFixesFile of a missing file = %v, want a not-exist error", err)
+	}
+}
+
+func TestSetLearnedFix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "incident_memory.json")
+	store := openTestStore(t, path)
+	resolveWith(t, store, models.ServiceDown, "Restart the service")
+	resolveWith(t, store, models.ServiceDown, "Kill the stuck worker")
+
+	injected := &models.Resolution{FixType: "restart", Steps: []string{"Restart with a clean cache"}, Success: true}
+	if err := store.SetLearnedFix(models.ServiceDown, injected); err != nil {
+		t.Fatalf("SetLearnedFix: %v", err)
+	}
+	if injected.LearnedAt != nil {
+		t.Error("SetLearnedFix changed the caller's resolution")
+	}
+
+	// The injected fix replaces what was learned, and survives a reload
+	for _, s := range []*Store{store, openTestStore(t, path)} {
+		fix, ok := s.GetLearnedFix(models.ServiceDown)
+		if !ok || fix.Steps[0] != "Restart with a clean cache" || fix.LearnedAt == nil {
+			t.Errorf("learned fix = %+v, %v; want the injected fix with a learned time", fix, ok)
+		}
+		if history := s.FixHistory(models.ServiceDown); len(history) != 1 {
+			t.Errorf("%d fixes in the history, want only the injected one", len(history))
+		}
+	}
+	if store.HasLearnedFix(models.ConfigError) {
+		t.Error("other incident types got a learned fix")
+	}
+}
@@ -0,0 +1,149 @@
+package memory
+
+import (
+	"incident-ai/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// countedIncident is what the running statistics counted for an incident. Incidents are
+// changed in place by their owners before being stored again, so the previous counts
+// can't be read back from the incident itself.
+type countedIncident struct {
+	incidentType    models.IncidentType
+	status          models.IncidentStatus
+	rootCause       models.RootCauseCategory
+	recommendations []string
+}
+
+// statCounters are statistics over a store's incidents and learned fixes, updated as they
+// change so reading them never scans the store. They have their own lock, so reads
+// don't wait for a write that holds the store's lock while saving to disk.
+type statCounters struct {
+	mu              sync.Mutex
+	counted         map[string]countedIncident // incident ID -> how it is currently counted
+	byStatus        map[string]int
+	byType          map[string]int
+	rootCauses      map[string]int
+	recommendations map[string]int
+	fixTypes        map[string]bool // incident types with a learned fix
+}
+
+// reset recounts everything from scratch, e.g. after the store was loaded or cleared
+func (c *statCounters) reset(incidents map[string]*models.Incident, fixes map[string][]*models.Resolution) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.counted = make(map[string]countedIncident, len(incidents))
+	c.byStatus = make(map[string]int)
+	c.byType = make(map[string]int)
+	c.rootCauses = make(map[string]int)
+	c.recommendations = make(map[string]int)
+	c.fixTypes = make(map[string]bool, len(fixes))
+
+	for _, incident := range incidents {
+		c.countLocked(incident)
+	}
+	for incidentType := range fixes {
+		c.fixTypes[incidentType] = true
+	}
+}
+
+// count counts an added or changed incident, replacing whatever was counted for it before
+func (c *statCounters) count(incident *models.Incident) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.countLocked(incident)
+}
+
+// countLocked is count for callers holding c.mu
+func (c *statCounters) countLocked(incident *models.Incident) {
+	if previous, ok := c.counted[incident.ID]; ok {
+		c.apply(previous, -1)
+	}
+
+	counted := countedIncident{
+		incidentType:    incident.Type,
+		status:          incident.Status,
+		rootCause:       incident.RootCauseCategory,
+		recommendations: append([]string(nil), incident.Recommendations...),
+	}
+	c.apply(counted, 1)
+	c.counted[incident.ID] = counted
+}
+
+// uncount removes a deleted incident from the counts
+func (c *statCounters) uncount(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if previous, ok := c.counted[id]; ok {
+		c.apply(previous, -1)
+		delete(c.counted, id)
+	}
+}
+
+// learnedFix records that an incident type has a learned fix
+func (c *statCounters) learnedFix(incidentType string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.fixTypes[incidentType] = true
+}
+
+// apply adds delta to every counter the incident contributes to. The caller must hold c.mu.
+func (c *statCounters) apply(counted countedIncident, delta int) {
+	adjust(c.byStatus, string(counted.status), delta)
+	adjust(c.byType, string(counted.incidentType), delta)
+	if counted.rootCause != "" {
+		adjust(c.rootCauses, string(counted.rootCause), delta)
+	}
+	for _, recommendation := range counted.recommendations {
+		adjust(c.recommendations, recommendation, delta)
+	}
+}
+
+// adjust adds delta to counts[key], dropping keys that reach zero
+func adjust(counts map[string]int, key string, delta int) {
+	counts[key] += delta
+	if counts[key] == 0 {
+		delete(counts, key)
+	}
+}
+
+// summary returns a snapshot of the counters
+func (c *statCounters) summary() Summary {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	summary := Summary{
+		TotalIncidents:    len(c.counted),
+		Resolved:          c.byStatus[string(models.StatusResolved)],
+		Failed:            c.byStatus[string(models.StatusFailed)],
+		Diagnosed:         c.byStatus[string(models.StatusDiagnosed)],
+		ManualReview:      c.byStatus[string(models.StatusManualReview)],
+		Aborted:           c.byStatus[string(models.StatusAborted)],
+		LearnedFixes:      len(c.fixTypes),
+		IncidentsByType:   copyCounts(c.byType),
+		RootCauses:        copyCounts(c.rootCauses),
+		Recommendations:   copyCounts(c.recommendations),
+		AvailableFixTypes: make([]string, 0, len(c.fixTypes)),
+		GeneratedAt:       time.Now(),
+	}
+	for incidentType := range c.fixTypes {
+		summary.AvailableFixTypes = append(summary.AvailableFixTypes, incidentType)
+	}
+	sort.Strings(summary.AvailableFixTypes)
+
+	return summary
+}
+
+func copyCounts(counts map[string]int) map[string]int {
+	copied := make(map[string]int, len(counts))
+	for key, n := range counts {
+		copied[key] = n
+	}
+	return copied
+}
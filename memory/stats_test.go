@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"incident-ai/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStatsFollowEveryChange(t *testing.T) {
+	store := newTestStore(t)
+	old := time.Now().Add(-48 * time.Hour)
+
+	incident := &models.Incident{ID: "a", Type: models.ConfigError, Status: models.StatusDetected, DetectedAt: old}
+	steps := []struct {
+		name   string
+		change func() error
+	}{
+		{name: "store", change: func() error { return store.StoreIncident(incident) }},
+		{name: "store another", change: func() error {
+			return store.StoreIncident(&models.Incident{ID: "b", Type: models.ServiceDown, Status: models.StatusManualReview, DetectedAt: time.Now()})
+		}},
+		{name: "update status", change: func() error { return store.UpdateIncidentStatus("b", models.StatusFailed) }},
+		{name: "change in place and store again", change: func() error {
+			incident.Status = models.StatusResolved
+			incident.RootCauseCategory = models.CauseConfig
+			incident.Recommendations = []string{"Validate config on deploy"}
+			incident.Resolution = &models.Resolution{FixType: "config", Steps: []string{"Restore the config"}, Success: true}
+			return store.StoreIncident(incident)
+		}},
+		{name: "compact", change: func() error { _, err := store.Compact(24*time.Hour, 0); return err }},
+		{name: "reload", change: store.Load},
+		{name: "clear", change: store.Clear},
+	}
+
+	for _, step := range steps {
+		if err := step.change(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+
+		got, want := store.Summary(), recount(store)
+		got.GeneratedAt, want.GeneratedAt = time.Time{}, time.Time{}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("after %s, summary = %+v, want a full recount's %+v", step.name, got, want)
+		}
+	}
+}
+
+func TestSummaryDoesNotWaitForWrites(t *testing.T) {
+	store := newTestStore(t)
+	resolveWith(t, store, models.ServiceDown, "Restart the service")
+
+	// A write holds the store's lock, e.g. while saving to a slow disk
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	done := make(chan Summary)
+	go func() { done <- store.Summary() }()
+	select {
+	case summary := <-done:
+		if summary.TotalIncidents != 1 || summary.Resolved != 1 {
+			t.Errorf("summary = %+v, want the resolved incident", summary)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Summary blocked behind a write")
+	}
+}
+
+// recount computes the store's summary from scratch
+func recount(store *Store) Summary {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	var counters statCounters
+	counters.reset(store.incidents, store.fixes)
+	return counters.summary()
+}
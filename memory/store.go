@@ -2,59 +2,115 @@ package memory
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"incident-ai/models"
 	"log"
 	"os"
-	"strings"
+	"sort"
 	"sync"
 	"time"
 )
 
 // Store manages incident history and learned fixes
 type Store struct {
-	incidents map[string]*models.Incident // incident ID -> incident
-	fixes     map[string]*models.Resolution // incident type -> successful resolution
-	mu        sync.RWMutex
-	filePath  string
+	incidents  map[string]*models.Incident     // incident ID -> incident
+	fixes      map[string][]*models.Resolution // incident type -> learned fixes, oldest first
+	streaks    map[string]int                  // incident type -> consecutive failed resolutions
+	mu         sync.RWMutex
+	filePath   string
+	fixHistory int          // learned fixes kept per incident type
+	format     Format       // how the store file is written
+	stats      statCounters // running statistics, kept up to date by every change
+
+	dedupWindow time.Duration // how recently an open incident must have occurred to absorb a duplicate (0 = no dedup)
+
+	opts     []Option   // applied to tenant stores too
+	root     *Store     // the store this tenant store was created from (nil for the root)
+	tenant   string     // DefaultTenant for the root store
+	tenants  namespaces // tenant stores, only used on the root
+	readOnly bool       // a snapshot of another process's tenant (see View)
 }
 
 // StoredData represents the data structure saved to disk
 type StoredData struct {
-	Incidents   map[string]*models.Incident   `json:"incidents"`
-	Fixes       map[string]*models.Resolution `json:"fixes"`
-	LastUpdated time.Time                     `json:"last_updated"`
+	SchemaVersion  int                             `json:"schema_version"`
+	Incidents      map[string]*models.Incident     `json:"incidents"`
+	Fixes          map[string][]*models.Resolution `json:"fixes"`
+	FailureStreaks map[string]int                  `json:"failure_streaks,omitempty"`
+	LastUpdated    time.Time                       `json:"last_updated"`
 }
 
-// NewStore creates a new memory store
-func NewStore(filePath string) *Store {
-	store := &Store{
-		incidents: make(map[string]*models.Incident),
-		fixes:     make(map[string]*models.Resolution),
-		filePath:  filePath,
+// NewStore creates a new memory store, loading filePath if it exists. A file that exists
+// but can't be loaded, e.g. one written by a newer version, is an error rather than being
+// replaced: starting fresh would overwrite it on the next save.
+func NewStore(filePath string, opts ...Option) (*Store, error) {
+	store := newStore(filePath, opts...)
+	if filePath == "" {
+		return store, nil
 	}
 
-	// Try to load existing data
 	if err := store.Load(); err != nil {
-		log.Printf("[MEMORY] No existing data found, starting fresh: %v\n", err)
-	} else {
-		log.Printf("[MEMORY] Loaded %d incidents and %d learned fixes\n",
-			len(store.incidents), len(store.fixes))
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to load store file %s: %w", filePath, err)
+		}
+		log.Printf("[MEMORY] No existing data found at %s, starting fresh\n", filePath)
+		return store, nil
+	}
+
+	log.Printf("[MEMORY] Loaded %d incidents and %d learned fixes\n",
+		len(store.incidents), len(store.fixes))
+	return store, nil
+}
+
+// newStore creates an empty store for filePath without loading it
+func newStore(filePath string, opts ...Option) *Store {
+	store := &Store{
+		incidents:  make(map[string]*models.Incident),
+		fixes:      make(map[string][]*models.Resolution),
+		streaks:    make(map[string]int),
+		filePath:   filePath,
+		fixHistory: defaultFixHistory,
+		format:     FormatIndented,
+		opts:       opts,
 	}
 
+	for _, opt := range opts {
+		opt(store)
+	}
+	store.stats.reset(store.incidents, store.fixes)
 	return store
 }
 
-// StoreIncident saves an incident to memory
+// StoreIncident saves an incident to memory. A new incident that is the same problem as a
+// recent open incident isn't stored: it is counted as another occurrence of that incident,
+// and an ErrDuplicateIncident error is returned (see WithDedupWindow).
 func (s *Store) StoreIncident(incident *models.Incident) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if _, exists := s.incidents[incident.ID]; !exists {
+		if incident.Fingerprint == "" {
+			incident.Fingerprint = Fingerprint(incident)
+		}
+		if original := s.recordOccurrence(incident); original != nil {
+			s.relinkFollowUp(incident, original)
+			if err := s.save(); err != nil {
+				return err
+			}
+			return fmt.Errorf("%w: %s (%d occurrences)", ErrDuplicateIncident, original.ID, original.Occurrences)
+		}
+		if incident.Occurrences == 0 {
+			incident.Occurrences = 1
+		}
+	}
+
 	s.incidents[incident.ID] = incident
+	s.stats.count(incident)
 
 	// If incident was resolved successfully, store the fix for future use
 	if incident.Status == models.StatusResolved && incident.Resolution != nil && incident.Resolution.Success {
-		s.fixes[string(incident.Type)] = incident.Resolution
+		s.learnFix(incident.Type, incident.Resolution)
 		log.Printf("[MEMORY] Learned fix for %s incidents\n", incident.Type)
 	}
 
@@ -74,13 +130,35 @@ func (s *Store) GetIncident(id string) (*models.Incident, error) {
 	return incident, nil
 }
 
-// GetLearnedFix checks if we have a learned fix for this incident type
+// GetLearnedFix returns a copy of the best-performing learned fix for this incident type
 func (s *Store) GetLearnedFix(incidentType models.IncidentType) (*models.Resolution, bool) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	fix, exists := s.fixes[string(incidentType)]
-	return fix, exists
+	best := s.bestFix(incidentType)
+	if best == nil {
+		return nil, false
+	}
+
+	fix := *best
+	return &fix, true
+}
+
+// SetLearnedFix makes fix the only learned fix for an incident type, replacing its history,
+// without waiting for an incident to be resolved
+func (s *Store) SetLearnedFix(incidentType models.IncidentType, fix *models.Resolution) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := *fix
+	if stored.LearnedAt == nil {
+		now := time.Now()
+		stored.LearnedAt = &now
+	}
+
+	s.fixes[string(incidentType)] = []*models.Resolution{&stored}
+	s.stats.learnedFix(string(incidentType))
+	return s.save()
 }
 
 // HasLearnedFix checks if we have a fix for this incident type
@@ -105,33 +183,87 @@ func (s *Store) GetAllIncidents() []*models.Incident {
 	return incidents
 }
 
-// GetStats returns statistics about stored incidents
-func (s *Store) GetStats() map[string]interface{} {
+// GetInFlightIncidents returns incidents in a non-terminal status detected before the given time
+func (s *Store) GetInFlightIncidents(detectedBefore time.Time) []*models.Incident {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	totalIncidents := len(s.incidents)
-	resolvedCount := 0
-	failedCount := 0
-	typeCount := make(map[string]int)
+	var incidents []*models.Incident
+	for _, incident := range s.incidents {
+		if !incident.Status.IsTerminal() && incident.DetectedAt.Before(detectedBefore) {
+			incidents = append(incidents, incident)
+		}
+	}
+
+	sort.Slice(incidents, func(i, j int) bool {
+		return incidents[i].DetectedAt.Before(incidents[j].DetectedAt)
+	})
+
+	return incidents
+}
 
+// Compact removes resolved, failed and aborted incidents older than maxAge, then the oldest of the
+// remaining ones beyond maxCount. Incidents still needing attention and learned fixes are
+// always kept. A zero maxAge or maxCount disables that limit. It returns how many were removed.
+func (s *Store) Compact(maxAge time.Duration, maxCount int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var closed []*models.Incident
 	for _, incident := range s.incidents {
-		typeCount[string(incident.Type)]++
+		if incident.Status == models.StatusResolved || incident.Status == models.StatusFailed || incident.Status == models.StatusAborted {
+			closed = append(closed, incident)
+		}
+	}
 
-		if incident.Status == models.StatusResolved {
-			resolvedCount++
-		} else if incident.Status == models.StatusFailed {
-			failedCount++
+	// Newest first, so everything past maxCount is the oldest
+	sort.Slice(closed, func(i, j int) bool {
+		return closedAt(closed[i]).After(closedAt(closed[j]))
+	})
+
+	cutoff := time.Now().Add(-maxAge)
+	removed := 0
+	kept := 0
+	for _, incident := range closed {
+		if (maxAge > 0 && closedAt(incident).Before(cutoff)) || (maxCount > 0 && kept >= maxCount) {
+			delete(s.incidents, incident.ID)
+			s.stats.uncount(incident.ID)
+			removed++
+			continue
 		}
+		kept++
 	}
 
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, s.save()
+}
+
+// closedAt returns when an incident was resolved, falling back to when it was detected
+func closedAt(incident *models.Incident) time.Time {
+	if incident.ResolvedAt != nil {
+		return *incident.ResolvedAt
+	}
+	return incident.DetectedAt
+}
+
+// GetStats returns statistics about stored incidents
+func (s *Store) GetStats() map[string]interface{} {
+	summary := s.Summary()
+
 	return map[string]interface{}{
-		"total_incidents":    totalIncidents,
-		"resolved":           resolvedCount,
-		"failed":             failedCount,
-		"learned_fixes":      len(s.fixes),
-		"incidents_by_type":  typeCount,
-		"available_fix_types": s.getFixTypes(),
+		"total_incidents":     summary.TotalIncidents,
+		"resolved":            summary.Resolved,
+		"failed":              summary.Failed,
+		"diagnosed":           summary.Diagnosed,
+		"manual_review":       summary.ManualReview,
+		"aborted":             summary.Aborted,
+		"learned_fixes":       summary.LearnedFixes,
+		"incidents_by_type":   summary.IncidentsByType,
+		"root_causes":         summary.RootCauses,
+		"recommendations":     summary.Recommendations,
+		"available_fix_types": summary.AvailableFixTypes,
 	}
 }
 
@@ -143,12 +275,21 @@ func (s *Store) getFixTypes() []string {
 	return types
 }
 
-// Save persists the store to disk
+// Save persists the store to disk. A store without a file path is kept in memory only.
 func (s *Store) save() error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+	if s.filePath == "" {
+		return nil
+	}
+
 	data := StoredData{
-		Incidents:   s.incidents,
-		Fixes:       s.fixes,
-		LastUpdated: time.Now(),
+		SchemaVersion:  CurrentSchemaVersion,
+		Incidents:      s.incidents,
+		Fixes:          s.fixes,
+		FailureStreaks: s.streaks,
+		LastUpdated:    time.Now(),
 	}
 
 	file, err := os.Create(s.filePath)
@@ -157,8 +298,11 @@ func (s *Store) save() error {
 	}
 	defer file.Close()
 
+	// Encode straight to the file rather than building the whole document in memory first
 	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
+	if s.format == FormatIndented {
+		encoder.SetIndent("", "  ")
+	}
 
 	if err := encoder.Encode(data); err != nil {
 		return fmt.Errorf("failed to encode store data: %w", err)
@@ -167,24 +311,64 @@ func (s *Store) save() error {
 	return nil
 }
 
-// Load reads the store from disk
+// Load reads the store from disk, migrating older schema versions in memory
 func (s *Store) Load() error {
-	file, err := os.Open(s.filePath)
+	if s.filePath == "" {
+		return fmt.Errorf("in-memory store")
+	}
+
+	raw, err := os.ReadFile(s.filePath)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
 
-	var data StoredData
-	if err := json.NewDecoder(file).Decode(&data); err != nil {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
 		return fmt.Errorf("failed to decode store data: %w", err)
 	}
 
+	if version := schemaVersionOf(doc); version < CurrentSchemaVersion {
+		log.Printf("[MEMORY] Migrating store from schema v%d to v%d\n", version, CurrentSchemaVersion)
+	}
+
+	if err := migrate(doc); err != nil {
+		return err
+	}
+
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to re-encode migrated store data: %w", err)
+	}
+
+	data, skipped, err := decodeEntries(migrated)
+	if err != nil {
+		return err
+	}
+
+	// The next save drops the skipped entries, so keep the original for repairing them by hand.
+	// A read-only store never saves, and the file belongs to another process.
+	if skipped > 0 && !s.readOnly {
+		backup := s.filePath + ".bak"
+		if err := os.WriteFile(backup, raw, 0644); err != nil {
+			log.Printf("[MEMORY] ⚠️  Skipped %d malformed entries and failed to back up the store file: %v\n", skipped, err)
+		} else {
+			log.Printf("[MEMORY] ⚠️  Skipped %d malformed entries; the original file was saved to %s\n", skipped, backup)
+		}
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	s.incidents = data.Incidents
 	s.fixes = data.Fixes
+	if s.fixes == nil {
+		s.fixes = make(map[string][]*models.Resolution)
+	}
+	s.streaks = data.FailureStreaks
+	if s.streaks == nil {
+		s.streaks = make(map[string]int)
+	}
+	s.stats.reset(s.incidents, s.fixes)
 
 	return nil
 }
@@ -195,7 +379,9 @@ func (s *Store) Clear() error {
 	defer s.mu.Unlock()
 
 	s.incidents = make(map[string]*models.Incident)
-	s.fixes = make(map[string]*models.Resolution)
+	s.fixes = make(map[string][]*models.Resolution)
+	s.streaks = make(map[string]int)
+	s.stats.reset(s.incidents, s.fixes)
 
 	return s.save()
 }
@@ -216,28 +402,66 @@ func (s *Store) UpdateIncidentStatus(id string, status models.IncidentStatus) er
 		now := time.Now()
 		incident.ResolvedAt = &now
 	}
+	s.stats.count(incident)
 
 	return s.save()
 }
 
-// PrintSummary prints a summary of stored incidents
-func (s *Store) PrintSummary() {
-	stats := s.GetStats()
+// AcknowledgeIncident records who has taken ownership of an incident
+func (s *Store) AcknowledgeIncident(id, by string) (*models.Incident, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	incident, exists := s.incidents[id]
+	if !exists {
+		return nil, fmt.Errorf("incident not found: %s", id)
+	}
+
+	now := time.Now()
+	incident.AcknowledgedBy = by
+	incident.AcknowledgedAt = &now
+
+	return incident, s.save()
+}
+
+// AddDiagnosisRevision appends a re-analysis to an incident's diagnosis revisions
+func (s *Store) AddDiagnosisRevision(id string, revision models.DiagnosisRevision) (*models.Incident, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	log.Println("\n" + strings.Repeat("=", 70))
-	log.Println("[MEMORY] Incident Response System - Summary")
-	log.Println(strings.Repeat("=", 70))
-	log.Printf("Total Incidents Handled: %v\n", stats["total_incidents"])
-	log.Printf("Successfully Resolved:   %v\n", stats["resolved"])
-	log.Printf("Failed:                  %v\n", stats["failed"])
-	log.Printf("Learned Fixes Available: %v\n", stats["learned_fixes"])
+	incident, exists := s.incidents[id]
+	if !exists {
+		return nil, fmt.Errorf("incident not found: %s", id)
+	}
 
-	if fixTypes, ok := stats["available_fix_types"].([]string); ok && len(fixTypes) > 0 {
-		log.Println("\nLearned fixes for incident types:")
-		for _, t := range fixTypes {
-			log.Printf("  ✓ %s\n", t)
-		}
+	incident.DiagnosisRevisions = append(incident.DiagnosisRevisions, revision)
+
+	return incident, s.save()
+}
+
+// ClearAcknowledgment removes ownership from an unresolved incident if it is still held
+// by the acknowledgment made at ackedAt. It returns who held it, or "" if nothing was
+// cleared.
+func (s *Store) ClearAcknowledgment(id string, ackedAt time.Time) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	incident, exists := s.incidents[id]
+	if !exists {
+		return "", fmt.Errorf("incident not found: %s", id)
+	}
+	if incident.Status == models.StatusResolved {
+		return "", nil
 	}
 
-	log.Println(strings.Repeat("=", 70) + "\n")
+	// A newer acknowledgment supersedes the one that expired
+	if incident.AcknowledgedAt == nil || !incident.AcknowledgedAt.Equal(ackedAt) {
+		return "", nil
+	}
+
+	owner := incident.AcknowledgedBy
+	incident.AcknowledgedBy = ""
+	incident.AcknowledgedAt = nil
+
+	return owner, s.save()
 }
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"incident-ai/models"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestCompact(t *testing.T) {
+	now := time.Now()
+	ago := func(d time.Duration) time.Time { return now.Add(-d) }
+	resolvedAt := ago(time.Hour)
+
+	// Newest first: closed incidents are ordered by when they were resolved, falling back
+	// to when they were detected
+	incidents := []*models.Incident{
+		{ID: "resolved-recently", Status: models.StatusResolved, DetectedAt: ago(40 * 24 * time.Hour), ResolvedAt: &resolvedAt},
+		{ID: "failed-2d", Status: models.StatusFailed, DetectedAt: ago(2 * 24 * time.Hour)},
+		{ID: "aborted-10d", Status: models.StatusAborted, DetectedAt: ago(10 * 24 * time.Hour)},
+		{ID: "resolved-30d", Status: models.StatusResolved, DetectedAt: ago(30 * 24 * time.Hour)},
+		{ID: "manual-review-60d", Status: models.StatusManualReview, DetectedAt: ago(60 * 24 * time.Hour)},
+		{ID: "fixing-60d", Status: models.StatusFixing, DetectedAt: ago(60 * 24 * time.Hour)},
+	}
+
+	tests := []struct {
+		name        string
+		maxAge      time.Duration
+		maxCount    int
+		wantRemoved []string
+	}{
+		{name: "no limits"},
+		{name: "max age", maxAge: 7 * 24 * time.Hour, wantRemoved: []string{"aborted-10d", "resolved-30d"}},
+		{name: "max count", maxCount: 1, wantRemoved: []string{"aborted-10d", "failed-2d", "resolved-30d"}},
+		{name: "age tighter than count", maxAge: 7 * 24 * time.Hour, maxCount: 3, wantRemoved: []string{"aborted-10d", "resolved-30d"}},
+		{name: "count tighter than age", maxAge: 7 * 24 * time.Hour, maxCount: 1, wantRemoved: []string{"aborted-10d", "failed-2d", "resolved-30d"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestStore(t)
+			for _, incident := range incidents {
+				stored := *incident
+				stored.Type = models.ServiceDown
+				stored.Symptoms = []string{stored.ID}
+				if err := store.StoreIncident(&stored); err != nil {
+					t.Fatalf("StoreIncident: %v", err)
+				}
+			}
+			if err := store.SetLearnedFix(models.ConfigError, &models.Resolution{FixType: "config", Steps: []string{"Restore the config"}, Success: true}); err != nil {
+				t.Fatalf("SetLearnedFix: %v", err)
+			}
+
+			removed, err := store.Compact(tt.maxAge, tt.maxCount)
+			if err != nil {
+				t.Fatalf("Compact: %v", err)
+			}
+
+			var gone []string
+			for _, incident := range incidents {
+				if _, err := store.GetIncident(incident.ID); err != nil {
+					gone = append(gone, incident.ID)
+				}
+			}
+			sort.Strings(gone)
+			if removed != len(tt.wantRemoved) || !slices.Equal(gone, tt.wantRemoved) {
+				t.Errorf("Compact removed %d: %v, want %v", removed, gone, tt.wantRemoved)
+			}
+
+			// Learned fixes outlive the incidents they were learned from, and so does the file
+			if _, ok := store.GetLearnedFix(models.ConfigError); !ok {
+				t.Error("compaction dropped a learned fix")
+			}
+			if reloaded := openTestStore(t, store.filePath); len(reloaded.GetAllIncidents()) != len(incidents)-removed {
+				t.Errorf("store file holds %d incidents after compaction, want %d", len(reloaded.GetAllIncidents()), len(incidents)-removed)
+			}
+		})
+	}
+}
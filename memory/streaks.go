@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"incident-ai/models"
+	"log"
+)
+
+// RecordResolutionOutcome updates the failure streak for an incident type: a failed
+// resolution extends it, a successful one resets it. It returns the new streak.
+func (s *Store) RecordResolutionOutcome(incidentType models.IncidentType, success bool) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := string(incidentType)
+	if success {
+		delete(s.streaks, key)
+	} else {
+		s.streaks[key]++
+	}
+
+	return s.streaks[key], s.save()
+}
+
+// FailureStreak returns how many resolutions of an incident type have failed in a row
+func (s *Store) FailureStreak(incidentType models.IncidentType) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.streaks[string(incidentType)]
+}
+
+// FailureStreaks returns the current failure streak of every incident type that has one
+func (s *Store) FailureStreaks() map[string]int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	streaks := make(map[string]int, len(s.streaks))
+	for t, n := range s.streaks {
+		streaks[t] = n
+	}
+	return streaks
+}
+
+// ResetFailureStreak clears an incident type's failure streak, e.g. once a human has fixed the
+// underlying problem, so it is auto-remediated again
+func (s *Store) ResetFailureStreak(incidentType models.IncidentType) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.streaks[string(incidentType)]; !exists {
+		return nil
+	}
+
+	delete(s.streaks, string(incidentType))
+	log.Printf("[MEMORY] Reset failure streak for %s incidents\n", incidentType)
+	return s.save()
+}
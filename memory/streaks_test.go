@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"incident-ai/models"
+	"maps"
+	"path/filepath"
+	"testing"
+)
+
+func TestFailureStreaks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "incident_memory.json")
+	store := openTestStore(t, path)
+
+	outcomes := []struct {
+		incidentType models.IncidentType
+		success      bool
+		wantStreak   int
+	}{
+		{models.ServiceDown, false, 1},
+		{models.ServiceDown, false, 2},
+		{models.ConfigError, false, 1},
+		{models.ServiceDown, true, 0},
+		{models.ServiceDown, false, 1},
+		{models.ConfigError, false, 2},
+	}
+	for i, outcome := range outcomes {
+		streak, err := store.RecordResolutionOutcome(outcome.incidentType, outcome.success)
+		if err != nil {
+			t.Fatalf("RecordResolutionOutcome: %v", err)
+		}
+		if streak != outcome.wantStreak {
+			t.Errorf("outcome %d: %s streak = %d, want %d", i+1, outcome.incidentType, streak, outcome.wantStreak)
+		}
+	}
+
+	// Streaks survive a reload
+	want := map[string]int{string(models.ServiceDown): 1, string(models.ConfigError): 2}
+	for _, s := range []*Store{store, openTestStore(t, path)} {
+		if streaks := s.FailureStreaks(); !maps.Equal(streaks, want) {
+			t.Errorf("streaks = %v, want %v", streaks, want)
+		}
+	}
+
+	if err := store.ResetFailureStreak(models.ConfigError); err != nil {
+		t.Fatalf("ResetFailureStreak: %v", err)
+	}
+	if n := store.FailureStreak(models.ConfigError); n != 0 {
+		t.Errorf("streak after the reset = %d, want 0", n)
+	}
+	if err := store.ResetFailureStreak(models.Degraded); err != nil {
+		t.Errorf("ResetFailureStreak of a type without a streak: %v", err)
+	}
+	if n := openTestStore(t, path).FailureStreak(models.ConfigError); n != 0 {
+		t.Errorf("reset streak reloaded as %d", n)
+	}
+}
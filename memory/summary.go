@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"incident-ai/models"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Summary is a machine-readable snapshot of the store's incident statistics
+type Summary struct {
+	TotalIncidents    int            `json:"total_incidents"`
+	Resolved          int            `json:"resolved"`
+	Failed            int            `json:"failed"`
+	Diagnosed         int            `json:"diagnosed"`
+	ManualReview      int            `json:"manual_review"`
+	Aborted           int            `json:"aborted"`
+	LearnedFixes      int            `json:"learned_fixes"`
+	IncidentsByType   map[string]int `json:"incidents_by_type"`
+	RootCauses        map[string]int `json:"root_causes"`     // root cause category -> incidents; uncategorized ones are omitted
+	Recommendations   map[string]int `json:"recommendations"` // preventive recommendation -> incidents it was suggested for
+	AvailableFixTypes []string       `json:"available_fix_types"`
+	GeneratedAt       time.Time      `json:"generated_at"`
+}
+
+// Summary returns statistics about stored incidents. It reads running counters rather
+// than scanning the store, so it is cheap and doesn't wait for writes.
+func (s *Store) Summary() Summary {
+	return s.stats.summary()
+}
+
+// SummaryJSON returns the store summary encoded as indented JSON
+func (s *Store) SummaryJSON() ([]byte, error) {
+	return json.MarshalIndent(s.Summary(), "", "  ")
+}
+
+// PrintSummary prints a summary of stored incidents
+func (s *Store) PrintSummary() {
+	summary := s.Summary()
+
+	log.Println("\n" + strings.Repeat("=", 70))
+	log.Println("[MEMORY] Incident Response System - Summary")
+	log.Println(strings.Repeat("=", 70))
+	log.Printf("Total Incidents Handled: %d\n", summary.TotalIncidents)
+	log.Printf("Successfully Resolved:   %d\n", summary.Resolved)
+	log.Printf("Failed:                  %d\n", summary.Failed)
+	log.Printf("Diagnosed Only:          %d\n", summary.Diagnosed)
+	log.Printf("Awaiting Manual Review:  %d\n", summary.ManualReview)
+	log.Printf("Aborted:                 %d\n", summary.Aborted)
+	log.Printf("Learned Fixes Available: %d\n", summary.LearnedFixes)
+
+	if len(summary.RootCauses) > 0 {
+		log.Println("\nRoot causes:")
+		for _, category := range models.RootCauseCategories {
+			if n := summary.RootCauses[string(category)]; n > 0 {
+				log.Printf("  %-12s %d\n", category, n)
+			}
+		}
+	}
+
+	if len(summary.Recommendations) > 0 {
+		recommendations := make([]string, 0, len(summary.Recommendations))
+		for r := range summary.Recommendations {
+			recommendations = append(recommendations, r)
+		}
+		sort.Slice(recommendations, func(i, j int) bool {
+			ni, nj := summary.Recommendations[recommendations[i]], summary.Recommendations[recommendations[j]]
+			if ni != nj {
+				return ni > nj
+			}
+			return recommendations[i] < recommendations[j]
+		})
+
+		log.Println("\nPreventive recommendations (not applied):")
+		for _, r := range recommendations {
+			log.Printf("  💡 %s (%d)\n", r, summary.Recommendations[r])
+		}
+	}
+
+	if report := s.FixEffectivenessReport(); len(report) > 0 {
+		log.Println("\nFix effectiveness:")
+		for _, e := range report {
+			line := fmt.Sprintf("  %-20s %d incidents, %d resolved (%d cached, %d runbook, %d AI)",
+				e.IncidentType, e.Occurrences, e.Resolved, e.ResolvedByCachedFix, e.ResolvedByRunbook, e.ResolvedByAI)
+			if e.Resolved > 0 {
+				line += fmt.Sprintf(", avg %.1fs", e.AvgResolutionSeconds)
+			}
+			if fix := e.LearnedFix; fix != nil && fix.Successes+fix.Failures > 0 {
+				line += fmt.Sprintf(", learned %s fix %d/%d (%.0f%%)",
+					fix.FixType, fix.Successes, fix.Successes+fix.Failures, fix.SuccessRate*100)
+			} else if fix != nil {
+				line += fmt.Sprintf(", learned %s fix untried", fix.FixType)
+			}
+			log.Println(line)
+		}
+	}
+
+	if len(summary.AvailableFixTypes) > 0 {
+		log.Println("\nLearned fixes for incident types:")
+		for _, t := range summary.AvailableFixTypes {
+			log.Printf("  ✓ %s\n", t)
+		}
+	}
+
+	log.Println(strings.Repeat("=", 70) + "\n")
+}
@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"encoding/json"
+	"incident-ai/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSummaryJSONMatchesSeededData(t *testing.T) {
+	store := newTestStore(t)
+
+	seed := []struct {
+		incidentType    models.IncidentType
+		status          models.IncidentStatus
+		rootCause       models.RootCauseCategory
+		recommendations []string
+	}{
+		{models.ServiceDown, models.StatusResolved, models.CauseResource, []string{"Set a memory limit"}},
+		{models.ServiceDown, models.StatusFailed, models.CauseResource, []string{"Set a memory limit", "Add a readiness probe"}},
+		{models.ConfigError, models.StatusResolved, models.CauseConfig, nil},
+		{models.ConfigError, models.StatusDiagnosed, "", nil},
+		{models.DependencyFailure, models.StatusManualReview, models.CauseDependency, nil},
+		{models.DependencyFailure, models.StatusAborted, "", nil},
+	}
+	for i, s := range seed {
+		incident := &models.Incident{
+			ID:                string(rune('a' + i)),
+			Type:              s.incidentType,
+			Status:            s.status,
+			DetectedAt:        time.Now(),
+			Symptoms:          []string{string(rune('a' + i))},
+			RootCauseCategory: s.rootCause,
+			Recommendations:   s.recommendations,
+		}
+		if s.status == models.StatusResolved {
+			incident.Resolution = &models.Resolution{FixType: "restart", Steps: []string{"Restart the service"}, Success: true}
+		}
+		if err := store.StoreIncident(incident); err != nil {
+			t.Fatalf("StoreIncident: %v", err)
+		}
+	}
+
+	raw, err := store.SummaryJSON()
+	if err != nil {
+		t.Fatalf("SummaryJSON: %v", err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatalf("decoding summary: %v\n%s", err, raw)
+	}
+	if _, err := time.Parse(time.RFC3339, got["generated_at"].(string)); err != nil {
+		t.Errorf("generated_at: %v", err)
+	}
+	delete(got, "generated_at")
+
+	want := map[string]interface{}{
+		"total_incidents": 6.0,
+		"resolved":        2.0,
+		"failed":          1.0,
+		"diagnosed":       1.0,
+		"manual_review":   1.0,
+		"aborted":         1.0,
+		"learned_fixes":   2.0,
+		"incidents_by_type": map[string]interface{}{
+			string(models.ServiceDown):       2.0,
+			string(models.ConfigError):       2.0,
+			string(models.DependencyFailure): 2.0,
+		},
+		"root_causes": map[string]interface{}{
+			string(models.CauseResource):   2.0,
+			string(models.CauseConfig):     1.0,
+			string(models.CauseDependency): 1.0,
+		},
+		"recommendations": map[string]interface{}{
+			"Set a memory limit":    2.0,
+			"Add a readiness probe": 1.0,
+		},
+		"available_fix_types": []interface{}{string(models.ConfigError), string(models.ServiceDown)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("summary JSON = %v\nwant %v", got, want)
+	}
+}
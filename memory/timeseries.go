@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"fmt"
+	"time"
+)
+
+// Groupings accepted by CountByTimeBucket
+const (
+	GroupByType   = "type"
+	GroupByStatus = "status"
+)
+
+// maxTimeBuckets bounds a time series, so a narrow bucket over a long history fails
+// instead of allocating millions of mostly empty buckets
+const maxTimeBuckets = 10000
+
+// TimeBucket is the number of incidents detected in one bucket of a time series
+type TimeBucket struct {
+	Start  time.Time      `json:"start"`
+	Total  int            `json:"total"`
+	Counts map[string]int `json:"counts,omitempty"` // type or status -> incidents, when grouped
+}
+
+// CountByTimeBucket counts incidents by detection time in buckets of the given width,
+// aligned like time.Truncate, so hourly buckets start on the hour and daily ones at UTC
+// midnight. by groups the counts by GroupByType or GroupByStatus; empty means totals
+// only. The series runs from the earliest to the latest incident, empty buckets included,
+// so it can be charted as is.
+func (s *Store) CountByTimeBucket(bucket time.Duration, by string) ([]TimeBucket, error) {
+	if bucket <= 0 {
+		return nil, fmt.Errorf("bucket must be positive")
+	}
+	switch by {
+	case "", GroupByType, GroupByStatus:
+	default:
+		return nil, fmt.Errorf("unknown grouping %q (valid: %s, %s)", by, GroupByType, GroupByStatus)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.incidents) == 0 {
+		return []TimeBucket{}, nil
+	}
+
+	var first, last time.Time
+	for _, incident := range s.incidents {
+		start := incident.DetectedAt.UTC().Truncate(bucket)
+		if first.IsZero() || start.Before(first) {
+			first = start
+		}
+		if start.After(last) {
+			last = start
+		}
+	}
+
+	n := int(last.Sub(first)/bucket) + 1
+	if n > maxTimeBuckets {
+		return nil, fmt.Errorf("%v buckets would make %d points (max %d); use a wider bucket", bucket, n, maxTimeBuckets)
+	}
+
+	series := make([]TimeBucket, n)
+	for i := range series {
+		series[i].Start = first.Add(time.Duration(i) * bucket)
+		if by != "" {
+			series[i].Counts = make(map[string]int)
+		}
+	}
+
+	for _, incident := range s.incidents {
+		point := &series[incident.DetectedAt.UTC().Truncate(bucket).Sub(first)/bucket]
+		point.Total++
+
+		switch by {
+		case GroupByType:
+			point.Counts[string(incident.Type)]++
+		case GroupByStatus:
+			point.Counts[string(incident.Status)]++
+		}
+	}
+
+	return series, nil
+}
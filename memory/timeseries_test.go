@@ -0,0 +1,123 @@
+package memory
+
+import (
+	"fmt"
+	"incident-ai/models"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCountByTimeBucket(t *testing.T) {
+	store := newTestStore(t)
+	base := time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC)
+	seed := []struct {
+		at           time.Duration
+		incidentType models.IncidentType
+		status       models.IncidentStatus
+	}{
+		{10 * time.Minute, models.ServiceDown, models.StatusResolved},
+		{50 * time.Minute, models.ConfigError, models.StatusResolved},
+		{55 * time.Minute, models.ServiceDown, models.StatusFailed},
+		// Nothing from 11:00 to 12:00
+		{2*time.Hour + 5*time.Minute, models.ServiceDown, models.StatusResolved},
+	}
+	for i, s := range seed {
+		incident := &models.Incident{
+			ID:         fmt.Sprintf("incident-%d", i+1),
+			Type:       s.incidentType,
+			Status:     s.status,
+			DetectedAt: base.Add(s.at).In(time.FixedZone("CEST", 2*60*60)), // buckets are in UTC whatever the zone
+		}
+		if err := store.StoreIncident(incident); err != nil {
+			t.Fatalf("StoreIncident: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		bucket time.Duration
+		by     string
+		want   []TimeBucket
+	}{
+		{
+			name:   "hourly totals",
+			bucket: time.Hour,
+			want: []TimeBucket{
+				{Start: base, Total: 3},
+				{Start: base.Add(time.Hour), Total: 0},
+				{Start: base.Add(2 * time.Hour), Total: 1},
+			},
+		},
+		{
+			name:   "hourly by type",
+			bucket: time.Hour,
+			by:     GroupByType,
+			want: []TimeBucket{
+				{Start: base, Total: 3, Counts: map[string]int{"SERVICE_DOWN": 2, "CONFIG_ERROR": 1}},
+				{Start: base.Add(time.Hour), Total: 0, Counts: map[string]int{}},
+				{Start: base.Add(2 * time.Hour), Total: 1, Counts: map[string]int{"SERVICE_DOWN": 1}},
+			},
+		},
+		{
+			name:   "half-hourly by status",
+			bucket: 30 * time.Minute,
+			by:     GroupByStatus,
+			want: []TimeBucket{
+				{Start: base, Total: 1, Counts: map[string]int{"RESOLVED": 1}},
+				{Start: base.Add(30 * time.Minute), Total: 2, Counts: map[string]int{"RESOLVED": 1, "FAILED": 1}},
+				{Start: base.Add(time.Hour), Total: 0, Counts: map[string]int{}},
+				{Start: base.Add(90 * time.Minute), Total: 0, Counts: map[string]int{}},
+				{Start: base.Add(2 * time.Hour), Total: 1, Counts: map[string]int{"RESOLVED": 1}},
+			},
+		},
+		{
+			name:   "daily from UTC midnight",
+			bucket: 24 * time.Hour,
+			want:   []TimeBucket{{Start: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), Total: 4}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			series, err := store.CountByTimeBucket(tt.bucket, tt.by)
+			if err != nil {
+				t.Fatalf("CountByTimeBucket: %v", err)
+			}
+			if !reflect.DeepEqual(series, tt.want) {
+				t.Errorf("series:\n got %+v\nwant %+v", series, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountByTimeBucketRejectsBadRequests(t *testing.T) {
+	store := newTestStore(t)
+	if series, err := store.CountByTimeBucket(time.Hour, ""); err != nil || series == nil || len(series) != 0 {
+		t.Errorf("empty store series = %v, %v; want an empty series", series, err)
+	}
+
+	for i, at := range []time.Time{time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)} {
+		incident := &models.Incident{ID: fmt.Sprintf("incident-%d", i), Type: models.ServiceDown, Status: models.StatusResolved, DetectedAt: at}
+		if err := store.StoreIncident(incident); err != nil {
+			t.Fatalf("StoreIncident: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		bucket  time.Duration
+		by      string
+		wantErr string
+	}{
+		{name: "zero bucket", bucket: 0, wantErr: "must be positive"},
+		{name: "unknown grouping", bucket: time.Hour, by: "severity", wantErr: "unknown grouping"},
+		{name: "too many buckets", bucket: time.Minute, wantErr: "use a wider bucket"},
+	}
+	for _, tt := range tests {
+		if _, err := store.CountByTimeBucket(tt.bucket, tt.by); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package memory
+
+import (
+	"incident-ai/models"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// minTypeSamples is how many resolved incidents must share keywords with the symptoms
+// before SuggestType suggests their type
+const minTypeSamples = 2
+
+// symptomStopWords appear in the symptoms of every kind of incident and say nothing about the type
+var symptomStopWords = map[string]bool{
+	"and": true, "the": true, "for": true, "with": true, "from": true, "was": true, "not": true,
+	"service": true, "health": true, "check": true, "returned": true, "status": true,
+	"code": true, "failing": true, "failed": true, "detected": true,
+}
+
+// SuggestType suggests an incident type for symptoms from the types resolved incidents
+// with the same symptom keywords turned out to be. Each keyword votes for the types it
+// was seen with, weighted by how often; the returned confidence is the winning type's
+// share of the votes. It returns "" and 0 when too few resolved incidents match.
+// Incidents whose type was itself a suggestion only count once the AI corrected it, so a
+// guess that happened to resolve doesn't reinforce itself.
+func (s *Store) SuggestType(symptoms []string) (models.IncidentType, float64) {
+	query := symptomKeywords(symptoms)
+	if len(query) == 0 {
+		return "", 0
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// keyword -> incident type -> resolved incidents with that keyword
+	counts := make(map[string]map[models.IncidentType]int)
+	matched := make(map[models.IncidentType]int)
+	for _, incident := range s.incidents {
+		if incident.Status != models.StatusResolved || (learnedType(incident) && incident.DetectedType == "") {
+			continue
+		}
+
+		matches := false
+		for keyword := range symptomKeywords(incident.Symptoms) {
+			if !query[keyword] {
+				continue
+			}
+			if counts[keyword] == nil {
+				counts[keyword] = make(map[models.IncidentType]int)
+			}
+			counts[keyword][incident.Type]++
+			matches = true
+		}
+		if matches {
+			matched[incident.Type]++
+		}
+	}
+
+	votes := make(map[models.IncidentType]float64)
+	total := 0.0
+	for _, byType := range counts {
+		seen := 0
+		for _, n := range byType {
+			seen += n
+		}
+		for incidentType, n := range byType {
+			votes[incidentType] += float64(n) / float64(seen)
+		}
+		total++
+	}
+	if total == 0 {
+		return "", 0
+	}
+
+	types := make([]string, 0, len(votes))
+	for incidentType := range votes {
+		types = append(types, string(incidentType))
+	}
+	sort.Strings(types) // ties go to the first type alphabetically
+
+	var best models.IncidentType
+	for _, t := range types {
+		if votes[models.IncidentType(t)] > votes[best] {
+			best = models.IncidentType(t)
+		}
+	}
+
+	if matched[best] < minTypeSamples {
+		return "", 0
+	}
+	return best, votes[best] / total
+}
+
+// learnedType reports whether the incident's type was suggested from resolved incidents
+func learnedType(incident *models.Incident) bool {
+	for _, symptom := range incident.Symptoms {
+		if strings.Contains(symptom, models.LearnedTypeSymptom) {
+			return true
+		}
+	}
+	return false
+}
+
+// symptomKeywords extracts the distinct lower-case words of three or more letters from
+// symptoms, leaving out stop words
+func symptomKeywords(symptoms []string) map[string]bool {
+	keywords := make(map[string]bool)
+	for _, symptom := range symptoms {
+		words := strings.FieldsFunc(strings.ToLower(symptom), func(r rune) bool { return !unicode.IsLetter(r) })
+		for _, word := range words {
+			if len(word) >= 3 && !symptomStopWords[word] {
+				keywords[word] = true
+			}
+		}
+	}
+	return keywords
+}
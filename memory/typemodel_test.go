@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"fmt"
+	"incident-ai/models"
+	"testing"
+	"time"
+)
+
+// storeTyped stores an incident of incidentType with symptoms in status
+func storeTyped(t *testing.T, store *Store, incidentType models.IncidentType, status models.IncidentStatus, symptoms ...string) *models.Incident {
+	t.Helper()
+
+	incident := &models.Incident{
+		ID:         fmt.Sprintf("incident-%d", len(store.GetAllIncidents())+1),
+		Type:       incidentType,
+		Status:     status,
+		DetectedAt: time.Now(),
+		Symptoms:   symptoms,
+	}
+	if err := store.StoreIncident(incident); err != nil {
+		t.Fatalf("StoreIncident: %v", err)
+	}
+	return incident
+}
+
+func TestSuggestType(t *testing.T) {
+	store := newTestStore(t)
+	storeTyped(t, store, models.DependencyFailure, models.StatusResolved, "Health check returned status code: 503", "Postgres connection refused")
+	storeTyped(t, store, models.DependencyFailure, models.StatusResolved, "Health check returned status code: 500", "postgres timeout on connection")
+	storeTyped(t, store, models.ConfigError, models.StatusResolved, "Health check returned status code: 503", "Invalid YAML in connection settings")
+	// Unresolved incidents say nothing about what the symptoms turned out to be
+	storeTyped(t, store, models.ResourceExhaustion, models.StatusFailed, "postgres connection refused")
+	storeTyped(t, store, models.ResourceExhaustion, models.StatusFailed, "postgres connection refused")
+
+	incidentType, confidence := store.SuggestType([]string{"Health check returned status code: 503", "postgres connection refused"})
+	if incidentType != models.DependencyFailure || confidence <= 0.5 || confidence > 1 {
+		t.Errorf("SuggestType = %s (%.2f), want DEPENDENCY_FAILURE with most of the votes", incidentType, confidence)
+	}
+
+	tests := []struct {
+		name     string
+		symptoms []string
+	}{
+		{name: "only stop words", symptoms: []string{"Service health check failed", "Health check returned status code: 503"}},
+		{name: "no resolved incident shares a keyword", symptoms: []string{"disk quota exceeded"}},
+		{name: "one resolved incident is too few", symptoms: []string{"invalid yaml"}},
+		{name: "no symptoms"},
+	}
+	for _, tt := range tests {
+		if incidentType, confidence := store.SuggestType(tt.symptoms); incidentType != "" || confidence != 0 {
+			t.Errorf("%s: SuggestType = %s (%.2f), want no suggestion", tt.name, incidentType, confidence)
+		}
+	}
+}
+
+func TestSuggestTypeIgnoresItsOwnGuesses(t *testing.T) {
+	store := newTestStore(t)
+	learned := "Classified as FLAPPING " + models.LearnedTypeSymptom + " (80% confidence)"
+	storeTyped(t, store, models.Flapping, models.StatusResolved, "intermittent gateway resets", learned)
+	storeTyped(t, store, models.Flapping, models.StatusResolved, "intermittent gateway resets", learned)
+	if incidentType, _ := store.SuggestType([]string{"intermittent gateway resets"}); incidentType != "" {
+		t.Fatalf("SuggestType = %s from resolved guesses alone, want no suggestion", incidentType)
+	}
+
+	// A learned type the AI corrected is what the incident really was
+	for i := 0; i < 2; i++ {
+		corrected := storeTyped(t, store, models.DependencyFailure, models.StatusResolved, "intermittent gateway resets", learned)
+		corrected.DetectedType = models.Flapping
+		if err := store.StoreIncident(corrected); err != nil {
+			t.Fatalf("StoreIncident: %v", err)
+		}
+	}
+	if incidentType, _ := store.SuggestType([]string{"intermittent gateway resets"}); incidentType != models.DependencyFailure {
+		t.Errorf("SuggestType = %s, want the AI-corrected DEPENDENCY_FAILURE", incidentType)
+	}
+}
@@ -0,0 +1,29 @@
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// CheckWritable reports whether a store file can be written at path without modifying it.
+// A file created by the check is removed again.
+func CheckWritable(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	_, statErr := os.Stat(path)
+	existed := statErr == nil
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("store file %s is not writable: %w", path, err)
+	}
+	file.Close()
+
+	if !existed && errors.Is(statErr, os.ErrNotExist) {
+		os.Remove(path)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package memory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckWritable(t *testing.T) {
+	dir := t.TempDir()
+
+	// A new file is created to check, then removed again
+	path := filepath.Join(dir, "incident_memory.json")
+	if err := CheckWritable(path); err != nil {
+		t.Fatalf("CheckWritable(new file): %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("check left the file behind: %v", err)
+	}
+
+	// An existing file is left as it was
+	if err := os.WriteFile(path, []byte(`{"incidents": {}}`), 0644); err != nil {
+		t.Fatalf("writing store file: %v", err)
+	}
+	if err := CheckWritable(path); err != nil {
+		t.Fatalf("CheckWritable(existing file): %v", err)
+	}
+	if raw, _ := os.ReadFile(path); string(raw) != `{"incidents": {}}` {
+		t.Errorf("check changed the existing file: %s", raw)
+	}
+
+	if err := CheckWritable(filepath.Join(dir, "missing", "incident_memory.json")); err == nil {
+		t.Error("CheckWritable accepted a file in a directory that doesn't exist")
+	}
+	if err := CheckWritable(""); err != nil {
+		t.Errorf("CheckWritable without a file = %v, want no error", err)
+	}
+}
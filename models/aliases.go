@@ -0,0 +1,52 @@
+package models
+
+import "strings"
+
+// incidentTypeAliases maps normalized names used by people and alert sources to the
+// canonical incident types. Keys are lower case with '-' and ' ' folded to '_'.
+var incidentTypeAliases = map[string]IncidentType{
+	"service_down": ServiceDown,
+	"crash":        ServiceDown,
+	"crashed":      ServiceDown,
+	"down":         ServiceDown,
+	"outage":       ServiceDown,
+	"unavailable":  ServiceDown,
+
+	"config_error":  ConfigError,
+	"config":        ConfigError,
+	"configuration": ConfigError,
+	"misconfig":     ConfigError,
+	"bad_config":    ConfigError,
+
+	"resource_exhaustion": ResourceExhaustion,
+	"resource":            ResourceExhaustion,
+	"resources":           ResourceExhaustion,
+	"oom":                 ResourceExhaustion,
+	"out_of_memory":       ResourceExhaustion,
+
+	"dependency_failure": DependencyFailure,
+	"dependency":         DependencyFailure,
+	"dependency_down":    DependencyFailure,
+	"database":           DependencyFailure,
+	"db":                 DependencyFailure,
+	"upstream":           DependencyFailure,
+
+	"flapping": Flapping,
+	"flap":     Flapping,
+	"flaky":    Flapping,
+
+	"degraded":    Degraded,
+	"degradation": Degraded,
+	"slow":        Degraded,
+}
+
+// NormalizeIncidentType resolves a type name or alias, in any case and with '-', '_' or
+// ' ' as separators, to its canonical incident type, e.g. "crash", "service-down" and
+// "SERVICE_DOWN" all give ServiceDown. It reports false for unknown names.
+func NormalizeIncidentType(s string) (IncidentType, bool) {
+	key := strings.ToLower(strings.TrimSpace(s))
+	key = strings.NewReplacer("-", "_", " ", "_").Replace(key)
+
+	t, ok := incidentTypeAliases[key]
+	return t, ok
+}
@@ -0,0 +1,60 @@
+package models
+
+import "testing"
+
+func TestNormalizeIncidentType(t *testing.T) {
+	tests := []struct {
+		name string
+		want IncidentType
+	}{
+		{"SERVICE_DOWN", ServiceDown},
+		{"service-down", ServiceDown},
+		{"Service Down", ServiceDown},
+		{"crash", ServiceDown},
+		{"  DOWN  ", ServiceDown},
+		{"config_error", ConfigError},
+		{"Misconfig", ConfigError},
+		{"bad-config", ConfigError},
+		{"RESOURCE_EXHAUSTION", ResourceExhaustion},
+		{"OOM", ResourceExhaustion},
+		{"out of memory", ResourceExhaustion},
+		{"dependency-failure", DependencyFailure},
+		{"db", DependencyFailure},
+		{"Upstream", DependencyFailure},
+		{"FLAPPING", Flapping},
+		{"flaky", Flapping},
+		{"degraded", Degraded},
+		{"slow", Degraded},
+	}
+
+	for _, tt := range tests {
+		if got, ok := NormalizeIncidentType(tt.name); !ok || got != tt.want {
+			t.Errorf("NormalizeIncidentType(%q) = %q, %t; want %q", tt.name, got, ok, tt.want)
+		}
+	}
+
+	for _, name := range []string{"", "  ", "meltdown", "service__down", "down!", "SERVICE_DOWN_NOW"} {
+		if got, ok := NormalizeIncidentType(name); ok {
+			t.Errorf("NormalizeIncidentType(%q) = %q, want it rejected", name, got)
+		}
+	}
+}
+
+func TestEveryAliasIsCanonical(t *testing.T) {
+	canonical := map[IncidentType]bool{
+		ServiceDown: true, ConfigError: true, ResourceExhaustion: true,
+		DependencyFailure: true, Flapping: true, Degraded: true,
+	}
+
+	for alias, incidentType := range incidentTypeAliases {
+		if !canonical[incidentType] {
+			t.Errorf("alias %q maps to unknown type %q", alias, incidentType)
+		}
+	}
+	// Each type is reachable by its own name
+	for incidentType := range canonical {
+		if got, ok := NormalizeIncidentType(string(incidentType)); !ok || got != incidentType {
+			t.Errorf("NormalizeIncidentType(%q) = %q, %t", incidentType, got, ok)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+)
+
+// IDGenerator mints IDs for incidents and injected faults
+type IDGenerator interface {
+	NewID() string
+}
+
+// UUIDGenerator mints random UUIDs. It is the default everywhere IDs are minted.
+type UUIDGenerator struct{}
+
+// NewID returns a new random UUID
+func (UUIDGenerator) NewID() string {
+	return uuid.New().String()
+}
+
+// SequenceGenerator mints predictable IDs "<prefix>-1", "<prefix>-2", ..., so tests can
+// assert on the IDs they expect. It is safe for concurrent use.
+type SequenceGenerator struct {
+	prefix string
+	next   atomic.Uint64
+}
+
+// NewSequenceGenerator creates a generator whose IDs start at "<prefix>-1"
+func NewSequenceGenerator(prefix string) *SequenceGenerator {
+	return &SequenceGenerator{prefix: prefix}
+}
+
+// NewID returns the next ID in the sequence
+func (g *SequenceGenerator) NewID() string {
+	return fmt.Sprintf("%s-%d", g.prefix, g.next.Add(1))
+}
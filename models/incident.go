@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"sort"
+	"strings"
+	"time"
+)
 
 // IncidentType represents the type of incident
 type IncidentType string
@@ -10,55 +14,325 @@ const (
 	ConfigError        IncidentType = "CONFIG_ERROR"
 	ResourceExhaustion IncidentType = "RESOURCE_EXHAUSTION"
 	DependencyFailure  IncidentType = "DEPENDENCY_FAILURE"
+	Flapping           IncidentType = "FLAPPING"
+	Degraded           IncidentType = "DEGRADED"
+)
+
+// IsValid reports whether t is a known incident type
+func (t IncidentType) IsValid() bool {
+	switch t {
+	case ServiceDown, ConfigError, ResourceExhaustion, DependencyFailure, Flapping, Degraded:
+		return true
+	default:
+		return false
+	}
+}
+
+// LearnedTypeSymptom marks the symptom recorded when an incident's type was learned from
+// resolved incidents rather than observed, so the learned types don't learn from themselves
+const LearnedTypeSymptom = "from resolved incidents with similar symptoms"
+
+// Severity ranks how urgently an incident needs attention
+type Severity string
+
+const (
+	SeverityCritical Severity = "critical"
+	SeverityHigh     Severity = "high"
+	SeverityMedium   Severity = "medium"
+	SeverityLow      Severity = "low"
+)
+
+// Raise returns the next more urgent severity. Critical stays critical, and an unknown
+// severity is returned unchanged.
+func (s Severity) Raise() Severity {
+	switch s {
+	case SeverityLow:
+		return SeverityMedium
+	case SeverityMedium:
+		return SeverityHigh
+	case SeverityHigh, SeverityCritical:
+		return SeverityCritical
+	default:
+		return s
+	}
+}
+
+// RootCauseCategory is a coarse classification of an incident's root cause, for aggregation
+type RootCauseCategory string
+
+const (
+	CauseResource   RootCauseCategory = "resource"
+	CauseConfig     RootCauseCategory = "config"
+	CauseDependency RootCauseCategory = "dependency"
+	CauseCodeBug    RootCauseCategory = "code-bug"
+	CauseExternal   RootCauseCategory = "external"
 )
 
+// RootCauseCategories lists every valid root cause category
+var RootCauseCategories = []RootCauseCategory{CauseResource, CauseConfig, CauseDependency, CauseCodeBug, CauseExternal}
+
+// IsValid reports whether c is a known root cause category
+func (c RootCauseCategory) IsValid() bool {
+	for _, category := range RootCauseCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
 // IncidentStatus represents the current state of an incident
 type IncidentStatus string
 
 const (
-	StatusDetected  IncidentStatus = "DETECTED"
-	StatusAnalyzing IncidentStatus = "ANALYZING"
-	StatusFixing    IncidentStatus = "FIXING"
-	StatusResolved  IncidentStatus = "RESOLVED"
-	StatusFailed    IncidentStatus = "FAILED"
+	StatusDetected     IncidentStatus = "DETECTED"
+	StatusAnalyzing    IncidentStatus = "ANALYZING"
+	StatusFixing       IncidentStatus = "FIXING"
+	StatusResolved     IncidentStatus = "RESOLVED"
+	StatusFailed       IncidentStatus = "FAILED"
+	StatusDiagnosed    IncidentStatus = "DIAGNOSED"     // analyzed only, fix left to humans
+	StatusManualReview IncidentStatus = "MANUAL_REVIEW" // fix applied, awaiting human verification
+	StatusAborted      IncidentStatus = "ABORTED"       // processing cancelled by an operator
 )
 
+// IsTerminal reports whether the orchestrator is done with an incident in this status
+func (s IncidentStatus) IsTerminal() bool {
+	switch s {
+	case StatusResolved, StatusFailed, StatusDiagnosed, StatusManualReview, StatusAborted:
+		return true
+	default:
+		return false
+	}
+}
+
 // Incident represents a detected system incident
 type Incident struct {
-	ID          string         `json:"id"`
-	Type        IncidentType   `json:"type"`
-	Status      IncidentStatus `json:"status"`
-	DetectedAt  time.Time      `json:"detected_at"`
-	ResolvedAt  *time.Time     `json:"resolved_at,omitempty"`
-	Symptoms    []string       `json:"symptoms"`
-	Logs        []string       `json:"logs"`
-	Diagnosis   string         `json:"diagnosis,omitempty"`
-	Resolution  *Resolution    `json:"resolution,omitempty"`
-	UsedCachedFix bool         `json:"used_cached_fix"`
+	ID                 string              `json:"id"`
+	CorrelationID      string              `json:"correlation_id,omitempty"` // tags the incident's log lines and outbound requests; shared by follow-ups
+	Type               IncidentType        `json:"type"`
+	Severity           Severity            `json:"severity,omitempty"`
+	Status             IncidentStatus      `json:"status"`
+	DetectedAt         time.Time           `json:"detected_at"`
+	ResolvedAt         *time.Time          `json:"resolved_at,omitempty"`
+	Symptoms           []string            `json:"symptoms"`
+	Logs               []string            `json:"logs"`
+	TriggerLog         string              `json:"trigger_log,omitempty"`     // the service log line that reported the injected fault
+	ServiceConfig      map[string]string   `json:"service_config,omitempty"`  // service config captured at detection
+	ConfigBaseline     map[string]string   `json:"config_baseline,omitempty"` // service config when it was last known good, before this incident
+	Diagnosis          string              `json:"diagnosis,omitempty"`
+	RootCauseCategory  RootCauseCategory   `json:"root_cause_category,omitempty"`
+	Recommendations    []string            `json:"recommendations,omitempty"`    // preventive measures for operators to review, never applied
+	SystemFingerprint  string              `json:"system_fingerprint,omitempty"` // AI backend that produced the diagnosis
+	Resolution         *Resolution         `json:"resolution,omitempty"`
+	UsedCachedFix      bool                `json:"used_cached_fix"`
+	UsedRunbook        bool                `json:"used_runbook,omitempty"`    // fixed by an operator runbook rather than a learned or AI fix
+	RecommendedFix     *Resolution         `json:"recommended_fix,omitempty"` // set in diagnose-only mode, never applied
+	AcknowledgedBy     string              `json:"acknowledged_by,omitempty"`
+	AcknowledgedAt     *time.Time          `json:"acknowledged_at,omitempty"`
+	ImpactScore        float64             `json:"impact_score"` // fraction of sampled API requests failing at detection (0-1)
+	FailureReason      string              `json:"failure_reason,omitempty"`
+	Relapses           int                 `json:"relapses,omitempty"`            // times a verified fix failed during the soak period
+	CausedBy           string              `json:"caused_by,omitempty"`           // incident whose fix led to this one
+	FollowUp           string              `json:"follow_up,omitempty"`           // incident this one's fix led to
+	Recurring          bool                `json:"recurring,omitempty"`           // its type keeps recurring, so severity was raised
+	Recurrences        int                 `json:"recurrences,omitempty"`         // incidents of this type in the recurrence window, this one included
+	DetectedType       IncidentType        `json:"detected_type,omitempty"`       // original type when the AI corrected it
+	Metadata           map[string]string   `json:"metadata,omitempty"`            // service ownership and environment from the inventory, e.g. team and tier
+	Profile            *ProfileSummary     `json:"profile,omitempty"`             // runtime profiles captured from the service at detection
+	DiagnosisRevisions []DiagnosisRevision `json:"diagnosis_revisions,omitempty"` // later re-analyses, oldest first; the fields above keep the original
+	Fingerprint        string              `json:"fingerprint,omitempty"`         // identifies the problem: type, normalized symptoms and config
+	Occurrences        int                 `json:"occurrences,omitempty"`         // detections of this problem folded into this incident, the first included
+	LastOccurredAt     *time.Time          `json:"last_occurred_at,omitempty"`    // latest detection folded into this incident
+	DuplicateOf        string              `json:"duplicate_of,omitempty"`        // open incident this detection was counted against; such incidents aren't stored
+	RootCauseService   string              `json:"root_cause_service,omitempty"`  // health endpoint whose failure caused the others, for a composite service
+	CorrelatedFailures []string            `json:"correlated_failures,omitempty"` // failing health endpoints that depend on RootCauseService
+}
+
+// FormatMetadata renders incident metadata as "key=value" pairs sorted by key
+func FormatMetadata(metadata map[string]string) string {
+	pairs := make([]string, 0, len(metadata))
+	for key, value := range metadata {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
 }
 
 // Resolution represents how an incident was fixed
 type Resolution struct {
-	FixType     string   `json:"fix_type"`     // "code", "config", "restart"
-	Description string   `json:"description"`
-	Steps       []string `json:"steps"`
-	Code        string   `json:"code,omitempty"`
-	Success     bool     `json:"success"`
+	FixType           string            `json:"fix_type"` // "code", "config", "restart"
+	Description       string            `json:"description"`
+	Steps             []string          `json:"steps"`
+	Code              string            `json:"code,omitempty"`
+	Success           bool              `json:"success"`
+	CommandOutput     string            `json:"command_output,omitempty"`      // output of an external remediation command
+	ConfigChanges     map[string]string `json:"config_changes,omitempty"`      // config values the fix asked for, replayed when the fix is reused
+	TargetConfig      map[string]string `json:"target_config,omitempty"`       // config values a config fix set out to write, whether or not each write took
+	AppliedConfig     map[string]string `json:"applied_config,omitempty"`      // config values set by a config fix
+	UnknownConfigKeys []string          `json:"unknown_config_keys,omitempty"` // keys the fix referred to that the service doesn't have
+	BlockedConfigKeys []string          `json:"blocked_config_keys,omitempty"` // keys the fix wanted to change that the guardrails forbid
+	StepResults       []StepResult      `json:"step_results,omitempty"`        // what each step of a config fix did, in order
+
+	// Learned fix statistics, maintained by the memory store
+	LearnedAt *time.Time `json:"learned_at,omitempty"` // when the fix was first learned
+	Successes int        `json:"successes,omitempty"`  // incidents this fix resolved
+	Failures  int        `json:"failures,omitempty"`   // times this fix was reused and did not resolve the incident
+}
+
+// StepResult records whether one step of a fix took effect
+type StepResult struct {
+	Step    string `json:"step"`
+	Applied bool   `json:"applied"`
+	Action  string `json:"action,omitempty"` // what the step was understood to do, e.g. "set timeout=30s"
+	Error   string `json:"error,omitempty"`  // why the step didn't take effect
+}
+
+// DiagnosisRevision is a diagnosis made by re-analyzing an incident after it was handled
+type DiagnosisRevision struct {
+	RevisedAt         time.Time         `json:"revised_at"`
+	Diagnosis         string            `json:"diagnosis"`
+	RootCauseCategory RootCauseCategory `json:"root_cause_category,omitempty"`
+	Recommendations   []string          `json:"recommendations,omitempty"`
+	CorrectedType     IncidentType      `json:"corrected_type,omitempty"` // type the AI thinks the incident really was, never applied
+	Confidence        float64           `json:"confidence"`
+	SystemFingerprint string            `json:"system_fingerprint,omitempty"`
+	RecommendedFix    *Resolution       `json:"recommended_fix,omitempty"` // never applied
+}
+
+// ProfileSummary summarizes goroutine and heap profiles captured from a service's
+// /debug/pprof endpoints
+type ProfileSummary struct {
+	CapturedAt     time.Time        `json:"captured_at"`
+	Goroutines     int              `json:"goroutines"`       // 0 if the goroutine profile could not be captured
+	HeapInUseBytes int64            `json:"heap_inuse_bytes"` // 0 if the heap profile could not be captured
+	TopAllocations []AllocationSite `json:"top_allocations,omitempty"`
+	GoroutineFile  string           `json:"goroutine_file,omitempty"` // raw goroutine profile, when kept
+	HeapFile       string           `json:"heap_file,omitempty"`      // raw heap profile, when kept
+}
+
+// AllocationSite is a function holding live heap memory in a heap profile
+type AllocationSite struct {
+	Function   string `json:"function"`
+	InUseBytes int64  `json:"inuse_bytes"`
 }
 
 // AIResponse represents the response from the AI
 type AIResponse struct {
-	Diagnosis   string   `json:"diagnosis"`
-	FixType     string   `json:"fix_type"`
-	FixSteps    []string `json:"fix_steps"`
-	Code        string   `json:"code,omitempty"`
-	Confidence  float64  `json:"confidence,omitempty"`
+	Diagnosis         string            `json:"diagnosis"`
+	FixType           string            `json:"fix_type"`
+	FixSteps          []string          `json:"fix_steps"`
+	Code              string            `json:"code,omitempty"`
+	ConfigChanges     map[string]string `json:"config_changes,omitempty"` // config key -> value a config fix sets; FixSteps describe them for humans
+	Confidence        float64           `json:"confidence,omitempty"`
+	CorrectedType     IncidentType      `json:"corrected_type,omitempty"` // AI's reclassification of the incident, if it disagrees with detection
+	RootCauseCategory RootCauseCategory `json:"root_cause_category,omitempty"`
+	Recommendations   []string          `json:"recommendations,omitempty"`    // optional preventive measures beyond the immediate fix
+	SystemFingerprint string            `json:"system_fingerprint,omitempty"` // backend that produced the response, set by the analyzer
+}
+
+// HealthState is a service's reported health beyond up/down
+type HealthState string
+
+const (
+	HealthHealthy   HealthState = "healthy"
+	HealthDegraded  HealthState = "degraded" // still serving, but impaired
+	HealthUnhealthy HealthState = "unhealthy"
+)
+
+// IsValid reports whether s is a known health state
+func (s HealthState) IsValid() bool {
+	switch s {
+	case HealthHealthy, HealthDegraded, HealthUnhealthy:
+		return true
+	default:
+		return false
+	}
 }
 
+// HealthFailure is how an unhealthy health check failed
+type HealthFailure string
+
+const (
+	FailureConnection  HealthFailure = "connection" // no response: refused, reset or unresolvable
+	FailureTimeout     HealthFailure = "timeout"    // no response in time
+	FailureServerError HealthFailure = "5xx"        // answered with a 5xx status, e.g. draining
+	FailureClientError HealthFailure = "4xx"        // answered with a 4xx status, e.g. a wrong path or auth
+	FailureUnhealthy   HealthFailure = "unhealthy"  // answered normally but reported itself unhealthy, or unreadably
+	FailureAuth        HealthFailure = "auth"       // no probe auth token could be had, so the service wasn't asked
+)
+
+// HealthFailures lists the health failure categories an incident can be raised for.
+// FailureAuth isn't one: without a token the service's health is unknown.
+var HealthFailures = []HealthFailure{FailureConnection, FailureTimeout, FailureServerError, FailureClientError, FailureUnhealthy}
+
 // HealthStatus represents the health of a service
 type HealthStatus struct {
-	Healthy   bool      `json:"healthy"`
+	Healthy    bool          `json:"healthy"`          // false only when unhealthy; degraded services are still healthy
+	Status     HealthState   `json:"status,omitempty"` // optional finer-grained state
+	Timestamp  time.Time     `json:"timestamp"`
+	Message    string        `json:"message"`
+	StatusCode int           `json:"status_code,omitempty"`
+	Failure    HealthFailure `json:"failure,omitempty"` // how the check failed, set by the detector when unhealthy
+}
+
+// State returns the reported health state, deriving it from Healthy for services that
+// only report the boolean. An unhealthy boolean always wins over a contradicting state.
+func (h HealthStatus) State() HealthState {
+	if !h.Healthy {
+		return HealthUnhealthy
+	}
+	if h.Status == HealthDegraded {
+		return HealthDegraded
+	}
+	return HealthHealthy
+}
+
+// LogLevel represents the severity of a service log entry
+type LogLevel string
+
+const (
+	LogInfo  LogLevel = "INFO"
+	LogWarn  LogLevel = "WARN"
+	LogError LogLevel = "ERROR"
+)
+
+// Rank orders log levels so they can be compared (higher is more severe)
+func (l LogLevel) Rank() int {
+	switch l {
+	case LogError:
+		return 2
+	case LogWarn:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// LogEntry represents a single structured log line from a service
+type LogEntry struct {
 	Timestamp time.Time `json:"timestamp"`
+	Level     LogLevel  `json:"level"`
 	Message   string    `json:"message"`
-	StatusCode int      `json:"status_code,omitempty"`
+	TriggerID string    `json:"trigger_id,omitempty"` // incident ID of the injected fault this line reports
+}
+
+// String formats the entry as a single log line
+func (e LogEntry) String() string {
+	return "[" + e.Timestamp.Format("15:04:05") + "] " + string(e.Level) + " " + e.Message
+}
+
+// LogLineLevel returns the level of a line formatted by LogEntry.String, or LogInfo for a
+// line without one
+func LogLineLevel(line string) LogLevel {
+	_, rest, ok := strings.Cut(line, "] ")
+	if !ok {
+		return LogInfo
+	}
+	level, _, _ := strings.Cut(rest, " ")
+	switch LogLevel(level) {
+	case LogWarn, LogError:
+		return LogLevel(level)
+	default:
+		return LogInfo
+	}
 }
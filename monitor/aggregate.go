@@ -0,0 +1,104 @@
+package monitor
+
+import (
+	"fmt"
+	"incident-ai/models"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AggregationMode decides how per-endpoint results combine into one health status
+type AggregationMode string
+
+const (
+	// AggregateAll requires every endpoint to be healthy
+	AggregateAll AggregationMode = "all"
+	// AggregateMajority requires more than half of the endpoints to be healthy
+	AggregateMajority AggregationMode = "majority"
+)
+
+// ParseAggregationMode validates an aggregation mode name
+func ParseAggregationMode(s string) (AggregationMode, error) {
+	switch AggregationMode(s) {
+	case AggregateAll, AggregateMajority:
+		return AggregationMode(s), nil
+	default:
+		return "", fmt.Errorf("unknown aggregation mode %q (valid: %s, %s)", s, AggregateAll, AggregateMajority)
+	}
+}
+
+// checkAggregateHealth probes every configured health endpoint concurrently and combines
+// the results into a single status whose message carries per-endpoint detail
+func (id *IncidentDetector) checkAggregateHealth() models.HealthStatus {
+	results := make([]models.HealthStatus, len(id.healthEndpoints))
+
+	var wg sync.WaitGroup
+	for i, endpoint := range id.healthEndpoints {
+		wg.Add(1)
+		go func(i int, endpoint string) {
+			defer wg.Done()
+			results[i] = id.probeHealth(endpoint)
+		}(i, endpoint)
+	}
+	wg.Wait()
+
+	id.recordEndpointHealth(results)
+	return aggregateHealth(id.healthEndpoints, results, id.aggregation)
+}
+
+// aggregateHealth combines per-endpoint results according to mode. The status code and
+// failure category are taken from the first unhealthy endpoint when the aggregate is
+// unhealthy, otherwise the status code is from the first healthy one. A healthy aggregate is degraded if any endpoint is.
+func aggregateHealth(endpoints []string, results []models.HealthStatus, mode AggregationMode) models.HealthStatus {
+	healthyCount, degradedCount := 0, 0
+	healthyCode, unhealthyCode := 0, 0
+	var failure models.HealthFailure
+	details := make([]string, len(results))
+
+	for i, result := range results {
+		if result.Healthy {
+			healthyCount++
+			details[i] = fmt.Sprintf("%s OK", endpoints[i])
+			if result.State() == models.HealthDegraded {
+				degradedCount++
+				details[i] = fmt.Sprintf("%s DEGRADED", endpoints[i])
+			}
+			if healthyCode == 0 {
+				healthyCode = result.StatusCode
+			}
+		} else {
+			details[i] = fmt.Sprintf("%s FAIL (%s)", endpoints[i], result.Message)
+			if unhealthyCode == 0 {
+				unhealthyCode = result.StatusCode
+			}
+			if failure == "" {
+				failure = result.Failure
+			}
+		}
+	}
+
+	var healthy bool
+	if mode == AggregateMajority {
+		healthy = healthyCount*2 > len(results)
+	} else {
+		healthy = healthyCount == len(results)
+	}
+
+	statusCode, state := unhealthyCode, models.HealthUnhealthy
+	if healthy {
+		statusCode, state, failure = healthyCode, models.HealthHealthy, ""
+		if degradedCount > 0 {
+			state = models.HealthDegraded
+		}
+	}
+
+	return models.HealthStatus{
+		Healthy:    healthy,
+		Status:     state,
+		Timestamp:  time.Now(),
+		Message:    fmt.Sprintf("%d/%d endpoints healthy (%s required): %s", healthyCount, len(results), mode, strings.Join(details, "; ")),
+		StatusCode: statusCode,
+		Failure:    failure,
+	}
+}
@@ -0,0 +1,75 @@
+package monitor
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAggregateHealth(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        AggregationMode
+		services    int
+		failing     int
+		wantHealthy bool
+	}{
+		{name: "all, one failing", mode: AggregateAll, services: 3, failing: 1},
+		{name: "all, none failing", mode: AggregateAll, services: 3, wantHealthy: true},
+		{name: "majority, one failing", mode: AggregateMajority, services: 3, failing: 1, wantHealthy: true},
+		{name: "majority, two failing", mode: AggregateMajority, services: 3, failing: 2},
+		{name: "majority, half failing", mode: AggregateMajority, services: 2, failing: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var endpoints []string
+			for i := 0; i < tt.services; i++ {
+				service := newFakeService(t, fmt.Sprintf("part-%d", i))
+				service.healthy.Store(i >= tt.failing)
+				endpoints = append(endpoints, service.healthURL())
+			}
+
+			detector := NewIncidentDetector("http://unused", time.Second, WithHealthEndpoints(endpoints, tt.mode))
+			health := detector.checkHealth()
+
+			if health.Healthy != tt.wantHealthy {
+				t.Errorf("healthy = %v, want %v: %s", health.Healthy, tt.wantHealthy, health.Message)
+			}
+			wantCode := http.StatusOK
+			if !tt.wantHealthy {
+				wantCode = http.StatusServiceUnavailable
+			}
+			if health.StatusCode != wantCode {
+				t.Errorf("status code = %d, want %d", health.StatusCode, wantCode)
+			}
+
+			wantSummary := fmt.Sprintf("%d/%d endpoints healthy (%s required)", tt.services-tt.failing, tt.services, tt.mode)
+			if !strings.HasPrefix(health.Message, wantSummary) {
+				t.Errorf("message = %q, want it to start with %q", health.Message, wantSummary)
+			}
+			for i, endpoint := range endpoints {
+				want := endpoint + " OK"
+				if i < tt.failing {
+					want = endpoint + " FAIL"
+				}
+				if !strings.Contains(health.Message, want) {
+					t.Errorf("message = %q, want the detail %q", health.Message, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseAggregationMode(t *testing.T) {
+	for _, s := range []string{"all", "majority"} {
+		if mode, err := ParseAggregationMode(s); err != nil || string(mode) != s {
+			t.Errorf("ParseAggregationMode(%q) = %q, %v", s, mode, err)
+		}
+	}
+	if _, err := ParseAggregationMode("any"); err == nil {
+		t.Error("ParseAggregationMode accepted an unknown mode")
+	}
+}
@@ -0,0 +1,75 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"incident-ai/models"
+	"incident-ai/telemetry"
+)
+
+// ErrUnknownAlertType is returned for a pushed alert whose type matches no incident type
+var ErrUnknownAlertType = errors.New("unknown incident type")
+
+// ErrAlertSuppressed is returned for an alert pushed during maintenance
+var ErrAlertSuppressed = errors.New("alert suppressed during maintenance")
+
+// ErrAlertDropped is returned when the incident queue is full
+var ErrAlertDropped = errors.New("incident queue full")
+
+// Alert is an incident pushed by an external alerting source rather than found by a probe
+type Alert struct {
+	Type     string   `json:"type"`               // incident type or an alias of one, e.g. "down"
+	Severity string   `json:"severity,omitempty"` // critical, high, medium or low (default high)
+	Source   string   `json:"source,omitempty"`   // the alerting system, recorded as a symptom
+	Symptoms []string `json:"symptoms,omitempty"`
+}
+
+// ReceiveAlert raises an incident for a pushed alert, queued with detected incidents so the
+// orchestrator handles it the same way. The type is normalized with
+// models.NormalizeIncidentType. correlationID, from the alert's X-Correlation-ID header,
+// becomes the incident's so its handling can be traced back to the alert; an empty one is
+// generated. Like failed probes, alerts are suppressed during maintenance.
+func (id *IncidentDetector) ReceiveAlert(alert Alert, correlationID string) (*models.Incident, error) {
+	incidentType, ok := models.NormalizeIncidentType(alert.Type)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownAlertType, alert.Type)
+	}
+	severity := models.SeverityHigh
+	if alert.Severity != "" {
+		severity = models.Severity(alert.Severity)
+		switch severity {
+		case models.SeverityCritical, models.SeverityHigh, models.SeverityMedium, models.SeverityLow:
+		default:
+			return nil, fmt.Errorf("unknown severity %q", alert.Severity)
+		}
+	}
+
+	if correlationID == "" {
+		correlationID = telemetry.NewCorrelationID()
+	}
+	ctx := telemetry.WithCorrelationID(context.Background(), correlationID)
+	if id.InMaintenance() {
+		telemetry.Logf(ctx, "[MONITOR] 🔧 %s alert received during maintenance - incident suppressed\n", incidentType)
+		return nil, ErrAlertSuppressed
+	}
+
+	symptoms := append([]string(nil), alert.Symptoms...)
+	if alert.Source != "" {
+		symptoms = append(symptoms, "Alert from "+alert.Source)
+	}
+	if symptoms == nil {
+		symptoms = []string{}
+	}
+
+	incident := id.newIncident(ctx, id.fetchServiceStatus(ctx), false, incidentType, symptoms, severity)
+	telemetry.Logf(ctx, "[MONITOR] 📨 Received %s alert, raising incident %s\n", incidentType, incident.ID)
+
+	id.detectionMu.Lock()
+	id.lastDetection = incident.DetectedAt
+	id.detectionMu.Unlock()
+	if !id.queue(incident) {
+		return nil, ErrAlertDropped
+	}
+	return incident, nil
+}
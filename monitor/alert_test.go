@@ -0,0 +1,80 @@
+package monitor
+
+import (
+	"errors"
+	"incident-ai/models"
+	"testing"
+	"time"
+)
+
+func TestReceiveAlert(t *testing.T) {
+	tests := []struct {
+		name          string
+		alert         Alert
+		correlationID string
+		wantType      models.IncidentType
+		wantSeverity  models.Severity
+		wantErr       error
+	}{
+		{name: "canonical type", alert: Alert{Type: "SERVICE_DOWN"}, wantType: models.ServiceDown, wantSeverity: models.SeverityHigh},
+		{name: "alias", alert: Alert{Type: "Out-Of-Memory", Severity: "critical"}, wantType: models.ResourceExhaustion, wantSeverity: models.SeverityCritical},
+		{name: "inbound correlation ID", alert: Alert{Type: "db"}, correlationID: "alertmanager-42", wantType: models.DependencyFailure, wantSeverity: models.SeverityHigh},
+		{name: "unknown type", alert: Alert{Type: "meteor-strike"}, wantErr: ErrUnknownAlertType},
+		{name: "unknown severity", alert: Alert{Type: "down", Severity: "urgent"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector := NewIncidentDetector("http://127.0.0.1:1", time.Second)
+
+			incident, err := detector.ReceiveAlert(tt.alert, tt.correlationID)
+			if tt.wantType == "" {
+				if err == nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ReceiveAlert = %v, %v; want error %v", incident, err, tt.wantErr)
+				}
+				if queued := nextIncident(detector, 20*time.Millisecond); queued != nil {
+					t.Errorf("rejected alert raised incident %s", queued.ID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReceiveAlert: %v", err)
+			}
+
+			if incident.Type != tt.wantType || incident.Severity != tt.wantSeverity {
+				t.Errorf("incident is %s/%s, want %s/%s", incident.Type, incident.Severity, tt.wantType, tt.wantSeverity)
+			}
+			if tt.correlationID != "" && incident.CorrelationID != tt.correlationID {
+				t.Errorf("correlation ID = %q, want the inbound %q", incident.CorrelationID, tt.correlationID)
+			}
+			if incident.CorrelationID == "" {
+				t.Error("incident has no correlation ID")
+			}
+			if queued := nextIncident(detector, time.Second); queued != incident {
+				t.Errorf("queued incident = %v, want the alert's incident", queued)
+			}
+		})
+	}
+}
+
+func TestReceiveAlertSuppressedOrDropped(t *testing.T) {
+	detector := NewIncidentDetector("http://127.0.0.1:1", time.Second, WithIncidentBuffer(1))
+
+	detector.SetMaintenanceMode(true)
+	if _, err := detector.ReceiveAlert(Alert{Type: "down"}, ""); !errors.Is(err, ErrAlertSuppressed) {
+		t.Errorf("alert during maintenance: %v, want %v", err, ErrAlertSuppressed)
+	}
+	detector.SetMaintenanceMode(false)
+
+	if _, err := detector.ReceiveAlert(Alert{Type: "down", Source: "pager"}, ""); err != nil {
+		t.Fatalf("first alert: %v", err)
+	}
+	if _, err := detector.ReceiveAlert(Alert{Type: "down"}, ""); !errors.Is(err, ErrAlertDropped) {
+		t.Errorf("alert with a full queue: %v, want %v", err, ErrAlertDropped)
+	}
+
+	incident := nextIncident(detector, time.Second)
+	if incident == nil || len(incident.Symptoms) != 1 || incident.Symptoms[0] != "Alert from pager" {
+		t.Errorf("queued incident = %+v, want the first alert with its source as a symptom", incident)
+	}
+}
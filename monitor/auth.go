@@ -0,0 +1,187 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"incident-ai/telemetry"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenProvider supplies bearer tokens for requests to a service behind a token-auth gateway
+type TokenProvider interface {
+	// Token returns a valid token, fetching one only if none is cached or it has expired
+	Token() (string, error)
+	// Refresh discards the cached token and fetches a new one, e.g. after a 401
+	Refresh() (string, error)
+}
+
+const (
+	// defaultTokenTTL is how long a token is cached when the endpoint doesn't say
+	defaultTokenTTL = 5 * time.Minute
+	// tokenExpirySkew refreshes tokens this long before they expire, so a probe never
+	// carries a token that expires in flight
+	tokenExpirySkew = 10 * time.Second
+)
+
+// EndpointTokenProvider fetches tokens from a token endpoint with a POST request and caches
+// them until they expire. The endpoint must answer 200 with a JSON body holding the token in
+// "token" or "access_token" and, optionally, its lifetime in seconds in "expires_in".
+type EndpointTokenProvider struct {
+	url    string
+	client *http.Client
+	now    func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewEndpointTokenProvider creates a provider that fetches tokens from url
+func NewEndpointTokenProvider(url string) *EndpointTokenProvider {
+	return &EndpointTokenProvider{
+		url:    url,
+		client: &http.Client{Timeout: 5 * time.Second},
+		now:    time.Now,
+	}
+}
+
+// Token returns the cached token, fetching a new one if it is missing or about to expire
+func (p *EndpointTokenProvider) Token() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token != "" && p.now().Before(p.expiresAt) {
+		return p.token, nil
+	}
+	return p.fetch()
+}
+
+// Refresh fetches a new token regardless of the cached one's expiry
+func (p *EndpointTokenProvider) Refresh() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.token = ""
+	return p.fetch()
+}
+
+// fetch requests a token from the endpoint and caches it. The caller must hold p.mu.
+func (p *EndpointTokenProvider) fetch() (string, error) {
+	resp, err := p.client.Post(p.url, "application/json", nil)
+	if err != nil {
+		return "", fmt.Errorf("token request failed: %w", err)
+	}
+	defer closeBody(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Token       string  `json:"token"`
+		AccessToken string  `json:"access_token"`
+		ExpiresIn   float64 `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to parse token response: %w", err)
+	}
+
+	token := body.Token
+	if token == "" {
+		token = body.AccessToken
+	}
+	if token == "" {
+		return "", fmt.Errorf("token response contains no token")
+	}
+
+	ttl := defaultTokenTTL
+	if body.ExpiresIn > 0 {
+		ttl = time.Duration(body.ExpiresIn * float64(time.Second))
+	}
+	if ttl > 2*tokenExpirySkew {
+		ttl -= tokenExpirySkew
+	}
+
+	p.token = token
+	p.expiresAt = p.now().Add(ttl)
+	log.Printf("[MONITOR] 🔑 Fetched probe auth token (valid for %v)\n", ttl.Round(time.Second))
+	return token, nil
+}
+
+// tokenError is a failure to get an auth token, before the service was requested at all
+type tokenError struct {
+	err error
+}
+
+func (e *tokenError) Error() string { return e.err.Error() }
+func (e *tokenError) Unwrap() error { return e.err }
+
+// get requests url, authenticating with the token provider if one is configured and url
+// is on the monitored service. A 401 response refreshes the token and retries the request
+// once. The request carries ctx's correlation ID, if any.
+func (id *IncidentDetector) get(ctx context.Context, client *http.Client, url string) (*http.Response, error) {
+	return id.request(ctx, client, http.MethodGet, url)
+}
+
+// request is get for any method without a body
+func (id *IncidentDetector) request(ctx context.Context, client *http.Client, method, url string) (*http.Response, error) {
+	if id.tokens == nil || !id.onService(url) {
+		req, err := newRequest(ctx, method, url)
+		if err != nil {
+			return nil, err
+		}
+		return client.Do(req)
+	}
+
+	token, err := id.tokens.Token()
+	if err != nil {
+		return nil, &tokenError{fmt.Errorf("failed to get auth token: %w", err)}
+	}
+
+	resp, err := id.authorizedRequest(ctx, client, method, url, token)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	closeBody(resp)
+
+	telemetry.Logf(ctx, "[MONITOR] 🔑 %s rejected the auth token, refreshing\n", url)
+	token, err = id.tokens.Refresh()
+	if err != nil {
+		return nil, &tokenError{fmt.Errorf("failed to refresh auth token: %w", err)}
+	}
+	return id.authorizedRequest(ctx, client, method, url, token)
+}
+
+func (id *IncidentDetector) authorizedRequest(ctx context.Context, client *http.Client, method, url, token string) (*http.Response, error) {
+	req, err := newRequest(ctx, method, url)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return client.Do(req)
+}
+
+// onService reports whether url is on the monitored service, so the service's token may
+// be sent with it. Other hosts, e.g. an absolute verification URL, never see the token.
+func (id *IncidentDetector) onService(url string) bool {
+	rest, ok := strings.CutPrefix(url, id.serviceURL)
+	return ok && (rest == "" || strings.HasPrefix(rest, "/") || strings.HasPrefix(rest, "?"))
+}
+
+// newRequest builds a request without a body, setting the correlation header when ctx
+// carries an ID
+func newRequest(ctx context.Context, method, url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if id := telemetry.CorrelationID(ctx); id != "" {
+		req.Header.Set(telemetry.CorrelationHeader, id)
+	}
+	return req, nil
+}
@@ -0,0 +1,113 @@
+package monitor
+
+import (
+	"fmt"
+	"incident-ai/models"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// tokenServer is a fake token endpoint issuing tokens t1, t2, ... and a service behind a
+// gateway that only accepts the latest one
+type tokenServer struct {
+	issued   atomic.Int64
+	mu       sync.Mutex
+	received []string // Authorization headers the service got, in order
+	tokens   *httptest.Server
+	service  *httptest.Server
+}
+
+func newTokenServer(t *testing.T) *tokenServer {
+	t.Helper()
+
+	s := &tokenServer{}
+	s.tokens = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"access_token": "t%d", "expires_in": 3600}`, s.issued.Add(1))
+	}))
+	s.service = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		s.mu.Lock()
+		s.received = append(s.received, auth)
+		s.mu.Unlock()
+
+		if auth != fmt.Sprintf("Bearer t%d", s.issued.Load()) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"healthy": true}`)
+	}))
+	t.Cleanup(s.tokens.Close)
+	t.Cleanup(s.service.Close)
+	return s
+}
+
+func (s *tokenServer) requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.received...)
+}
+
+func TestProbeRefreshesTokenOn401(t *testing.T) {
+	s := newTokenServer(t)
+	tokens := NewEndpointTokenProvider(s.tokens.URL)
+	detector := NewIncidentDetector(s.service.URL, time.Second, WithTokenProvider(tokens))
+
+	// A token the gateway has already rotated away from
+	if _, err := tokens.Token(); err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+	s.issued.Add(1)
+
+	health := detector.probeHealth(s.service.URL + "/health")
+	if !health.Healthy {
+		t.Fatalf("probe after refresh unhealthy: %+v", health)
+	}
+	if got := fmt.Sprint(s.requests()); got != "[Bearer t1 Bearer t3]" {
+		t.Errorf("service got %s, want the stale token then the refreshed one", got)
+	}
+
+	// The refreshed token is cached for the next probe
+	detector.probeHealth(s.service.URL + "/health")
+	if issued := s.issued.Load(); issued != 3 {
+		t.Errorf("%d tokens issued, want the refreshed one reused", issued)
+	}
+}
+
+func TestProbeTokenFailureIsAuthFailure(t *testing.T) {
+	s := newTokenServer(t)
+	s.tokens.Close()
+
+	detector := NewIncidentDetector(s.service.URL, time.Second, WithTokenProvider(NewEndpointTokenProvider(s.tokens.URL)))
+	health := detector.probeHealth(s.service.URL + "/health")
+	if health.Healthy || health.Failure != models.FailureAuth {
+		t.Errorf("probe = %+v, want unhealthy with failure %s", health, models.FailureAuth)
+	}
+	if len(s.requests()) != 0 {
+		t.Errorf("service was requested without a token")
+	}
+}
+
+func TestTokenOnlySentToService(t *testing.T) {
+	detector := NewIncidentDetector("http://svc:8080", time.Second)
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"http://svc:8080", true},
+		{"http://svc:8080/health", true},
+		{"http://svc:8080?probe=1", true},
+		{"http://svc:80801/health", false},
+		{"http://svc:8080.evil.example/health", false},
+		{"http://other:8080/health", false},
+	}
+
+	for _, tt := range tests {
+		if got := detector.onService(tt.url); got != tt.want {
+			t.Errorf("onService(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package monitor
+
+import (
+	"log"
+	"time"
+)
+
+// probeBackoff tracks how far probes have backed off while the service stays unhealthy
+type probeBackoff struct {
+	max      time.Duration // longest wait between probes (0 = backoff off)
+	failures int           // consecutive failed probes
+}
+
+// delay returns the wait before the next probe: the check interval, doubled for each
+// failed probe after the first, up to max
+func (b probeBackoff) delay(interval time.Duration) time.Duration {
+	if b.max <= interval || b.failures < 2 {
+		return interval
+	}
+
+	delay := interval
+	for i := 1; i < b.failures && delay < b.max; i++ {
+		delay *= 2
+	}
+	return min(delay, b.max)
+}
+
+// recordProbe updates the backoff with a probe's result. A healthy probe ends the backoff.
+func (id *IncidentDetector) recordProbe(healthy bool) {
+	id.backoffMu.Lock()
+	defer id.backoffMu.Unlock()
+
+	if healthy {
+		id.backoff.failures = 0
+		return
+	}
+	id.backoff.failures++
+}
+
+// probeInterval returns the wait before the next probe, before jitter
+func (id *IncidentDetector) probeInterval() time.Duration {
+	id.backoffMu.Lock()
+	defer id.backoffMu.Unlock()
+	return id.backoff.delay(id.checkInterval)
+}
+
+// ResetBackoff returns the detector to its normal probe cadence at once, e.g. after an
+// operator fixed the service by hand while probes were backed off: the service is probed
+// right away and then every check interval. If it is still unhealthy, probes back off
+// again from the start; no new incident is raised for the failure already reported.
+func (id *IncidentDetector) ResetBackoff() {
+	id.backoffMu.Lock()
+	backedOff := id.backoff.failures > 1
+	id.backoff.failures = 0
+	id.backoffMu.Unlock()
+
+	if backedOff {
+		log.Println("[MONITOR] ⏩ Probe backoff reset - probing at the normal interval")
+	}
+
+	// Wake the monitor loop for an immediate probe; one pending wake-up is enough
+	select {
+	case id.probeNow <- struct{}{}:
+	default:
+	}
+}
+
+// BackoffStatus reports how far health probes have backed off
+func (id *IncidentDetector) BackoffStatus() map[string]interface{} {
+	id.backoffMu.Lock()
+	defer id.backoffMu.Unlock()
+
+	return map[string]interface{}{
+		"max_interval":         id.backoff.max.String(),
+		"consecutive_failures": id.backoff.failures,
+		"current_interval":     id.backoff.delay(id.checkInterval).String(),
+	}
+}
@@ -0,0 +1,107 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProbeBackoffDelay(t *testing.T) {
+	const interval = 10 * time.Millisecond
+
+	tests := []struct {
+		max      time.Duration
+		failures int
+		want     time.Duration
+	}{
+		{max: 0, failures: 5, want: interval},
+		{max: interval / 2, failures: 5, want: interval},
+		{max: 100 * time.Millisecond, failures: 0, want: interval},
+		{max: 100 * time.Millisecond, failures: 1, want: interval},
+		{max: 100 * time.Millisecond, failures: 2, want: 20 * time.Millisecond},
+		{max: 100 * time.Millisecond, failures: 3, want: 40 * time.Millisecond},
+		{max: 100 * time.Millisecond, failures: 4, want: 80 * time.Millisecond},
+		{max: 100 * time.Millisecond, failures: 5, want: 100 * time.Millisecond},
+		{max: 100 * time.Millisecond, failures: 1000, want: 100 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		backoff := probeBackoff{max: tt.max, failures: tt.failures}
+		if got := backoff.delay(interval); got != tt.want {
+			t.Errorf("delay after %d failures with max %v = %v, want %v", tt.failures, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestResetBackoffRestoresCadence(t *testing.T) {
+	const interval = 5 * time.Millisecond
+
+	tests := []struct {
+		name        string
+		fixedByHand bool
+		maxFailures int // consecutive failures shortly after the reset
+	}{
+		{name: "service fixed by hand", fixedByHand: true, maxFailures: 0},
+		{name: "service still down", maxFailures: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newFakeService(t, "backing-off")
+			detector := NewIncidentDetector(service.server.URL, interval,
+				WithProbeBackoff(time.Minute), WithImpactSampling(0, 0))
+			startDetector(t, detector)
+			awaitProbes(t, 1, service)
+
+			service.healthy.Store(false)
+			if incident := nextIncident(detector, 2*time.Second); incident == nil {
+				t.Fatal("no incident raised")
+			}
+
+			// After 8 failed probes the detector waits 128 intervals before the next one
+			deadline := time.Now().Add(5 * time.Second)
+			for backoffFailures(detector) < 8 {
+				if time.Now().After(deadline) {
+					t.Fatalf("probes didn't back off: %v", detector.BackoffStatus())
+				}
+				time.Sleep(time.Millisecond)
+			}
+			if wait := detector.BackoffStatus()["current_interval"]; wait != (128 * interval).String() {
+				t.Errorf("backed-off interval = %v, want %v", wait, 128*interval)
+			}
+
+			service.healthy.Store(tt.fixedByHand)
+			reset := time.Now()
+			probes := service.probes.Load()
+			detector.ResetBackoff()
+
+			for service.probes.Load() == probes {
+				if time.Since(reset) > 100*time.Millisecond {
+					t.Fatal("no probe right after the reset")
+				}
+				time.Sleep(time.Millisecond)
+			}
+			if tt.fixedByHand {
+				// Back at the check interval: several probes well within one backed-off wait
+				awaitProbes(t, 5, service)
+				if elapsed := time.Since(reset); elapsed > 64*interval {
+					t.Errorf("6 probes took %v after the reset, want the normal %v cadence", elapsed, interval)
+				}
+			}
+
+			// A healthy probe ends the backoff; a failed one starts it over rather than going on
+			if failures := backoffFailures(detector); failures > tt.maxFailures {
+				t.Errorf("%d consecutive failures after the reset, want at most %d", failures, tt.maxFailures)
+			}
+			if incident := nextIncident(detector, 10*interval); incident != nil {
+				t.Errorf("reset raised %s incident %s, want the failure already reported to stay reported", incident.Type, incident.ID)
+			}
+		})
+	}
+}
+
+// backoffFailures returns how many consecutive probes have failed
+func backoffFailures(detector *IncidentDetector) int {
+	detector.backoffMu.Lock()
+	defer detector.backoffMu.Unlock()
+	return detector.backoff.failures
+}
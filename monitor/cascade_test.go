@@ -0,0 +1,77 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCascadeScenarioIsDeterministic(t *testing.T) {
+	plan := func(seed int64) []string {
+		s := newCascadeScenario(t, seed, time.Second, "orders", "payments", "search")
+		var steps []string
+		for _, step := range s.steps {
+			steps = append(steps, fmt.Sprintf("%v %s healthy=%t", step.at, step.service.name, step.healthy))
+		}
+		return steps
+	}
+
+	if first, again := plan(42), plan(42); !reflect.DeepEqual(first, again) {
+		t.Errorf("seed 42 planned different cascades:\n%v\n%v", first, again)
+	}
+	if plan(1)[0] != "0s dependency healthy=false" {
+		t.Errorf("cascade doesn't start with the dependency failing: %v", plan(1))
+	}
+}
+
+func TestCascadeRaisesOneCorrelatedIncident(t *testing.T) {
+	for _, seed := range []int64{1, 7, 2024} {
+		t.Run(fmt.Sprint(seed), func(t *testing.T) {
+			scenario := newCascadeScenario(t, seed, 50*time.Millisecond, "orders", "payments")
+			dependsOn := make(map[string][]string)
+			var downstream []string
+			for _, service := range scenario.downstream {
+				dependsOn[service.healthURL()] = []string{scenario.dependency.healthURL()}
+				downstream = append(downstream, service.healthURL())
+			}
+			sort.Strings(downstream)
+
+			detector := NewIncidentDetector(scenario.dependency.server.URL, 5*time.Millisecond,
+				WithHealthEndpoints(scenario.healthEndpoints(), AggregateAll),
+				WithDependencies(dependsOn, 500*time.Millisecond),
+				WithImpactSampling(0, 0))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			detector.Start(ctx)
+			scenario.awaitProbes(t, 2)
+			scenario.run(t)
+
+			incident := nextIncident(detector, 2*time.Second)
+			detector.Stop()
+			if incident == nil {
+				t.Fatal("cascade raised no incident")
+			}
+			if extra := nextIncident(detector, 20*time.Millisecond); extra != nil {
+				t.Fatalf("cascade raised another incident %s, want 1 for the whole cascade", extra.ID)
+			}
+
+			// The incident is raised for the root cause; the downstream failures that follow
+			// are attributed to it rather than incidents of their own
+			symptoms := strings.Join(incident.Symptoms, "\n")
+			if !strings.Contains(symptoms, scenario.dependency.healthURL()+" FAIL") {
+				t.Errorf("incident symptoms don't name the failed dependency:\n%s", symptoms)
+			}
+			if incident.RootCauseService != scenario.dependency.healthURL() {
+				t.Errorf("root cause = %q, want the dependency %s", incident.RootCauseService, scenario.dependency.healthURL())
+			}
+			if !reflect.DeepEqual(incident.CorrelatedFailures, downstream) {
+				t.Errorf("correlated failures = %v, want the downstream services %v", incident.CorrelatedFailures, downstream)
+			}
+		})
+	}
+}
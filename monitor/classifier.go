@@ -0,0 +1,89 @@
+package monitor
+
+import (
+	"fmt"
+	"incident-ai/models"
+)
+
+// Classifier decides what kind of incident an unhealthy health check represents.
+// status is the service's /status response (empty if it could not be fetched).
+type Classifier interface {
+	Classify(health models.HealthStatus, status map[string]interface{}) (models.IncidentType, []string, models.Severity)
+}
+
+// ClassifierFunc adapts a function to the Classifier interface
+type ClassifierFunc func(health models.HealthStatus, status map[string]interface{}) (models.IncidentType, []string, models.Severity)
+
+// Classify calls f
+func (f ClassifierFunc) Classify(health models.HealthStatus, status map[string]interface{}) (models.IncidentType, []string, models.Severity) {
+	return f(health, status)
+}
+
+// TypeSuggester suggests an incident type for symptoms, with a confidence in [0, 1], from
+// what similar incidents turned out to be. It returns "" when it has no suggestion.
+type TypeSuggester interface {
+	SuggestType(symptoms []string) (models.IncidentType, float64)
+}
+
+// HeuristicClassifier is the built-in classifier. It inspects the service's config,
+// running state and warning/error logs, defaulting to SERVICE_DOWN.
+type HeuristicClassifier struct{}
+
+// Classify implements Classifier
+func (h HeuristicClassifier) Classify(health models.HealthStatus, status map[string]interface{}) (models.IncidentType, []string, models.Severity) {
+	incidentType, symptoms, severity, _ := h.classify(health, status)
+	return incidentType, symptoms, severity
+}
+
+// classify is Classify, also reporting whether nothing pointed at a specific type and the
+// result is the SERVICE_DOWN default
+func (HeuristicClassifier) classify(health models.HealthStatus, status map[string]interface{}) (models.IncidentType, []string, models.Severity, bool) {
+	symptoms := []string{
+		fmt.Sprintf("Health check returned status code: %d", health.StatusCode),
+		health.Message,
+	}
+	if symptom := failureSymptom(health.Failure); symptom != "" {
+		symptoms = append(symptoms, symptom)
+	}
+
+	if config, ok := status["config"].(map[string]interface{}); ok {
+		// Check for config issues
+		if dbURL, exists := config["database_url"]; exists {
+			if str, ok := dbURL.(string); ok && (str == "invalid::url::format" || str == "") {
+				symptoms = append(symptoms, "Invalid database URL configuration detected")
+				return models.ConfigError, symptoms, models.SeverityHigh, false
+			}
+			if str, ok := dbURL.(string); ok && str == "unreachable-host:9999" {
+				symptoms = append(symptoms, "Database host unreachable")
+				return models.DependencyFailure, symptoms, models.SeverityHigh, false
+			}
+		}
+		if timeout, exists := config["timeout"]; exists {
+			if str, ok := timeout.(string); ok && str == "not-a-number" {
+				symptoms = append(symptoms, "Invalid timeout configuration detected")
+				return models.ConfigError, symptoms, models.SeverityHigh, false
+			}
+		}
+	}
+
+	// Check if service is not running at all
+	if running, ok := status["running"].(bool); ok && !running {
+		symptoms = append(symptoms, "Service process not running")
+		return models.ServiceDown, symptoms, models.SeverityCritical, false
+	}
+
+	// Check warning and error logs for resource issues
+	for _, entry := range parseLogEntries(status) {
+		if entry.Level.Rank() < models.LogWarn.Rank() {
+			continue
+		}
+		if contains(entry.Message, "resource") || contains(entry.Message, "port blocked") || contains(entry.Message, "memory") {
+			symptoms = append(symptoms, "Resource exhaustion detected in logs")
+			return models.ResourceExhaustion, symptoms, models.SeverityHigh, false
+		}
+	}
+
+	// Default to service down
+	symptoms = append(symptoms, "Service health check failing")
+	return models.ServiceDown, symptoms, models.SeverityCritical, true
+}
@@ -0,0 +1,179 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"incident-ai/memory"
+	"incident-ai/models"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHeuristicClassifier(t *testing.T) {
+	health := models.HealthStatus{Healthy: false, StatusCode: 503, Message: "Service unhealthy"}
+
+	tests := []struct {
+		name         string
+		status       map[string]interface{}
+		wantType     models.IncidentType
+		wantSeverity models.Severity
+		wantSymptom  string
+	}{
+		{
+			name:         "invalid database URL",
+			status:       map[string]interface{}{"config": map[string]interface{}{"database_url": "invalid::url::format"}},
+			wantType:     models.ConfigError,
+			wantSeverity: models.SeverityHigh,
+			wantSymptom:  "Invalid database URL configuration detected",
+		},
+		{
+			name:         "invalid timeout",
+			status:       map[string]interface{}{"config": map[string]interface{}{"database_url": "localhost:5432", "timeout": "not-a-number"}},
+			wantType:     models.ConfigError,
+			wantSeverity: models.SeverityHigh,
+			wantSymptom:  "Invalid timeout configuration detected",
+		},
+		{
+			name:         "unreachable database",
+			status:       map[string]interface{}{"config": map[string]interface{}{"database_url": "unreachable-host:9999"}},
+			wantType:     models.DependencyFailure,
+			wantSeverity: models.SeverityHigh,
+			wantSymptom:  "Database host unreachable",
+		},
+		{
+			name:         "process not running",
+			status:       map[string]interface{}{"running": false},
+			wantType:     models.ServiceDown,
+			wantSeverity: models.SeverityCritical,
+			wantSymptom:  "Service process not running",
+		},
+		{
+			name: "resource error logged",
+			status: map[string]interface{}{"running": true, "recent_logs": []interface{}{
+				map[string]interface{}{"level": "ERROR", "message": "Resource exhaustion - port blocked or memory full"},
+			}},
+			wantType:     models.ResourceExhaustion,
+			wantSeverity: models.SeverityHigh,
+			wantSymptom:  "Resource exhaustion detected in logs",
+		},
+		{
+			name: "resource mentioned at info level",
+			status: map[string]interface{}{"running": true, "recent_logs": []interface{}{
+				map[string]interface{}{"level": "INFO", "message": "memory usage at 40%"},
+			}},
+			wantType:     models.ServiceDown,
+			wantSeverity: models.SeverityCritical,
+			wantSymptom:  "Service health check failing",
+		},
+		{
+			name:         "status unavailable",
+			status:       map[string]interface{}{},
+			wantType:     models.ServiceDown,
+			wantSeverity: models.SeverityCritical,
+			wantSymptom:  "Service health check failing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			incidentType, symptoms, severity := HeuristicClassifier{}.Classify(health, tt.status)
+			if incidentType != tt.wantType || severity != tt.wantSeverity {
+				t.Errorf("classified as %s (%s), want %s (%s)", incidentType, severity, tt.wantType, tt.wantSeverity)
+			}
+			if !slices.Contains(symptoms, tt.wantSymptom) || !slices.Contains(symptoms, "Health check returned status code: 503") {
+				t.Errorf("symptoms = %q, want the status code and %q", symptoms, tt.wantSymptom)
+			}
+		})
+	}
+}
+
+func TestDetectorUsesCustomClassifier(t *testing.T) {
+	service := newFakeService(t, "classified")
+	service.healthy.Store(false)
+
+	classified := make(chan models.HealthStatus, 1)
+	classifier := ClassifierFunc(func(health models.HealthStatus, status map[string]interface{}) (models.IncidentType, []string, models.Severity) {
+		select {
+		case classified <- health:
+		default:
+		}
+		return models.DependencyFailure, []string{"Upstream payments API rejecting requests"}, models.SeverityLow
+	})
+
+	detector := NewIncidentDetector(service.server.URL, 10*time.Millisecond, WithClassifier(classifier), WithImpactSampling(0, 0))
+	startDetector(t, detector)
+
+	incident := nextIncident(detector, 2*time.Second)
+	if incident == nil {
+		t.Fatal("no incident raised")
+	}
+	if incident.Type != models.DependencyFailure || incident.Severity != models.SeverityLow {
+		t.Errorf("incident is %s (%s), want the custom classifier's DEPENDENCY_FAILURE (low)", incident.Type, incident.Severity)
+	}
+	if !slices.Contains(incident.Symptoms, "Upstream payments API rejecting requests") {
+		t.Errorf("symptoms = %q, want the custom classifier's", incident.Symptoms)
+	}
+	if health := <-classified; health.Healthy || health.StatusCode != 503 {
+		t.Errorf("classifier got health %+v, want the failed check", health)
+	}
+
+	// A nil classifier keeps the built-in heuristic
+	if detector := NewIncidentDetector(service.server.URL, time.Second, WithClassifier(nil)); detector.classifier != (HeuristicClassifier{}) {
+		t.Errorf("classifier = %T, want the built-in heuristic", detector.classifier)
+	}
+}
+
+func TestLearnedTypeBreaksTie(t *testing.T) {
+	store, err := memory.NewStore(filepath.Join(t.TempDir(), "incident_memory.json"))
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	// Past incidents with these symptoms turned out to be the database
+	for i := 0; i < 3; i++ {
+		incident := &models.Incident{
+			ID:         fmt.Sprintf("past-%d", i),
+			Type:       models.DependencyFailure,
+			Status:     models.StatusResolved,
+			DetectedAt: time.Now(),
+			Symptoms:   []string{"Health check returned status code: 503", "postgres pool timeout"},
+		}
+		if err := store.StoreIncident(incident); err != nil {
+			t.Fatalf("StoreIncident: %v", err)
+		}
+	}
+
+	ambiguous := models.HealthStatus{StatusCode: 503, Message: "postgres pool timeout"}
+	configBroken := map[string]interface{}{"config": map[string]interface{}{"timeout": "not-a-number"}}
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		health   models.HealthStatus
+		status   map[string]interface{}
+		want     models.IncidentType
+		wantNote bool
+	}{
+		{name: "learned type replaces the default", opts: []Option{WithTypeSuggester(store, 0.6)}, health: ambiguous, want: models.DependencyFailure, wantNote: true},
+		{name: "no suggester", health: ambiguous, want: models.ServiceDown},
+		{name: "suggestion below the confidence", opts: []Option{WithTypeSuggester(store, 1.01)}, health: ambiguous, want: models.ServiceDown},
+		{name: "heuristic finds something specific", opts: []Option{WithTypeSuggester(store, 0.6)}, health: ambiguous, status: configBroken, want: models.ConfigError},
+		{name: "unrelated symptoms", opts: []Option{WithTypeSuggester(store, 0.6)}, health: models.HealthStatus{StatusCode: 503, Message: "disk full"}, want: models.ServiceDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector := NewIncidentDetector("http://127.0.0.1:1", time.Second, tt.opts...)
+			incidentType, symptoms, _ := detector.analyzeSymptoms(context.Background(), tt.health, tt.status)
+			if incidentType != tt.want {
+				t.Errorf("classified as %s, want %s", incidentType, tt.want)
+			}
+			noted := slices.ContainsFunc(symptoms, func(s string) bool { return strings.Contains(s, models.LearnedTypeSymptom) })
+			if noted != tt.wantNote {
+				t.Errorf("symptoms = %q, want a learned-type note: %t", symptoms, tt.wantNote)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"incident-ai/models"
+	"incident-ai/telemetry"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultCorrelationWindow is how long a composite incident waits for downstream failures
+const defaultCorrelationWindow = 5 * time.Second
+
+// ParseDependencies parses a comma-separated list of downstream=upstream pairs of health
+// endpoints, e.g. "http://orders/health=http://db/health". A service depending on several
+// others is listed once per dependency.
+func ParseDependencies(s string) (map[string][]string, error) {
+	dependsOn := make(map[string][]string)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		downstream, upstream, ok := strings.Cut(entry, "=")
+		downstream, upstream = strings.TrimSpace(downstream), strings.TrimSpace(upstream)
+		if !ok || downstream == "" || upstream == "" {
+			return nil, fmt.Errorf("invalid entry %q (expected downstream=upstream)", entry)
+		}
+		if downstream == upstream {
+			return nil, fmt.Errorf("%s can't depend on itself", downstream)
+		}
+		dependsOn[downstream] = append(dependsOn[downstream], upstream)
+	}
+	return dependsOn, nil
+}
+
+// endpointFailure is the latest failure of one health endpoint
+type endpointFailure struct {
+	since   time.Time // when the endpoint started failing
+	failing bool      // whether it is still failing
+}
+
+// recordEndpointHealth tracks when each health endpoint last started failing, from one
+// aggregate probe's per-endpoint results
+func (id *IncidentDetector) recordEndpointHealth(results []models.HealthStatus) {
+	if len(id.dependsOn) == 0 {
+		return
+	}
+
+	id.endpointMu.Lock()
+	defer id.endpointMu.Unlock()
+
+	now := time.Now()
+	for i, result := range results {
+		endpoint := id.healthEndpoints[i]
+		failure := id.endpointFailures[endpoint]
+		if !result.Healthy && !failure.failing {
+			failure.since = now
+		}
+		failure.failing = !result.Healthy
+		id.endpointFailures[endpoint] = failure
+	}
+}
+
+// correlate attributes a composite incident to its root cause. It waits until the
+// correlation window after detection has passed, so downstream services that fail as a
+// consequence are seen, then takes the endpoints that started failing within the window
+// around the detection. The earliest of them none of whose dependencies failed with it is
+// the root cause; those that depend on it, directly or through other services, are its
+// correlated failures and are attributed to it instead of being failures of their own.
+func (id *IncidentDetector) correlate(incident *models.Incident) {
+	time.Sleep(time.Until(incident.DetectedAt.Add(id.correlationWindow)))
+
+	failed := make(map[string]time.Time)
+	id.endpointMu.Lock()
+	for endpoint, failure := range id.endpointFailures {
+		if !failure.since.IsZero() && failure.since.After(incident.DetectedAt.Add(-id.correlationWindow)) {
+			failed[endpoint] = failure.since
+		}
+	}
+	id.endpointMu.Unlock()
+
+	root, downstream := rootCause(failed, id.dependsOn)
+	if root == "" {
+		return
+	}
+
+	incident.RootCauseService = root
+	incident.CorrelatedFailures = downstream
+	if len(downstream) > 0 {
+		incident.Symptoms = append(incident.Symptoms, fmt.Sprintf("Root cause: %s failed, and %d service(s) depending on it failed too: %s",
+			root, len(downstream), strings.Join(downstream, ", ")))
+		ctx := telemetry.WithCorrelationID(context.Background(), incident.CorrelationID)
+		telemetry.Logf(ctx, "[MONITOR] 🔗 Attributed %d downstream failure(s) to %s\n", len(downstream), root)
+	}
+}
+
+// rootCause returns the earliest-failing endpoint with no failing dependency, and the
+// failing endpoints that depend on it, sorted. failing maps each failing endpoint to when it
+// started failing. It returns "" if nothing is failing.
+func rootCause(failing map[string]time.Time, dependsOn map[string][]string) (string, []string) {
+	var roots []string
+	for endpoint := range failing {
+		if !slices.ContainsFunc(dependencies(endpoint, dependsOn), func(dependency string) bool {
+			_, failed := failing[dependency]
+			return failed
+		}) {
+			roots = append(roots, endpoint)
+		}
+	}
+	if len(roots) == 0 {
+		return "", nil
+	}
+
+	sort.Slice(roots, func(i, j int) bool {
+		if !failing[roots[i]].Equal(failing[roots[j]]) {
+			return failing[roots[i]].Before(failing[roots[j]])
+		}
+		return roots[i] < roots[j]
+	})
+	root := roots[0]
+
+	var downstream []string
+	for endpoint := range failing {
+		if slices.Contains(dependencies(endpoint, dependsOn), root) {
+			downstream = append(downstream, endpoint)
+		}
+	}
+	sort.Strings(downstream)
+	return root, downstream
+}
+
+// dependencies returns every endpoint that endpoint depends on, directly or transitively
+func dependencies(endpoint string, dependsOn map[string][]string) []string {
+	var all []string
+	seen := map[string]bool{endpoint: true}
+	queue := append([]string(nil), dependsOn[endpoint]...)
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		if seen[next] {
+			continue
+		}
+		seen[next] = true
+		all = append(all, next)
+		queue = append(queue, dependsOn[next]...)
+	}
+	return all
+}
@@ -0,0 +1,107 @@
+package monitor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDependencies(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    map[string][]string
+		wantErr string
+	}{
+		{in: "", want: map[string][]string{}},
+		{in: "orders=db", want: map[string][]string{"orders": {"db"}}},
+		{in: " orders = db , orders=cache,search=orders,", want: map[string][]string{"orders": {"db", "cache"}, "search": {"orders"}}},
+		{in: "orders", wantErr: "expected downstream=upstream"},
+		{in: "orders=", wantErr: "expected downstream=upstream"},
+		{in: "db=db", wantErr: "can't depend on itself"},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDependencies(tt.in)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseDependencies(%q) error = %v, want %q", tt.in, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseDependencies(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestRootCause(t *testing.T) {
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	at := func(offset int) time.Time { return start.Add(time.Duration(offset) * time.Second) }
+
+	// search -> orders -> db, payments -> db; mail depends on nothing
+	dependsOn := map[string][]string{
+		"orders":   {"db"},
+		"search":   {"orders"},
+		"payments": {"db"},
+	}
+
+	tests := []struct {
+		name           string
+		failing        map[string]time.Time
+		wantRoot       string
+		wantDownstream []string
+	}{
+		{name: "nothing failing"},
+		{
+			name:     "dependency alone",
+			failing:  map[string]time.Time{"db": at(0)},
+			wantRoot: "db",
+		},
+		{
+			name:           "downstream failures, directly and transitively",
+			failing:        map[string]time.Time{"db": at(0), "search": at(1), "orders": at(2), "payments": at(3)},
+			wantRoot:       "db",
+			wantDownstream: []string{"orders", "payments", "search"},
+		},
+		{
+			name:           "upstream noticed after a downstream service",
+			failing:        map[string]time.Time{"orders": at(0), "db": at(1)},
+			wantRoot:       "db",
+			wantDownstream: []string{"orders"},
+		},
+		{
+			name:           "unrelated service failing too",
+			failing:        map[string]time.Time{"db": at(1), "mail": at(2), "orders": at(3)},
+			wantRoot:       "db",
+			wantDownstream: []string{"orders"},
+		},
+		{
+			name:     "unrelated service first",
+			failing:  map[string]time.Time{"mail": at(0), "db": at(1), "orders": at(2)},
+			wantRoot: "mail",
+		},
+		{
+			name:           "middle of the chain",
+			failing:        map[string]time.Time{"orders": at(0), "search": at(1)},
+			wantRoot:       "orders",
+			wantDownstream: []string{"search"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, downstream := rootCause(tt.failing, dependsOn)
+			if root != tt.wantRoot || !reflect.DeepEqual(downstream, tt.wantDownstream) {
+				t.Errorf("rootCause = %q, %v; want %q, %v", root, downstream, tt.wantRoot, tt.wantDownstream)
+			}
+		})
+	}
+}
+
+func TestDependenciesFollowCycles(t *testing.T) {
+	dependsOn := map[string][]string{"a": {"b"}, "b": {"c"}, "c": {"a"}}
+	if got := dependencies("a", dependsOn); !reflect.DeepEqual(got, []string{"b", "c"}) {
+		t.Errorf("dependencies(a) = %v, want [b c]", got)
+	}
+}
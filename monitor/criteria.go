@@ -0,0 +1,65 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// HealthCriteria decides from a health response body whether the service is healthy, for
+// services that don't report the standard "healthy" boolean. The zero value uses "healthy".
+type HealthCriteria struct {
+	Field string // dot-separated path into the JSON body, e.g. "status" or "checks.db.state"
+	Value string // expected value, compared case-insensitively as text (empty = the field must be true)
+}
+
+// ParseHealthCriteria parses "field=value", e.g. "status=UP", or a bare "field" that must
+// be true. An empty string gives the default criteria.
+func ParseHealthCriteria(s string) (HealthCriteria, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return HealthCriteria{}, nil
+	}
+
+	field, value, _ := strings.Cut(s, "=")
+	criteria := HealthCriteria{Field: strings.TrimSpace(field), Value: strings.TrimSpace(value)}
+	if criteria.Field == "" {
+		return HealthCriteria{}, fmt.Errorf("invalid health criteria %q: expected field or field=value", s)
+	}
+	for _, part := range strings.Split(criteria.Field, ".") {
+		if part == "" {
+			return HealthCriteria{}, fmt.Errorf("invalid health criteria %q: empty segment in field path", s)
+		}
+	}
+	return criteria, nil
+}
+
+// healthy evaluates the criteria against a JSON health body. A missing field is unhealthy;
+// the returned text describes what was found, for the message of an unhealthy status.
+func (c HealthCriteria) healthy(body []byte) (bool, string, error) {
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return false, "", err
+	}
+
+	value := doc
+	for _, part := range strings.Split(c.Field, ".") {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return false, fmt.Sprintf("%s not found", c.Field), nil
+		}
+		if value, ok = obj[part]; !ok {
+			return false, fmt.Sprintf("%s not found", c.Field), nil
+		}
+	}
+
+	expected := c.Value
+	if expected == "" {
+		expected = "true"
+	}
+	actual := fmt.Sprint(value)
+	if s, ok := value.(string); ok {
+		actual = s
+	}
+	return strings.EqualFold(actual, expected), fmt.Sprintf("%s = %s, expected %s", c.Field, actual, expected), nil
+}
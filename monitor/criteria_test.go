@@ -0,0 +1,97 @@
+package monitor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseHealthCriteria(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    HealthCriteria
+		wantErr bool
+	}{
+		{s: "", want: HealthCriteria{}},
+		{s: "status=UP", want: HealthCriteria{Field: "status", Value: "UP"}},
+		{s: " checks.db.state = ok ", want: HealthCriteria{Field: "checks.db.state", Value: "ok"}},
+		{s: "alive", want: HealthCriteria{Field: "alive"}},
+		{s: "=UP", wantErr: true},
+		{s: "checks..state=ok", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseHealthCriteria(tt.s)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseHealthCriteria(%q) = %+v, %v; want %+v, error %t", tt.s, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestHealthCriteria(t *testing.T) {
+	tests := []struct {
+		criteria HealthCriteria
+		body     string
+		want     bool
+	}{
+		{HealthCriteria{Field: "status", Value: "UP"}, `{"status": "UP"}`, true},
+		{HealthCriteria{Field: "status", Value: "UP"}, `{"status": "up"}`, true},
+		{HealthCriteria{Field: "status", Value: "UP"}, `{"status": "DOWN"}`, false},
+		{HealthCriteria{Field: "status", Value: "UP"}, `{"healthy": true}`, false},
+		{HealthCriteria{Field: "checks.db.state", Value: "ok"}, `{"checks": {"db": {"state": "ok"}}}`, true},
+		{HealthCriteria{Field: "checks.db.state", Value: "ok"}, `{"checks": {"db": "ok"}}`, false},
+		{HealthCriteria{Field: "alive"}, `{"alive": true}`, true},
+		{HealthCriteria{Field: "alive"}, `{"alive": false}`, false},
+		{HealthCriteria{Field: "replicas", Value: "3"}, `{"replicas": 3}`, true},
+	}
+
+	for _, tt := range tests {
+		got, found, err := tt.criteria.healthy([]byte(tt.body))
+		if err != nil || got != tt.want {
+			t.Errorf("%+v on %s = %t (%s), %v; want %t", tt.criteria, tt.body, got, found, err, tt.want)
+		}
+	}
+
+	if _, _, err := (HealthCriteria{Field: "status", Value: "UP"}).healthy([]byte("UP")); err == nil {
+		t.Error("a body that isn't JSON was judged")
+	}
+}
+
+func TestCheckHealthWithCriteria(t *testing.T) {
+	var body atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body.Load().(string)))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		criteria    HealthCriteria
+		body        string
+		wantHealthy bool
+		wantMessage string
+	}{
+		{name: "status UP", criteria: HealthCriteria{Field: "status", Value: "UP"}, body: `{"status": "UP"}`, wantHealthy: true},
+		{name: "status DOWN", criteria: HealthCriteria{Field: "status", Value: "UP"}, body: `{"status": "DOWN"}`, wantMessage: "status = DOWN, expected UP"},
+		{name: "default on a conforming body", body: `{"healthy": true, "message": "ok"}`, wantHealthy: true},
+		{name: "default on a status body", body: `{"status": "UP"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body.Store(tt.body)
+			detector := NewIncidentDetector(server.URL, time.Second, WithHealthCriteria(tt.criteria))
+
+			health := detector.checkHealth()
+			if health.Healthy != tt.wantHealthy || !strings.Contains(health.Message, tt.wantMessage) {
+				t.Errorf("health = %t %q, want %t %q", health.Healthy, health.Message, tt.wantHealthy, tt.wantMessage)
+			}
+			if health.StatusCode != http.StatusOK {
+				t.Errorf("status code = %d, want %d", health.StatusCode, http.StatusOK)
+			}
+		})
+	}
+}
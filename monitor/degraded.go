@@ -0,0 +1,65 @@
+package monitor
+
+import (
+	"fmt"
+	"incident-ai/models"
+	"log"
+)
+
+// DegradedMode decides what the detector does when the service reports a degraded health state
+type DegradedMode string
+
+const (
+	// DegradedIgnore treats a degraded service as healthy
+	DegradedIgnore DegradedMode = "ignore"
+	// DegradedWarn logs a warning when the service becomes degraded, without raising an incident
+	DegradedWarn DegradedMode = "warn"
+	// DegradedEscalate raises a DEGRADED incident when the service becomes degraded
+	DegradedEscalate DegradedMode = "escalate"
+)
+
+// ParseDegradedMode validates a degraded mode name
+func ParseDegradedMode(s string) (DegradedMode, error) {
+	switch DegradedMode(s) {
+	case DegradedIgnore, DegradedWarn, DegradedEscalate:
+		return DegradedMode(s), nil
+	default:
+		return "", fmt.Errorf("unknown degraded mode %q (valid: %s, %s, %s)", s, DegradedIgnore, DegradedWarn, DegradedEscalate)
+	}
+}
+
+// degradedChanged handles the service entering or leaving a degraded state according to
+// the configured mode
+func (id *IncidentDetector) degradedChanged(health models.HealthStatus, degraded bool) {
+	if id.degradedMode == DegradedIgnore {
+		return
+	}
+
+	if !degraded {
+		// Going from degraded to down is a failure, reported by the health check, not a recovery
+		if health.Healthy {
+			log.Println("[MONITOR] ✓ Service is no longer degraded")
+		}
+		return
+	}
+
+	switch {
+	case id.degradedMode == DegradedWarn:
+		log.Printf("[MONITOR] ⚠️  Service reports DEGRADED health: %s\n", health.Message)
+	case id.InMaintenance():
+		log.Println("[MONITOR] 🔧 Service DEGRADED during maintenance - incident suppressed")
+	default:
+		log.Println("[MONITOR] ⚠️  Service reports DEGRADED health - Incident detected!")
+		id.raise(id.createDegradedIncident(health))
+	}
+}
+
+// createDegradedIncident builds an incident for a service that is serving but impaired
+func (id *IncidentDetector) createDegradedIncident(health models.HealthStatus) *models.Incident {
+	symptoms := []string{
+		"Health check reports a degraded service",
+		health.Message,
+	}
+	ctx := incidentContext()
+	return id.newIncident(ctx, id.fetchServiceStatus(ctx), true, models.Degraded, symptoms, models.SeverityLow)
+}
@@ -0,0 +1,136 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"incident-ai/models"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newStateService serves a /health reporting state. A healthy service answers with only
+// the boolean, as services did before health states.
+func newStateService(t *testing.T) (*httptest.Server, *atomic.Value) {
+	t.Helper()
+
+	var state atomic.Value
+	state.Store(models.HealthHealthy)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			http.NotFound(w, r)
+			return
+		}
+		switch current := state.Load().(models.HealthState); current {
+		case models.HealthHealthy:
+			w.Write([]byte(`{"healthy": true}`))
+		default:
+			json.NewEncoder(w).Encode(models.HealthStatus{Healthy: true, Status: current, Message: "p99 latency 4s"})
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &state
+}
+
+// syncBuffer collects log output written while the monitor loop runs
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestDegradedModes(t *testing.T) {
+	tests := []struct {
+		mode         DegradedMode
+		wantIncident bool
+		wantWarning  bool
+		wantResolved bool // whether a degraded service passes verification
+	}{
+		{mode: DegradedIgnore, wantResolved: true},
+		{mode: DegradedWarn, wantWarning: true, wantResolved: true},
+		{mode: DegradedEscalate, wantIncident: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			var logs syncBuffer
+			log.SetOutput(&logs)
+			t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+			server, state := newStateService(t)
+			detector := NewIncidentDetector(server.URL, 5*time.Millisecond, WithDegradedMode(tt.mode), WithImpactSampling(0, 0))
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			detector.Start(ctx)
+
+			time.Sleep(20 * time.Millisecond)
+			state.Store(models.HealthDegraded)
+
+			incident := nextIncident(detector, 200*time.Millisecond)
+			if got := incident != nil; got != tt.wantIncident {
+				t.Fatalf("incident raised = %v, want %v", got, tt.wantIncident)
+			}
+			if incident != nil && (incident.Type != models.Degraded || incident.Severity != models.SeverityLow || !strings.Contains(strings.Join(incident.Symptoms, "\n"), "p99 latency 4s")) {
+				t.Errorf("incident = %s (%s) with symptoms %q, want a low-severity DEGRADED incident", incident.Type, incident.Severity, incident.Symptoms)
+			}
+			if resolved := detector.VerifyResolution(); resolved != tt.wantResolved {
+				t.Errorf("degraded service verified as resolved = %v, want %v", resolved, tt.wantResolved)
+			}
+			if warned := strings.Contains(logs.String(), "Service reports DEGRADED health: p99 latency 4s"); warned != tt.wantWarning {
+				t.Errorf("degraded warning logged = %v, want %v", warned, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestHealthStateBackwardCompatible(t *testing.T) {
+	tests := []struct {
+		body string
+		want models.HealthState
+	}{
+		{body: `{"healthy": true}`, want: models.HealthHealthy},
+		{body: `{"healthy": false}`, want: models.HealthUnhealthy},
+		{body: `{"healthy": true, "status": "degraded"}`, want: models.HealthDegraded},
+		{body: `{"healthy": false, "status": "degraded"}`, want: models.HealthUnhealthy},
+		{body: `{"healthy": true, "status": "unhealthy"}`, want: models.HealthHealthy},
+	}
+
+	for _, tt := range tests {
+		var health models.HealthStatus
+		if err := json.Unmarshal([]byte(tt.body), &health); err != nil {
+			t.Fatalf("decoding %s: %v", tt.body, err)
+		}
+		if got := health.State(); got != tt.want {
+			t.Errorf("state of %s = %s, want %s", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestParseDegradedMode(t *testing.T) {
+	for _, name := range []string{"ignore", "warn", "escalate"} {
+		if mode, err := ParseDegradedMode(name); err != nil || string(mode) != name {
+			t.Errorf("ParseDegradedMode(%q) = %q, %v", name, mode, err)
+		}
+	}
+	if _, err := ParseDegradedMode("page"); err == nil {
+		t.Error("ParseDegradedMode accepted an unknown mode")
+	}
+}
@@ -5,32 +5,133 @@ import (
 	"encoding/json"
 	"fmt"
 	"incident-ai/models"
+	"incident-ai/telemetry"
 	"io"
 	"log"
+	"math/rand"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
-
-	"github.com/google/uuid"
 )
 
 // IncidentDetector monitors services and detects incidents
 type IncidentDetector struct {
 	serviceURL      string
+	socketPath      string // Unix socket every request is dialed over (empty = TCP)
 	checkInterval   time.Duration
+	warmup          time.Duration // after Start, failed probes aren't incidents for this long
 	incidentChannel chan *models.Incident
+	incidentBuffer  int
+	dropped         atomic.Int64 // incidents dropped because the channel was full
 	stopChannel     chan bool
 	isRunning       bool
+
+	maintenanceMu      sync.RWMutex
+	maintenanceWindows []MaintenanceWindow
+	maintenanceManual  bool
+
+	remediationMu    sync.Mutex
+	remediations     int         // fixes currently being applied
+	remediationEnded time.Time   // when the most recent fix finished being applied
+	failureHandled   atomic.Bool // the service's current failure already has an incident being handled
+
+	historyMu     sync.RWMutex
+	history       []HealthSample
+	historyWindow time.Duration
+	flapThreshold float64
+
+	impactSamples  int
+	impactInterval time.Duration
+
+	captureProfiles bool   // capture pprof profiles for resource exhaustion incidents
+	profileDir      string // where raw profiles are kept (empty = summaries only)
+
+	healthEndpoints []string        // health URLs of a composite service (empty = serviceURL/health)
+	aggregation     AggregationMode // how healthEndpoints results are combined
+	healthCriteria  HealthCriteria  // how a health body is judged (zero = the "healthy" boolean)
+	probeMethod     ProbeMethod     // HTTP method of health probes (empty = GET)
+
+	dependsOn         map[string][]string // health endpoint -> endpoints it depends on (nil = no correlation)
+	correlationWindow time.Duration       // how long a composite incident waits for downstream failures
+	endpointMu        sync.Mutex
+	endpointFailures  map[string]endpointFailure // health endpoint -> its latest failure
+
+	verifyPath         string // functional endpoint checked after a fix (empty = health only)
+	verifyBodyContains string // substring the verification response must contain (empty = any)
+
+	classifier Classifier // decides the type and severity of detected incidents
+
+	typeSuggester         TypeSuggester                                // types learned from resolved incidents, for the heuristic's default case (nil = unused)
+	failureTypes          map[models.HealthFailure]models.IncidentType // incident type per health failure category, for the heuristic's default case
+	learnedTypeConfidence float64                                      // minimum confidence of a learned type to replace the default
+
+	degradedMode DegradedMode // how a degraded (impaired but serving) health state is handled
+
+	tokens    TokenProvider     // supplies Authorization headers for requests to the service (nil = none)
+	transport http.RoundTripper // shared by all requests so connections are pooled
+
+	probeJitter float64        // probe delays vary by up to ±this fraction of checkInterval
+	random      func() float64 // source of jitter in [0, 1)
+
+	backoffMu sync.Mutex
+	backoff   probeBackoff
+	probeNow  chan struct{} // wakes the monitor loop for an immediate probe (see ResetBackoff)
+
+	ids models.IDGenerator // mints IDs of incidents not raised by a trigger
+
+	driftWindow time.Duration // how long config drift must persist before it is an incident
+	driftChecks int           // consecutive checks config drift must be seen in (0 = drift detection off)
+	driftMu     sync.Mutex
+	drift       configDrift
+
+	detectionMu   sync.RWMutex
+	lastDetection time.Time // when the most recent incident was raised
+
+	latencyMu      sync.RWMutex
+	latencyAlpha   float64       // weight of each new probe in the EMA, in (0, 1]
+	latencyEMA     float64       // moving average of health probe latency, in nanoseconds
+	lastLatency    time.Duration // latency of the most recent answered probe
+	latencySamples int
 }
 
+// defaultIncidentBuffer is how many detected incidents can queue before new ones are dropped
+const defaultIncidentBuffer = 10
+
 // NewIncidentDetector creates a new incident detector
-func NewIncidentDetector(serviceURL string, checkInterval time.Duration) *IncidentDetector {
-	return &IncidentDetector{
-		serviceURL:      serviceURL,
-		checkInterval:   checkInterval,
-		incidentChannel: make(chan *models.Incident, 10),
-		stopChannel:     make(chan bool),
-		isRunning:       false,
+func NewIncidentDetector(serviceURL string, checkInterval time.Duration, opts ...Option) *IncidentDetector {
+	baseURL, socketPath := parseServiceURL(serviceURL)
+	id := &IncidentDetector{
+		serviceURL:        baseURL,
+		socketPath:        socketPath,
+		checkInterval:     checkInterval,
+		stopChannel:       make(chan bool),
+		probeNow:          make(chan struct{}, 1),
+		isRunning:         false,
+		incidentBuffer:    defaultIncidentBuffer,
+		historyWindow:     defaultHistoryWindow,
+		impactSamples:     defaultImpactSamples,
+		impactInterval:    defaultImpactInterval,
+		classifier:        HeuristicClassifier{},
+		latencyAlpha:      defaultLatencyAlpha,
+		degradedMode:      DegradedWarn,
+		random:            rand.Float64,
+		ids:               models.UUIDGenerator{},
+		driftWindow:       defaultDriftWindow,
+		correlationWindow: defaultCorrelationWindow,
+		endpointFailures:  make(map[string]endpointFailure),
+		transport:         NewTransport(),
+	}
+
+	for _, opt := range opts {
+		opt(id)
 	}
+	if id.socketPath != "" {
+		id.transport = unixTransport(id.transport, id.socketPath)
+	}
+
+	id.incidentChannel = make(chan *models.Incident, id.incidentBuffer)
+	return id
 }
 
 // Start begins monitoring
@@ -41,7 +142,11 @@ func (id *IncidentDetector) Start(ctx context.Context) {
 	}
 
 	id.isRunning = true
-	log.Printf("[MONITOR] Started monitoring %s (interval: %v)\n", id.serviceURL, id.checkInterval)
+	if id.warmup > 0 {
+		log.Printf("[MONITOR] Started monitoring %s (interval: %v, warm-up: %v)\n", id.target(), id.checkInterval, id.warmup)
+	} else {
+		log.Printf("[MONITOR] Started monitoring %s (interval: %v)\n", id.target(), id.checkInterval)
+	}
 
 	go id.monitorLoop(ctx)
 }
@@ -62,11 +167,88 @@ func (id *IncidentDetector) GetIncidentChannel() <-chan *models.Incident {
 	return id.incidentChannel
 }
 
+// QueueDepth returns how many detected incidents are waiting to be processed, and the queue's capacity
+func (id *IncidentDetector) QueueDepth() (int, int) {
+	return len(id.incidentChannel), cap(id.incidentChannel)
+}
+
+// Dropped returns how many incidents were dropped because the incident queue was full
+func (id *IncidentDetector) Dropped() int64 {
+	return id.dropped.Load()
+}
+
+// LastDetection returns when the most recent incident was raised (zero if none yet)
+func (id *IncidentDetector) LastDetection() time.Time {
+	id.detectionMu.RLock()
+	defer id.detectionMu.RUnlock()
+	return id.lastDetection
+}
+
+// raise records the detection time and queues the incident for processing. With impact
+// sampling, the samples are taken in the background and the incident is queued once they
+// are in, so health checks keep running meanwhile. The monitor loop never waits on a full
+// queue either: the incident is logged and dropped instead.
+func (id *IncidentDetector) raise(incident *models.Incident) {
+	id.raiseAfter(incident, nil)
+}
+
+// raiseHealthFailure raises the incident for a failed health check. With dependencies
+// between composite health endpoints, it is attributed to its root cause first, in the
+// background like impact sampling (see WithDependencies).
+func (id *IncidentDetector) raiseHealthFailure(incident *models.Incident) {
+	if len(id.dependsOn) == 0 || len(id.healthEndpoints) == 0 {
+		id.raise(incident)
+		return
+	}
+	id.raiseAfter(incident, id.correlate)
+}
+
+// raiseAfter raises the incident once prepare, if any, has completed it
+func (id *IncidentDetector) raiseAfter(incident *models.Incident, prepare func(*models.Incident)) {
+	id.detectionMu.Lock()
+	id.lastDetection = incident.DetectedAt
+	id.detectionMu.Unlock()
+
+	if id.impactSamples <= 0 && prepare == nil {
+		id.queue(incident)
+		return
+	}
+	go func() {
+		if id.impactSamples > 0 {
+			id.addImpact(incident)
+		}
+		if prepare != nil {
+			prepare(incident)
+		}
+		id.queue(incident)
+	}()
+}
+
+// queue hands an incident to the orchestrator without waiting on a full queue, and
+// reports whether it was queued
+func (id *IncidentDetector) queue(incident *models.Incident) bool {
+	ctx := telemetry.WithCorrelationID(context.Background(), incident.CorrelationID)
+	select {
+	case id.incidentChannel <- incident:
+		telemetry.Logf(ctx, "[MONITOR] 📤 Raised %s incident %s\n", incident.Type, incident.ID)
+		return true
+	default:
+		id.dropped.Add(1)
+		telemetry.Logf(ctx, "[MONITOR] ⚠️  Incident queue full (%d), dropping %s incident %s\n", cap(id.incidentChannel), incident.Type, incident.ID)
+		return false
+	}
+}
+
 func (id *IncidentDetector) monitorLoop(ctx context.Context) {
-	ticker := time.NewTicker(id.checkInterval)
-	defer ticker.Stop()
+	// A timer re-armed every probe rather than a ticker, so each wait can be jittered
+	timer := time.NewTimer(id.nextProbeDelay())
+	defer timer.Stop()
 
+	warmupEnds := time.Now().Add(id.warmup)
 	previousHealthy := true
+	previousDegraded := false
+	suppressing := false
+	flapReported := false
 
 	for {
 		select {
@@ -78,153 +260,442 @@ func (id *IncidentDetector) monitorLoop(ctx context.Context) {
 			log.Println("[MONITOR] Stopped")
 			return
 
-		case <-ticker.C:
+		case <-id.probeNow:
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(0)
+
+		case <-timer.C:
+			// A failure the orchestrator already handles, e.g. a relapse during the soak,
+			// isn't a healthy-to-unhealthy transition
+			if id.failureHandled.Swap(false) {
+				previousHealthy = false
+			}
+
+			probeStarted := time.Now()
 			health := id.checkHealth()
 
+			// The wait is counted from the probe's start, and backs off while it fails
+			id.recordProbe(health.Healthy)
+			timer.Reset(id.nextProbeDelay() - time.Since(probeStarted))
+
+			// A service still starting up during the warm-up isn't an incident, and its
+			// failures don't count as flaps. Keeping previousHealthy true means a service
+			// still down once the warm-up ends is reported on the next probe.
+			if remaining := time.Until(warmupEnds); remaining > 0 {
+				if !health.Healthy && !suppressing {
+					log.Printf("[MONITOR] ⏳ Health check FAILED during warm-up (%v left) - waiting for the service to start\n", remaining.Round(time.Second))
+					suppressing = true
+				}
+				continue
+			}
+
+			// A fix restarting the service takes it down on purpose, so that downtime is
+			// neither an incident nor a flap. As with maintenance, a service still down
+			// once the fix is done is reported on the next probe.
+			if !health.Healthy && id.remediatingSince(probeStarted) {
+				if !suppressing {
+					log.Println("[MONITOR] 🔧 Health check FAILED during remediation - incident suppressed")
+					suppressing = true
+				}
+				continue
+			}
+
+			// Without an auth token the service wasn't asked at all, so its health is
+			// unknown: the token endpoint failed, which is neither an incident nor a flap
+			if health.Failure == models.FailureAuth {
+				if !suppressing {
+					log.Printf("[MONITOR] 🔑 Can't authenticate the health check - service health unknown, no incident: %s\n", health.Message)
+					suppressing = true
+				}
+				continue
+			}
+
+			id.recordHealth(HealthSample{Timestamp: time.Now(), Healthy: health.Healthy})
+
+			// A service that reports itself as still initializing isn't an incident yet
+			if !health.Healthy && id.checkReady() == readinessNotReady {
+				if !suppressing {
+					log.Println("[MONITOR] ⏳ Health check FAILED but service reports not ready - waiting for initialization")
+					suppressing = true
+				}
+				continue
+			}
+
+			// Planned downtime is logged but never turned into an incident. Keeping
+			// previousHealthy untouched means a service still down once maintenance
+			// ends is reported on the next probe.
+			if !health.Healthy && id.InMaintenance() {
+				if !suppressing {
+					log.Println("[MONITOR] 🔧 Health check FAILED during maintenance - incident suppressed")
+					suppressing = true
+				}
+				continue
+			}
+			suppressing = false
+
+			flapping, rate := id.isFlapping()
+
 			// Only trigger incident on transition from healthy to unhealthy
 			if previousHealthy && !health.Healthy {
-				log.Println("[MONITOR] ⚠️  Health check FAILED - Incident detected!")
-				incident := id.createIncident(health)
-				id.incidentChannel <- incident
+				if flapping && flapReported {
+					log.Printf("[MONITOR] 🔁 Health check FAILED while flapping (%.1f transitions/min) - incident already raised\n", rate)
+				} else if flapping {
+					log.Printf("[MONITOR] 🔁 Service is FLAPPING (%.1f transitions/min) - Incident detected!\n", rate)
+					flapReported = true
+					id.raise(id.createFlappingIncident(health, rate))
+				} else {
+					log.Println("[MONITOR] ⚠️  Health check FAILED - Incident detected!")
+					id.raiseHealthFailure(id.createIncident(health, true))
+				}
 			} else if !previousHealthy && health.Healthy {
 				log.Println("[MONITOR] ✓ Health check PASSED - Service recovered")
+				id.resetConfigBaseline()
+			}
+
+			if flapReported && !flapping {
+				log.Println("[MONITOR] Flapping has subsided")
+				flapReported = false
 			}
 
 			previousHealthy = health.Healthy
+
+			// Config drift on an unhealthy service is already covered by the health incident
+			if health.Healthy {
+				id.checkConfigDrift()
+			}
+
+			if degraded := health.State() == models.HealthDegraded; degraded != previousDegraded {
+				id.degradedChanged(health, degraded)
+				previousDegraded = degraded
+			}
 		}
 	}
 }
 
 func (id *IncidentDetector) checkHealth() models.HealthStatus {
-	client := &http.Client{
-		Timeout: 5 * time.Second,
+	start := time.Now()
+
+	var health models.HealthStatus
+	if len(id.healthEndpoints) > 0 {
+		health = id.checkAggregateHealth()
+	} else {
+		health = id.probeHealth(id.serviceURL + "/health")
+	}
+
+	// Unanswered probes only measure the client timeout, so they don't count toward latency
+	if health.StatusCode != 0 {
+		id.recordLatency(time.Since(start))
 	}
 
-	resp, err := client.Get(id.serviceURL + "/health")
+	return health
+}
+
+// probeHealth performs a single health request against the given URL
+func (id *IncidentDetector) probeHealth(url string) models.HealthStatus {
+	client := id.client(5 * time.Second)
+	if id.probeMethod == ProbeHEAD {
+		return id.probeHealthHead(client, url)
+	}
+	return id.probeHealthGet(client, url)
+}
+
+// probeHealthGet judges health by the JSON body of a GET request
+func (id *IncidentDetector) probeHealthGet(client *http.Client, url string) models.HealthStatus {
+	resp, err := id.get(context.Background(), client, url)
 	if err != nil {
 		return models.HealthStatus{
-			Healthy:   false,
-			Timestamp: time.Now(),
-			Message:   fmt.Sprintf("Health check failed: %v", err),
+			Healthy:    false,
+			Timestamp:  time.Now(),
+			Message:    fmt.Sprintf("Health check failed: %v", err),
 			StatusCode: 0,
+			Failure:    requestFailure(err),
 		}
 	}
-	defer resp.Body.Close()
+	defer closeBody(resp)
 
 	body, _ := io.ReadAll(resp.Body)
 
 	var healthStatus models.HealthStatus
-	if err := json.Unmarshal(body, &healthStatus); err != nil {
+	if id.healthCriteria.Field != "" {
+		// The criteria judge the raw body, which needn't have the HealthStatus shape
+		healthy, found, err := id.healthCriteria.healthy(body)
+		if err != nil {
+			return models.HealthStatus{
+				Healthy:    false,
+				Timestamp:  time.Now(),
+				Message:    "Failed to parse health response",
+				StatusCode: resp.StatusCode,
+				Failure:    responseFailure(resp.StatusCode),
+			}
+		}
+		_ = json.Unmarshal(body, &healthStatus) // best effort, for the message and timestamp
+		healthStatus.Healthy = healthy
+		if !healthy {
+			healthStatus.Message = found
+		}
+		if healthStatus.Timestamp.IsZero() {
+			healthStatus.Timestamp = time.Now()
+		}
+	} else if err := json.Unmarshal(body, &healthStatus); err != nil {
 		return models.HealthStatus{
-			Healthy:   false,
-			Timestamp: time.Now(),
-			Message:   "Failed to parse health response",
+			Healthy:    false,
+			Timestamp:  time.Now(),
+			Message:    "Failed to parse health response",
 			StatusCode: resp.StatusCode,
+			Failure:    responseFailure(resp.StatusCode),
 		}
 	}
 
 	healthStatus.StatusCode = resp.StatusCode
+	if !healthStatus.Healthy {
+		healthStatus.Failure = responseFailure(resp.StatusCode)
+	}
 	return healthStatus
 }
 
-func (id *IncidentDetector) createIncident(health models.HealthStatus) *models.Incident {
-	// Determine incident type and gather symptoms
-	incidentType, symptoms := id.analyzeSymptoms(health)
+// readiness is the result of a /ready probe
+type readiness int
 
-	// Fetch logs from the service
-	logs := id.fetchLogs()
+const (
+	readinessUnknown  readiness = iota // endpoint missing or unreachable
+	readinessReady                     // service is initialized
+	readinessNotReady                  // service is up but still initializing
+)
+
+// checkReady probes /ready. Only an explicit 503 counts as not ready; an unreachable
+// service is a health failure, not a readiness one.
+func (id *IncidentDetector) checkReady() readiness {
+	client := id.client(5 * time.Second)
+
+	resp, err := id.get(context.Background(), client, id.serviceURL+"/ready")
+	if err != nil {
+		return readinessUnknown
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return readinessReady
+	case http.StatusServiceUnavailable:
+		return readinessNotReady
+	default:
+		return readinessUnknown
+	}
+}
+
+// createIncident classifies an unhealthy result and builds its incident. adoptTrigger lets
+// the incident take the ID of an open injected fault; an incident that isn't that fault's
+// detection passes false so it gets an ID of its own.
+func (id *IncidentDetector) createIncident(health models.HealthStatus, adoptTrigger bool) *models.Incident {
+	ctx := incidentContext()
+
+	// One /status snapshot serves classification, logs, config and the trigger
+	status := id.fetchServiceStatus(ctx)
+
+	// Determine incident type and severity and gather symptoms
+	incidentType, symptoms, severity := id.analyzeSymptoms(ctx, health, status)
+	return id.newIncident(ctx, status, adoptTrigger, incidentType, symptoms, severity)
+}
+
+// incidentContext starts the context of a new incident, carrying the correlation ID that
+// its log lines and requests share from the first fetch on
+func incidentContext() context.Context {
+	return telemetry.WithCorrelationID(context.Background(), telemetry.NewCorrelationID())
+}
+
+// newIncident builds an incident of the given type from the service's /status response,
+// adding its logs and config. ctx carries the incident's
+// correlation ID. Each incident takes exactly one ID: the open trigger's when adoptTrigger
+// is set and there is one, otherwise a new one.
+func (id *IncidentDetector) newIncident(ctx context.Context, status map[string]interface{}, adoptTrigger bool, incidentType models.IncidentType, symptoms []string, severity models.Severity) *models.Incident {
+	logs := serviceLogs(status)
+	config := serviceConfig(status)
+
+	// An incident injected through /trigger-incident keeps the ID the trigger returned,
+	// and the log line the service marked with it is singled out for analysis
+	var triggerLog, incidentID string
+	if adoptTrigger {
+		incidentID = triggeredIncidentID(status)
+	}
+	if incidentID == "" {
+		incidentID = id.ids.NewID()
+	} else {
+		triggerLog = findTriggerLogLine(status, incidentID)
+	}
 
 	incident := &models.Incident{
-		ID:         uuid.New().String(),
-		Type:       incidentType,
-		Status:     models.StatusDetected,
-		DetectedAt: time.Now(),
-		Symptoms:   symptoms,
-		Logs:       logs,
+		ID:            incidentID,
+		Type:          incidentType,
+		Severity:      severity,
+		Status:        models.StatusDetected,
+		DetectedAt:    time.Now(),
+		Symptoms:      symptoms,
+		Logs:          logs,
+		TriggerLog:    triggerLog,
+		ServiceConfig: config,
 		UsedCachedFix: false,
+		CorrelationID: telemetry.CorrelationID(ctx),
+	}
+
+	// The config from before the incident is what a config fix reverts to
+	incident.ConfigBaseline = id.knownGoodConfig()
+
+	// Runtime profiles show what is holding the exhausted resource
+	if id.captureProfiles && incidentType == models.ResourceExhaustion {
+		incident.Profile = id.captureProfile(ctx, incidentID)
 	}
 
 	return incident
 }
 
-func (id *IncidentDetector) analyzeSymptoms(health models.HealthStatus) (models.IncidentType, []string) {
-	symptoms := []string{
-		fmt.Sprintf("Health check returned status code: %d", health.StatusCode),
-		health.Message,
+func (id *IncidentDetector) createFlappingIncident(health models.HealthStatus, rate float64) *models.Incident {
+	incident := id.createIncident(health, false) // flapping spans many faults, so never adopt a trigger's ID
+	incident.Type = models.Flapping
+	incident.Severity = models.SeverityMedium
+	incident.Symptoms = append(incident.Symptoms,
+		fmt.Sprintf("Service flapping at %.1f health transitions per minute (threshold %.1f)", rate, id.flapThreshold))
+
+	return incident
+}
+
+// analyzeSymptoms classifies an unhealthy result using the configured classifier. When the
+// built-in heuristic can only fall back to its default, the type mapped to the health
+// check's failure category is used, or else a type learned from resolved incidents with the
+// same symptoms breaks the tie.
+func (id *IncidentDetector) analyzeSymptoms(ctx context.Context, health models.HealthStatus, status map[string]interface{}) (models.IncidentType, []string, models.Severity) {
+	heuristic, ok := id.classifier.(HeuristicClassifier)
+	if !ok || (id.typeSuggester == nil && len(id.failureTypes) == 0) {
+		return id.classifier.Classify(health, status)
 	}
 
-	// Get current service status for more context
-	status := id.fetchServiceStatus()
+	incidentType, symptoms, severity, fallback := heuristic.classify(health, status)
+	if !fallback {
+		return incidentType, symptoms, severity
+	}
 
-	if config, ok := status["config"].(map[string]interface{}); ok {
-		// Check for config issues
-		if dbURL, exists := config["database_url"]; exists {
-			if str, ok := dbURL.(string); ok && (str == "invalid::url::format" || str == "") {
-				symptoms = append(symptoms, "Invalid database URL configuration detected")
-				return models.ConfigError, symptoms
-			}
-			if str, ok := dbURL.(string); ok && str == "unreachable-host:9999" {
-				symptoms = append(symptoms, "Database host unreachable")
-				return models.DependencyFailure, symptoms
-			}
-		}
-		if timeout, exists := config["timeout"]; exists {
-			if str, ok := timeout.(string); ok && str == "not-a-number" {
-				symptoms = append(symptoms, "Invalid timeout configuration detected")
-				return models.ConfigError, symptoms
-			}
-		}
+	// An operator's mapping of how the check failed outranks types learned from history
+	if mapped, ok := id.failureTypes[health.Failure]; ok && mapped != incidentType {
+		telemetry.Logf(ctx, "[MONITOR] 🗂️  Health check failure category %s maps to %s - using it instead of %s\n", health.Failure, mapped, incidentType)
+		symptoms = append(symptoms, fmt.Sprintf("Classified as %s from the %s health check failure", mapped, health.Failure))
+		return mapped, symptoms, severity
+	}
+	if id.typeSuggester == nil {
+		return incidentType, symptoms, severity
 	}
 
-	// Check if service is not running at all
-	if running, ok := status["running"].(bool); ok && !running {
-		symptoms = append(symptoms, "Service process not running")
-		return models.ServiceDown, symptoms
+	learned, confidence := id.typeSuggester.SuggestType(symptoms)
+	if learned == "" || learned == incidentType || !learned.IsValid() || confidence < id.learnedTypeConfidence {
+		return incidentType, symptoms, severity
 	}
 
-	// Check logs for resource issues
-	if logs, ok := status["recent_logs"].([]interface{}); ok && len(logs) > 0 {
-		for _, logEntry := range logs {
-			if str, ok := logEntry.(string); ok {
-				if contains(str, "resource") || contains(str, "port blocked") || contains(str, "memory") {
-					symptoms = append(symptoms, "Resource exhaustion detected in logs")
-					return models.ResourceExhaustion, symptoms
-				}
-			}
-		}
+	telemetry.Logf(ctx, "[MONITOR] 🧠 Symptoms match resolved %s incidents (%.0f%% confidence) - using it instead of %s\n",
+		learned, confidence*100, incidentType)
+	symptoms = append(symptoms, fmt.Sprintf("Classified as %s %s (%.0f%% confidence)", learned, models.LearnedTypeSymptom, confidence*100))
+	return learned, symptoms, severity
+}
+
+// serviceLogs returns the recent log lines of a /status response
+func serviceLogs(status map[string]interface{}) []string {
+	entries := parseLogEntries(status)
+
+	strLogs := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		strLogs = append(strLogs, entry.String())
+	}
+
+	return strLogs
+}
+
+// fetchConfig returns the service's current configuration, or nil if unavailable
+func (id *IncidentDetector) fetchConfig() map[string]string {
+	return serviceConfig(id.fetchServiceStatus(context.Background()))
+}
+
+// serviceConfig returns the configuration in a /status response, or nil if it has none
+func serviceConfig(status map[string]interface{}) map[string]string {
+	raw, ok := status["config"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	config := make(map[string]string, len(raw))
+	for key, value := range raw {
+		config[key] = fmt.Sprint(value)
+	}
+	return config
+}
+
+// triggeredIncidentID returns the ID of the open injected fault in a /status response, or
+// "" if there is none
+func triggeredIncidentID(status map[string]interface{}) string {
+	trigger, ok := status["triggered_incident"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	incidentID, _ := trigger["id"].(string)
+	return incidentID
+}
+
+// findTriggerLogLine returns the log line in a /status response marked with the given
+// trigger ID, or "" if it has already rotated out of the recent logs
+func findTriggerLogLine(status map[string]interface{}, triggerID string) string {
+	entry, ok := findTriggerLog(parseLogEntries(status), triggerID)
+	if !ok {
+		return ""
 	}
+	return entry.String()
+}
 
-	// Default to service down
-	symptoms = append(symptoms, "Service health check failing")
-	return models.ServiceDown, symptoms
+// findTriggerLog returns the most recent entry marked with triggerID
+func findTriggerLog(entries []models.LogEntry, triggerID string) (models.LogEntry, bool) {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].TriggerID == triggerID {
+			return entries[i], true
+		}
+	}
+	return models.LogEntry{}, false
 }
 
-func (id *IncidentDetector) fetchLogs() []string {
-	status := id.fetchServiceStatus()
+// parseLogEntries extracts structured log entries from a service status response.
+// Plain string entries from older services are treated as INFO.
+func parseLogEntries(status map[string]interface{}) []models.LogEntry {
+	rawLogs, ok := status["recent_logs"].([]interface{})
+	if !ok {
+		return []models.LogEntry{}
+	}
 
-	if logs, ok := status["recent_logs"].([]interface{}); ok {
-		strLogs := make([]string, 0, len(logs))
-		for _, log := range logs {
-			if str, ok := log.(string); ok {
-				strLogs = append(strLogs, str)
+	entries := make([]models.LogEntry, 0, len(rawLogs))
+	for _, raw := range rawLogs {
+		switch v := raw.(type) {
+		case string:
+			entries = append(entries, models.LogEntry{Level: models.LogInfo, Message: v})
+		case map[string]interface{}:
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				continue
+			}
+			var entry models.LogEntry
+			if err := json.Unmarshal(encoded, &entry); err != nil {
+				continue
 			}
+			entries = append(entries, entry)
 		}
-		return strLogs
 	}
 
-	return []string{}
+	return entries
 }
 
-func (id *IncidentDetector) fetchServiceStatus() map[string]interface{} {
-	client := &http.Client{
-		Timeout: 5 * time.Second,
-	}
+func (id *IncidentDetector) fetchServiceStatus(ctx context.Context) map[string]interface{} {
+	client := id.client(5 * time.Second)
 
-	resp, err := client.Get(id.serviceURL + "/status")
+	resp, err := id.get(ctx, client, id.serviceURL+"/status")
 	if err != nil {
 		return map[string]interface{}{}
 	}
-	defer resp.Body.Close()
+	defer closeBody(resp)
 
 	var status map[string]interface{}
 	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
@@ -234,16 +705,34 @@ func (id *IncidentDetector) fetchServiceStatus() map[string]interface{} {
 	return status
 }
 
-// VerifyResolution checks if an incident has been resolved
-func (id *IncidentDetector) VerifyResolution() bool {
-	health := id.checkHealth()
-	return health.Healthy
+// Status returns the detector's current monitoring state
+func (id *IncidentDetector) Status() map[string]interface{} {
+	flapping, _ := id.isFlapping()
+	manual, windows := id.MaintenanceStatus()
+
+	return map[string]interface{}{
+		"service_url":    id.target(),
+		"check_interval": id.checkInterval.String(),
+		"flap_rate":      id.FlapRate(),
+		"flap_threshold": id.flapThreshold,
+		"flapping":       flapping,
+		"health_history": id.HealthHistory(),
+		"degraded_mode":  id.degradedMode,
+		"latency":        id.latencyStatus(),
+		"config_drift":   id.driftStatus(),
+		"probe_backoff":  id.BackoffStatus(),
+		"maintenance": map[string]interface{}{
+			"active":  id.InMaintenance(),
+			"manual":  manual,
+			"windows": windows,
+		},
+	}
 }
 
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) &&
 		(s[:len(substr)] == substr || s[len(s)-len(substr):] == substr ||
-		len(s) > len(substr) && hasSubstring(s, substr)))
+			len(s) > len(substr) && hasSubstring(s, substr)))
 }
 
 func hasSubstring(s, substr string) bool {
@@ -0,0 +1,168 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"incident-ai/models"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// startDetector starts monitoring, stopped when the test ends
+func startDetector(t *testing.T, detector *IncidentDetector) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	detector.Start(ctx)
+	t.Cleanup(func() {
+		detector.Stop()
+		cancel()
+	})
+}
+
+// nextIncident waits up to timeout for the detector to raise an incident, returning nil
+// if it raises none
+func nextIncident(detector *IncidentDetector, timeout time.Duration) *models.Incident {
+	select {
+	case incident := <-detector.GetIncidentChannel():
+		return incident
+	case <-time.After(timeout):
+		return nil
+	}
+}
+
+func TestWarmup(t *testing.T) {
+	const warmup = 200 * time.Millisecond
+
+	tests := []struct {
+		name         string
+		readyAfter   time.Duration // 0 = never comes up
+		wantIncident bool
+	}{
+		{name: "ready within warm-up", readyAfter: warmup / 4},
+		{name: "still down after warm-up", wantIncident: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newFakeService(t, "slow-starter")
+			service.healthy.Store(false)
+
+			detector := NewIncidentDetector(service.server.URL, 5*time.Millisecond,
+				WithWarmup(warmup), WithImpactSampling(0, 0))
+			started := time.Now()
+			startDetector(t, detector)
+
+			if tt.readyAfter > 0 {
+				time.Sleep(tt.readyAfter)
+				service.healthy.Store(true)
+			}
+
+			incident := nextIncident(detector, 2*warmup)
+			if (incident != nil) != tt.wantIncident {
+				t.Fatalf("incident = %v, want one: %v", incident, tt.wantIncident)
+			}
+			if incident != nil && incident.DetectedAt.Before(started.Add(warmup)) {
+				t.Errorf("incident detected %v after start, during the warm-up", incident.DetectedAt.Sub(started))
+			}
+			if service.probes.Load() == 0 {
+				t.Error("service wasn't probed during the warm-up")
+			}
+		})
+	}
+}
+
+func TestIncidentIDs(t *testing.T) {
+	var trigger atomic.Value // ID of the open injected fault, "" for none
+	trigger.Store("")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := trigger.Load().(string); r.URL.Path == "/status" && id != "" {
+			fmt.Fprintf(w, `{"triggered_incident": {"id": %q}}`, id)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprint(w, `{"healthy": false}`)
+	}))
+	defer server.Close()
+
+	detector := NewIncidentDetector(server.URL, time.Second,
+		WithIDGenerator(models.NewSequenceGenerator("incident")), WithImpactSampling(0, 0))
+	health := detector.checkHealth()
+
+	tests := []struct {
+		name    string
+		trigger string
+		adopt   bool
+		want    string
+	}{
+		{name: "minted", adopt: true, want: "incident-1"},
+		{name: "minted again", adopt: true, want: "incident-2"},
+		{name: "injected fault", trigger: "fault-7", adopt: true, want: "fault-7"},
+		{name: "not adopting the fault", trigger: "fault-7", want: "incident-3"},
+	}
+
+	// The cases run in order, since each depends on the IDs minted before it
+	for _, tt := range tests {
+		trigger.Store(tt.trigger)
+		if got := detector.createIncident(health, tt.adopt).ID; got != tt.want {
+			t.Errorf("%s: incident ID = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	// Flapping spans many faults, so it never takes a trigger's ID
+	trigger.Store("fault-8")
+	if got := detector.createFlappingIncident(health, 10).ID; got != "incident-4" {
+		t.Errorf("flapping incident ID = %q, want incident-4", got)
+	}
+}
+
+func TestIncidentCarriesTriggerLog(t *testing.T) {
+	logs := `[
+		{"timestamp": "2026-10-16T09:00:00Z", "level": "ERROR", "message": "Service crashed - simulated failure [incident fault-1]", "trigger_id": "fault-1"},
+		{"timestamp": "2026-10-16T09:01:00Z", "level": "INFO", "message": "Service started"},
+		{"timestamp": "2026-10-16T09:02:00Z", "level": "ERROR", "message": "Configuration corrupted - invalid values detected [incident fault-2]", "trigger_id": "fault-2"},
+		{"timestamp": "2026-10-16T09:02:01Z", "level": "WARN", "message": "Retrying database connection"}
+	]`
+
+	tests := []struct {
+		name    string
+		trigger string
+		adopt   bool
+		want    string
+	}{
+		{name: "open trigger", trigger: "fault-2", adopt: true, want: "[09:02:00] ERROR Configuration corrupted - invalid values detected [incident fault-2]"},
+		{name: "trigger line rotated out", trigger: "fault-3", adopt: true},
+		{name: "not adopting the trigger", trigger: "fault-2"},
+		{name: "no trigger", adopt: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/status" {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					fmt.Fprint(w, `{"healthy": false}`)
+					return
+				}
+				trigger := "null"
+				if tt.trigger != "" {
+					trigger = fmt.Sprintf(`{"id": %q}`, tt.trigger)
+				}
+				fmt.Fprintf(w, `{"running": true, "recent_logs": %s, "triggered_incident": %s}`, logs, trigger)
+			}))
+			defer server.Close()
+
+			detector := NewIncidentDetector(server.URL, time.Second, WithImpactSampling(0, 0))
+			incident := detector.createIncident(detector.checkHealth(), tt.adopt)
+			if incident.TriggerLog != tt.want {
+				t.Errorf("trigger log = %q, want %q", incident.TriggerLog, tt.want)
+			}
+			if len(incident.Logs) != 4 {
+				t.Errorf("%d recent logs, want all of them alongside the trigger log", len(incident.Logs))
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"incident-ai/models"
+	"incident-ai/telemetry"
+	"log"
+	"maps"
+	"sort"
+	"time"
+)
+
+// defaultDriftWindow is how long config drift must persist before it becomes an incident
+const defaultDriftWindow = 30 * time.Second
+
+// configDrift tracks the service's config against its last known-good baseline
+type configDrift struct {
+	baseline map[string]string // config when the service was last known good (nil = not captured yet)
+	captured bool              // a baseline capture was attempted since startup or the last recovery
+	since    time.Time         // when the current drift was first seen (zero = no drift)
+	checks   int               // consecutive checks the current drift has been seen
+	reported bool              // an incident was raised for the current drift
+}
+
+// checkConfigDrift compares the live config to the baseline. Drift only becomes a
+// CONFIG_ERROR incident once it has persisted for the drift window and across the
+// minimum number of checks, so a mid-deploy change that corrects itself is ignored.
+// With drift detection off, the baseline is still captured for incidents to snapshot.
+func (id *IncidentDetector) checkConfigDrift() {
+	if id.driftChecks <= 0 && id.baselineCaptured() && !id.InMaintenance() {
+		return
+	}
+
+	status := id.fetchServiceStatus(context.Background())
+	changes, since, checks := id.trackDrift(serviceConfig(status))
+	if changes == nil {
+		return
+	}
+
+	ctx := incidentContext()
+	telemetry.Logf(ctx, "[MONITOR] ⚠️  Config drift persisted for %v across %d checks - Incident detected!\n",
+		time.Since(since).Round(time.Second), checks)
+
+	symptoms := append([]string{"Service config drifted from its last known-good baseline"}, changes...)
+	id.raise(id.newIncident(ctx, status, true, models.ConfigError, symptoms, models.SeverityMedium))
+}
+
+// trackDrift updates the drift state with the live config. It returns the changes once
+// drift has persisted long enough to become an incident, along with when it was first
+// seen and across how many checks, and nil changes otherwise.
+func (id *IncidentDetector) trackDrift(config map[string]string) ([]string, time.Time, int) {
+	id.driftMu.Lock()
+	defer id.driftMu.Unlock()
+	drift := &id.drift
+
+	if config == nil {
+		drift.captured = true // the service doesn't expose its config
+		return nil, time.Time{}, 0
+	}
+
+	// Config changes during maintenance are expected; whatever is in place when it
+	// ends is the new baseline
+	if drift.baseline == nil || id.InMaintenance() || id.driftChecks <= 0 {
+		id.resetDriftLocked(config)
+		return nil, time.Time{}, 0
+	}
+
+	changes := configChanges(drift.baseline, config)
+	if len(changes) == 0 {
+		switch {
+		case drift.reported:
+			log.Println("[MONITOR] ✓ Config matches its baseline again")
+		case !drift.since.IsZero():
+			log.Printf("[MONITOR] ✓ Config drift corrected itself after %v - no incident\n", time.Since(drift.since).Round(time.Second))
+		}
+		drift.since = time.Time{}
+		drift.checks = 0
+		drift.reported = false
+		return nil, time.Time{}, 0
+	}
+
+	if drift.since.IsZero() {
+		drift.since = time.Now()
+		log.Printf("[MONITOR] ⚠️  Config drift detected (%d change(s)) - waiting %v to see if it persists\n", len(changes), id.driftWindow)
+	}
+	drift.checks++
+
+	if drift.reported || drift.checks < id.driftChecks || time.Since(drift.since) < id.driftWindow {
+		return nil, time.Time{}, 0
+	}
+
+	drift.reported = true
+	return changes, drift.since, drift.checks
+}
+
+// resetConfigBaseline makes the service's current config the known-good baseline,
+// e.g. once it has recovered from an incident
+func (id *IncidentDetector) resetConfigBaseline() {
+	config := id.fetchConfig()
+	if config == nil {
+		return
+	}
+
+	id.driftMu.Lock()
+	defer id.driftMu.Unlock()
+	id.resetDriftLocked(config)
+}
+
+// resetDriftLocked replaces the baseline and clears any pending drift. The caller must hold id.driftMu.
+func (id *IncidentDetector) resetDriftLocked(config map[string]string) {
+	id.drift = configDrift{baseline: config, captured: true}
+}
+
+// baselineCaptured reports whether a baseline capture was attempted since startup or the last recovery
+func (id *IncidentDetector) baselineCaptured() bool {
+	id.driftMu.Lock()
+	defer id.driftMu.Unlock()
+	return id.drift.captured
+}
+
+// knownGoodConfig returns a copy of the config baseline, for an incident to revert to (nil = not captured)
+func (id *IncidentDetector) knownGoodConfig() map[string]string {
+	id.driftMu.Lock()
+	defer id.driftMu.Unlock()
+	return maps.Clone(id.drift.baseline)
+}
+
+// configChanges describes how config differs from baseline, sorted by key
+func configChanges(baseline, config map[string]string) []string {
+	keys := make(map[string]bool, len(baseline)+len(config))
+	for key := range baseline {
+		keys[key] = true
+	}
+	for key := range config {
+		keys[key] = true
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	var changes []string
+	for _, key := range sorted {
+		before, hadBefore := baseline[key]
+		after, hasAfter := config[key]
+		switch {
+		case !hadBefore:
+			changes = append(changes, fmt.Sprintf("Config %s added: %q", key, after))
+		case !hasAfter:
+			changes = append(changes, fmt.Sprintf("Config %s removed (was %q)", key, before))
+		case before != after:
+			changes = append(changes, fmt.Sprintf("Config %s changed: %q -> %q", key, before, after))
+		}
+	}
+	return changes
+}
+
+// driftStatus reports drift detection settings and the current drift, if any
+func (id *IncidentDetector) driftStatus() map[string]interface{} {
+	id.driftMu.Lock()
+	defer id.driftMu.Unlock()
+
+	status := map[string]interface{}{
+		"enabled":    id.driftChecks > 0,
+		"window":     id.driftWindow.String(),
+		"min_checks": id.driftChecks,
+		"drifting":   !id.drift.since.IsZero(),
+	}
+	if !id.drift.since.IsZero() {
+		status["since"] = id.drift.since
+		status["checks"] = id.drift.checks
+		status["reported"] = id.drift.reported
+	}
+	return status
+}
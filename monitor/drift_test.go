@@ -0,0 +1,204 @@
+package monitor
+
+import (
+	"encoding/json"
+	"incident-ai/models"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// configService is a fake service exposing its config on /status, as the target service does
+type configService struct {
+	server  *httptest.Server
+	healthy atomic.Bool
+	config  atomic.Value // map[string]string, replaced rather than changed
+}
+
+func newConfigService(t *testing.T, config map[string]string) *configService {
+	t.Helper()
+
+	s := &configService{}
+	s.healthy.Store(true)
+	s.config.Store(config)
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			healthy := s.healthy.Load()
+			if !healthy {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"healthy": healthy})
+		case "/status":
+			json.NewEncoder(w).Encode(map[string]interface{}{"config": s.config.Load()})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+func TestIncidentSnapshotsKnownGoodConfig(t *testing.T) {
+	good := map[string]string{"database_url": "localhost:5432", "timeout": "30s", "max_retries": "3"}
+	corrupted := map[string]string{"database_url": "invalid::url::format", "timeout": "not-a-number", "max_retries": "3"}
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		healthy  bool                // the service stays healthy once its config is corrupted
+		wantType models.IncidentType // "" = however the failure is classified
+	}{
+		{name: "health incident, drift detection off"},
+		{name: "drift incident", opts: []Option{WithConfigDrift(0, 2)}, healthy: true, wantType: models.ConfigError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newConfigService(t, good)
+			detector := NewIncidentDetector(service.server.URL, 5*time.Millisecond,
+				append([]Option{WithImpactSampling(0, 0)}, tt.opts...)...)
+			startDetector(t, detector)
+			awaitBaseline(t, detector)
+
+			service.config.Store(corrupted)
+			service.healthy.Store(tt.healthy)
+
+			incident := nextIncident(detector, 2*time.Second)
+			if incident == nil {
+				t.Fatal("no incident raised")
+			}
+			if tt.wantType != "" && incident.Type != tt.wantType {
+				t.Errorf("incident type = %s, want %s", incident.Type, tt.wantType)
+			}
+			if !maps.Equal(incident.ConfigBaseline, good) {
+				t.Errorf("incident baseline = %v, want the known-good config %v", incident.ConfigBaseline, good)
+			}
+		})
+	}
+}
+
+func TestRecoveryResetsConfigBaseline(t *testing.T) {
+	first := map[string]string{"timeout": "30s"}
+	second := map[string]string{"timeout": "45s"}
+
+	service := newConfigService(t, first)
+	detector := NewIncidentDetector(service.server.URL, 5*time.Millisecond, WithImpactSampling(0, 0))
+	startDetector(t, detector)
+	awaitBaseline(t, detector)
+
+	// The service fails, then recovers with a different config, which is the new known-good one
+	service.healthy.Store(false)
+	if incident := nextIncident(detector, 2*time.Second); incident == nil || !maps.Equal(incident.ConfigBaseline, first) {
+		t.Fatalf("first incident = %+v, want one with baseline %v", incident, first)
+	}
+	service.config.Store(second)
+	service.healthy.Store(true)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !maps.Equal(detector.knownGoodConfig(), second) {
+		if time.Now().After(deadline) {
+			t.Fatalf("baseline = %v after recovery, want %v", detector.knownGoodConfig(), second)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	service.healthy.Store(false)
+	if incident := nextIncident(detector, 2*time.Second); incident == nil || !maps.Equal(incident.ConfigBaseline, second) {
+		t.Fatalf("second incident = %+v, want one with baseline %v", incident, second)
+	}
+}
+
+// awaitBaseline waits until the detector has captured a config baseline
+func awaitBaseline(t *testing.T, detector *IncidentDetector) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for detector.knownGoodConfig() == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("no config baseline captured within 2s")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestDriftMustPersistAcrossWindowAndChecks(t *testing.T) {
+	const window = 50 * time.Millisecond
+	good := map[string]string{"timeout": "30s"}
+	deploying := map[string]string{"timeout": "45s"}
+
+	detector := NewIncidentDetector("http://127.0.0.1:1", time.Second, WithConfigDrift(window, 3))
+	if changes, _, _ := detector.trackDrift(good); changes != nil {
+		t.Fatalf("first config reported as drift: %q", changes)
+	}
+
+	// A change that reverts before the window is up is never reported
+	for i := 0; i < 2; i++ {
+		if changes, _, _ := detector.trackDrift(deploying); changes != nil {
+			t.Fatalf("drift reported after %d check(s): %q", i+1, changes)
+		}
+	}
+	if changes, _, _ := detector.trackDrift(good); changes != nil || detector.driftStatus()["drifting"] != false {
+		t.Fatalf("corrected drift = %q, status %v; want it cleared", changes, detector.driftStatus())
+	}
+
+	// Enough checks aren't enough until the window has passed, and vice versa
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if changes, _, _ := detector.trackDrift(deploying); changes != nil && time.Since(start) < window {
+			t.Fatalf("drift reported after %v, within the %v window", time.Since(start), window)
+		}
+	}
+	time.Sleep(window)
+	changes, since, checks := detector.trackDrift(deploying)
+	if len(changes) != 1 || changes[0] != `Config timeout changed: "30s" -> "45s"` {
+		t.Fatalf("persistent drift = %q, want the timeout change", changes)
+	}
+	if checks != 6 || since.Before(start) {
+		t.Errorf("drift reported across %d checks since %v, want 6 since %v", checks, since, start)
+	}
+	if changes, _, _ := detector.trackDrift(deploying); changes != nil {
+		t.Errorf("drift reported again: %q", changes)
+	}
+
+	detector = NewIncidentDetector("http://127.0.0.1:1", time.Second, WithConfigDrift(0, 3))
+	detector.trackDrift(good)
+	for i := 0; i < 3; i++ {
+		if changes, _, _ := detector.trackDrift(deploying); (changes != nil) != (i == 2) {
+			t.Errorf("check %d: drift = %q, want it reported on the third check only", i+1, changes)
+		}
+	}
+}
+
+func TestTransientDriftRaisesNoIncident(t *testing.T) {
+	good := map[string]string{"timeout": "30s"}
+	deploying := map[string]string{"timeout": "45s"}
+
+	service := newConfigService(t, good)
+	detector := NewIncidentDetector(service.server.URL, 5*time.Millisecond,
+		WithImpactSampling(0, 0), WithConfigDrift(200*time.Millisecond, 2))
+	startDetector(t, detector)
+	awaitBaseline(t, detector)
+
+	// A mid-deploy change that settles back within the window
+	service.config.Store(deploying)
+	time.Sleep(50 * time.Millisecond)
+	service.config.Store(good)
+	if incident := nextIncident(detector, 400*time.Millisecond); incident != nil {
+		t.Fatalf("transient drift raised %s incident: %v", incident.Type, incident.Symptoms)
+	}
+
+	// The same change left in place
+	service.config.Store(deploying)
+	incident := nextIncident(detector, 2*time.Second)
+	if incident == nil || incident.Type != models.ConfigError {
+		t.Fatalf("persistent drift raised %+v, want a CONFIG_ERROR incident", incident)
+	}
+	if !slices.Contains(incident.Symptoms, `Config timeout changed: "30s" -> "45s"`) {
+		t.Errorf("symptoms = %q, want the drifted key", incident.Symptoms)
+	}
+}
@@ -0,0 +1,91 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"incident-ai/models"
+	"net"
+	"slices"
+	"strings"
+)
+
+// failureDescriptions explain each health failure category in incident symptoms
+var failureDescriptions = map[models.HealthFailure]string{
+	models.FailureConnection:  "connection failed, the process may be dead",
+	models.FailureTimeout:     "timed out, the service may be hung or overloaded",
+	models.FailureServerError: "server error response, the service is up but failing or draining",
+	models.FailureClientError: "client error response, the health endpoint or credentials may be wrong",
+	models.FailureUnhealthy:   "the service reported itself unhealthy",
+	models.FailureAuth:        "no auth token for the probe, the service itself was not reached",
+}
+
+// requestFailure categorizes a health request that got no response
+func requestFailure(err error) models.HealthFailure {
+	var tokenErr *tokenError
+	if errors.As(err, &tokenErr) {
+		return models.FailureAuth
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return models.FailureTimeout
+	}
+	return models.FailureConnection
+}
+
+// responseFailure categorizes an unhealthy health response by its status code
+func responseFailure(statusCode int) models.HealthFailure {
+	switch {
+	case statusCode >= 500:
+		return models.FailureServerError
+	case statusCode >= 400:
+		return models.FailureClientError
+	default:
+		return models.FailureUnhealthy
+	}
+}
+
+// failureSymptom describes how a health check failed, or returns "" if that's unknown
+func failureSymptom(failure models.HealthFailure) string {
+	description, ok := failureDescriptions[failure]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("Health check failure category: %s (%s)", failure, description)
+}
+
+// ParseFailureTypes parses a comma-separated list of category=TYPE pairs mapping health
+// failure categories to incident types, e.g. "5xx=DEGRADED,timeout=RESOURCE_EXHAUSTION"
+func ParseFailureTypes(s string) (map[models.HealthFailure]models.IncidentType, error) {
+	types := make(map[models.HealthFailure]models.IncidentType)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		category, incidentType, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid entry %q (expected category=TYPE)", entry)
+		}
+		failure := models.HealthFailure(strings.TrimSpace(category))
+		if !slices.Contains(models.HealthFailures, failure) {
+			return nil, fmt.Errorf("unknown health failure category %q (valid: %s)", failure, joinFailures(models.HealthFailures))
+		}
+		t := models.IncidentType(strings.TrimSpace(incidentType))
+		if !t.IsValid() {
+			return nil, fmt.Errorf("unknown incident type %q for %s", t, failure)
+		}
+		types[failure] = t
+	}
+	return types, nil
+}
+
+func joinFailures(failures []models.HealthFailure) string {
+	names := make([]string, len(failures))
+	for i, failure := range failures {
+		names[i] = string(failure)
+	}
+	return strings.Join(names, ", ")
+}
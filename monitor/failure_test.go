@@ -0,0 +1,128 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"incident-ai/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHealthFailureCategories(t *testing.T) {
+	// Each category is mapped to its own type, so the incident shows which one was seen
+	failureTypes := map[models.HealthFailure]models.IncidentType{
+		models.FailureConnection:  models.ServiceDown,
+		models.FailureServerError: models.Degraded,
+		models.FailureClientError: models.ConfigError,
+		models.FailureUnhealthy:   models.DependencyFailure,
+	}
+
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		refused bool
+		want    models.HealthFailure
+	}{
+		{name: "connection refused", refused: true, want: models.FailureConnection},
+		{name: "5xx with health body", status: http.StatusServiceUnavailable, body: `{"healthy": false, "message": "draining"}`, want: models.FailureServerError},
+		{name: "5xx without body", status: http.StatusBadGateway, want: models.FailureServerError},
+		{name: "4xx", status: http.StatusNotFound, body: "404 page not found", want: models.FailureClientError},
+		{name: "reported unhealthy", status: http.StatusOK, body: `{"healthy": false, "message": "db down"}`, want: models.FailureUnhealthy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/health" {
+					http.NotFound(w, r)
+					return
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			if tt.refused {
+				server.Close()
+			} else {
+				defer server.Close()
+			}
+
+			detector := NewIncidentDetector(server.URL, time.Second,
+				WithFailureTypes(failureTypes), WithImpactSampling(0, 0))
+			health := detector.checkHealth()
+			if health.Healthy || health.Failure != tt.want {
+				t.Fatalf("health = %+v, want unhealthy with failure %s", health, tt.want)
+			}
+
+			incident := detector.createIncident(health, false)
+			if incident.Type != failureTypes[tt.want] {
+				t.Errorf("incident type = %s, want %s mapped from %s", incident.Type, failureTypes[tt.want], tt.want)
+			}
+			symptom := fmt.Sprintf("Health check failure category: %s (", tt.want)
+			if !strings.Contains(strings.Join(incident.Symptoms, "\n"), symptom) {
+				t.Errorf("symptoms don't name the category %s: %q", tt.want, incident.Symptoms)
+			}
+		})
+	}
+}
+
+// timeoutError is a net.Error that timed out
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestRequestFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want models.HealthFailure
+	}{
+		{name: "deadline", err: fmt.Errorf("get: %w", context.DeadlineExceeded), want: models.FailureTimeout},
+		{name: "net timeout", err: fmt.Errorf("get: %w", timeoutError{}), want: models.FailureTimeout},
+		{name: "refused", err: errors.New("connect: connection refused"), want: models.FailureConnection},
+		{name: "no token", err: &tokenError{errors.New("token endpoint down")}, want: models.FailureAuth},
+	}
+
+	for _, tt := range tests {
+		if got := requestFailure(tt.err); got != tt.want {
+			t.Errorf("%s: requestFailure = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseFailureTypes(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    map[models.HealthFailure]models.IncidentType
+		wantErr string
+	}{
+		{in: "", want: map[models.HealthFailure]models.IncidentType{}},
+		{in: " 5xx=DEGRADED , timeout=RESOURCE_EXHAUSTION", want: map[models.HealthFailure]models.IncidentType{
+			models.FailureServerError: models.Degraded,
+			models.FailureTimeout:     models.ResourceExhaustion,
+		}},
+		{in: "5xx", wantErr: "expected category=TYPE"},
+		{in: "3xx=DEGRADED", wantErr: "unknown health failure category"},
+		{in: "auth=SERVICE_DOWN", wantErr: "unknown health failure category"},
+		{in: "5xx=BROKEN", wantErr: "unknown incident type"},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseFailureTypes(tt.in)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseFailureTypes(%q) error = %v, want %q", tt.in, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("ParseFailureTypes(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
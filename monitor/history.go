@@ -0,0 +1,75 @@
+package monitor
+
+import (
+	"time"
+)
+
+// defaultHistoryWindow is how long health results are retained when flap detection is not configured
+const defaultHistoryWindow = 5 * time.Minute
+
+// HealthSample is a single health check result
+type HealthSample struct {
+	Timestamp time.Time `json:"timestamp"`
+	Healthy   bool      `json:"healthy"`
+}
+
+// recordHealth appends a health result and drops samples older than the history window
+func (id *IncidentDetector) recordHealth(health HealthSample) {
+	id.historyMu.Lock()
+	defer id.historyMu.Unlock()
+
+	id.history = append(id.history, health)
+
+	cutoff := health.Timestamp.Add(-id.historyWindow)
+	drop := 0
+	for drop < len(id.history) && id.history[drop].Timestamp.Before(cutoff) {
+		drop++
+	}
+	id.history = id.history[drop:]
+}
+
+// HealthHistory returns the retained health results, oldest first
+func (id *IncidentDetector) HealthHistory() []HealthSample {
+	id.historyMu.RLock()
+	defer id.historyMu.RUnlock()
+
+	history := make([]HealthSample, len(id.history))
+	copy(history, id.history)
+	return history
+}
+
+// FlapRate returns the number of healthy/unhealthy transitions per minute
+// across the retained health history
+func (id *IncidentDetector) FlapRate() float64 {
+	id.historyMu.RLock()
+	defer id.historyMu.RUnlock()
+
+	if len(id.history) < 2 {
+		return 0
+	}
+
+	transitions := 0
+	for i := 1; i < len(id.history); i++ {
+		if id.history[i].Healthy != id.history[i-1].Healthy {
+			transitions++
+		}
+	}
+
+	// Measure over at least a minute so a couple of quick samples don't look like a storm
+	span := id.history[len(id.history)-1].Timestamp.Sub(id.history[0].Timestamp)
+	if span < time.Minute {
+		span = time.Minute
+	}
+
+	return float64(transitions) / span.Minutes()
+}
+
+// isFlapping reports whether the flap rate currently exceeds the configured threshold
+func (id *IncidentDetector) isFlapping() (bool, float64) {
+	if id.flapThreshold <= 0 {
+		return false, 0
+	}
+
+	rate := id.FlapRate()
+	return rate > id.flapThreshold, rate
+}
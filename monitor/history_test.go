@@ -0,0 +1,76 @@
+package monitor
+
+import (
+	"incident-ai/models"
+	"testing"
+	"time"
+)
+
+func TestFlapRate(t *testing.T) {
+	detector := NewIncidentDetector("http://127.0.0.1:1", time.Second, WithFlapDetection(2*time.Minute, 3))
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	record := func(offset time.Duration, healthy bool) {
+		detector.recordHealth(HealthSample{Timestamp: start.Add(offset), Healthy: healthy})
+	}
+
+	record(0, true)
+	if rate := detector.FlapRate(); rate != 0 {
+		t.Errorf("flap rate of one sample = %v, want 0", rate)
+	}
+
+	// 4 transitions within a few seconds count over at least a minute
+	for i, healthy := range []bool{false, true, false, true} {
+		record(time.Duration(i+1)*time.Second, healthy)
+	}
+	if rate := detector.FlapRate(); rate != 4 {
+		t.Errorf("flap rate = %v, want 4 transitions per minute", rate)
+	}
+	if flapping, _ := detector.isFlapping(); !flapping {
+		t.Error("not flapping above the threshold")
+	}
+
+	// Samples older than the window are dropped; the rest span 2 minutes with one transition
+	record(3*time.Minute, true)
+	record(4*time.Minute, false)
+	record(5*time.Minute, false)
+	if history := detector.HealthHistory(); len(history) != 3 {
+		t.Errorf("%d samples kept, want the 3 within the window", len(history))
+	}
+	if rate := detector.FlapRate(); rate != 0.5 {
+		t.Errorf("flap rate = %v, want 0.5 transitions per minute", rate)
+	}
+	if flapping, _ := detector.isFlapping(); flapping {
+		t.Error("still flapping below the threshold")
+	}
+}
+
+func TestFlappingServiceRaisesOneFlappingIncident(t *testing.T) {
+	service := newFakeService(t, "flapping")
+	detector := NewIncidentDetector(service.server.URL, 5*time.Millisecond,
+		WithFlapDetection(time.Minute, 3), WithImpactSampling(0, 0))
+	startDetector(t, detector)
+	awaitProbes(t, 2, service)
+
+	// Each failure is a new healthy-to-unhealthy transition
+	for i := 0; i < 4; i++ {
+		service.healthy.Store(false)
+		awaitProbes(t, 2, service)
+		service.healthy.Store(true)
+		awaitProbes(t, 2, service)
+	}
+
+	var types []models.IncidentType
+	for incident := nextIncident(detector, time.Second); incident != nil; incident = nextIncident(detector, 50*time.Millisecond) {
+		types = append(types, incident.Type)
+	}
+
+	// Transitions 1 and 3 are failures below the threshold; by the 5th the service is
+	// flapping, and the 7th is part of the same flapping incident
+	want := []models.IncidentType{models.ServiceDown, models.ServiceDown, models.Flapping}
+	if len(types) != len(want) || types[0] != want[0] || types[1] != want[1] || types[2] != want[2] {
+		t.Errorf("incidents raised: %v, want %v", types, want)
+	}
+	if status := detector.Status(); status["flapping"] != true || status["flap_rate"].(float64) <= 3 {
+		t.Errorf("status = flapping %v at %v transitions/min, want flapping above 3", status["flapping"], status["flap_rate"])
+	}
+}
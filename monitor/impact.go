@@ -0,0 +1,81 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"incident-ai/models"
+	"incident-ai/telemetry"
+	"time"
+)
+
+// Default impact sampling: five requests spread over one second
+const (
+	defaultImpactSamples  = 5
+	defaultImpactInterval = 200 * time.Millisecond
+)
+
+// ImpactSample is the result of probing the service's API while it is unhealthy
+type ImpactSample struct {
+	Requests     int
+	Failures     int
+	Duration     time.Duration
+	FailuresPerS float64 // failed requests per second
+	Score        float64 // fraction of requests that failed (0-1)
+}
+
+// addImpact estimates an incident's blast radius from how many API requests are failing,
+// recording the impact score and a symptom. It takes the whole sampling period, so the
+// monitor loop calls it off its own goroutine.
+func (id *IncidentDetector) addImpact(incident *models.Incident) {
+	if id.impactSamples <= 0 {
+		return
+	}
+
+	ctx := telemetry.WithCorrelationID(context.Background(), incident.CorrelationID)
+	impact := id.sampleImpact(ctx)
+	incident.ImpactScore = impact.Score
+	incident.Symptoms = append(incident.Symptoms, fmt.Sprintf("Requests failing at %.1f/sec (%d/%d sampled requests failed)",
+		impact.FailuresPerS, impact.Failures, impact.Requests))
+}
+
+// sampleImpact hits /api/data a fixed number of times and measures how many requests fail
+func (id *IncidentDetector) sampleImpact(ctx context.Context) ImpactSample {
+	sample := ImpactSample{}
+	if id.impactSamples <= 0 {
+		return sample
+	}
+
+	client := id.client(2 * time.Second)
+
+	start := time.Now()
+	for i := 0; i < id.impactSamples; i++ {
+		if i > 0 {
+			time.Sleep(id.impactInterval)
+		}
+
+		sample.Requests++
+		resp, err := id.get(ctx, client, id.serviceURL+"/api/data")
+		if err != nil {
+			sample.Failures++
+			continue
+		}
+		closeBody(resp)
+
+		if resp.StatusCode >= 400 {
+			sample.Failures++
+		}
+	}
+	sample.Duration = time.Since(start)
+
+	// Measure over at least one interval so a single quick sample doesn't inflate the rate
+	window := sample.Duration
+	if window < id.impactInterval {
+		window = id.impactInterval
+	}
+	if window > 0 {
+		sample.FailuresPerS = float64(sample.Failures) / window.Seconds()
+	}
+	sample.Score = float64(sample.Failures) / float64(sample.Requests)
+
+	return sample
+}
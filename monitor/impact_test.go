@@ -0,0 +1,106 @@
+package monitor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newFailingAPI starts a service whose health check fails and whose /api/data fails every
+// failEvery-th request (0 = never), stopped when the test ends
+func newFailingAPI(t *testing.T, failEvery int64) *httptest.Server {
+	t.Helper()
+
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/data":
+			if n := requests.Add(1); failEvery > 0 && n%failEvery == 0 {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.Write([]byte(`{"data": "ok"}`))
+		case "/ready":
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSampleImpact(t *testing.T) {
+	const (
+		samples  = 4
+		interval = 10 * time.Millisecond
+	)
+
+	tests := []struct {
+		name         string
+		failEvery    int64 // 0 = no errors; -1 = service unreachable
+		wantFailures int
+	}{
+		{name: "no errors", failEvery: 0, wantFailures: 0},
+		{name: "every other request", failEvery: 2, wantFailures: 2},
+		{name: "every request", failEvery: 1, wantFailures: 4},
+		{name: "unreachable", failEvery: -1, wantFailures: 4},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := newFailingAPI(t, max(tt.failEvery, 0))
+			if tt.failEvery < 0 {
+				server.Close()
+			}
+
+			detector := NewIncidentDetector(server.URL, time.Second, WithImpactSampling(samples, interval))
+			impact := detector.sampleImpact(context.Background())
+
+			if impact.Requests != samples || impact.Failures != tt.wantFailures {
+				t.Fatalf("%d/%d requests failed, want %d/%d", impact.Failures, impact.Requests, tt.wantFailures, samples)
+			}
+			if want := float64(tt.wantFailures) / samples; impact.Score != want {
+				t.Errorf("score = %v, want %v", impact.Score, want)
+			}
+			if impact.Duration < (samples-1)*interval {
+				t.Errorf("sampling took %v, want the samples spread %v apart", impact.Duration, interval)
+			}
+			if want := float64(tt.wantFailures) / impact.Duration.Seconds(); impact.FailuresPerS != want {
+				t.Errorf("failure rate = %.1f/sec, want %.1f/sec", impact.FailuresPerS, want)
+			}
+		})
+	}
+}
+
+func TestIncidentCarriesImpact(t *testing.T) {
+	server := newFailingAPI(t, 2)
+
+	detector := NewIncidentDetector(server.URL, 5*time.Millisecond, WithImpactSampling(4, 5*time.Millisecond))
+	startDetector(t, detector)
+
+	incident := nextIncident(detector, 2*time.Second)
+	if incident == nil {
+		t.Fatal("no incident raised")
+	}
+	if incident.ImpactScore != 0.5 {
+		t.Errorf("impact score = %v, want 0.5", incident.ImpactScore)
+	}
+	if !strings.Contains(strings.Join(incident.Symptoms, "\n"), "Requests failing at") {
+		t.Errorf("symptoms %q don't report the failing requests", incident.Symptoms)
+	}
+
+	// Without sampling, incidents carry no impact
+	detector = NewIncidentDetector(server.URL, 5*time.Millisecond, WithImpactSampling(0, 0))
+	startDetector(t, detector)
+	if incident := nextIncident(detector, 2*time.Second); incident == nil || incident.ImpactScore != 0 {
+		t.Errorf("incident without sampling = %+v, want no impact score", incident)
+	}
+}
@@ -0,0 +1,16 @@
+package monitor
+
+import "time"
+
+// nextProbeDelay returns the wait before the next health probe: the check interval, or
+// the backed-off interval while the service keeps failing, shifted by a random amount of
+// up to ±probeJitter of it
+func (id *IncidentDetector) nextProbeDelay() time.Duration {
+	interval := id.probeInterval()
+	if id.probeJitter <= 0 {
+		return interval
+	}
+
+	offset := (2*id.random() - 1) * id.probeJitter
+	return time.Duration(float64(interval) * (1 + offset))
+}
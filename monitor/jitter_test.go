@@ -0,0 +1,100 @@
+package monitor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNextProbeDelay(t *testing.T) {
+	const interval = 100 * time.Millisecond
+
+	tests := []struct {
+		name   string
+		jitter float64
+		random float64
+		want   time.Duration
+	}{
+		{name: "earliest", jitter: 0.2, random: 0, want: 80 * time.Millisecond},
+		{name: "centre", jitter: 0.2, random: 0.5, want: interval},
+		{name: "late", jitter: 0.2, random: 0.75, want: 110 * time.Millisecond},
+		{name: "no jitter", jitter: 0, random: 0.9, want: interval},
+		{name: "fraction of 1 ignored", jitter: 1, random: 0, want: interval},
+		{name: "negative fraction ignored", jitter: -0.2, random: 0, want: interval},
+	}
+
+	for _, tt := range tests {
+		detector := NewIncidentDetector("http://127.0.0.1:1", interval, WithProbeJitter(tt.jitter))
+		detector.random = func() float64 { return tt.random }
+		if got := detector.nextProbeDelay(); got != tt.want {
+			t.Errorf("%s: delay = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Jitter applies to the backed-off interval too
+	detector := NewIncidentDetector("http://127.0.0.1:1", interval, WithProbeJitter(0.2), WithProbeBackoff(time.Minute))
+	detector.random = func() float64 { return 0 }
+	detector.backoff.failures = 3
+	if got := detector.nextProbeDelay(); got != 320*time.Millisecond {
+		t.Errorf("backed-off delay = %v, want 400ms less 20%%", got)
+	}
+}
+
+func TestNextProbeDelayVariesWithinBounds(t *testing.T) {
+	const interval = 100 * time.Millisecond
+	detector := NewIncidentDetector("http://127.0.0.1:1", interval, WithProbeJitter(0.2))
+
+	shortest, longest := time.Hour, time.Duration(0)
+	for i := 0; i < 1000; i++ {
+		delay := detector.nextProbeDelay()
+		if delay < 80*time.Millisecond || delay > 120*time.Millisecond {
+			t.Fatalf("delay %v outside ±20%% of %v", delay, interval)
+		}
+		shortest, longest = min(shortest, delay), max(longest, delay)
+	}
+	if shortest > 85*time.Millisecond || longest < 115*time.Millisecond {
+		t.Errorf("delays only spread over [%v, %v], want most of [80ms, 120ms]", shortest, longest)
+	}
+}
+
+func TestJitteredProbeTimings(t *testing.T) {
+	const interval = 20 * time.Millisecond
+
+	var mu sync.Mutex
+	var probes []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" {
+			mu.Lock()
+			probes = append(probes, time.Now())
+			mu.Unlock()
+		}
+		w.Write([]byte(`{"healthy": true}`))
+	}))
+	defer server.Close()
+
+	detector := NewIncidentDetector(server.URL, interval, WithProbeJitter(0.5))
+	startDetector(t, detector)
+	time.Sleep(25 * interval)
+	detector.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(probes) < 10 {
+		t.Fatalf("%d probes, want at least 10", len(probes))
+	}
+	shortest, longest := time.Hour, time.Duration(0)
+	for i := 1; i < len(probes); i++ {
+		gap := probes[i].Sub(probes[i-1])
+		shortest, longest = min(shortest, gap), max(longest, gap)
+	}
+
+	// Gaps are measured where probes arrive, so allow a little slack either side
+	if shortest < interval/2-5*time.Millisecond || longest > 3*interval/2+20*time.Millisecond {
+		t.Errorf("probe gaps in [%v, %v], want within ±50%% of %v", shortest, longest, interval)
+	}
+	if longest-shortest < interval/4 {
+		t.Errorf("probe gaps in [%v, %v] barely vary, want them spread by the jitter", shortest, longest)
+	}
+}
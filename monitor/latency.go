@@ -0,0 +1,45 @@
+package monitor
+
+import (
+	"time"
+)
+
+// defaultLatencyAlpha weights each new health probe in the latency EMA
+const defaultLatencyAlpha = 0.2
+
+// recordLatency folds a health probe's latency into the exponential moving average.
+// The first probe seeds the average directly so it doesn't start out skewed toward zero.
+func (id *IncidentDetector) recordLatency(latency time.Duration) {
+	id.latencyMu.Lock()
+	defer id.latencyMu.Unlock()
+
+	if id.latencySamples == 0 {
+		id.latencyEMA = float64(latency)
+	} else {
+		id.latencyEMA = id.latencyAlpha*float64(latency) + (1-id.latencyAlpha)*id.latencyEMA
+	}
+	id.latencySamples++
+	id.lastLatency = latency
+}
+
+// AverageLatency returns the exponential moving average of /health response latency
+// (zero until the service has answered a probe)
+func (id *IncidentDetector) AverageLatency() time.Duration {
+	id.latencyMu.RLock()
+	defer id.latencyMu.RUnlock()
+	return time.Duration(id.latencyEMA)
+}
+
+// latencyStatus reports the latency EMA alongside the raw latency of the last probe
+func (id *IncidentDetector) latencyStatus() map[string]interface{} {
+	id.latencyMu.RLock()
+	defer id.latencyMu.RUnlock()
+
+	return map[string]interface{}{
+		"ema":     time.Duration(id.latencyEMA).String(),
+		"ema_ms":  id.latencyEMA / float64(time.Millisecond),
+		"last":    id.lastLatency.String(),
+		"alpha":   id.latencyAlpha,
+		"samples": id.latencySamples,
+	}
+}
@@ -0,0 +1,96 @@
+package monitor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLatencyEMA(t *testing.T) {
+	detector := NewIncidentDetector("http://127.0.0.1:1", time.Second, WithLatencyAlpha(0.5))
+
+	// The first probe seeds the average; each later one moves it halfway toward its latency
+	probes := []struct {
+		latency time.Duration
+		want    time.Duration
+	}{
+		{latency: 100 * time.Millisecond, want: 100 * time.Millisecond},
+		{latency: 20 * time.Millisecond, want: 60 * time.Millisecond},
+		{latency: 20 * time.Millisecond, want: 40 * time.Millisecond},
+		{latency: 20 * time.Millisecond, want: 30 * time.Millisecond},
+		{latency: 20 * time.Millisecond, want: 25 * time.Millisecond},
+	}
+	for i, probe := range probes {
+		detector.recordLatency(probe.latency)
+		if got := detector.AverageLatency(); got != probe.want {
+			t.Errorf("average after probe %d = %v, want %v", i+1, got, probe.want)
+		}
+	}
+
+	status := detector.Status()["latency"].(map[string]interface{})
+	if status["ema"] != "25ms" || status["last"] != "20ms" || status["samples"] != 5 || status["alpha"] != 0.5 {
+		t.Errorf("latency status = %v", status)
+	}
+}
+
+func TestWithLatencyAlpha(t *testing.T) {
+	for _, tt := range []struct {
+		alpha float64
+		want  float64
+	}{
+		{alpha: 0.5, want: 0.5},
+		{alpha: 1, want: 1},
+		{alpha: 0, want: defaultLatencyAlpha},
+		{alpha: -0.3, want: defaultLatencyAlpha},
+		{alpha: 1.5, want: defaultLatencyAlpha},
+	} {
+		if got := NewIncidentDetector("http://127.0.0.1:1", time.Second, WithLatencyAlpha(tt.alpha)).latencyAlpha; got != tt.want {
+			t.Errorf("WithLatencyAlpha(%v) set alpha %v, want %v", tt.alpha, got, tt.want)
+		}
+	}
+}
+
+func TestAverageLatencyConvergesOverProbes(t *testing.T) {
+	const steady = 10 * time.Millisecond
+
+	// A slow first answer, then a steady latency
+	var probes atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if probes.Add(1) == 1 {
+			time.Sleep(20 * steady)
+		} else {
+			time.Sleep(steady)
+		}
+		w.Write([]byte(`{"healthy": true}`))
+	}))
+	t.Cleanup(server.Close)
+	detector := NewIncidentDetector(server.URL, time.Second, WithLatencyAlpha(0.5))
+
+	detector.checkHealth()
+	spike := detector.AverageLatency()
+	if spike < 20*steady {
+		t.Fatalf("average after the first probe = %v, want it seeded with the slow answer", spike)
+	}
+
+	previous := spike
+	for i := 0; i < 8; i++ {
+		detector.checkHealth()
+		average := detector.AverageLatency()
+		if previous > 2*steady && average >= previous {
+			t.Errorf("average rose from %v to %v on a steady probe", previous, average)
+		}
+		previous = average
+	}
+	if previous < steady || previous > 3*steady {
+		t.Errorf("average after 8 steady probes = %v, want close to %v", previous, steady)
+	}
+
+	// An unanswered probe measures only the client's timeout and isn't counted
+	server.Close()
+	detector.checkHealth()
+	if average := detector.AverageLatency(); average != previous {
+		t.Errorf("unanswered probe moved the average from %v to %v", previous, average)
+	}
+}
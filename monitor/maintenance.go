@@ -0,0 +1,109 @@
+package monitor
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// MaintenanceWindow is a scheduled period during which incidents are suppressed
+type MaintenanceWindow struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Contains reports whether t falls inside the window
+func (w MaintenanceWindow) Contains(t time.Time) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
+// ParseMaintenanceWindows parses a comma-separated list of RFC3339 "start/end" pairs
+func ParseMaintenanceWindows(spec string) ([]MaintenanceWindow, error) {
+	windows := []MaintenanceWindow{}
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		bounds := strings.SplitN(part, "/", 2)
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("invalid maintenance window %q: expected start/end", part)
+		}
+
+		start, err := time.Parse(time.RFC3339, strings.TrimSpace(bounds[0]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid maintenance window start %q: %w", bounds[0], err)
+		}
+
+		end, err := time.Parse(time.RFC3339, strings.TrimSpace(bounds[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid maintenance window end %q: %w", bounds[1], err)
+		}
+
+		if !end.After(start) {
+			return nil, fmt.Errorf("invalid maintenance window %q: end must be after start", part)
+		}
+
+		windows = append(windows, MaintenanceWindow{Start: start, End: end})
+	}
+
+	return windows, nil
+}
+
+// AddMaintenanceWindow schedules a maintenance window
+func (id *IncidentDetector) AddMaintenanceWindow(window MaintenanceWindow) {
+	id.maintenanceMu.Lock()
+	defer id.maintenanceMu.Unlock()
+
+	id.maintenanceWindows = append(id.maintenanceWindows, window)
+	log.Printf("[MONITOR] Scheduled maintenance window %s → %s\n",
+		window.Start.Format(time.RFC3339), window.End.Format(time.RFC3339))
+}
+
+// SetMaintenanceMode manually enables or disables maintenance mode
+func (id *IncidentDetector) SetMaintenanceMode(enabled bool) {
+	id.maintenanceMu.Lock()
+	defer id.maintenanceMu.Unlock()
+
+	id.maintenanceManual = enabled
+	if enabled {
+		log.Println("[MONITOR] 🔧 Maintenance mode ENABLED - incidents will be suppressed")
+	} else {
+		log.Println("[MONITOR] Maintenance mode disabled")
+	}
+}
+
+// MaintenanceStatus returns the manual toggle state and scheduled windows
+func (id *IncidentDetector) MaintenanceStatus() (bool, []MaintenanceWindow) {
+	id.maintenanceMu.RLock()
+	defer id.maintenanceMu.RUnlock()
+
+	windows := make([]MaintenanceWindow, len(id.maintenanceWindows))
+	copy(windows, id.maintenanceWindows)
+	return id.maintenanceManual, windows
+}
+
+// InMaintenance reports whether incidents are currently suppressed
+func (id *IncidentDetector) InMaintenance() bool {
+	return id.inMaintenanceAt(time.Now())
+}
+
+func (id *IncidentDetector) inMaintenanceAt(t time.Time) bool {
+	id.maintenanceMu.RLock()
+	defer id.maintenanceMu.RUnlock()
+
+	if id.maintenanceManual {
+		return true
+	}
+
+	for _, window := range id.maintenanceWindows {
+		if window.Contains(t) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,88 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseMaintenanceWindows(t *testing.T) {
+	start := time.Date(2025, 1, 15, 2, 0, 0, 0, time.UTC)
+	end := start.Add(2 * time.Hour)
+
+	tests := []struct {
+		spec    string
+		want    []MaintenanceWindow
+		wantErr string
+	}{
+		{spec: "", want: []MaintenanceWindow{}},
+		{spec: "2025-01-15T02:00:00Z/2025-01-15T04:00:00Z", want: []MaintenanceWindow{{Start: start, End: end}}},
+		{spec: " 2025-01-15T02:00:00Z / 2025-01-15T04:00:00Z , ", want: []MaintenanceWindow{{Start: start, End: end}}},
+		{spec: "2025-01-15T02:00:00Z", wantErr: "expected start/end"},
+		{spec: "tonight/2025-01-15T04:00:00Z", wantErr: "invalid maintenance window start"},
+		{spec: "2025-01-15T04:00:00Z/2025-01-15T02:00:00Z", wantErr: "end must be after start"},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMaintenanceWindows(tt.spec)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseMaintenanceWindows(%q) error = %v, want %q", tt.spec, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || len(got) != len(tt.want) || len(got) > 0 && got[0] != tt.want[0] {
+			t.Errorf("ParseMaintenanceWindows(%q) = %v, %v; want %v", tt.spec, got, err, tt.want)
+		}
+	}
+}
+
+func TestMaintenanceSuppressesIncidents(t *testing.T) {
+	const window = 300 * time.Millisecond
+
+	tests := []struct {
+		name  string
+		begin func(detector *IncidentDetector) // starts maintenance lasting about window
+	}{
+		{
+			name: "scheduled window",
+			begin: func(detector *IncidentDetector) {
+				now := time.Now()
+				detector.AddMaintenanceWindow(MaintenanceWindow{Start: now.Add(-time.Minute), End: now.Add(window)})
+			},
+		},
+		{
+			name: "manual toggle",
+			begin: func(detector *IncidentDetector) {
+				detector.SetMaintenanceMode(true)
+				time.AfterFunc(window, func() { detector.SetMaintenanceMode(false) })
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newFakeService(t, "maintained")
+			detector := NewIncidentDetector(service.server.URL, 5*time.Millisecond, WithImpactSampling(0, 0))
+			tt.begin(detector)
+			startDetector(t, detector)
+
+			service.healthy.Store(false)
+			awaitProbes(t, 3, service)
+			if !detector.InMaintenance() {
+				t.Fatal("maintenance ended before the failed probes")
+			}
+			if incident := nextIncident(detector, 20*time.Millisecond); incident != nil {
+				t.Fatalf("incident %s raised during maintenance", incident.ID)
+			}
+
+			// A service still down once maintenance ends is reported on the next probe
+			if incident := nextIncident(detector, window+2*time.Second); incident == nil {
+				t.Fatal("no incident after maintenance ended")
+			}
+			if detector.InMaintenance() {
+				t.Error("incident raised while still in maintenance")
+			}
+		})
+	}
+}
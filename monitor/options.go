@@ -0,0 +1,226 @@
+package monitor
+
+import (
+	"incident-ai/models"
+	"net/http"
+	"time"
+)
+
+// Option configures an IncidentDetector
+type Option func(*IncidentDetector)
+
+// WithFlapDetection enables flap detection. Health results are kept for the given window,
+// and an unhealthy transition while the flap rate exceeds threshold (transitions per minute)
+// raises a single FLAPPING incident instead of repeated crash incidents.
+func WithFlapDetection(window time.Duration, threshold float64) Option {
+	return func(id *IncidentDetector) {
+		id.historyWindow = window
+		id.flapThreshold = threshold
+	}
+}
+
+// WithImpactSampling sets how many /api/data requests are made, and how far apart,
+// to estimate an incident's impact. Zero samples disables impact estimation.
+func WithImpactSampling(samples int, interval time.Duration) Option {
+	return func(id *IncidentDetector) {
+		id.impactSamples = samples
+		id.impactInterval = interval
+	}
+}
+
+// WithWarmup gives a slow-starting service time to come up: for this long after Start,
+// health is probed but failed probes raise no incidents. A service still unhealthy when
+// the warm-up ends is reported on the next probe. A zero or negative warm-up disables it.
+func WithWarmup(warmup time.Duration) Option {
+	return func(id *IncidentDetector) {
+		if warmup > 0 {
+			id.warmup = warmup
+		}
+	}
+}
+
+// WithProfileCapture makes resource exhaustion incidents capture goroutine and heap profiles
+// from the service's /debug/pprof endpoints and attach a summary. Raw profiles are kept in
+// dir, if set.
+func WithProfileCapture(enabled bool, dir string) Option {
+	return func(id *IncidentDetector) {
+		id.captureProfiles = enabled
+		id.profileDir = dir
+	}
+}
+
+// WithHealthEndpoints probes several health URLs for one logical service and combines
+// them using mode. The detector's service URL is still used for status, logs and readiness.
+func WithHealthEndpoints(endpoints []string, mode AggregationMode) Option {
+	return func(id *IncidentDetector) {
+		id.healthEndpoints = endpoints
+		id.aggregation = mode
+	}
+}
+
+// WithDependencies declares which health endpoints of a composite service depend on which,
+// as downstream endpoint -> upstream endpoints, so a cascading failure is attributed to its
+// root cause. An incident raised for the composite waits window after detection for the
+// failures that follow, then names the earliest endpoint to fail none of whose
+// dependencies failed with it as its root cause, and the failed endpoints depending on it
+// as correlated failures. A non-positive window keeps the default.
+func WithDependencies(dependsOn map[string][]string, window time.Duration) Option {
+	return func(id *IncidentDetector) {
+		id.dependsOn = dependsOn
+		if window > 0 {
+			id.correlationWindow = window
+		}
+	}
+}
+
+// WithHealthCriteria judges health responses by criteria instead of the "healthy"
+// boolean, e.g. {Field: "status", Value: "UP"} for services that report a status string
+func WithHealthCriteria(criteria HealthCriteria) Option {
+	return func(id *IncidentDetector) {
+		id.healthCriteria = criteria
+	}
+}
+
+// WithProbeMethod sets the HTTP method of health probes. HEAD probes judge health by the
+// status code alone, so health criteria and degraded states, which are read from the
+// body, don't apply; a failed HEAD probe is followed by a GET for the message incidents
+// are classified from. GET is the default.
+func WithProbeMethod(method ProbeMethod) Option {
+	return func(id *IncidentDetector) {
+		id.probeMethod = method
+	}
+}
+
+// WithIncidentBuffer sets the capacity of the incident channel. Incidents raised while it
+// is full are logged and dropped rather than stalling the monitor loop. Negative sizes are
+// treated as 0 (unbuffered), which drops incidents raised while the orchestrator is busy.
+func WithIncidentBuffer(size int) Option {
+	return func(id *IncidentDetector) {
+		if size < 0 {
+			size = 0
+		}
+		id.incidentBuffer = size
+	}
+}
+
+// WithVerificationEndpoint makes fix verification also request a functional endpoint,
+// e.g. "/api/data", which must return 200 and, if bodyContains is set, include it in
+// the body. Paths are relative to the service URL; full URLs are used as is.
+func WithVerificationEndpoint(path, bodyContains string) Option {
+	return func(id *IncidentDetector) {
+		id.verifyPath = path
+		id.verifyBodyContains = bodyContains
+	}
+}
+
+// WithClassifier replaces the built-in heuristic used to classify detected incidents.
+// A nil classifier keeps the default.
+func WithClassifier(c Classifier) Option {
+	return func(id *IncidentDetector) {
+		if c != nil {
+			id.classifier = c
+		}
+	}
+}
+
+// WithTypeSuggester lets the built-in classifier use types learned from resolved incidents
+// when its heuristics find nothing specific: a suggestion with at least minConfidence
+// replaces the SERVICE_DOWN default. A nil suggester disables learned types.
+func WithTypeSuggester(suggester TypeSuggester, minConfidence float64) Option {
+	return func(id *IncidentDetector) {
+		id.typeSuggester = suggester
+		id.learnedTypeConfidence = minConfidence
+	}
+}
+
+// WithFailureTypes maps health failure categories to incident types, e.g. 5xx responses to
+// DEGRADED and connection failures to SERVICE_DOWN. A mapped type is used when the
+// built-in heuristic finds nothing more specific, ahead of types learned from resolved
+// incidents. Unmapped categories keep the heuristic's default.
+func WithFailureTypes(types map[models.HealthFailure]models.IncidentType) Option {
+	return func(id *IncidentDetector) {
+		id.failureTypes = types
+	}
+}
+
+// WithDegradedMode sets how a degraded health state is handled: ignored, logged as a
+// warning, or escalated as a DEGRADED incident
+func WithDegradedMode(mode DegradedMode) Option {
+	return func(id *IncidentDetector) {
+		id.degradedMode = mode
+	}
+}
+
+// WithProbeJitter spreads health probes out by varying each wait between probes by up to
+// ±fraction of the check interval, e.g. 0.2 for ±20%, so monitors sharing an interval
+// don't probe in lockstep. Fractions outside [0, 1) keep probes unjittered.
+func WithProbeJitter(fraction float64) Option {
+	return func(id *IncidentDetector) {
+		if fraction >= 0 && fraction < 1 {
+			id.probeJitter = fraction
+		}
+	}
+}
+
+// WithProbeBackoff backs health probes off while the service stays unhealthy: after the
+// failed probe that raises the incident, each further failed probe doubles the wait, up to
+// max. A healthy probe or ResetBackoff returns probes to the check interval. A max no
+// longer than the check interval leaves probes at the check interval.
+func WithProbeBackoff(max time.Duration) Option {
+	return func(id *IncidentDetector) {
+		id.backoff.max = max
+	}
+}
+
+// WithLatencyAlpha sets the smoothing factor of the health latency moving average.
+// Higher values react faster to change; values outside (0, 1] keep the default.
+func WithLatencyAlpha(alpha float64) Option {
+	return func(id *IncidentDetector) {
+		if alpha > 0 && alpha <= 1 {
+			id.latencyAlpha = alpha
+		}
+	}
+}
+
+// WithTokenProvider authenticates every request to the service with a bearer token from
+// the provider; requests to other hosts never carry it. A 401 response refreshes the token
+// and retries once. A nil provider sends requests unauthenticated.
+func WithTokenProvider(p TokenProvider) Option {
+	return func(id *IncidentDetector) {
+		id.tokens = p
+	}
+}
+
+// WithTransport replaces the detector's HTTP transport, e.g. to share one connection pool
+// with other clients or to add TLS settings. A nil transport keeps the default.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(id *IncidentDetector) {
+		if transport != nil {
+			id.transport = transport
+		}
+	}
+}
+
+// WithIDGenerator sets how the IDs of detected incidents are minted, e.g. predictably in
+// tests. Incidents raised for an injected fault keep the trigger's ID.
+func WithIDGenerator(ids models.IDGenerator) Option {
+	return func(id *IncidentDetector) {
+		if ids != nil {
+			id.ids = ids
+		}
+	}
+}
+
+// WithConfigDrift raises a CONFIG_ERROR incident when the service's config drifts from its
+// last known-good baseline, but only once the drift has persisted for window and been seen
+// in at least checks consecutive health checks, so a config change mid-deploy that corrects
+// itself is ignored. A non-positive checks disables drift detection; a negative window
+// keeps the default.
+func WithConfigDrift(window time.Duration, checks int) Option {
+	return func(id *IncidentDetector) {
+		if window >= 0 {
+			id.driftWindow = window
+		}
+		id.driftChecks = checks
+	}
+}
@@ -0,0 +1,68 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"incident-ai/models"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ProbeMethod is the HTTP method health probes use
+type ProbeMethod string
+
+const (
+	// ProbeGET reads and parses the health response body
+	ProbeGET ProbeMethod = http.MethodGet
+	// ProbeHEAD judges health by the status code alone, so healthy probes transfer no body
+	ProbeHEAD ProbeMethod = http.MethodHead
+)
+
+// ParseProbeMethod validates a probe method name, case-insensitively
+func ParseProbeMethod(s string) (ProbeMethod, error) {
+	switch method := ProbeMethod(strings.ToUpper(s)); method {
+	case ProbeGET, ProbeHEAD:
+		return method, nil
+	default:
+		return "", fmt.Errorf("unknown probe method %q (valid: %s, %s)", s, ProbeGET, ProbeHEAD)
+	}
+}
+
+// probeHealthHead derives health from the status code of a HEAD request: any 2xx is
+// healthy. An unhealthy probe is followed by a GET, since classifying the incident relies
+// on the message in the health JSON, but the GET doesn't change the verdict.
+func (id *IncidentDetector) probeHealthHead(client *http.Client, url string) models.HealthStatus {
+	resp, err := id.request(context.Background(), client, http.MethodHead, url)
+	if err != nil {
+		return models.HealthStatus{
+			Healthy:    false,
+			Timestamp:  time.Now(),
+			Message:    fmt.Sprintf("Health check failed: %v", err),
+			StatusCode: 0,
+			Failure:    requestFailure(err),
+		}
+	}
+	closeBody(resp)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return models.HealthStatus{
+			Healthy:    true,
+			Timestamp:  time.Now(),
+			Message:    "OK",
+			StatusCode: resp.StatusCode,
+		}
+	}
+
+	health := models.HealthStatus{
+		Healthy:    false,
+		Timestamp:  time.Now(),
+		Message:    fmt.Sprintf("Health check returned status %d", resp.StatusCode),
+		StatusCode: resp.StatusCode,
+		Failure:    responseFailure(resp.StatusCode),
+	}
+	if detail := id.probeHealthGet(client, url); !detail.Healthy && detail.StatusCode != 0 && detail.Message != "" {
+		health.Message = detail.Message
+	}
+	return health
+}
@@ -0,0 +1,85 @@
+package monitor
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProbeMethods(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      ProbeMethod
+		status      int
+		body        string
+		wantHealthy bool
+		wantMessage string
+		wantMethods string // requests the fake server got, in order
+	}{
+		{name: "GET healthy", method: ProbeGET, status: http.StatusOK, body: `{"healthy": true, "message": "ok"}`,
+			wantHealthy: true, wantMessage: "ok", wantMethods: "GET"},
+		{name: "GET reads the body", method: ProbeGET, status: http.StatusOK, body: `{"healthy": false, "message": "db down"}`,
+			wantMessage: "db down", wantMethods: "GET"},
+		{name: "HEAD healthy by status alone", method: ProbeHEAD, status: http.StatusOK, body: `{"healthy": false}`,
+			wantHealthy: true, wantMessage: "OK", wantMethods: "HEAD"},
+		{name: "HEAD failure fetches the message", method: ProbeHEAD, status: http.StatusServiceUnavailable, body: `{"healthy": false, "message": "draining"}`,
+			wantMessage: "draining", wantMethods: "HEAD GET"},
+		{name: "HEAD verdict stands over the GET", method: ProbeHEAD, status: http.StatusMethodNotAllowed, body: `{"healthy": true}`,
+			wantMessage: "Health check returned status 405", wantMethods: "HEAD GET"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var methods []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				methods = append(methods, r.Method)
+				mu.Unlock()
+
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			detector := NewIncidentDetector(server.URL, time.Second, WithProbeMethod(tt.method))
+			health := detector.checkHealth()
+
+			if health.Healthy != tt.wantHealthy || health.Message != tt.wantMessage || health.StatusCode != tt.status {
+				t.Errorf("health = %+v, want healthy %v, message %q, status %d", health, tt.wantHealthy, tt.wantMessage, tt.status)
+			}
+			if !tt.wantHealthy && health.Failure != responseFailure(tt.status) {
+				t.Errorf("failure = %s, want %s", health.Failure, responseFailure(tt.status))
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if got := strings.Join(methods, " "); got != tt.wantMethods {
+				t.Errorf("requests = %s, want %s", got, tt.wantMethods)
+			}
+		})
+	}
+}
+
+func TestParseProbeMethod(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    ProbeMethod
+		wantErr bool
+	}{
+		{in: "GET", want: ProbeGET},
+		{in: "head", want: ProbeHEAD},
+		{in: "POST", wantErr: true},
+		{in: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseProbeMethod(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseProbeMethod(%q) = %q, %v; want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"incident-ai/models"
+	"incident-ai/telemetry"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxTopAllocations is how many allocation sites a profile summary lists
+const maxTopAllocations = 5
+
+// maxProfileSize bounds how much of a profile is read, so a huge goroutine dump can't
+// exhaust the orchestrator's own memory
+const maxProfileSize = 16 << 20
+
+var (
+	// goroutineTotal matches the first line of a debug=1 goroutine profile
+	goroutineTotal = regexp.MustCompile(`^goroutine profile: total (\d+)`)
+	// heapRecord matches a record header of a debug=1 heap profile: in-use objects and
+	// bytes, then allocated objects and bytes. The first line has the same shape.
+	heapRecord = regexp.MustCompile(`^(?:heap profile: )?\d+: (\d+) \[\d+: \d+\] @`)
+)
+
+// captureProfile fetches the service's goroutine and heap profiles and summarizes them,
+// keeping the raw profiles in profileDir when set. It returns nil if neither profile
+// could be fetched, e.g. because the service doesn't expose /debug/pprof.
+func (id *IncidentDetector) captureProfile(ctx context.Context, incidentID string) *models.ProfileSummary {
+	client := id.client(10 * time.Second)
+	summary := &models.ProfileSummary{CapturedAt: time.Now()}
+
+	goroutines, goroutineErr := id.fetchProfile(ctx, client, "goroutine")
+	if goroutineErr == nil {
+		summary.Goroutines = parseGoroutineCount(goroutines)
+		summary.GoroutineFile = id.keepProfile(incidentID, "goroutine", goroutines)
+	}
+
+	heap, heapErr := id.fetchProfile(ctx, client, "heap")
+	if heapErr == nil {
+		summary.HeapInUseBytes, summary.TopAllocations = parseHeapProfile(heap, maxTopAllocations)
+		summary.HeapFile = id.keepProfile(incidentID, "heap", heap)
+	}
+
+	if goroutineErr != nil && heapErr != nil {
+		telemetry.Logf(ctx, "[MONITOR] ⚠️  Profile capture failed: %v\n", goroutineErr)
+		return nil
+	}
+	telemetry.Logf(ctx, "[MONITOR] 🔬 Captured profiles: %d goroutines, %d bytes of heap in use\n", summary.Goroutines, summary.HeapInUseBytes)
+	return summary
+}
+
+// fetchProfile fetches a profile in its text form (debug=1)
+func (id *IncidentDetector) fetchProfile(ctx context.Context, client *http.Client, name string) ([]byte, error) {
+	resp, err := id.get(ctx, client, id.serviceURL+"/debug/pprof/"+name+"?debug=1")
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s profile returned status %d", name, resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxProfileSize))
+}
+
+// keepProfile writes a raw profile to profileDir, returning its path, or "" when raw
+// profiles aren't kept or the write fails
+func (id *IncidentDetector) keepProfile(incidentID, name string, profile []byte) string {
+	if id.profileDir == "" {
+		return ""
+	}
+
+	path := filepath.Join(id.profileDir, fmt.Sprintf("%s-%s.txt", incidentID, name))
+	if err := os.WriteFile(path, profile, 0644); err != nil {
+		log.Printf("[MONITOR] ⚠️  Failed to keep %s profile: %v\n", name, err)
+		return ""
+	}
+	return path
+}
+
+// parseGoroutineCount returns the total from a debug=1 goroutine profile, or 0
+func parseGoroutineCount(profile []byte) int {
+	line, _, _ := strings.Cut(string(profile), "\n")
+	m := goroutineTotal.FindStringSubmatch(line)
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n
+}
+
+// parseHeapProfile returns the in-use bytes of a debug=1 heap profile and the functions
+// holding the most of them, attributing each record to the innermost frame of its stack
+func parseHeapProfile(profile []byte, top int) (int64, []models.AllocationSite) {
+	var total int64
+	byFunction := make(map[string]int64)
+
+	pending := int64(-1) // in-use bytes of the record whose stack comes next (-1 = none)
+	scanner := bufio.NewScanner(strings.NewReader(string(profile)))
+	scanner.Buffer(make([]byte, 64*1024), maxProfileSize)
+	for first := true; scanner.Scan(); first = false {
+		line := scanner.Text()
+
+		// The runtime.MemStats trailer ends the records
+		if strings.HasPrefix(line, "# runtime.MemStats") {
+			break
+		}
+
+		if m := heapRecord.FindStringSubmatch(line); m != nil {
+			bytes, _ := strconv.ParseInt(m[1], 10, 64)
+			if first {
+				total = bytes
+				continue
+			}
+			pending = bytes
+			continue
+		}
+
+		// Stack frames look like "#\t0x4a1b2c\tmain.leak+0x2c\t/src/main.go:12"
+		if pending >= 0 && strings.HasPrefix(line, "#\t") {
+			fields := strings.Split(line, "\t")
+			if len(fields) >= 3 {
+				function, _, _ := strings.Cut(fields[2], "+0x")
+				byFunction[function] += pending
+			}
+			pending = -1
+		}
+	}
+
+	sites := make([]models.AllocationSite, 0, len(byFunction))
+	for function, bytes := range byFunction {
+		if bytes > 0 {
+			sites = append(sites, models.AllocationSite{Function: function, InUseBytes: bytes})
+		}
+	}
+	sort.Slice(sites, func(i, j int) bool {
+		if sites[i].InUseBytes != sites[j].InUseBytes {
+			return sites[i].InUseBytes > sites[j].InUseBytes
+		}
+		return sites[i].Function < sites[j].Function
+	})
+	if len(sites) > top {
+		sites = sites[:top]
+	}
+
+	return total, sites
+}
@@ -0,0 +1,162 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"incident-ai/models"
+	"net/http"
+	"net/http/httptest"
+	"net/http/pprof"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// heapProfile is a debug=1 heap profile: 3 MiB in use, held by two functions, with a
+// record whose memory has all been freed
+const heapProfile = `heap profile: 2: 3145728 [10: 5242880] @ heap/1048576
+1: 2097152 [1: 2097152] @ 0x4a1b2c 0x4a1b00
+#	0x4a1b2c	main.leakCache+0x2c	/src/main.go:12
+#	0x4a1b00	main.main+0x10	/src/main.go:5
+
+1: 1048576 [4: 2097152] @ 0x4a1c00
+#	0x4a1c00	main.buffers+0x1	/src/main.go:20
+
+0: 0 [5: 1048576] @ 0x4a1d00
+#	0x4a1d00	main.freed+0x1	/src/main.go:30
+
+
+# runtime.MemStats
+# Alloc = 3145728
+`
+
+// newPprofServer serves the goroutine and heap profiles given, answering 404 for a profile
+// given as ""
+func newPprofServer(t *testing.T, goroutine, heap string) *httptest.Server {
+	t.Helper()
+
+	profiles := map[string]string{"/debug/pprof/goroutine": goroutine, "/debug/pprof/heap": heap}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		profile := profiles[r.URL.Path]
+		if profile == "" || r.URL.Query().Get("debug") != "1" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, profile)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestParseHeapProfile(t *testing.T) {
+	total, sites := parseHeapProfile([]byte(heapProfile), 5)
+	if total != 3<<20 {
+		t.Errorf("heap in use = %d, want %d", total, 3<<20)
+	}
+	want := []models.AllocationSite{
+		{Function: "main.leakCache", InUseBytes: 2 << 20},
+		{Function: "main.buffers", InUseBytes: 1 << 20},
+	}
+	if fmt.Sprint(sites) != fmt.Sprint(want) {
+		t.Errorf("allocation sites = %v, want %v, largest first without freed ones", sites, want)
+	}
+
+	if _, sites := parseHeapProfile([]byte(heapProfile), 1); len(sites) != 1 || sites[0].Function != "main.leakCache" {
+		t.Errorf("top 1 allocation sites = %v, want only main.leakCache", sites)
+	}
+	if total, sites := parseHeapProfile([]byte("not a profile"), 5); total != 0 || len(sites) != 0 {
+		t.Errorf("parsing a non-profile = %d, %v; want nothing", total, sites)
+	}
+}
+
+func TestParseGoroutineCount(t *testing.T) {
+	tests := []struct {
+		profile string
+		want    int
+	}{
+		{"goroutine profile: total 1234\n1 @ 0x1\n", 1234},
+		{"goroutine profile: total 7", 7},
+		{"404 page not found\n", 0},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseGoroutineCount([]byte(tt.profile)); got != tt.want {
+			t.Errorf("parseGoroutineCount(%q) = %d, want %d", tt.profile, got, tt.want)
+		}
+	}
+}
+
+func TestResourceIncidentCapturesProfiles(t *testing.T) {
+	tests := []struct {
+		name           string
+		goroutine      string
+		heap           string
+		incidentType   models.IncidentType
+		wantGoroutines int
+		wantHeap       int64
+		wantProfile    bool
+	}{
+		{name: "both profiles", goroutine: "goroutine profile: total 1234\n", heap: heapProfile, incidentType: models.ResourceExhaustion, wantGoroutines: 1234, wantHeap: 3 << 20, wantProfile: true},
+		{name: "heap only", heap: heapProfile, incidentType: models.ResourceExhaustion, wantHeap: 3 << 20, wantProfile: true},
+		{name: "no pprof", incidentType: models.ResourceExhaustion},
+		{name: "other incident types", goroutine: "goroutine profile: total 1234\n", heap: heapProfile, incidentType: models.ServiceDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newPprofServer(t, tt.goroutine, tt.heap)
+			dir := t.TempDir()
+			detector := NewIncidentDetector(server.URL, time.Second, WithProfileCapture(true, dir))
+
+			incident := detector.newIncident(context.Background(), nil, false, tt.incidentType, []string{"Memory usage at 98%"}, models.SeverityHigh)
+			profile := incident.Profile
+			if (profile != nil) != tt.wantProfile {
+				t.Fatalf("profile = %+v, want one: %v", profile, tt.wantProfile)
+			}
+			if profile == nil {
+				return
+			}
+
+			if profile.Goroutines != tt.wantGoroutines || profile.HeapInUseBytes != tt.wantHeap || len(profile.TopAllocations) != 2 {
+				t.Errorf("profile = %+v, want %d goroutines and %d bytes in use at 2 sites", profile, tt.wantGoroutines, tt.wantHeap)
+			}
+
+			// The raw profiles fetched are kept, named after the incident
+			if profile.HeapFile != filepath.Join(dir, incident.ID+"-heap.txt") {
+				t.Errorf("heap profile kept at %q, want in %s", profile.HeapFile, dir)
+			} else if raw, _ := os.ReadFile(profile.HeapFile); string(raw) != tt.heap {
+				t.Errorf("kept heap profile differs from the one served:\n%s", raw)
+			}
+			if (profile.GoroutineFile != "") != (tt.goroutine != "") {
+				t.Errorf("goroutine profile kept at %q, want it kept only when served", profile.GoroutineFile)
+			}
+		})
+	}
+}
+
+func TestCaptureProfileFromRuntime(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	detector := NewIncidentDetector(server.URL, time.Second, WithProfileCapture(true, ""))
+	profile := detector.captureProfile(context.Background(), "incident-1")
+	if profile == nil {
+		t.Fatal("no profile captured from net/http/pprof")
+	}
+	if profile.Goroutines == 0 || profile.HeapInUseBytes == 0 {
+		t.Errorf("profile = %+v, want the goroutine count and heap in use", profile)
+	}
+	if profile.GoroutineFile != "" || profile.HeapFile != "" {
+		t.Errorf("raw profiles kept at %q and %q without a profile dir", profile.GoroutineFile, profile.HeapFile)
+	}
+	for _, site := range profile.TopAllocations {
+		if site.Function == "" || strings.Contains(site.Function, "+0x") || site.InUseBytes <= 0 {
+			t.Errorf("malformed allocation site %+v", site)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package monitor
+
+import (
+	"fmt"
+	"incident-ai/models"
+	"testing"
+	"time"
+)
+
+func TestIncidentBuffer(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		wantSize int
+	}{
+		{name: "default", wantSize: defaultIncidentBuffer},
+		{name: "configured", opts: []Option{WithIncidentBuffer(3)}, wantSize: 3},
+		{name: "unbuffered", opts: []Option{WithIncidentBuffer(0)}, wantSize: 0},
+		{name: "negative", opts: []Option{WithIncidentBuffer(-5)}, wantSize: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector := NewIncidentDetector("http://unused", time.Second, tt.opts...)
+			if size := cap(detector.GetIncidentChannel()); size != tt.wantSize {
+				t.Fatalf("incident channel capacity = %d, want %d", size, tt.wantSize)
+			}
+
+			// The buffer fills to capacity; the next incident is dropped instead of blocking
+			for i := 0; i < tt.wantSize; i++ {
+				if !detector.queue(&models.Incident{ID: fmt.Sprint(i), Type: models.ServiceDown}) {
+					t.Fatalf("incident %d dropped with room in the buffer", i)
+				}
+			}
+			queued := make(chan bool)
+			go func() { queued <- detector.queue(&models.Incident{ID: "overflow", Type: models.ServiceDown}) }()
+			select {
+			case ok := <-queued:
+				if ok {
+					t.Error("incident queued beyond the buffer's capacity")
+				}
+			case <-time.After(time.Second):
+				t.Fatal("queueing into a full buffer blocked")
+			}
+			if dropped := detector.Dropped(); dropped != 1 {
+				t.Errorf("%d incidents dropped, want 1", dropped)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package monitor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCheckReady(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int // /ready response; 0 = service unreachable
+		want   readiness
+	}{
+		{name: "ready", status: http.StatusOK, want: readinessReady},
+		{name: "initializing", status: http.StatusServiceUnavailable, want: readinessNotReady},
+		{name: "no readiness endpoint", status: http.StatusNotFound, want: readinessUnknown},
+		{name: "unreachable", want: readinessUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			if tt.status == 0 {
+				server.Close()
+			} else {
+				defer server.Close()
+			}
+
+			detector := NewIncidentDetector(server.URL, time.Second)
+			if got := detector.checkReady(); got != tt.want {
+				t.Errorf("checkReady = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotReadyServiceRaisesNoIncidentUntilReady(t *testing.T) {
+	var ready atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ready" && ready.Load() {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		// Unhealthy throughout, and not ready until the test says so
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	detector := NewIncidentDetector(server.URL, 5*time.Millisecond, WithImpactSampling(0, 0))
+	startDetector(t, detector)
+
+	if incident := nextIncident(detector, 100*time.Millisecond); incident != nil {
+		t.Fatalf("%s incident %s raised while the service was initializing", incident.Type, incident.ID)
+	}
+
+	// Once initialized, a failing health check is an incident again
+	ready.Store(true)
+	if incident := nextIncident(detector, 2*time.Second); incident == nil {
+		t.Error("no incident raised for a ready service failing its health check")
+	}
+}
@@ -0,0 +1,43 @@
+package monitor
+
+import (
+	"sync"
+	"time"
+)
+
+// BeginRemediation tells the detector a fix is being applied or soaked, so health check
+// failures until the returned function is called are the fix's own doing, e.g. a restart,
+// or its relapse, rather than a new incident. Remediations may overlap; the returned function may be called more
+// than once.
+func (id *IncidentDetector) BeginRemediation() (end func()) {
+	id.remediationMu.Lock()
+	id.remediations++
+	id.remediationMu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			id.remediationMu.Lock()
+			defer id.remediationMu.Unlock()
+			id.remediations--
+			id.remediationEnded = time.Now()
+		})
+	}
+}
+
+// FailureHandled tells the detector the service is failing and that failure already has an
+// incident being handled, e.g. a fix that relapsed during its soak and was reopened, so the
+// next failed check isn't raised as a new incident. Call it before ending the remediation
+// that suppressed detection. Failures are reported again once the service recovers.
+func (id *IncidentDetector) FailureHandled() {
+	id.failureHandled.Store(true)
+}
+
+// remediatingSince reports whether a fix was being applied at any point since t, so a
+// probe that started during a restart isn't blamed on the service once the restart ends
+func (id *IncidentDetector) remediatingSince(t time.Time) bool {
+	id.remediationMu.Lock()
+	defer id.remediationMu.Unlock()
+
+	return id.remediations > 0 || id.remediationEnded.After(t)
+}
@@ -0,0 +1,66 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestControlledRestartRaisesNoIncident(t *testing.T) {
+	tests := []struct {
+		name         string
+		upAfterFix   bool // the service is back up when the fix ends
+		wantIncident bool
+	}{
+		{name: "back up after the restart", upAfterFix: true},
+		{name: "still down after the fix", wantIncident: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newFakeService(t, "restarting")
+			detector := NewIncidentDetector(service.server.URL, 5*time.Millisecond, WithImpactSampling(0, 0))
+			startDetector(t, detector)
+			awaitProbes(t, 2, service)
+
+			// The restart takes the service down while the fix is being applied
+			end := detector.BeginRemediation()
+			service.healthy.Store(false)
+			awaitProbes(t, 5, service)
+			service.healthy.Store(tt.upAfterFix)
+			awaitProbes(t, 2, service)
+			end()
+			end() // ending twice is harmless
+
+			incident := nextIncident(detector, 200*time.Millisecond)
+			if (incident != nil) != tt.wantIncident {
+				t.Errorf("incident = %v, want one: %v", incident, tt.wantIncident)
+			}
+		})
+	}
+}
+
+func TestRemediatingSince(t *testing.T) {
+	detector := NewIncidentDetector("http://localhost:8080", time.Second)
+	before := time.Now()
+	if detector.remediatingSince(before) {
+		t.Fatal("remediating before any fix began")
+	}
+
+	// Overlapping fixes keep detection suppressed until the last one ends
+	endFirst := detector.BeginRemediation()
+	endSecond := detector.BeginRemediation()
+	endFirst()
+	if !detector.remediatingSince(time.Now()) {
+		t.Error("not remediating while a second fix is still being applied")
+	}
+	endSecond()
+
+	// A probe that started during the fix is still covered once it has ended
+	if !detector.remediatingSince(before) {
+		t.Error("probe started during the fix not covered after it ended")
+	}
+	time.Sleep(time.Millisecond)
+	if detector.remediatingSince(time.Now()) {
+		t.Error("still remediating for a probe started after the fix ended")
+	}
+}
@@ -0,0 +1,150 @@
+package monitor
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeService is a health endpoint whose health a scenario changes
+type fakeService struct {
+	name    string
+	server  *httptest.Server
+	healthy atomic.Bool
+	probes  atomic.Int64 // /health requests served so far
+}
+
+// newFakeService starts a healthy fake service, stopped when the test ends
+func newFakeService(t *testing.T, name string) *fakeService {
+	t.Helper()
+
+	s := &fakeService{name: name}
+	s.healthy.Store(true)
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			http.NotFound(w, r)
+			return
+		}
+		s.probes.Add(1)
+
+		healthy := s.healthy.Load()
+		message := name + " is healthy"
+		if !healthy {
+			message = name + " is down"
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"healthy": healthy, "message": message})
+	}))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+// healthURL is the service's health endpoint
+func (s *fakeService) healthURL() string {
+	return s.server.URL + "/health"
+}
+
+// scenarioStep is one health change a scenario makes, at an offset from the scenario's start
+type scenarioStep struct {
+	at      time.Duration
+	service *fakeService
+	healthy bool
+}
+
+// cascadeScenario drives a cascading failure through fake services: a dependency fails,
+// each downstream service follows within window of it, and once they are all down the
+// dependency recovers, followed by the downstream services. The downstream order and
+// offsets come from seed, so a seed always produces the same cascade.
+type cascadeScenario struct {
+	dependency *fakeService
+	downstream []*fakeService
+	steps      []scenarioStep
+}
+
+// newCascadeScenario starts the fake services and plans the cascade for seed
+func newCascadeScenario(t *testing.T, seed int64, window time.Duration, downstream ...string) *cascadeScenario {
+	t.Helper()
+
+	random := rand.New(rand.NewSource(seed))
+	s := &cascadeScenario{dependency: newFakeService(t, "dependency")}
+	s.steps = append(s.steps, scenarioStep{at: 0, service: s.dependency})
+
+	var last time.Duration
+	for _, i := range random.Perm(len(downstream)) {
+		service := newFakeService(t, downstream[i])
+		s.downstream = append(s.downstream, service)
+
+		at := time.Duration(random.Int63n(int64(window))) + 1
+		s.steps = append(s.steps, scenarioStep{at: at, service: service})
+		last = max(last, at)
+	}
+	sort.SliceStable(s.steps, func(i, j int) bool { return s.steps[i].at < s.steps[j].at })
+
+	// Recovery follows the cascade's order: the dependency first, then what depends on it
+	recovery := last + window
+	s.steps = append(s.steps, scenarioStep{at: recovery, service: s.dependency, healthy: true})
+	for _, service := range s.downstream {
+		recovery += time.Duration(random.Int63n(int64(window))) + 1
+		s.steps = append(s.steps, scenarioStep{at: recovery, service: service, healthy: true})
+	}
+	return s
+}
+
+// services returns the dependency followed by the downstream services
+func (s *cascadeScenario) services() []*fakeService {
+	return append([]*fakeService{s.dependency}, s.downstream...)
+}
+
+// healthEndpoints returns the health URLs of every service in the scenario
+func (s *cascadeScenario) healthEndpoints() []string {
+	var endpoints []string
+	for _, service := range s.services() {
+		endpoints = append(endpoints, service.healthURL())
+	}
+	return endpoints
+}
+
+// run applies the steps in order, each no earlier than its offset. After each step it waits
+// until every service has answered two more probes, so whatever watches them sees every
+// state of the cascade however the timing falls.
+func (s *cascadeScenario) run(t *testing.T) {
+	t.Helper()
+
+	start := time.Now()
+	for _, step := range s.steps {
+		time.Sleep(time.Until(start.Add(step.at)))
+		step.service.healthy.Store(step.healthy)
+		s.awaitProbes(t, 2)
+	}
+}
+
+// awaitProbes waits until every service has been probed n more times
+func (s *cascadeScenario) awaitProbes(t *testing.T, n int64) {
+	t.Helper()
+	awaitProbes(t, n, s.services()...)
+}
+
+// awaitProbes waits until each of services has been probed n more times
+func awaitProbes(t *testing.T, n int64, services ...*fakeService) {
+	t.Helper()
+
+	targets := make([]int64, len(services))
+	for i, service := range services {
+		targets[i] = service.probes.Load() + n
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for i, service := range services {
+		for service.probes.Load() < targets[i] {
+			if time.Now().After(deadline) {
+				t.Fatalf("%s wasn't probed within 5s", service.name)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package monitor
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// unixHost is the host of requests sent over a Unix socket. The socket is dialed whatever
+// the host, so it only shows up in the request's Host header.
+const unixHost = "unix"
+
+// NewTransport returns an HTTP transport tuned for frequent requests to a few hosts.
+// Sharing one transport lets probes reuse keep-alive connections instead of dialing, and
+// doing a TLS handshake, every few seconds.
+func NewTransport() *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   10, // several endpoints of a composite service may share a host
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		ForceAttemptHTTP2:     true,
+	}
+}
+
+// parseServiceURL splits a unix:// service URL, e.g. unix:///var/run/app.sock, into the
+// base URL requests are built on and the socket path. Other URLs are returned unchanged.
+func parseServiceURL(serviceURL string) (baseURL, socketPath string) {
+	socketPath, ok := strings.CutPrefix(serviceURL, "unix://")
+	if !ok {
+		return serviceURL, ""
+	}
+	return "http://" + unixHost, socketPath
+}
+
+// unixTransport returns a copy of base that dials socketPath for every request, leaving
+// base itself, which may be shared with other clients, untouched
+func unixTransport(base http.RoundTripper, socketPath string) *http.Transport {
+	transport, ok := base.(*http.Transport)
+	if ok {
+		transport = transport.Clone()
+	} else {
+		log.Printf("[MONITOR] ⚠️  Custom transport can't dial Unix sockets, using the default transport for %s\n", socketPath)
+		transport = NewTransport()
+	}
+
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "unix", socketPath)
+	}
+	transport.Proxy = nil // a proxy can't reach a local socket
+	return transport
+}
+
+// target returns the monitored service as configured, for logs and status
+func (id *IncidentDetector) target() string {
+	if id.socketPath != "" {
+		return "unix://" + id.socketPath
+	}
+	return id.serviceURL
+}
+
+// client returns a client on the detector's shared transport. Clients are cheap; the
+// pooled connections live in the transport.
+func (id *IncidentDetector) client(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: id.transport,
+		Timeout:   timeout,
+	}
+}
+
+// closeBody drains and closes a response body so its connection can be reused
+func closeBody(resp *http.Response) {
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+}
@@ -0,0 +1,132 @@
+package monitor
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestProbesReuseConnections(t *testing.T) {
+	var dialed atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			w.Write([]byte(`{"healthy": true, "message": "ok"}`))
+		case "/status":
+			w.Write([]byte(`{"uptime": "1m"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			dialed.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	// Two detectors on one transport share its pool, as the detector and demo do in main
+	transport := NewTransport()
+	detectors := []*IncidentDetector{
+		NewIncidentDetector(server.URL, time.Second, WithTransport(transport)),
+		NewIncidentDetector(server.URL, time.Second, WithTransport(transport)),
+	}
+	for i := 0; i < 20; i++ {
+		detector := detectors[i%len(detectors)]
+		if health := detector.checkHealth(); !health.Healthy {
+			t.Fatalf("probe %d unhealthy: %s", i, health.Message)
+		}
+		if status := detector.fetchServiceStatus(context.Background()); status["uptime"] != "1m" {
+			t.Fatalf("status fetch %d = %v", i, status)
+		}
+	}
+
+	if n := dialed.Load(); n != 1 {
+		t.Errorf("%d connections dialed for 40 sequential requests, want one reused throughout", n)
+	}
+}
+
+func TestWithNilTransportKeepsDefault(t *testing.T) {
+	detector := NewIncidentDetector("http://127.0.0.1:1", time.Second, WithTransport(nil))
+	if detector.transport == nil {
+		t.Fatal("nil transport replaced the default")
+	}
+}
+
+func TestParseServiceURL(t *testing.T) {
+	tests := []struct {
+		url, wantBase, wantSocket string
+	}{
+		{"http://localhost:8080", "http://localhost:8080", ""},
+		{"https://orders.internal", "https://orders.internal", ""},
+		{"unix:///var/run/app.sock", "http://unix", "/var/run/app.sock"},
+	}
+
+	for _, tt := range tests {
+		if base, socket := parseServiceURL(tt.url); base != tt.wantBase || socket != tt.wantSocket {
+			t.Errorf("parseServiceURL(%q) = %q, %q; want %q, %q", tt.url, base, socket, tt.wantBase, tt.wantSocket)
+		}
+	}
+}
+
+func TestProbeOverUnixSocket(t *testing.T) {
+	// Socket paths are limited to about 100 bytes, which t.TempDir can exceed
+	dir, err := os.MkdirTemp("", "probe")
+	if err != nil {
+		t.Fatalf("MkdirTemp: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "service.sock")
+
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("Unix sockets unavailable: %v", err)
+	}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			w.Write([]byte(`{"healthy": true, "message": "ok"}`))
+		case "/status":
+			w.Write([]byte(`{"config": {"timeout": "30s"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	server.Listener.Close()
+	server.Listener = listener
+	server.Start()
+
+	tcp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer tcp.Close()
+
+	shared := NewTransport()
+	detector := NewIncidentDetector("unix://"+socket, time.Second, WithTransport(shared))
+	if health := detector.checkHealth(); !health.Healthy {
+		t.Fatalf("probe over the socket unhealthy: %s", health.Message)
+	}
+	if config := serviceConfig(detector.fetchServiceStatus(context.Background())); config["timeout"] != "30s" {
+		t.Errorf("config fetched over the socket = %v", config)
+	}
+	if target := detector.Status()["service_url"]; target != "unix://"+socket {
+		t.Errorf("status service_url = %v, want the socket URL", target)
+	}
+
+	// The shared transport still dials TCP for everyone else
+	resp, err := (&http.Client{Transport: shared, Timeout: time.Second}).Get(tcp.URL)
+	if err != nil {
+		t.Fatalf("shared transport can't reach a TCP server: %v", err)
+	}
+	closeBody(resp)
+
+	server.Close()
+	if health := detector.checkHealth(); health.Healthy {
+		t.Error("probe healthy after the socket closed")
+	}
+}
@@ -0,0 +1,85 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"incident-ai/models"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// VerifyResolution checks if an incident has been resolved. The service must pass its
+// health check and, if a verification endpoint is configured, serve that endpoint successfully.
+// When degraded health is escalated as an incident, a degraded service is not resolved either.
+func (id *IncidentDetector) VerifyResolution() bool {
+	health := id.checkHealth()
+	if !health.Healthy {
+		return false
+	}
+	if id.degradedMode == DegradedEscalate && health.State() == models.HealthDegraded {
+		log.Println("[VERIFICATION] ✗ Service is still degraded")
+		return false
+	}
+
+	if id.verifyPath == "" {
+		return true
+	}
+
+	if err := id.checkVerificationEndpoint(); err != nil {
+		log.Printf("[VERIFICATION] ✗ Verification endpoint failed: %v\n", err)
+		return false
+	}
+	return true
+}
+
+// DetectCurrent checks the service now and returns the incident its current state
+// amounts to, or nil if it is healthy. The incident always gets a new ID.
+func (id *IncidentDetector) DetectCurrent() *models.Incident {
+	health := id.checkHealth()
+	if health.Healthy {
+		return nil
+	}
+
+	// A trigger's ID and log line belong to the incident it injected, not this one
+	incident := id.createIncident(health, false)
+	id.addImpact(incident)
+	return incident
+}
+
+// checkVerificationEndpoint requests the verification endpoint and requires a 200 response
+// whose body contains verifyBodyContains, if set
+func (id *IncidentDetector) checkVerificationEndpoint() error {
+	url := id.verifyPath
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		url = id.serviceURL + url
+	}
+
+	client := id.client(5 * time.Second)
+
+	resp, err := id.get(context.Background(), client, url)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %w", url, err)
+	}
+	defer closeBody(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", url, resp.StatusCode)
+	}
+
+	if id.verifyBodyContains == "" {
+		return nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s response: %w", url, err)
+	}
+	if !strings.Contains(string(body), id.verifyBodyContains) {
+		return fmt.Errorf("%s response does not contain %q", url, id.verifyBodyContains)
+	}
+
+	return nil
+}
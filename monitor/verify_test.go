@@ -0,0 +1,69 @@
+package monitor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestVerifyResolutionWithFunctionalEndpoint(t *testing.T) {
+	tests := []struct {
+		name         string
+		healthy      bool
+		dataStatus   int
+		dataBody     string
+		path         string // verification endpoint; "" = health check only
+		bodyContains string
+		want         bool
+	}{
+		{name: "health only", healthy: true, dataStatus: http.StatusInternalServerError, want: true},
+		{name: "serving data", healthy: true, dataStatus: http.StatusOK, dataBody: `{"data": "Sample API response"}`, path: "/api/data", want: true},
+		{name: "data failing", healthy: true, dataStatus: http.StatusInternalServerError, path: "/api/data"},
+		{name: "body contains expected text", healthy: true, dataStatus: http.StatusOK, dataBody: `{"data": "Sample API response"}`, path: "/api/data", bodyContains: "Sample API", want: true},
+		{name: "body missing expected text", healthy: true, dataStatus: http.StatusOK, dataBody: `{"data": null}`, path: "/api/data", bodyContains: "Sample API"},
+		{name: "data not 200", healthy: true, dataStatus: http.StatusNoContent, path: "/api/data"},
+		{name: "unhealthy", dataStatus: http.StatusOK, path: "/api/data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/health":
+					if !tt.healthy {
+						w.WriteHeader(http.StatusServiceUnavailable)
+					}
+					json.NewEncoder(w).Encode(map[string]bool{"healthy": tt.healthy})
+				case "/api/data":
+					w.WriteHeader(tt.dataStatus)
+					w.Write([]byte(tt.dataBody))
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+
+			detector := NewIncidentDetector(server.URL, time.Second, WithVerificationEndpoint(tt.path, tt.bodyContains))
+			if got := detector.VerifyResolution(); got != tt.want {
+				t.Errorf("VerifyResolution = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerificationEndpointFullURL(t *testing.T) {
+	service := newFakeService(t, "orders")
+	functional := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/orders/recent" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer functional.Close()
+
+	detector := NewIncidentDetector(service.server.URL, time.Second, WithVerificationEndpoint(functional.URL+"/orders/recent", ""))
+	if !detector.VerifyResolution() {
+		t.Error("VerifyResolution = false, want the full URL requested as is")
+	}
+}
@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"incident-ai/models"
+	"log"
+	"strings"
+)
+
+// Message is a notification about an incident
+type Message struct {
+	IncidentID    string           `json:"incident_id"`
+	CorrelationID string           `json:"correlation_id,omitempty"` // also sent as the X-Correlation-ID header by webhooks
+	Title         string           `json:"title"`
+	Body          string           `json:"body"`
+	Incident      *models.Incident `json:"incident,omitempty"`
+}
+
+// Notifier delivers incident notifications to humans
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+// LogNotifier writes notifications to the log
+type LogNotifier struct{}
+
+// NewLogNotifier creates a notifier that logs messages
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+// Notify logs the notification
+func (n *LogNotifier) Notify(ctx context.Context, msg Message) error {
+	log.Printf("[NOTIFY] 📣 %s\n", msg.Title)
+	if msg.IncidentID != "" {
+		log.Printf("[NOTIFY]   Incident: %s\n", msg.IncidentID)
+	}
+	if msg.Incident != nil && len(msg.Incident.Metadata) > 0 {
+		log.Printf("[NOTIFY]   Service: %s\n", models.FormatMetadata(msg.Incident.Metadata))
+	}
+	for _, line := range strings.Split(msg.Body, "\n") {
+		if line != "" {
+			log.Printf("[NOTIFY]   %s\n", line)
+		}
+	}
+	return nil
+}
+
+// MultiNotifier delivers each notification to several notifiers
+type MultiNotifier struct {
+	notifiers []Notifier
+}
+
+// NewMultiNotifier creates a notifier that fans out to all of notifiers
+func NewMultiNotifier(notifiers ...Notifier) *MultiNotifier {
+	return &MultiNotifier{notifiers: notifiers}
+}
+
+// Notify delivers msg to every notifier, even if earlier ones fail, and returns their
+// combined errors
+func (n *MultiNotifier) Notify(ctx context.Context, msg Message) error {
+	var errs []error
+	for _, notifier := range n.notifiers {
+		if err := notifier.Notify(ctx, msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,96 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// ThrottledNotifier coalesces notifications for the same incident. The first message for
+// an incident is delivered immediately; further messages within the window are suppressed,
+// and when the window ends the latest one is delivered with a count of those suppressed.
+type ThrottledNotifier struct {
+	next   Notifier
+	window time.Duration
+
+	mu      sync.Mutex
+	pending map[string]*throttleState // incident ID -> open throttle window
+}
+
+// throttleState tracks one incident's open throttle window
+type throttleState struct {
+	latest     Message
+	suppressed int
+	timer      *time.Timer
+}
+
+// NewThrottledNotifier wraps next so each incident notifies at most once per window.
+// A zero window disables throttling.
+func NewThrottledNotifier(next Notifier, window time.Duration) *ThrottledNotifier {
+	return &ThrottledNotifier{
+		next:    next,
+		window:  window,
+		pending: make(map[string]*throttleState),
+	}
+}
+
+// Notify delivers msg unless another notification for the same incident was sent within the window
+func (n *ThrottledNotifier) Notify(ctx context.Context, msg Message) error {
+	if msg.IncidentID == "" || n.window <= 0 {
+		return n.next.Notify(ctx, msg)
+	}
+
+	n.mu.Lock()
+	if state, ok := n.pending[msg.IncidentID]; ok {
+		state.latest = msg
+		state.suppressed++
+		suppressed := state.suppressed
+		n.mu.Unlock()
+		log.Printf("[NOTIFY] Throttled notification for incident %s (%d suppressed)\n", msg.IncidentID, suppressed)
+		return nil
+	}
+
+	id := msg.IncidentID
+	n.pending[id] = &throttleState{
+		timer: time.AfterFunc(n.window, func() { n.flush(id) }),
+	}
+	n.mu.Unlock()
+
+	return n.next.Notify(ctx, msg)
+}
+
+// flush closes an incident's window, delivering the latest suppressed message if any
+func (n *ThrottledNotifier) flush(id string) {
+	n.mu.Lock()
+	state, ok := n.pending[id]
+	delete(n.pending, id)
+	n.mu.Unlock()
+
+	if !ok || state.suppressed == 0 {
+		return
+	}
+
+	msg := state.latest
+	msg.Body += fmt.Sprintf("\n(%d duplicate notification(s) suppressed in the last %v)\n", state.suppressed, n.window)
+
+	if err := n.next.Notify(context.Background(), msg); err != nil {
+		log.Printf("[NOTIFY] Warning: failed to send throttled notification: %v\n", err)
+	}
+}
+
+// Close delivers any suppressed notifications immediately and stops pending timers
+func (n *ThrottledNotifier) Close() {
+	n.mu.Lock()
+	ids := make([]string, 0, len(n.pending))
+	for id, state := range n.pending {
+		state.timer.Stop()
+		ids = append(ids, id)
+	}
+	n.mu.Unlock()
+
+	for _, id := range ids {
+		n.flush(id)
+	}
+}
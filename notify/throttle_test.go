@@ -0,0 +1,105 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// channelNotifier sends every message it is given on a channel
+type channelNotifier chan Message
+
+func (n channelNotifier) Notify(ctx context.Context, msg Message) error {
+	n <- msg
+	return nil
+}
+
+// receive returns the next message delivered within timeout, or false if none is
+func (n channelNotifier) receive(timeout time.Duration) (Message, bool) {
+	select {
+	case msg := <-n:
+		return msg, true
+	case <-time.After(timeout):
+		return Message{}, false
+	}
+}
+
+func TestThrottledNotifierCoalescesRepeats(t *testing.T) {
+	const window = 100 * time.Millisecond
+
+	delivered := make(channelNotifier, 32)
+	throttled := NewThrottledNotifier(delivered, window)
+	defer throttled.Close()
+
+	for i := 1; i <= 5; i++ {
+		msg := Message{IncidentID: "incident-1", Title: fmt.Sprintf("Retry %d failed", i), Body: "Verification failed\n"}
+		if err := throttled.Notify(context.Background(), msg); err != nil {
+			t.Fatalf("Notify: %v", err)
+		}
+	}
+	// Another incident and messages without one aren't throttled with it
+	throttled.Notify(context.Background(), Message{IncidentID: "incident-2", Title: "Other incident"})
+	throttled.Notify(context.Background(), Message{Title: "Budget exhausted"})
+	throttled.Notify(context.Background(), Message{Title: "Budget exhausted"})
+
+	var titles []string
+	for {
+		msg, ok := delivered.receive(window / 4)
+		if !ok {
+			break
+		}
+		titles = append(titles, msg.Title)
+	}
+	if got := strings.Join(titles, ", "); got != "Retry 1 failed, Other incident, Budget exhausted, Budget exhausted" {
+		t.Errorf("delivered during the window: %s; want the first message per incident and every one without an incident", got)
+	}
+
+	// When the window ends, the latest repeat is delivered once with the suppressed count
+	msg, ok := delivered.receive(2 * window)
+	if !ok {
+		t.Fatal("suppressed notifications never delivered")
+	}
+	if msg.Title != "Retry 5 failed" || !strings.Contains(msg.Body, "4 duplicate notification(s) suppressed") {
+		t.Errorf("throttled message = %q %q, want the latest with 4 suppressed", msg.Title, msg.Body)
+	}
+	if msg, ok := delivered.receive(2 * window); ok {
+		t.Errorf("unexpected message %q after the throttled one", msg.Title)
+	}
+
+	// The next window starts afresh
+	throttled.Notify(context.Background(), Message{IncidentID: "incident-1", Title: "Retry 6 failed"})
+	if msg, ok := delivered.receive(window / 4); !ok || msg.Title != "Retry 6 failed" {
+		t.Errorf("first message of a new window = %q, %v; want it delivered immediately", msg.Title, ok)
+	}
+}
+
+func TestThrottledNotifierCloseFlushes(t *testing.T) {
+	delivered := make(channelNotifier, 8)
+	throttled := NewThrottledNotifier(delivered, time.Hour)
+
+	throttled.Notify(context.Background(), Message{IncidentID: "incident-1", Title: "First"})
+	throttled.Notify(context.Background(), Message{IncidentID: "incident-1", Title: "Second"})
+	throttled.Close()
+
+	var titles []string
+	for len(delivered) > 0 {
+		titles = append(titles, (<-delivered).Title)
+	}
+	if got := strings.Join(titles, ", "); got != "First, Second" {
+		t.Errorf("delivered %s, want the suppressed message flushed on close", got)
+	}
+}
+
+func TestThrottledNotifierDisabled(t *testing.T) {
+	delivered := make(channelNotifier, 8)
+	throttled := NewThrottledNotifier(delivered, 0)
+
+	for i := 0; i < 3; i++ {
+		throttled.Notify(context.Background(), Message{IncidentID: "incident-1", Title: "Repeat"})
+	}
+	if len(delivered) != 3 {
+		t.Errorf("%d messages delivered with no window, want all 3", len(delivered))
+	}
+}
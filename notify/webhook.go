@@ -0,0 +1,319 @@
+package notify
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"incident-ai/telemetry"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Default webhook delivery: three retries, waiting 1s, 2s and 4s between attempts
+const (
+	defaultWebhookRetries = 3
+	defaultWebhookBackoff = 1 * time.Second
+)
+
+// WebhookNotifier posts notifications as JSON to a URL. Delivery happens in the background,
+// so retries never hold up incident handling. Failed posts are retried with exponential
+// backoff; once retries are exhausted the notification is appended to a dead-letter file,
+// if configured, so it can be replayed later.
+type WebhookNotifier struct {
+	url     string
+	client  *http.Client
+	retries int
+	backoff time.Duration
+
+	deliveries sync.WaitGroup // background deliveries in flight, waited for by Close
+
+	replayMu       sync.Mutex // one replay at a time, each owning the dead letters it read
+	deadLetterMu   sync.Mutex // guards the dead-letter file, never held across a delivery
+	deadLetterPath string     // JSON lines of undeliverable notifications (empty = dropped)
+}
+
+// WebhookOption configures a WebhookNotifier
+type WebhookOption func(*WebhookNotifier)
+
+// WithRetries sets how many times a failed post is retried, and the wait before the first
+// retry, which doubles on each further retry. Negative values keep the defaults.
+func WithRetries(retries int, backoff time.Duration) WebhookOption {
+	return func(n *WebhookNotifier) {
+		if retries >= 0 {
+			n.retries = retries
+		}
+		if backoff >= 0 {
+			n.backoff = backoff
+		}
+	}
+}
+
+// WithDeadLetterFile appends notifications that could not be delivered to path, one JSON
+// object per line, for ReplayDeadLetters
+func WithDeadLetterFile(path string) WebhookOption {
+	return func(n *WebhookNotifier) {
+		n.deadLetterPath = path
+	}
+}
+
+// deadLetter is an undeliverable notification as stored in the dead-letter file
+type deadLetter struct {
+	Message  json.RawMessage `json:"message"` // the notification as it was posted
+	Error    string          `json:"error"`
+	FailedAt time.Time       `json:"failed_at"`
+}
+
+// NewWebhookNotifier creates a notifier that posts to url
+func NewWebhookNotifier(url string, opts ...WebhookOption) *WebhookNotifier {
+	n := &WebhookNotifier{
+		url:     url,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		retries: defaultWebhookRetries,
+		backoff: defaultWebhookBackoff,
+	}
+
+	for _, opt := range opts {
+		opt(n)
+	}
+
+	return n
+}
+
+// Notify posts msg in the background, retrying failures, and returns without waiting.
+// A notification that still fails is dead-lettered, or logged without a dead-letter file.
+func (n *WebhookNotifier) Notify(ctx context.Context, msg Message) error {
+	// Encoded now: the incident keeps changing while the post is retried
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+	// The notification outlives the step that sent it, e.g. an aborted incident's cancelled context
+	ctx = telemetry.WithCorrelationID(context.WithoutCancel(ctx), msg.CorrelationID)
+
+	n.deliveries.Add(1)
+	go func() {
+		defer n.deliveries.Done()
+		n.send(ctx, payload)
+	}()
+	return nil
+}
+
+// Close waits for background deliveries, including their retries, to finish
+func (n *WebhookNotifier) Close() {
+	n.deliveries.Wait()
+}
+
+// send delivers an encoded notification, dead-lettering it if delivery fails
+func (n *WebhookNotifier) send(ctx context.Context, payload []byte) {
+	err := n.deliver(ctx, payload)
+	if err == nil {
+		return
+	}
+
+	if n.deadLetterPath == "" {
+		telemetry.Logf(ctx, "[NOTIFY] Warning: failed to send notification: %v\n", err)
+		return
+	}
+	if dlErr := n.addDeadLetter(payload, err); dlErr != nil {
+		telemetry.Logf(ctx, "[NOTIFY] Warning: failed to send notification: %v (dead-letter failed: %v)\n", err, dlErr)
+		return
+	}
+	telemetry.Logf(ctx, "[NOTIFY] ✉️  Webhook delivery failed, notification dead-lettered to %s: %v\n", n.deadLetterPath, err)
+}
+
+// deliver posts an encoded notification, retrying with exponential backoff while the
+// failure is retryable
+func (n *WebhookNotifier) deliver(ctx context.Context, payload []byte) error {
+	// Taken from the message so replayed dead letters keep their ID
+	var ids struct {
+		CorrelationID string `json:"correlation_id"`
+	}
+	if err := json.Unmarshal(payload, &ids); err == nil {
+		ctx = telemetry.WithCorrelationID(ctx, ids.CorrelationID)
+	}
+
+	wait := n.backoff
+	for attempt := 0; ; attempt++ {
+		retryable, err := n.post(ctx, payload)
+		if err == nil {
+			return nil
+		}
+		if !retryable || attempt >= n.retries {
+			return fmt.Errorf("webhook failed after %d attempt(s): %w", attempt+1, err)
+		}
+
+		log.Printf("[NOTIFY] Webhook attempt %d failed, retrying in %v: %v\n", attempt+1, wait, err)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return fmt.Errorf("webhook failed after %d attempt(s): %w", attempt+1, err)
+		}
+		wait *= 2
+	}
+}
+
+// post sends one request, reporting whether a failure is worth retrying. Network errors,
+// timeouts, rate limiting and server errors are; other rejections won't change on retry.
+func (n *WebhookNotifier) post(ctx context.Context, payload []byte) (retryable bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(payload))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if id := telemetry.CorrelationID(ctx); id != "" {
+		req.Header.Set(telemetry.CorrelationHeader, id)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		return true, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+}
+
+// ReplayDeadLetters re-sends every dead-lettered notification. Those that fail again stay
+// in the file; the rest are removed. It returns how many were delivered.
+func (n *WebhookNotifier) ReplayDeadLetters(ctx context.Context) (int, error) {
+	if n.deadLetterPath == "" {
+		return 0, fmt.Errorf("no dead-letter file configured")
+	}
+
+	n.replayMu.Lock()
+	defer n.replayMu.Unlock()
+
+	// The file isn't held during delivery: notifications that fail meanwhile are appended
+	// behind the letters read here, and kept when the file is rewritten
+	n.deadLetterMu.Lock()
+	letters, err := n.readDeadLetters()
+	n.deadLetterMu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	var remaining []deadLetter
+	for _, letter := range letters {
+		if err := ctx.Err(); err != nil {
+			remaining = append(remaining, letter)
+			continue
+		}
+		if err := n.deliver(ctx, letter.Message); err != nil {
+			letter.Error = err.Error()
+			letter.FailedAt = time.Now()
+			remaining = append(remaining, letter)
+			continue
+		}
+		delivered++
+	}
+
+	n.deadLetterMu.Lock()
+	defer n.deadLetterMu.Unlock()
+
+	current, err := n.readDeadLetters()
+	if err != nil {
+		return delivered, err
+	}
+	if len(current) > len(letters) {
+		remaining = append(remaining, current[len(letters):]...)
+	}
+	if err := n.rewriteDeadLetters(remaining); err != nil {
+		return delivered, err
+	}
+
+	log.Printf("[NOTIFY] ✉️  Replayed dead letters: %d delivered, %d still failing\n", delivered, len(remaining))
+	return delivered, ctx.Err()
+}
+
+// readDeadLetters loads the dead-letter file. A missing file has no dead letters.
+// The caller must hold n.deadLetterMu.
+func (n *WebhookNotifier) readDeadLetters() ([]deadLetter, error) {
+	file, err := os.Open(n.deadLetterPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dead-letter file: %w", err)
+	}
+	defer file.Close()
+
+	var letters []deadLetter
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024) // notifications embed the whole incident
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var letter deadLetter
+		if err := json.Unmarshal(scanner.Bytes(), &letter); err != nil {
+			return nil, fmt.Errorf("failed to parse dead-letter file: %w", err)
+		}
+		letters = append(letters, letter)
+	}
+	return letters, scanner.Err()
+}
+
+// addDeadLetter appends an undeliverable notification to the dead-letter file
+func (n *WebhookNotifier) addDeadLetter(payload []byte, deliveryErr error) error {
+	n.deadLetterMu.Lock()
+	defer n.deadLetterMu.Unlock()
+
+	return appendDeadLetters(n.deadLetterPath, []deadLetter{{Message: payload, Error: deliveryErr.Error(), FailedAt: time.Now()}})
+}
+
+// rewriteDeadLetters replaces the dead-letter file with letters, via a temporary file so
+// a crash never loses them, and removes it when none remain. The caller must hold n.deadLetterMu.
+func (n *WebhookNotifier) rewriteDeadLetters(letters []deadLetter) error {
+	if len(letters) == 0 {
+		if err := os.Remove(n.deadLetterPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to remove dead-letter file: %w", err)
+		}
+		return nil
+	}
+
+	tmp := n.deadLetterPath + ".tmp"
+	if err := os.Remove(tmp); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to rewrite dead-letter file: %w", err)
+	}
+	if err := appendDeadLetters(tmp, letters); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, n.deadLetterPath); err != nil {
+		return fmt.Errorf("failed to replace dead-letter file: %w", err)
+	}
+	return nil
+}
+
+// appendDeadLetters appends letters to the file at path, one JSON object per line
+func appendDeadLetters(path string, letters []deadLetter) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open dead-letter file: %w", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, letter := range letters {
+		if err := encoder.Encode(letter); err != nil {
+			return fmt.Errorf("failed to write dead letter: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"incident-ai/telemetry"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// webhookServer is a webhook endpoint answering each post with status(attempt, msg), where
+// attempt counts every post received so far, from 1
+type webhookServer struct {
+	*httptest.Server
+	status func(attempt int, msg Message) int
+
+	mu        sync.Mutex
+	attempts  int
+	delivered []Message // messages accepted with a 2xx status
+	headers   []string  // correlation ID header of each accepted message
+}
+
+func newWebhookServer(t *testing.T, status func(attempt int, msg Message) int) *webhookServer {
+	t.Helper()
+
+	s := &webhookServer{status: status}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg Message
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.attempts++
+		code := s.status(s.attempts, msg)
+		if code < 300 {
+			s.delivered = append(s.delivered, msg)
+			s.headers = append(s.headers, r.Header.Get(telemetry.CorrelationHeader))
+		}
+		w.WriteHeader(code)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// results returns how many posts were received and the messages accepted
+func (s *webhookServer) results() (int, []Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attempts, append([]Message(nil), s.delivered...)
+}
+
+func TestWebhookRetries(t *testing.T) {
+	tests := []struct {
+		name           string
+		statuses       []int // answer to each attempt, the last repeated
+		retries        int
+		wantAttempts   int
+		wantDelivered  bool
+		wantDeadLetter bool
+	}{
+		{name: "delivered first time", statuses: []int{200}, retries: 3, wantAttempts: 1, wantDelivered: true},
+		{name: "delivered on retry", statuses: []int{500, 503, 204}, retries: 3, wantAttempts: 3, wantDelivered: true},
+		{name: "rate limited then delivered", statuses: []int{429, 200}, retries: 3, wantAttempts: 2, wantDelivered: true},
+		{name: "retries exhausted", statuses: []int{502}, retries: 2, wantAttempts: 3, wantDeadLetter: true},
+		{name: "rejection not retried", statuses: []int{400}, retries: 3, wantAttempts: 1, wantDeadLetter: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newWebhookServer(t, func(attempt int, msg Message) int {
+				return tt.statuses[min(attempt, len(tt.statuses))-1]
+			})
+			deadLetters := filepath.Join(t.TempDir(), "dead_letters.jsonl")
+			notifier := NewWebhookNotifier(server.URL, WithRetries(tt.retries, time.Millisecond), WithDeadLetterFile(deadLetters))
+
+			if err := notifier.Notify(context.Background(), Message{IncidentID: "a", Title: "down"}); err != nil {
+				t.Fatalf("Notify: %v", err)
+			}
+			notifier.Close()
+
+			attempts, delivered := server.results()
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+			if (len(delivered) == 1) != tt.wantDelivered {
+				t.Errorf("delivered %d messages, want delivered = %v", len(delivered), tt.wantDelivered)
+			}
+
+			letters, err := notifier.readDeadLetters()
+			if err != nil {
+				t.Fatalf("readDeadLetters: %v", err)
+			}
+			if (len(letters) == 1) != tt.wantDeadLetter {
+				t.Fatalf("%d dead letters, want dead-lettered = %v", len(letters), tt.wantDeadLetter)
+			}
+			if tt.wantDeadLetter && letters[0].Error == "" {
+				t.Error("dead letter doesn't record the delivery error")
+			}
+		})
+	}
+}
+
+func TestReplayDeadLetters(t *testing.T) {
+	var mu sync.Mutex
+	up, rejectB := false, true // the endpoint keeps rejecting incident b once it is back
+	server := newWebhookServer(t, func(attempt int, msg Message) int {
+		mu.Lock()
+		defer mu.Unlock()
+		if !up || (rejectB && msg.IncidentID == "b") {
+			return http.StatusServiceUnavailable
+		}
+		return http.StatusOK
+	})
+
+	deadLetters := filepath.Join(t.TempDir(), "dead_letters.jsonl")
+	notifier := NewWebhookNotifier(server.URL, WithRetries(1, time.Millisecond), WithDeadLetterFile(deadLetters))
+
+	for _, id := range []string{"a", "b", "c"} {
+		msg := Message{IncidentID: id, CorrelationID: "corr-" + id, Title: id + " is down"}
+		if err := notifier.Notify(context.Background(), msg); err != nil {
+			t.Fatalf("Notify(%s): %v", id, err)
+		}
+	}
+	notifier.Close()
+
+	if _, delivered := server.results(); len(delivered) != 0 {
+		t.Fatalf("%d messages delivered to a failing endpoint", len(delivered))
+	}
+	letters, err := notifier.readDeadLetters()
+	if err != nil || len(letters) != 3 {
+		t.Fatalf("readDeadLetters = %d letters, %v; want all 3", len(letters), err)
+	}
+
+	mu.Lock()
+	up = true
+	mu.Unlock()
+
+	replayed, err := notifier.ReplayDeadLetters(context.Background())
+	if err != nil {
+		t.Fatalf("ReplayDeadLetters: %v", err)
+	}
+	if replayed != 2 {
+		t.Errorf("ReplayDeadLetters delivered %d, want 2", replayed)
+	}
+
+	_, delivered := server.results()
+	server.mu.Lock()
+	headers := append([]string(nil), server.headers...)
+	server.mu.Unlock()
+	got := make(map[string]string)
+	for i, msg := range delivered {
+		got[msg.IncidentID] = headers[i]
+	}
+	for _, id := range []string{"a", "c"} {
+		if got[id] != "corr-"+id {
+			t.Errorf("replayed %s with correlation ID %q, want %q", id, got[id], "corr-"+id)
+		}
+	}
+
+	letters, err = notifier.readDeadLetters()
+	if err != nil {
+		t.Fatalf("readDeadLetters: %v", err)
+	}
+	if len(letters) != 1 {
+		t.Fatalf("%d dead letters after replay, want only b's", len(letters))
+	}
+	var msg Message
+	if err := json.Unmarshal(letters[0].Message, &msg); err != nil || msg.IncidentID != "b" {
+		t.Errorf("remaining dead letter = %s (%v), want b's", letters[0].Message, err)
+	}
+
+	// Once everything is delivered the file is removed
+	mu.Lock()
+	rejectB = false
+	mu.Unlock()
+	if replayed, err := notifier.ReplayDeadLetters(context.Background()); err != nil || replayed != 1 {
+		t.Fatalf("second ReplayDeadLetters = %d, %v; want 1 delivered", replayed, err)
+	}
+	if _, err := os.Stat(deadLetters); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("dead-letter file still exists after every letter was delivered: %v", err)
+	}
+}
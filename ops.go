@@ -0,0 +1,58 @@
+package main
+
+import (
+	"incident-ai/models"
+	"sort"
+	"time"
+)
+
+// providerHealthReporter is implemented by analyzers that track AI provider health
+type providerHealthReporter interface {
+	ProviderHealth() map[string]interface{}
+}
+
+// OpsSnapshot returns live operational metrics gathered from the detector, the
+// orchestrator and the store. Unlike the summary, it describes the present moment.
+func (o *Orchestrator) OpsSnapshot() map[string]interface{} {
+	depth, capacity := o.detector.QueueDepth()
+
+	var lastDetection interface{}
+	if t := o.detector.LastDetection(); !t.IsZero() {
+		lastDetection = t
+	}
+
+	aiProvider := map[string]interface{}{"status": "disabled"}
+	if reporter, ok := o.analyzer.(providerHealthReporter); ok && o.useAI {
+		aiProvider = reporter.ProviderHealth()
+	}
+
+	return map[string]interface{}{
+		"queue": map[string]interface{}{
+			"depth":    depth,
+			"capacity": capacity,
+			"requeued": len(o.requeue),
+			"accepted": len(o.accepted),
+			"dropped":  o.detector.Dropped(),
+		},
+		"in_flight_remediations": o.remediating.Load(),
+		"in_flight_incidents":    len(o.store.GetInFlightIncidents(time.Now())),
+		"last_detection":         lastDetection,
+		"ai_provider":            aiProvider,
+		"remediation_enabled":    !o.diagnoseOnly,
+		"escalated_types":        o.escalatedTypes(),
+		"maintenance":            o.detector.InMaintenance(),
+		"time":                   time.Now(),
+	}
+}
+
+// escalatedTypes lists incident types whose failure streak currently suspends auto-remediation
+func (o *Orchestrator) escalatedTypes() []string {
+	types := []string{}
+	for incidentType := range o.store.FailureStreaks() {
+		if _, suspended := o.remediationSuspended(models.IncidentType(incidentType)); suspended {
+			types = append(types, incidentType)
+		}
+	}
+	sort.Strings(types)
+	return types
+}
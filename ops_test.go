@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"incident-ai/ai"
+	"incident-ai/models"
+	"incident-ai/monitor"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// blockingExecutor applies fixes like fakeExecutor, but holds each one until released
+type blockingExecutor struct {
+	fakeExecutor
+	applying chan struct{}
+	release  chan struct{}
+}
+
+func (e *blockingExecutor) ExecuteFix(ctx context.Context, incident *models.Incident, aiResponse *models.AIResponse) (*models.Resolution, error) {
+	e.applying <- struct{}{}
+	<-e.release
+	return e.fakeExecutor.ExecuteFix(ctx, incident, aiResponse)
+}
+
+// getOps returns the API's /ops response
+func getOps(t *testing.T, api *APIServer) map[string]interface{} {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	api.handleOps(recorder, httptest.NewRequest(http.MethodGet, "/ops", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("GET /ops = %d: %s", recorder.Code, recorder.Body)
+	}
+	var ops map[string]interface{}
+	if err := json.NewDecoder(recorder.Body).Decode(&ops); err != nil {
+		t.Fatalf("decoding /ops: %v", err)
+	}
+	return ops
+}
+
+func TestOpsEndpoint(t *testing.T) {
+	o := newTestOrchestrator(t)
+	api := NewAPIServer("0", o)
+
+	// A service that is down, and a detector whose incidents nobody takes yet
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]interface{}{"healthy": false, "message": "down"})
+	}))
+	t.Cleanup(down.Close)
+	o.detector = monitor.NewIncidentDetector(down.URL, 10*time.Millisecond, monitor.WithImpactSampling(0, 0))
+	startAt := time.Now()
+	ctx, cancel := context.WithCancel(context.Background())
+	o.detector.Start(ctx)
+	t.Cleanup(func() {
+		o.detector.Stop()
+		cancel()
+	})
+
+	var ops map[string]interface{}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		ops = getOps(t, api)
+		if ops["queue"].(map[string]interface{})["depth"] == 1.0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("queue = %v, want the detected incident waiting", ops["queue"])
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if queue := ops["queue"].(map[string]interface{}); queue["capacity"] != 10.0 || queue["dropped"] != 0.0 {
+		t.Errorf("queue = %v, want the default capacity and nothing dropped", queue)
+	}
+	lastDetection, err := time.Parse(time.RFC3339Nano, ops["last_detection"].(string))
+	if err != nil || lastDetection.Before(startAt) {
+		t.Errorf("last detection = %v (%v), want the incident just raised", ops["last_detection"], err)
+	}
+
+	// The fake analyzer doesn't report provider health; a real one reports the last call's outcome
+	if provider := ops["ai_provider"].(map[string]interface{}); provider["status"] != "disabled" {
+		t.Errorf("ai provider = %v for an analyzer without provider health, want disabled", provider)
+	}
+	analyzer := ai.NewAnalyzer("sk-test", ai.WithCompletionFunc(func(ctx context.Context, req openai.ChatCompletionRequest) (string, error) {
+		return "", errors.New("503 service unavailable")
+	}))
+	o.analyzer = analyzer
+	if getOps(t, api)["ai_provider"].(map[string]interface{})["status"] != "unknown" {
+		t.Error("ai provider status before any call isn't unknown")
+	}
+	analyzer.AnalyzeIncident(context.Background(), newTestIncident("probe", models.ServiceDown), nil)
+	if provider := getOps(t, api)["ai_provider"].(map[string]interface{}); provider["status"] != "failing" || provider["consecutive_failures"] != 1.0 {
+		t.Errorf("ai provider = %v after a failed call, want failing once", provider)
+	}
+	o.analyzer = &fakeAnalyzer{response: models.AIResponse{Diagnosis: "AI diagnosis", FixType: "restart", FixSteps: []string{"Restart the service"}, Confidence: 0.9}}
+
+	// A fix being applied is in flight until it returns
+	executor := &blockingExecutor{applying: make(chan struct{}), release: make(chan struct{})}
+	o.executor = executor
+	processed := make(chan error, 1)
+	go func() {
+		processed <- o.processIncident(context.Background(), <-o.detector.GetIncidentChannel())
+	}()
+	<-executor.applying
+	if ops := getOps(t, api); ops["in_flight_remediations"] != 1.0 || ops["remediation_enabled"] != true {
+		t.Errorf("in-flight remediations = %v, remediation enabled = %v; want 1 and true", ops["in_flight_remediations"], ops["remediation_enabled"])
+	}
+	close(executor.release)
+	if err := <-processed; err != nil {
+		t.Fatalf("processIncident: %v", err)
+	}
+	if ops := getOps(t, api); ops["in_flight_remediations"] != 0.0 {
+		t.Errorf("in-flight remediations = %v after the fix returned, want 0", ops["in_flight_remediations"])
+	}
+
+	// Escalated types and diagnose-only mode show as they change
+	o.escalateAfter = 1
+	o.diagnoseOnly = true
+	o.store.RecordResolutionOutcome(models.ConfigError, false)
+	if ops := getOps(t, api); ops["remediation_enabled"] != false || len(ops["escalated_types"].([]interface{})) != 1 {
+		t.Errorf("remediation enabled = %v, escalated types = %v; want false and [CONFIG_ERROR]", ops["remediation_enabled"], ops["escalated_types"])
+	}
+
+	recorder := httptest.NewRecorder()
+	api.handleOps(recorder, httptest.NewRequest(http.MethodPost, "/ops", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST /ops = %d, want %d", recorder.Code, http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"incident-ai/memory"
+	"incident-ai/models"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// defaultPostmortemTemplate renders a ready-to-edit Markdown postmortem. Sections left for
+// humans to fill in are marked TODO.
+const defaultPostmortemTemplate = `# Postmortem: {{.Incident.Type}} incident {{.Incident.ID}}
+
+| | |
+|---|---|
+| Status | {{.Incident.Status}} |
+| Severity | {{if .Incident.Severity}}{{.Incident.Severity}}{{else}}unknown{{end}} |
+| Detected | {{.Incident.DetectedAt.Format "2006-01-02 15:04:05 MST"}} |
+| Resolved | {{if .Incident.ResolvedAt}}{{.Incident.ResolvedAt.Format "2006-01-02 15:04:05 MST"}}{{else}}not resolved{{end}} |
+| Time to resolve | {{if .Duration}}{{.Duration}}{{else}}n/a{{end}} |
+| Impact | {{printf "%.0f" (percent .Incident.ImpactScore)}}% of sampled API requests failing at detection |
+{{if .Incident.AcknowledgedBy}}| Owner | {{.Incident.AcknowledgedBy}} |
+{{end}}
+## Summary
+
+TODO: Describe what happened and who was affected.
+
+## Timeline
+
+{{range .Timeline}}- **{{.At.Format "15:04:05"}}** {{.Event}}
+{{end}}
+## Symptoms
+
+{{range .Incident.Symptoms}}- {{.}}
+{{else}}No symptoms recorded.
+{{end}}
+## Logs
+
+{{if .Incident.TriggerLog}}Triggering log entry:
+
+` + "```" + `
+{{.Incident.TriggerLog}}
+` + "```" + `
+
+{{end}}{{if .Incident.Logs}}` + "```" + `
+{{range .Incident.Logs}}{{.}}
+{{end}}` + "```" + `
+{{else}}No logs captured.
+{{end}}
+## Root Cause
+
+{{if .Incident.Diagnosis}}{{.Incident.Diagnosis}}{{else}}TODO: No diagnosis was recorded.{{end}}
+{{if .Incident.RootCauseCategory}}
+Category: {{.Incident.RootCauseCategory}}
+{{end}}
+## Resolution
+
+{{with .Incident.Resolution}}Fix type: {{.FixType}}{{if $.Incident.UsedCachedFix}} (learned fix reused){{end}}
+
+{{range $i, $s := .Steps}}{{inc $i}}. {{$s}}
+{{end}}{{if .AppliedConfig}}
+Config applied:
+{{range $k, $v := .AppliedConfig}}- ` + "`{{$k}}`" + ` = ` + "`{{$v}}`" + `
+{{end}}{{end}}{{else}}{{with .Incident.RecommendedFix}}No fix was applied. Recommended fix ({{.FixType}}):
+
+{{range $i, $s := .Steps}}{{inc $i}}. {{$s}}
+{{end}}{{else}}No fix was applied.
+{{end}}{{end}}{{if .Incident.FailureReason}}
+Failure reason: {{.Incident.FailureReason}}
+{{end}}
+## Follow-up Actions
+
+{{range .Incident.Recommendations}}- [ ] {{.}}
+{{end}}- [ ] TODO: Add follow-up actions.
+
+## Lessons Learned
+
+TODO: What went well, what went wrong, where we got lucky.
+`
+
+// postmortemData is the data available to postmortem templates
+type postmortemData struct {
+	Incident *models.Incident
+	Timeline []timelineEntry
+	Duration string // detection to resolution, empty if unresolved
+}
+
+// timelineEntry is one event in an incident's history
+type timelineEntry struct {
+	At    time.Time
+	Event string
+}
+
+var postmortemFuncs = template.FuncMap{
+	"inc":     func(i int) int { return i + 1 },
+	"percent": func(f float64) float64 { return f * 100 },
+}
+
+// ParsePostmortemTemplate parses a postmortem template, or the default one if path is empty
+func ParsePostmortemTemplate(path string) (*template.Template, error) {
+	text := defaultPostmortemTemplate
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		text = string(data)
+	}
+	return template.New("postmortem").Funcs(postmortemFuncs).Option("missingkey=zero").Parse(text)
+}
+
+// GeneratePostmortem renders a Markdown postmortem for an incident in store, the
+// orchestrator's own or another tenant's
+func (o *Orchestrator) GeneratePostmortem(store *memory.Store, incidentID string) (string, error) {
+	incident, err := store.GetIncident(incidentID)
+	if err != nil {
+		return "", err
+	}
+
+	tmpl := o.postmortemTemplate
+	if tmpl == nil {
+		if tmpl, err = ParsePostmortemTemplate(""); err != nil {
+			return "", err
+		}
+	}
+
+	data := postmortemData{
+		Incident: incident,
+		Timeline: incidentTimeline(incident),
+	}
+	if incident.ResolvedAt != nil {
+		data.Duration = incident.ResolvedAt.Sub(incident.DetectedAt).Round(time.Second).String()
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render postmortem: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// incidentTimeline reconstructs the incident's history from its recorded timestamps
+func incidentTimeline(incident *models.Incident) []timelineEntry {
+	detected := fmt.Sprintf("%s detected", incident.Type)
+	if incident.CausedBy != "" {
+		detected += fmt.Sprintf(" after the fix for incident %s", incident.CausedBy)
+	}
+	timeline := []timelineEntry{{incident.DetectedAt, detected}}
+
+	if incident.AcknowledgedAt != nil {
+		timeline = append(timeline, timelineEntry{*incident.AcknowledgedAt, fmt.Sprintf("Acknowledged by %s", incident.AcknowledgedBy)})
+	}
+
+	if incident.ResolvedAt != nil {
+		event := "Resolved"
+		switch {
+		case incident.Resolution != nil && incident.UsedCachedFix:
+			event = fmt.Sprintf("Resolved by learned %s fix", incident.Resolution.FixType)
+		case incident.Resolution != nil && incident.UsedRunbook:
+			event = fmt.Sprintf("Resolved by %s runbook", incident.Resolution.FixType)
+		case incident.Resolution != nil:
+			event = fmt.Sprintf("Resolved by %s fix", incident.Resolution.FixType)
+		}
+		if incident.Relapses > 0 {
+			event += fmt.Sprintf(" after %d relapse(s)", incident.Relapses)
+		}
+		if incident.FollowUp != "" {
+			event += fmt.Sprintf(", followed by incident %s", incident.FollowUp)
+		}
+		timeline = append(timeline, timelineEntry{*incident.ResolvedAt, event})
+	}
+
+	sort.SliceStable(timeline, func(i, j int) bool { return timeline[i].At.Before(timeline[j].At) })
+	return timeline
+}
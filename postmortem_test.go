@@ -0,0 +1,146 @@
+package main
+
+import (
+	"incident-ai/models"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPostmortemForSeededIncident(t *testing.T) {
+	o := newTestOrchestrator(t)
+	api := NewAPIServer("0", o)
+
+	detected := time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC)
+	acknowledged := detected.Add(time.Minute)
+	resolved := detected.Add(5 * time.Minute)
+	incident := &models.Incident{
+		ID:                "incident-1",
+		Type:              models.ConfigError,
+		Status:            models.StatusResolved,
+		Severity:          models.SeverityHigh,
+		DetectedAt:        detected,
+		AcknowledgedAt:    &acknowledged,
+		AcknowledgedBy:    "alice",
+		ResolvedAt:        &resolved,
+		Symptoms:          []string{"Health check returned status code: 503"},
+		Logs:              []string{"ERROR: invalid database_url"},
+		Diagnosis:         "Database URL corrupted by a bad deploy",
+		RootCauseCategory: models.CauseConfig,
+		Recommendations:   []string{"Validate config before deploying"},
+		Resolution: &models.Resolution{
+			FixType:       "config",
+			Steps:         []string{"Restore database_url to localhost:5432", "Restart the service"},
+			AppliedConfig: map[string]string{"database_url": "localhost:5432"},
+			Success:       true,
+		},
+	}
+	if err := o.store.StoreIncident(incident); err != nil {
+		t.Fatalf("StoreIncident: %v", err)
+	}
+
+	recorder := serveAPI(api, http.MethodGet, "/incidents/incident-1/postmortem")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", recorder.Code, recorder.Body)
+	}
+	if ct := recorder.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/markdown") {
+		t.Errorf("Content-Type = %q, want Markdown", ct)
+	}
+
+	postmortem := recorder.Body.String()
+	for _, want := range []string{
+		"# Postmortem: CONFIG_ERROR incident incident-1",
+		"| Time to resolve | 5m0s |",
+		"| Owner | alice |",
+		"## Timeline",
+		"- **10:00:00** CONFIG_ERROR detected",
+		"- **10:01:00** Acknowledged by alice",
+		"- **10:05:00** Resolved by config fix",
+		"## Symptoms\n\n- Health check returned status code: 503",
+		"ERROR: invalid database_url",
+		"## Root Cause\n\nDatabase URL corrupted by a bad deploy",
+		"Category: config",
+		"1. Restore database_url to localhost:5432\n2. Restart the service",
+		"- `database_url` = `localhost:5432`",
+		"- [ ] Validate config before deploying",
+		"## Lessons Learned",
+	} {
+		if !strings.Contains(postmortem, want) {
+			t.Errorf("postmortem doesn't contain %q:\n%s", want, postmortem)
+		}
+	}
+
+	if recorder := serveAPI(api, http.MethodGet, "/incidents/missing/postmortem"); recorder.Code != http.StatusNotFound {
+		t.Errorf("postmortem of an unknown incident: status = %d, want %d", recorder.Code, http.StatusNotFound)
+	}
+}
+
+func TestPostmortemOfUnresolvedIncident(t *testing.T) {
+	o := newTestOrchestrator(t)
+	incident := newTestIncident("a", models.ServiceDown, "health check timed out")
+	incident.Status = models.StatusDiagnosed
+	incident.RecommendedFix = &models.Resolution{FixType: "restart", Steps: []string{"Restart the service"}}
+	if err := o.store.StoreIncident(incident); err != nil {
+		t.Fatalf("StoreIncident: %v", err)
+	}
+
+	postmortem, err := o.GeneratePostmortem(o.store, "a")
+	if err != nil {
+		t.Fatalf("GeneratePostmortem: %v", err)
+	}
+	for _, want := range []string{
+		"| Resolved | not resolved |",
+		"| Time to resolve | n/a |",
+		"TODO: No diagnosis was recorded.",
+		"No fix was applied. Recommended fix (restart):\n\n1. Restart the service",
+	} {
+		if !strings.Contains(postmortem, want) {
+			t.Errorf("postmortem doesn't contain %q:\n%s", want, postmortem)
+		}
+	}
+}
+
+func TestCustomPostmortemTemplate(t *testing.T) {
+	tmpl, err := ParsePostmortemTemplate(writeTemplate(t, "{{.Incident.ID}} ({{.Incident.Type}}) took {{.Duration}}\n{{range .Timeline}}{{.Event}}\n{{end}}"))
+	if err != nil {
+		t.Fatalf("ParsePostmortemTemplate: %v", err)
+	}
+	o := newTestOrchestrator(t)
+	o.postmortemTemplate = tmpl
+
+	incident := newTestIncident("a", models.ServiceDown, "health check timed out")
+	resolved := incident.DetectedAt.Add(90 * time.Second)
+	incident.Status, incident.ResolvedAt = models.StatusResolved, &resolved
+	incident.Resolution = &models.Resolution{FixType: "restart", Steps: []string{"Restart the service"}, Success: true}
+	if err := o.store.StoreIncident(incident); err != nil {
+		t.Fatalf("StoreIncident: %v", err)
+	}
+
+	postmortem, err := o.GeneratePostmortem(o.store, "a")
+	if err != nil {
+		t.Fatalf("GeneratePostmortem: %v", err)
+	}
+	if want := "a (SERVICE_DOWN) took 1m30s\nSERVICE_DOWN detected\nResolved by restart fix\n"; postmortem != want {
+		t.Errorf("postmortem = %q, want %q", postmortem, want)
+	}
+
+	for _, bad := range []string{filepath.Join(t.TempDir(), "missing.tmpl"), writeTemplate(t, "{{.Incident.ID")} {
+		if _, err := ParsePostmortemTemplate(bad); err == nil {
+			t.Errorf("ParsePostmortemTemplate(%s) accepted an unusable template", bad)
+		}
+	}
+}
+
+// writeTemplate writes text to a template file and returns its path
+func writeTemplate(t *testing.T, text string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "template.tmpl")
+	if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+		t.Fatalf("writing template: %v", err)
+	}
+	return path
+}
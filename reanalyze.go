@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"incident-ai/models"
+	"incident-ai/telemetry"
+	"time"
+)
+
+// errAIDisabled is returned when re-analysis is requested without AI analysis
+var errAIDisabled = errors.New("AI analysis is disabled")
+
+// errStillProcessing is returned when re-analyzing an incident that is being processed
+var errStillProcessing = errors.New("incident is still being processed")
+
+// Reanalyze asks the AI to diagnose a handled incident again, e.g. after its metadata
+// changed, and stores the result as a diagnosis revision. The original diagnosis and fix
+// are kept, and nothing is applied. A fix that failed is passed to the AI as a failed
+// attempt.
+func (o *Orchestrator) Reanalyze(ctx context.Context, id string) (*models.Incident, error) {
+	if !o.useAI {
+		return nil, errAIDisabled
+	}
+
+	o.processingMu.Lock()
+	_, processing := o.processing[id]
+	o.processingMu.Unlock()
+	if processing {
+		return nil, errStillProcessing
+	}
+
+	incident, err := o.store.GetIncident(id)
+	if err != nil {
+		return nil, err
+	}
+	ctx = telemetry.WithCorrelationID(ctx, incident.CorrelationID)
+
+	var previousAttempts []models.Resolution
+	if incident.Status == models.StatusFailed && incident.Resolution != nil {
+		previousAttempts = append(previousAttempts, *incident.Resolution)
+	}
+
+	telemetry.Logf(ctx, "[AI] 🔁 Re-analyzing %s incident %s\n", incident.Type, id)
+	aiResponse, err := o.analyzer.AnalyzeIncident(ctx, incident, previousAttempts)
+	if err != nil {
+		return nil, fmt.Errorf("re-analysis failed: %w", err)
+	}
+
+	incident, err = o.store.AddDiagnosisRevision(id, models.DiagnosisRevision{
+		RevisedAt:         time.Now(),
+		Diagnosis:         aiResponse.Diagnosis,
+		RootCauseCategory: aiResponse.RootCauseCategory,
+		Recommendations:   aiResponse.Recommendations,
+		CorrectedType:     aiResponse.CorrectedType,
+		Confidence:        aiResponse.Confidence,
+		SystemFingerprint: aiResponse.SystemFingerprint,
+		RecommendedFix: &models.Resolution{
+			FixType:       aiResponse.FixType,
+			Description:   aiResponse.Diagnosis,
+			Steps:         aiResponse.FixSteps,
+			Code:          aiResponse.Code,
+			ConfigChanges: aiResponse.ConfigChanges,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	telemetry.Logf(ctx, "[AI] 📝 Stored diagnosis revision %d: %s\n", len(incident.DiagnosisRevisions), aiResponse.Diagnosis)
+	return incident, nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"incident-ai/models"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecommendationsStoredNotApplied(t *testing.T) {
+	o := newTestOrchestrator(t)
+	analyzer := o.analyzer.(*fakeAnalyzer)
+	executor := o.executor.(*fakeExecutor)
+	recommendations := []string{"Add a retry budget", "Set a memory limit"}
+	analyzer.response.Recommendations = recommendations
+
+	incident := newTestIncident("a", models.ServiceDown, "health check timed out")
+	if err := o.processIncident(context.Background(), incident); err != nil {
+		t.Fatalf("processIncident: %v", err)
+	}
+
+	if len(executor.executed) != 1 {
+		t.Fatalf("executor ran %d fixes, want 1", len(executor.executed))
+	}
+	for _, step := range executor.executed[0].FixSteps {
+		if slices.Contains(recommendations, step) {
+			t.Errorf("recommendation %q applied as a fix step", step)
+		}
+	}
+	if incident.Resolution == nil || slices.ContainsFunc(incident.Resolution.Steps, func(step string) bool {
+		return slices.Contains(recommendations, step)
+	}) {
+		t.Errorf("resolution = %+v, want the fix without the recommendations", incident.Resolution)
+	}
+
+	// Stored with the incident and served by the REST API
+	if got := decodeIncident(t, serveAPI(NewAPIServer("0", o), "GET", "/incidents/a")); !slices.Equal(got.Recommendations, recommendations) {
+		t.Errorf("API incident recommendations = %q, want %q", got.Recommendations, recommendations)
+	}
+	if summary := o.store.Summary(); summary.Recommendations["Set a memory limit"] != 1 {
+		t.Errorf("summary recommendations = %v, want each counted once", summary.Recommendations)
+	}
+}
+
+func TestRecommendationsInDiagnosisNotification(t *testing.T) {
+	o := newTestOrchestrator(t)
+	o.diagnoseOnly = true
+	o.analyzer.(*fakeAnalyzer).response.Recommendations = []string{"Add a readiness probe"}
+	notifier := o.notifier.(*recordingNotifier)
+
+	incident := newTestIncident("a", models.ServiceDown, "health check timed out")
+	if err := o.processIncident(context.Background(), incident); err != nil {
+		t.Fatalf("processIncident: %v", err)
+	}
+
+	msg := notifier.next(t, time.Second)
+	if !strings.Contains(msg.Body, "Preventive recommendations:\n- Add a readiness probe") {
+		t.Errorf("notification body:\n%s\nwant the recommendation listed apart from the fix", msg.Body)
+	}
+}
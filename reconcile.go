@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"incident-ai/models"
+	"log"
+	"time"
+)
+
+// ReconcileMode controls what happens to incidents left in flight by a previous run
+type ReconcileMode string
+
+const (
+	// ReconcileRetry re-enqueues stuck incidents for processing
+	ReconcileRetry ReconcileMode = "retry"
+	// ReconcileFail marks stuck incidents FAILED with a reason
+	ReconcileFail ReconcileMode = "fail"
+)
+
+// ParseReconcileMode validates a reconcile mode name
+func ParseReconcileMode(s string) (ReconcileMode, error) {
+	switch ReconcileMode(s) {
+	case ReconcileRetry, ReconcileFail:
+		return ReconcileMode(s), nil
+	default:
+		return "", fmt.Errorf("unknown reconcile mode %q (valid: %s, %s)", s, ReconcileRetry, ReconcileFail)
+	}
+}
+
+// reconcile handles incidents a previous run left in a non-terminal status, e.g. because
+// the process crashed mid-fix. Only incidents detected before cutoff are touched.
+func (o *Orchestrator) reconcile(ctx context.Context, mode ReconcileMode, cutoff time.Time) {
+	stuck := o.store.GetInFlightIncidents(cutoff)
+	if len(stuck) == 0 {
+		return
+	}
+
+	log.Printf("[SYSTEM] Found %d incident(s) left in flight by a previous run (mode: %s)\n", len(stuck), mode)
+
+	for _, incident := range stuck {
+		previous := incident.Status
+
+		if mode == ReconcileFail {
+			incident.Status = models.StatusFailed
+			incident.FailureReason = fmt.Sprintf("orchestrator restarted while incident was %s", previous)
+			if err := o.store.StoreIncident(incident); err != nil {
+				log.Printf("[MEMORY] Warning: failed to store incident: %v\n", err)
+			}
+			log.Printf("[SYSTEM]   %s (%s): %s → %s\n", incident.ID, incident.Type, previous, models.StatusFailed)
+			continue
+		}
+
+		incident.Status = models.StatusDetected
+		incident.UsedCachedFix = false
+		log.Printf("[SYSTEM]   %s (%s): %s → re-enqueued\n", incident.ID, incident.Type, previous)
+
+		select {
+		case o.requeue <- incident:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
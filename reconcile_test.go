@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"incident-ai/memory"
+	"incident-ai/models"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseReconcileMode(t *testing.T) {
+	for _, mode := range []ReconcileMode{ReconcileRetry, ReconcileFail} {
+		if got, err := ParseReconcileMode(string(mode)); err != nil || got != mode {
+			t.Errorf("ParseReconcileMode(%q) = %q, %v", mode, got, err)
+		}
+	}
+	if _, err := ParseReconcileMode("ignore"); err == nil {
+		t.Error("ParseReconcileMode accepted an unknown mode")
+	}
+}
+
+func TestReconcileAfterRestart(t *testing.T) {
+	tests := []struct {
+		mode         ReconcileMode
+		wantStatus   models.IncidentStatus
+		wantRequeued bool
+	}{
+		{mode: ReconcileFail, wantStatus: models.StatusFailed},
+		{mode: ReconcileRetry, wantStatus: models.StatusDetected, wantRequeued: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "incident_memory.json")
+			startup := time.Now()
+
+			// The previous run crashed mid-fix, and left a resolved incident and one detected
+			// just before this run started reconciling
+			previous := openStore(t, path)
+			stuck := newTestIncident("stuck", models.ConfigError)
+			stuck.DetectedAt = startup.Add(-10 * time.Minute)
+			stuck.Status = models.StatusFixing
+			stuck.UsedCachedFix = true
+			resolved := newTestIncident("resolved", models.ServiceDown)
+			resolved.DetectedAt = startup.Add(-time.Hour)
+			resolved.Status = models.StatusResolved
+			recent := newTestIncident("recent", models.ServiceDown)
+			recent.Status = models.StatusAnalyzing
+			for _, incident := range []*models.Incident{stuck, resolved, recent} {
+				if err := previous.StoreIncident(incident); err != nil {
+					t.Fatalf("StoreIncident: %v", err)
+				}
+			}
+
+			o := newTestOrchestrator(t)
+			o.store = openStore(t, path)
+			o.reconcile(context.Background(), tt.mode, startup.Add(-time.Minute))
+
+			reconciled, err := o.store.GetIncident("stuck")
+			if err != nil {
+				t.Fatalf("GetIncident: %v", err)
+			}
+			if reconciled.Status != tt.wantStatus {
+				t.Errorf("stuck incident is %s, want %s", reconciled.Status, tt.wantStatus)
+			}
+			if tt.mode == ReconcileFail && reconciled.FailureReason != "orchestrator restarted while incident was FIXING" {
+				t.Errorf("failure reason = %q", reconciled.FailureReason)
+			}
+
+			select {
+			case requeued := <-o.requeue:
+				if !tt.wantRequeued || requeued.ID != "stuck" || requeued.UsedCachedFix {
+					t.Errorf("requeued %s (cached fix %t), want only the stuck incident re-enqueued fresh in retry mode", requeued.ID, requeued.UsedCachedFix)
+				}
+			default:
+				if tt.wantRequeued {
+					t.Error("stuck incident not re-enqueued")
+				}
+			}
+			if len(o.requeue) > 0 {
+				t.Errorf("%d more incidents re-enqueued", len(o.requeue))
+			}
+
+			for id, want := range map[string]models.IncidentStatus{"resolved": models.StatusResolved, "recent": models.StatusAnalyzing} {
+				if incident, _ := o.store.GetIncident(id); incident.Status != want {
+					t.Errorf("%s incident changed to %s, want it left %s", id, incident.Status, want)
+				}
+			}
+		})
+	}
+}
+
+// openStore opens the store file at path, failing the test if it can't be loaded
+func openStore(t *testing.T, path string) *memory.Store {
+	t.Helper()
+
+	store, err := memory.NewStore(path)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	return store
+}
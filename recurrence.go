@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"incident-ai/models"
+	"incident-ai/telemetry"
+	"time"
+)
+
+// boostRecurring tags an incident as a recurring problem when its type has been detected
+// at least recurrenceThreshold times within recurrenceWindow, raising its severity one
+// level for every multiple of the threshold. The incident must already be stored so it
+// counts itself. It reports whether the incident was boosted.
+func (o *Orchestrator) boostRecurring(ctx context.Context, incident *models.Incident) bool {
+	if o.recurrenceThreshold <= 0 || incident.Recurring {
+		return false
+	}
+
+	count := o.store.RecurrenceCount(incident.Type, time.Now().Add(-o.recurrenceWindow))
+	if count < o.recurrenceThreshold {
+		return false
+	}
+
+	original := incident.Severity
+	for i := 0; i < count/o.recurrenceThreshold; i++ {
+		incident.Severity = incident.Severity.Raise()
+	}
+	incident.Recurring = true
+	incident.Recurrences = count
+
+	telemetry.Logf(ctx, "[SYSTEM] 🔁 Recurring problem: %d %s incidents in the last %v\n", count, incident.Type, o.recurrenceWindow)
+	if incident.Severity != original {
+		telemetry.Logf(ctx, "[SYSTEM]    Severity raised: %s → %s\n", original, incident.Severity)
+	}
+	return true
+}
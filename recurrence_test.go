@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"incident-ai/models"
+	"testing"
+	"time"
+)
+
+func TestRecurringIncidentsRaiseSeverity(t *testing.T) {
+	o := newTestOrchestrator(t)
+	o.recurrenceThreshold = 2
+	o.recurrenceWindow = time.Hour
+
+	// An incident from before the window doesn't count
+	old := newTestIncident("old", models.ConfigError, "invalid timeout")
+	old.DetectedAt = time.Now().Add(-2 * time.Hour)
+	old.Status = models.StatusResolved
+	if err := o.store.StoreIncident(old); err != nil {
+		t.Fatalf("StoreIncident: %v", err)
+	}
+
+	tests := []struct {
+		wantRecurring bool
+		wantSeverity  models.Severity
+	}{
+		{wantRecurring: false, wantSeverity: models.SeverityLow},
+		{wantRecurring: true, wantSeverity: models.SeverityMedium},
+		{wantRecurring: true, wantSeverity: models.SeverityMedium},
+		{wantRecurring: true, wantSeverity: models.SeverityHigh},
+	}
+	for i, tt := range tests {
+		incident := newTestIncident(fmt.Sprintf("incident-%d", i+1), models.ConfigError, "invalid timeout")
+		incident.Severity = models.SeverityLow
+		if err := o.processIncident(context.Background(), incident); err != nil {
+			t.Fatalf("processIncident: %v", err)
+		}
+
+		if incident.Recurring != tt.wantRecurring || incident.Severity != tt.wantSeverity {
+			t.Errorf("incident %d: recurring %v at %s severity, want %v at %s", i+1, incident.Recurring, incident.Severity, tt.wantRecurring, tt.wantSeverity)
+		}
+		if tt.wantRecurring && incident.Recurrences != i+1 {
+			t.Errorf("incident %d: %d recurrences, want %d", i+1, incident.Recurrences, i+1)
+		}
+		if stored, _ := o.store.GetIncident(incident.ID); stored.Recurring != incident.Recurring || stored.Severity != incident.Severity {
+			t.Errorf("incident %d stored as recurring %v at %s severity", i+1, stored.Recurring, stored.Severity)
+		}
+	}
+
+	// Another type isn't boosted by this one's recurrence
+	other := newTestIncident("other", models.ServiceDown, "health check timed out")
+	other.Severity = models.SeverityLow
+	if err := o.processIncident(context.Background(), other); err != nil {
+		t.Fatalf("processIncident: %v", err)
+	}
+	if other.Recurring || other.Severity != models.SeverityLow {
+		t.Errorf("%s incident recurring %v at %s severity, want it left alone", other.Type, other.Recurring, other.Severity)
+	}
+}
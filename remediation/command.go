@@ -0,0 +1,52 @@
+package remediation
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"incident-ai/telemetry"
+	"os/exec"
+	"strings"
+)
+
+// runCommand executes an external command, returning its combined stdout and stderr
+func runCommand(ctx context.Context, cmd *CommandConfig) (string, error) {
+	parent := ctx
+	if cmd.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cmd.Timeout)
+		defer cancel()
+	}
+
+	telemetry.Logf(ctx, "[REMEDIATION]   → Running: %s %s\n", cmd.Path, strings.Join(cmd.Args, " "))
+
+	command := exec.CommandContext(ctx, cmd.Path, cmd.Args...)
+	command.Dir = cmd.Dir
+
+	var output bytes.Buffer
+	command.Stdout = &output
+	command.Stderr = &output
+
+	err := command.Run()
+	out := strings.TrimSpace(output.String())
+
+	for _, line := range strings.Split(out, "\n") {
+		if line != "" {
+			telemetry.Logf(ctx, "[REMEDIATION]     | %s\n", line)
+		}
+	}
+
+	if err != nil {
+		// The caller's context ending cancels the command; only its own timeout times it out
+		if parent.Err() != nil {
+			return out, fmt.Errorf("command cancelled: %w", parent.Err())
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return out, fmt.Errorf("command timed out after %v", cmd.Timeout)
+		}
+		return out, fmt.Errorf("command failed: %w", err)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,135 @@
+package remediation
+
+import (
+	"context"
+	"errors"
+	"incident-ai/models"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRestartCommand(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name       string
+		cmd        CommandConfig
+		wantOutput string
+		wantErr    string
+	}{
+		{name: "succeeds", cmd: CommandConfig{Path: "true"}},
+		{name: "fails", cmd: CommandConfig{Path: "false"}, wantErr: "command failed"},
+		{
+			name:       "output captured",
+			cmd:        CommandConfig{Path: "sh", Args: []string{"-c", "echo restarting; echo degraded >&2"}},
+			wantOutput: "restarting\ndegraded",
+		},
+		{
+			name:       "output of a failed command captured",
+			cmd:        CommandConfig{Path: "sh", Args: []string{"-c", "echo unit not found; exit 5"}},
+			wantOutput: "unit not found",
+			wantErr:    "exit status 5",
+		},
+		{name: "runs in the working directory", cmd: CommandConfig{Path: "pwd", Dir: dir}, wantOutput: dir},
+		{name: "times out", cmd: CommandConfig{Path: "sleep", Args: []string{"5"}, Timeout: 50 * time.Millisecond}, wantErr: "command timed out after 50ms"},
+		{name: "missing", cmd: CommandConfig{Path: filepath.Join(dir, "missing")}, wantErr: "command failed"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			executor := NewExecutor(nil, WithRestartCommand(tt.cmd))
+			incident := &models.Incident{ID: "incident-1", Type: models.ServiceDown}
+			fix := &models.AIResponse{FixType: "restart", FixSteps: []string{"Restart the service"}}
+
+			start := time.Now()
+			resolution, err := executor.ExecuteFix(context.Background(), incident, fix)
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("ExecuteFix took %v, want the command stopped at its timeout", elapsed)
+			}
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("ExecuteFix: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("ExecuteFix error = %v, want one containing %q", err, tt.wantErr)
+			}
+			if resolution.Success != (tt.wantErr == "") {
+				t.Errorf("resolution success = %v with error %v", resolution.Success, err)
+			}
+			if resolution.CommandOutput != tt.wantOutput {
+				t.Errorf("command output = %q, want %q", resolution.CommandOutput, tt.wantOutput)
+			}
+		})
+	}
+}
+
+func TestRestartCommandCancelled(t *testing.T) {
+	executor := NewExecutor(nil, WithRestartCommand(CommandConfig{Path: "sleep", Args: []string{"5"}}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := executor.Restart(ctx)
+	if err == nil || !strings.Contains(err.Error(), "command cancelled") || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Restart = %v, want the command cancelled with the context", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Restart took %v, want the command killed when the context ended", elapsed)
+	}
+}
+
+func TestRestartCommandModes(t *testing.T) {
+	tests := []struct {
+		name          string
+		mode          CommandMode
+		withoutTarget bool
+		wantRestarted bool // whether the in-process service was restarted too
+	}{
+		{name: "replace", mode: CommandReplace},
+		{name: "append", mode: CommandAppend, wantRestarted: true},
+		{name: "append without a target service", mode: CommandAppend, withoutTarget: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestService(t, nil)
+			target := ts
+			if tt.withoutTarget {
+				target = nil
+			}
+			executor := NewExecutor(target, WithRestartCommand(CommandConfig{Path: "echo", Args: []string{"restarted"}, Mode: tt.mode}))
+
+			output, err := executor.restart(context.Background())
+			if err != nil {
+				t.Fatalf("restart: %v", err)
+			}
+			if output != "restarted" {
+				t.Errorf("output = %q, want the command's output", output)
+			}
+			if running := ts.IsRunning(); running != tt.wantRestarted {
+				t.Errorf("in-process service running = %v, want %v", running, tt.wantRestarted)
+			}
+		})
+	}
+
+	// Without a command, an externally managed service can't be restarted
+	if err := NewExecutor(nil).Restart(context.Background()); !errors.Is(err, ErrUnmanagedService) {
+		t.Errorf("Restart without a command = %v, want ErrUnmanagedService", err)
+	}
+}
+
+func TestParseCommandMode(t *testing.T) {
+	for _, s := range []string{"replace", "append"} {
+		if mode, err := ParseCommandMode(s); err != nil || string(mode) != s {
+			t.Errorf("ParseCommandMode(%q) = %q, %v", s, mode, err)
+		}
+	}
+	if _, err := ParseCommandMode("prepend"); err == nil {
+		t.Error("ParseCommandMode accepted an unknown mode")
+	}
+}
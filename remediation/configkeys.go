@@ -0,0 +1,69 @@
+package remediation
+
+import (
+	"context"
+	"incident-ai/telemetry"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// quotedKeyPattern matches a key quoted in backticks, e.g. "Set `pool_size` to 10"
+	quotedKeyPattern = regexp.MustCompile("`([A-Za-z][A-Za-z0-9_.]*)`")
+	// snakeKeyPattern matches snake_case identifiers, the shape of the service's config keys
+	snakeKeyPattern = regexp.MustCompile(`\b[a-z][a-z0-9]*(?:_[a-z0-9]+)+\b`)
+)
+
+// unknownConfigKeys returns the config keys the fix steps refer to that the service doesn't
+// have, sorted. The AI sometimes invents keys; those steps would otherwise be dropped silently.
+func unknownConfigKeys(steps []string, known map[string]string) []string {
+	unknown := make(map[string]bool)
+	for _, step := range steps {
+		candidates := snakeKeyPattern.FindAllString(strings.ToLower(step), -1)
+		for _, match := range quotedKeyPattern.FindAllStringSubmatch(step, -1) {
+			candidates = append(candidates, strings.ToLower(match[1]))
+		}
+
+		for _, key := range candidates {
+			if _, ok := known[key]; !ok {
+				unknown[key] = true
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(unknown))
+	for key := range unknown {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// checkConfigKeys warns about fix steps that refer to config keys the service doesn't have
+func (e *Executor) checkConfigKeys(ctx context.Context, steps []string) []string {
+	known, err := e.targetService.GetConfig()
+	if err != nil {
+		telemetry.Logf(ctx, "[REMEDIATION]   ⚠️  Can't read the config to check keys: %v\n", err)
+		return nil
+	}
+	if len(known) == 0 {
+		return nil // nothing to validate against
+	}
+
+	unknown := unknownConfigKeys(steps, known)
+	if len(unknown) > 0 {
+		telemetry.Logf(ctx, "[REMEDIATION]   ⚠️  Fix refers to unknown config key(s) %s - not applied (known: %s)\n",
+			strings.Join(unknown, ", "), strings.Join(sortedKeys(known), ", "))
+	}
+	return unknown
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
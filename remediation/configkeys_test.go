@@ -0,0 +1,81 @@
+package remediation
+
+import (
+	"context"
+	"incident-ai/models"
+	"slices"
+	"testing"
+)
+
+func TestUnknownConfigKeys(t *testing.T) {
+	known := map[string]string{"database_url": "localhost:5432", "timeout": "30s", "max_retries": "3"}
+
+	tests := []struct {
+		name  string
+		steps []string
+		want  []string
+	}{
+		{name: "known keys", steps: []string{"Restore database_url to localhost:5432", "Set max_retries to 5"}, want: []string{}},
+		{name: "plain words", steps: []string{"Reset timeout to 30s", "Restart the service"}, want: []string{}},
+		{name: "invented snake_case key", steps: []string{"Set connection_pool_size to 50"}, want: []string{"connection_pool_size"}},
+		{name: "invented quoted key", steps: []string{"Raise `poolSize` to 20"}, want: []string{"poolsize"}},
+		{
+			name:  "each unknown key once, sorted",
+			steps: []string{"Set retry_budget to 3", "Set cache_ttl to 60s", "Lower retry_budget to 2"},
+			want:  []string{"cache_ttl", "retry_budget"},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := unknownConfigKeys(tt.steps, known); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: unknown keys = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExecuteFixFlagsHallucinatedKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		fix  *models.AIResponse
+	}{
+		{
+			name: "key in a step",
+			fix: &models.AIResponse{
+				FixType:  "config",
+				FixSteps: []string{"Set connection_pool_size to 50", "Reset timeout to 30s"},
+			},
+		},
+		{
+			name: "key in config changes",
+			fix: &models.AIResponse{
+				FixType:       "config",
+				FixSteps:      []string{"Grow the pool", "Reset the timeout"},
+				ConfigChanges: map[string]string{"connection_pool_size": "50", "timeout": "30s"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := newTestService(t, map[string]string{"timeout": "not-a-number"})
+			executor := NewExecutor(ts)
+
+			incident := &models.Incident{ID: "incident-1", Type: models.ConfigError}
+			resolution, err := executor.ExecuteFix(context.Background(), incident, tt.fix)
+			if err != nil {
+				t.Fatalf("ExecuteFix: %v", err)
+			}
+
+			if !slices.Equal(resolution.UnknownConfigKeys, []string{"connection_pool_size"}) {
+				t.Errorf("unknown config keys = %q, want the invented key flagged", resolution.UnknownConfigKeys)
+			}
+			got := config(t, ts)
+			if _, ok := got["connection_pool_size"]; ok || got["timeout"] != "30s" {
+				t.Errorf("config = %v, want the timeout fixed and no invented key", got)
+			}
+		})
+	}
+}
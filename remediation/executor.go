@@ -1,202 +1,477 @@
 package remediation
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"incident-ai/models"
 	"incident-ai/service"
+	"incident-ai/telemetry"
 	"log"
 	"strings"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Executor applies fixes to resolve incidents
 type Executor struct {
-	targetService *service.TargetService
+	targetService  *service.TargetService // nil when the service is managed externally
+	restartCommand *CommandConfig         // optional external command for restart fixes
+	guardrails     Guardrails             // actions fixes may not take (zero = no restrictions)
+	configStrategy ConfigStrategy         // how config fixes pick their changes (empty = ConfigFromSteps)
 }
 
-// NewExecutor creates a new remediation executor
-func NewExecutor(targetService *service.TargetService) *Executor {
-	return &Executor{
+// ErrUnmanagedService is returned for fixes that need the in-process target service
+// when the monitored service runs externally
+var ErrUnmanagedService = errors.New("service is managed externally and cannot be changed in-process")
+
+// NewExecutor creates a new remediation executor. targetService may be nil when the
+// monitored service runs externally; restarts then require a restart command.
+func NewExecutor(targetService *service.TargetService, opts ...Option) *Executor {
+	e := &Executor{
 		targetService: targetService,
 	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
 }
 
 // ExecuteFix applies the AI-suggested fix
-func (e *Executor) ExecuteFix(incident *models.Incident, aiResponse *models.AIResponse) (*models.Resolution, error) {
-	log.Printf("[REMEDIATION] Applying fix for incident %s (Type: %s)\n", incident.ID, aiResponse.FixType)
+func (e *Executor) ExecuteFix(ctx context.Context, incident *models.Incident, aiResponse *models.AIResponse) (*models.Resolution, error) {
+	telemetry.Logf(ctx, "[REMEDIATION] Applying fix for incident %s (Type: %s)\n", incident.ID, aiResponse.FixType)
+
+	ctx, span := telemetry.Tracer().Start(ctx, "remediation", trace.WithAttributes(
+		attribute.String("fix.type", aiResponse.FixType),
+		attribute.Bool("fix.cached", false),
+	))
 
 	resolution := &models.Resolution{
-		FixType:     aiResponse.FixType,
-		Description: aiResponse.Diagnosis,
-		Steps:       aiResponse.FixSteps,
-		Code:        aiResponse.Code,
-		Success:     false,
+		FixType:       aiResponse.FixType,
+		Description:   aiResponse.Diagnosis,
+		Steps:         aiResponse.FixSteps,
+		Code:          aiResponse.Code,
+		ConfigChanges: aiResponse.ConfigChanges,
+		Success:       false,
 	}
 
-	var err error
+	err := e.guardrails.checkFixType(ctx, aiResponse.FixType)
+	if err != nil {
+		telemetry.End(span, err)
+		return resolution, err
+	}
 
 	switch aiResponse.FixType {
 	case "restart":
-		err = e.executeRestart(aiResponse.FixSteps)
+		resolution.CommandOutput, err = e.executeRestart(ctx, aiResponse.FixSteps)
 	case "config":
-		err = e.executeConfigFix(aiResponse.FixSteps)
+		var result *configResult
+		result, err = e.executeConfigFix(ctx, aiResponse.FixSteps, aiResponse.ConfigChanges, incident.ConfigBaseline)
+		result.record(resolution)
 	case "code":
-		err = e.executeCodeFix(aiResponse)
+		err = e.executeCodeFix(ctx, aiResponse)
 	default:
 		err = fmt.Errorf("unknown fix type: %s", aiResponse.FixType)
 	}
 
+	telemetry.End(span, err)
+
 	if err != nil {
-		log.Printf("[REMEDIATION] ❌ Fix failed: %v\n", err)
+		telemetry.Logf(ctx, "[REMEDIATION] ❌ Fix failed: %v\n", err)
 		resolution.Success = false
 		return resolution, err
 	}
 
 	resolution.Success = true
-	log.Println("[REMEDIATION] ✓ Fix applied successfully")
+	telemetry.Logln(ctx, "[REMEDIATION] ✓ Fix applied successfully")
 
 	return resolution, nil
 }
 
-func (e *Executor) executeRestart(steps []string) error {
-	log.Println("[REMEDIATION] Executing restart fix...")
+// executeRestart restarts the service, in-process and/or via the configured external
+// command, returning any command output
+func (e *Executor) executeRestart(ctx context.Context, steps []string) (string, error) {
+	telemetry.Logln(ctx, "[REMEDIATION] Executing restart fix...")
 
 	for i, step := range steps {
-		log.Printf("[REMEDIATION]   Step %d: %s\n", i+1, step)
+		telemetry.Logf(ctx, "[REMEDIATION]   Step %d: %s\n", i+1, step)
+	}
+
+	return e.restart(ctx)
+}
+
+// Restart restarts the service outside of a fix, e.g. to give a fix whose verification
+// failed one more chance
+func (e *Executor) Restart(ctx context.Context) error {
+	if err := e.guardrails.checkFixType(ctx, "restart"); err != nil {
+		return err
+	}
+
+	telemetry.Logln(ctx, "[REMEDIATION] Restarting service...")
+	_, err := e.restart(ctx)
+	return err
+}
+
+// restart restarts the service in-process and/or via the configured external command,
+// returning any command output
+func (e *Executor) restart(ctx context.Context) (string, error) {
+	if e.restartCommand != nil && (e.restartCommand.Mode == CommandReplace || e.targetService == nil) {
+		return runCommand(ctx, e.restartCommand)
+	}
+
+	if e.targetService == nil {
+		return "", fmt.Errorf("cannot restart: %w (configure a restart command)", ErrUnmanagedService)
 	}
 
+	if err := e.restartInProcess(ctx); err != nil {
+		return "", err
+	}
+
+	if e.restartCommand != nil {
+		return runCommand(ctx, e.restartCommand)
+	}
+
+	return "", nil
+}
+
+// restartInProcess stops and starts the managed target service
+func (e *Executor) restartInProcess(ctx context.Context) error {
 	// Stop the service
 	if e.targetService.IsHealthy() || true { // Always try to stop
-		log.Println("[REMEDIATION]   → Stopping service...")
+		telemetry.Logln(ctx, "[REMEDIATION]   → Stopping service...")
 		if err := e.targetService.Stop(); err != nil {
-			log.Printf("[REMEDIATION]   → Stop error (continuing): %v\n", err)
+			telemetry.Logf(ctx, "[REMEDIATION]   → Stop error (continuing): %v\n", err)
 		}
 		time.Sleep(500 * time.Millisecond)
 	}
 
 	// Start the service
-	log.Println("[REMEDIATION]   → Starting service...")
+	telemetry.Logln(ctx, "[REMEDIATION]   → Starting service...")
 	if err := e.targetService.Start(); err != nil {
 		return fmt.Errorf("failed to start service: %w", err)
 	}
 
 	time.Sleep(1 * time.Second) // Give service time to fully start
 
-	log.Println("[REMEDIATION]   → Service restarted")
+	telemetry.Logln(ctx, "[REMEDIATION]   → Service restarted")
 	return nil
 }
 
-func (e *Executor) executeConfigFix(steps []string) error {
-	log.Println("[REMEDIATION] Executing config fix...")
+// configResult records what a config fix changed
+type configResult struct {
+	target  map[string]string   // key -> value the fix set out to write, whether or not the write took
+	applied map[string]string   // key -> value set on the service
+	unknown []string            // keys the service doesn't have, not set
+	blocked []string            // keys the guardrails forbid changing, not set
+	steps   []models.StepResult // outcome of each step, or of each structured change
+}
 
-	for i, step := range steps {
-		log.Printf("[REMEDIATION]   Step %d: %s\n", i+1, step)
+// record copies what a config fix did onto its resolution
+func (r *configResult) record(resolution *models.Resolution) {
+	resolution.TargetConfig, resolution.AppliedConfig = r.target, r.applied
+	resolution.UnknownConfigKeys, resolution.BlockedConfigKeys = r.unknown, r.blocked
+	resolution.StepResults = r.steps
+}
 
-		// Parse the step to extract config changes
-		if err := e.applyConfigStep(step); err != nil {
-			log.Printf("[REMEDIATION]   → Error: %v\n", err)
+// executeConfigFix sets the given config changes, or ones parsed from the steps when there
+// are none, and restarts the service. With the baseline strategy, it reverts the keys that
+// drifted from baseline instead. A fix whose every change was blocked by the guardrails
+// or failed to be written fails without restarting.
+func (e *Executor) executeConfigFix(ctx context.Context, steps []string, changes, baseline map[string]string) (*configResult, error) {
+	telemetry.Logln(ctx, "[REMEDIATION] Executing config fix...")
+
+	result := &configResult{target: make(map[string]string), applied: make(map[string]string)}
+	if e.targetService == nil {
+		return result, fmt.Errorf("cannot apply config: %w", ErrUnmanagedService)
+	}
+
+	useBaseline := e.configStrategy == ConfigFromBaseline
+	if useBaseline && len(baseline) == 0 {
+		telemetry.Logln(ctx, "[REMEDIATION]   ⚠️  No config baseline captured for this incident, applying the fix's steps")
+		useBaseline = false
+	}
+
+	if useBaseline {
+		// The baseline decides what changes, so the steps are only shown
+		for i, step := range steps {
+			telemetry.Logf(ctx, "[REMEDIATION]   Step %d: %s\n", i+1, step)
+		}
+		if err := e.restoreBaseline(ctx, baseline, result); err != nil {
+			return result, err
+		}
+	} else if len(changes) > 0 {
+		// Structured changes are exact, so the steps are only shown
+		for i, step := range steps {
+			telemetry.Logf(ctx, "[REMEDIATION]   Step %d: %s\n", i+1, step)
+		}
+		e.applyConfigChanges(ctx, changes, result)
+	} else {
+		result.unknown = e.checkConfigKeys(ctx, steps)
+
+		for i, step := range steps {
+			telemetry.Logf(ctx, "[REMEDIATION]   Step %d: %s\n", i+1, step)
+
+			// Parse the step to extract config changes
+			result.steps = append(result.steps, e.applyConfigStep(ctx, step, result))
 		}
 	}
 
+	if len(result.blocked) > 0 && len(result.applied) == 0 {
+		return result, fmt.Errorf("%w: config key(s) %s may not be changed", ErrForbiddenAction, strings.Join(result.blocked, ", "))
+	}
+	if len(result.target) > 0 && len(result.applied) == 0 {
+		return result, fmt.Errorf("no config change could be written: %s", strings.Join(sortedKeys(result.target), ", "))
+	}
+
 	// Always restart after config changes
-	log.Println("[REMEDIATION]   → Restarting service to apply config changes...")
-	return e.targetService.Restart()
+	telemetry.Logln(ctx, "[REMEDIATION]   → Restarting service to apply config changes...")
+	err := e.targetService.Restart()
+
+	// Restart steps are carried out by this restart
+	for i := range result.steps {
+		if result.steps[i].Action != restartAction {
+			continue
+		}
+		if err != nil {
+			result.steps[i].Error = err.Error()
+		} else {
+			result.steps[i].Applied = true
+		}
+	}
+	return result, err
+}
+
+// restartAction is the action of config fix steps carried out by the restart that ends the fix
+const restartAction = "restart the service"
+
+// setConfig updates a config value on the service and records it as applied, unless the
+// guardrails forbid changing the key or the write fails. The returned step result has no
+// step text.
+func (e *Executor) setConfig(ctx context.Context, key, value string, result *configResult) models.StepResult {
+	stepResult := models.StepResult{Action: fmt.Sprintf("set %s=%s", key, value)}
+	if !e.guardrails.allowsConfigKey(key) {
+		telemetry.Logf(ctx, "[REMEDIATION]     🛑 Guardrail blocked change to %s\n", key)
+		result.blocked = append(result.blocked, key)
+		stepResult.Error = fmt.Sprintf("%s may not be changed (guardrails)", key)
+		return stepResult
+	}
+
+	result.target[key] = value
+	if err := e.targetService.SetConfig(key, value); err != nil {
+		telemetry.Logf(ctx, "[REMEDIATION]     ✗ %v\n", err)
+		stepResult.Error = err.Error()
+		return stepResult
+	}
+	result.applied[key] = value
+	stepResult.Applied = true
+	return stepResult
+}
+
+// applyConfigChanges sets each change in key order, skipping keys the service doesn't have
+func (e *Executor) applyConfigChanges(ctx context.Context, changes map[string]string, result *configResult) {
+	known, err := e.targetService.GetConfig()
+	if err != nil {
+		telemetry.Logf(ctx, "[REMEDIATION]   ⚠️  Can't read the config to check keys: %v\n", err)
+	}
+
+	for _, key := range sortedKeys(changes) {
+		step := fmt.Sprintf("%s=%s", key, changes[key])
+		if _, ok := known[key]; !ok && len(known) > 0 {
+			result.unknown = append(result.unknown, key)
+			result.steps = append(result.steps, models.StepResult{Step: step, Error: fmt.Sprintf("unknown config key %s", key)})
+			continue
+		}
+		telemetry.Logf(ctx, "[REMEDIATION]     → Setting %s to %s\n", key, changes[key])
+		stepResult := e.setConfig(ctx, key, changes[key], result)
+		stepResult.Step = step
+		result.steps = append(result.steps, stepResult)
+	}
+
+	if len(result.unknown) > 0 {
+		telemetry.Logf(ctx, "[REMEDIATION]   ⚠️  Fix refers to unknown config key(s) %s - not applied (known: %s)\n",
+			strings.Join(result.unknown, ", "), strings.Join(sortedKeys(known), ", "))
+	}
+}
+
+// restoreBaseline reverts every config key whose value differs from baseline. Keys added
+// since the baseline was captured can't be removed, so they are only reported. It fails
+// if the current config can't be read to compare.
+func (e *Executor) restoreBaseline(ctx context.Context, baseline map[string]string, result *configResult) error {
+	telemetry.Logln(ctx, "[REMEDIATION]   Restoring config to its pre-incident baseline")
+	current, err := e.targetService.GetConfig()
+	if err != nil {
+		telemetry.Logf(ctx, "[REMEDIATION]     ✗ Can't read the config to compare: %v\n", err)
+		result.steps = append(result.steps, models.StepResult{Step: "read config", Action: "compare config to baseline", Error: err.Error()})
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	for _, key := range sortedKeys(baseline) {
+		value, ok := current[key]
+		if ok && value == baseline[key] {
+			continue
+		}
+		telemetry.Logf(ctx, "[REMEDIATION]     → Reverting %s from %q to %q\n", key, value, baseline[key])
+		stepResult := e.setConfig(ctx, key, baseline[key], result)
+		stepResult.Step = fmt.Sprintf("revert %s to baseline", key)
+		result.steps = append(result.steps, stepResult)
+	}
+
+	var added []string
+	for _, key := range sortedKeys(current) {
+		if _, ok := baseline[key]; !ok {
+			added = append(added, key)
+		}
+	}
+	if len(added) > 0 {
+		telemetry.Logf(ctx, "[REMEDIATION]   ⚠️  Config key(s) %s are not in the baseline, left as is\n", strings.Join(added, ", "))
+	}
+	if len(result.steps) == 0 {
+		telemetry.Logln(ctx, "[REMEDIATION]   Config already matches its baseline, nothing to revert")
+	}
+	return nil
 }
 
-func (e *Executor) applyConfigStep(step string) error {
-	step = strings.ToLower(step)
+// applyConfigStep applies the config change a step describes, returning what it did
+func (e *Executor) applyConfigStep(ctx context.Context, step string, result *configResult) models.StepResult {
+	stepResult := e.parseConfigStep(ctx, strings.ToLower(step), result)
+	stepResult.Step = step
+	return stepResult
+}
 
+func (e *Executor) parseConfigStep(ctx context.Context, step string, result *configResult) models.StepResult {
 	// Look for common config patterns in the step description
 	if strings.Contains(step, "database_url") || strings.Contains(step, "database url") {
 		if strings.Contains(step, "localhost:5432") || strings.Contains(step, "restore") {
-			log.Println("[REMEDIATION]     → Restoring database_url to localhost:5432")
-			e.targetService.SetConfig("database_url", "localhost:5432")
-			return nil
+			telemetry.Logln(ctx, "[REMEDIATION]     → Restoring database_url to localhost:5432")
+			return e.setConfig(ctx, "database_url", "localhost:5432", result)
 		}
 	}
 
 	if strings.Contains(step, "timeout") {
 		if strings.Contains(step, "30s") || strings.Contains(step, "restore") || strings.Contains(step, "reset") {
-			log.Println("[REMEDIATION]     → Restoring timeout to 30s")
-			e.targetService.SetConfig("timeout", "30s")
-			return nil
+			telemetry.Logln(ctx, "[REMEDIATION]     → Restoring timeout to 30s")
+			return e.setConfig(ctx, "timeout", "30s", result)
 		}
 	}
 
 	if strings.Contains(step, "max_retries") || strings.Contains(step, "retries") {
 		if strings.Contains(step, "3") || strings.Contains(step, "restore") {
-			log.Println("[REMEDIATION]     → Restoring max_retries to 3")
-			e.targetService.SetConfig("max_retries", "3")
-			return nil
+			telemetry.Logln(ctx, "[REMEDIATION]     → Restoring max_retries to 3")
+			return e.setConfig(ctx, "max_retries", "3", result)
 		}
 	}
 
 	// If it's a restart step, skip it (will be done after all config changes)
 	if strings.Contains(step, "restart") {
-		return nil
+		return models.StepResult{Action: restartAction}
 	}
 
-	// If we can't parse the step, log it but don't error
-	log.Printf("[REMEDIATION]     → Config step noted: %s\n", step)
-	return nil
+	// A step we can't parse doesn't fail the fix, but is recorded as not applied
+	telemetry.Logf(ctx, "[REMEDIATION]     → No config change recognized, not applied: %s\n", step)
+	return models.StepResult{Error: "no config change recognized in step"}
 }
 
-func (e *Executor) executeCodeFix(aiResponse *models.AIResponse) error {
-	log.Println("[REMEDIATION] Executing code fix...")
-	log.Println("[REMEDIATION]   ⚠️  Code fixes require manual intervention")
-	log.Println("[REMEDIATION]   Code provided by AI:")
+func (e *Executor) executeCodeFix(ctx context.Context, aiResponse *models.AIResponse) error {
+	telemetry.Logln(ctx, "[REMEDIATION] Executing code fix...")
+	telemetry.Logln(ctx, "[REMEDIATION]   ⚠️  Code fixes require manual intervention")
+	telemetry.Logln(ctx, "[REMEDIATION]   Code provided by AI:")
 	log.Println("[REMEDIATION]   " + strings.Repeat("-", 60))
 
 	if aiResponse.Code != "" {
 		// Print code with indentation
 		codeLines := strings.Split(aiResponse.Code, "\n")
 		for _, line := range codeLines {
-			log.Printf("[REMEDIATION]   %s\n", line)
+			telemetry.Logf(ctx, "[REMEDIATION]   %s\n", line)
 		}
 	} else {
-		log.Println("[REMEDIATION]   (No code provided)")
+		telemetry.Logln(ctx, "[REMEDIATION]   (No code provided)")
 	}
 
 	log.Println("[REMEDIATION]   " + strings.Repeat("-", 60))
 
+	if e.targetService == nil {
+		return fmt.Errorf("cannot restart as fallback: %w", ErrUnmanagedService)
+	}
+
 	// For demo purposes, we'll apply a generic fix
-	log.Println("[REMEDIATION]   → Attempting restart as fallback...")
+	telemetry.Logln(ctx, "[REMEDIATION]   → Attempting restart as fallback...")
 	return e.targetService.Restart()
 }
 
 // ApplyCachedFix applies a previously successful fix
-func (e *Executor) ApplyCachedFix(incident *models.Incident, cachedResolution *models.Resolution) error {
-	log.Printf("[REMEDIATION] Applying cached fix for incident %s\n", incident.ID)
-	log.Println("[REMEDIATION] ⚡ Using learned solution (no AI call needed)")
+func (e *Executor) ApplyCachedFix(ctx context.Context, incident *models.Incident, cachedResolution *models.Resolution) error {
+	telemetry.Logf(ctx, "[REMEDIATION] Applying cached fix for incident %s\n", incident.ID)
+	telemetry.Logln(ctx, "[REMEDIATION] ⚡ Using learned solution (no AI call needed)")
 
-	var err error
+	ctx, span := telemetry.Tracer().Start(ctx, "remediation", trace.WithAttributes(
+		attribute.String("fix.type", cachedResolution.FixType),
+		attribute.Bool("fix.cached", true),
+	))
+
+	err := e.guardrails.checkFixType(ctx, cachedResolution.FixType)
+	if err != nil {
+		telemetry.End(span, err)
+		return err
+	}
 
 	switch cachedResolution.FixType {
 	case "restart":
-		err = e.executeRestart(cachedResolution.Steps)
+		_, err = e.executeRestart(ctx, cachedResolution.Steps)
 	case "config":
-		err = e.executeConfigFix(cachedResolution.Steps)
+		// The copy of the learned fix becomes this incident's resolution, so it records what
+		// this run did rather than what the run it was learned from did
+		var result *configResult
+		result, err = e.executeConfigFix(ctx, cachedResolution.Steps, replayedConfig(cachedResolution), incident.ConfigBaseline)
+		result.record(cachedResolution)
 	case "code":
-		log.Println("[REMEDIATION] ⚠️  Code fixes cannot be auto-applied from cache")
-		err = e.targetService.Restart()
+		telemetry.Logln(ctx, "[REMEDIATION] ⚠️  Code fixes cannot be auto-applied from cache")
+		if e.targetService == nil {
+			err = fmt.Errorf("cannot restart: %w", ErrUnmanagedService)
+		} else {
+			err = e.targetService.Restart()
+		}
 	default:
 		err = fmt.Errorf("unknown fix type: %s", cachedResolution.FixType)
 	}
 
+	telemetry.End(span, err)
+
 	if err != nil {
-		log.Printf("[REMEDIATION] ❌ Cached fix failed: %v\n", err)
+		telemetry.Logf(ctx, "[REMEDIATION] ❌ Cached fix failed: %v\n", err)
 		return err
 	}
 
-	log.Println("[REMEDIATION] ✓ Cached fix applied successfully")
+	telemetry.Logln(ctx, "[REMEDIATION] ✓ Cached fix applied successfully")
 	return nil
 }
 
+// replayedConfig returns the config changes to replay for a learned config fix: the ones it
+// asked for, or for fixes learned before those were recorded, the ones it ended up setting
+func replayedConfig(resolution *models.Resolution) map[string]string {
+	if len(resolution.ConfigChanges) > 0 {
+		return resolution.ConfigChanges
+	}
+	return resolution.AppliedConfig
+}
+
 // GetStatus returns current status of the service
 func (e *Executor) GetStatus() map[string]interface{} {
-	return map[string]interface{}{
+	if e.targetService == nil {
+		return map[string]interface{}{"managed": false}
+	}
+
+	status := map[string]interface{}{
 		"service_healthy": e.targetService.IsHealthy(),
-		"configuration":   e.targetService.GetConfig(),
 		"recent_logs":     e.targetService.GetLogs(),
 	}
+	if config, err := e.targetService.GetConfig(); err != nil {
+		status["configuration_error"] = err.Error()
+	} else {
+		status["configuration"] = config
+	}
+	return status
 }
@@ -0,0 +1,265 @@
+package remediation
+
+import (
+	"context"
+	"errors"
+	"incident-ai/models"
+	"incident-ai/service"
+	"maps"
+	"reflect"
+	"testing"
+)
+
+// newTestService creates a target service holding the default config with changes
+// applied. It isn't started until a fix restarts it, and is stopped when the test ends.
+func newTestService(t *testing.T, changes map[string]string) *service.TargetService {
+	t.Helper()
+
+	ts, err := service.NewTargetService("0")
+	if err != nil {
+		t.Fatalf("NewTargetService: %v", err)
+	}
+	t.Cleanup(func() { ts.Stop() })
+	for key, value := range changes {
+		if err := ts.SetConfig(key, value); err != nil {
+			t.Fatalf("SetConfig(%s): %v", key, err)
+		}
+	}
+	return ts
+}
+
+// config reads the service's config, failing the test if it can't
+func config(t *testing.T, ts *service.TargetService) map[string]string {
+	t.Helper()
+
+	config, err := ts.GetConfig()
+	if err != nil {
+		t.Fatalf("GetConfig: %v", err)
+	}
+	return config
+}
+
+func TestApplyCachedFixReplaysConfigChanges(t *testing.T) {
+	changes := map[string]string{"database_url": "db.internal:5432", "max_retries": "5"}
+
+	tests := []struct {
+		name    string
+		learned *models.Resolution
+	}{
+		{
+			name: "config changes",
+			learned: &models.Resolution{
+				FixType:       "config",
+				Steps:         []string{"Point the service at the internal database", "Allow more retries"},
+				ConfigChanges: changes,
+				AppliedConfig: map[string]string{"timeout": "30s"},
+				Success:       true,
+			},
+		},
+		{
+			name: "learned before config changes were recorded",
+			learned: &models.Resolution{
+				FixType:       "config",
+				Steps:         []string{"Point the service at the internal database", "Allow more retries"},
+				AppliedConfig: changes,
+				Success:       true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := newTestService(t, map[string]string{"database_url": "invalid::url::format", "max_retries": "0"})
+			executor := NewExecutor(ts)
+
+			incident := &models.Incident{ID: "incident-1", Type: models.ConfigError}
+			if err := executor.ApplyCachedFix(context.Background(), incident, tt.learned); err != nil {
+				t.Fatalf("ApplyCachedFix: %v", err)
+			}
+
+			want := map[string]string{"database_url": "db.internal:5432", "max_retries": "5", "timeout": "30s"}
+			if got := config(t, ts); !maps.Equal(got, want) {
+				t.Errorf("config after replay = %v, want %v", got, want)
+			}
+			if !maps.Equal(tt.learned.AppliedConfig, changes) {
+				t.Errorf("resolution records %v as applied, want exactly %v", tt.learned.AppliedConfig, changes)
+			}
+		})
+	}
+}
+
+func TestConfigFixStepResults(t *testing.T) {
+	tests := []struct {
+		name       string
+		fix        *models.AIResponse
+		wantSteps  []models.StepResult
+		wantConfig map[string]string
+	}{
+		{
+			name: "parsed steps",
+			fix: &models.AIResponse{
+				FixType:  "config",
+				FixSteps: []string{"Restore database_url to localhost:5432", "Tune the connection pool", "Restart the service"},
+			},
+			wantSteps: []models.StepResult{
+				{Step: "Restore database_url to localhost:5432", Applied: true, Action: "set database_url=localhost:5432"},
+				{Step: "Tune the connection pool", Error: "no config change recognized in step"},
+				{Step: "Restart the service", Applied: true, Action: restartAction},
+			},
+			wantConfig: map[string]string{"database_url": "localhost:5432", "timeout": "not-a-number", "max_retries": "3"},
+		},
+		{
+			name: "config changes",
+			fix: &models.AIResponse{
+				FixType:       "config",
+				FixSteps:      []string{"Reset the timeout", "Raise the pool size"},
+				ConfigChanges: map[string]string{"timeout": "30s", "pool_size": "20"},
+			},
+			wantSteps: []models.StepResult{
+				{Step: "pool_size=20", Error: "unknown config key pool_size"},
+				{Step: "timeout=30s", Applied: true, Action: "set timeout=30s"},
+			},
+			wantConfig: map[string]string{"database_url": "invalid::url::format", "timeout": "30s", "max_retries": "3"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := newTestService(t, map[string]string{"database_url": "invalid::url::format", "timeout": "not-a-number"})
+			executor := NewExecutor(ts)
+
+			incident := &models.Incident{ID: "incident-1", Type: models.ConfigError}
+			resolution, err := executor.ExecuteFix(context.Background(), incident, tt.fix)
+			if err != nil {
+				t.Fatalf("ExecuteFix: %v", err)
+			}
+
+			// One step applying is enough for the fix to succeed
+			if !resolution.Success {
+				t.Error("partially applied fix not marked successful")
+			}
+			if !reflect.DeepEqual(resolution.StepResults, tt.wantSteps) {
+				t.Errorf("step results:\n got %+v\nwant %+v", resolution.StepResults, tt.wantSteps)
+			}
+			if got := config(t, ts); !maps.Equal(got, tt.wantConfig) {
+				t.Errorf("config = %v, want %v", got, tt.wantConfig)
+			}
+		})
+	}
+}
+
+func TestBaselineRestoreRevertsDriftedKeys(t *testing.T) {
+	baseline := map[string]string{"database_url": "localhost:5432", "timeout": "30s", "max_retries": "3"}
+	// Only the timeout is mentioned, though the database URL and retries drifted too
+	fix := &models.AIResponse{FixType: "config", FixSteps: []string{"Reset timeout to 30s", "Restart the service"}}
+
+	tests := []struct {
+		name       string
+		strategy   ConfigStrategy
+		baseline   map[string]string
+		wantConfig map[string]string
+		wantSteps  []string
+	}{
+		{
+			name:       "baseline strategy",
+			strategy:   ConfigFromBaseline,
+			baseline:   baseline,
+			wantConfig: map[string]string{"database_url": "localhost:5432", "timeout": "30s", "max_retries": "3", "debug": "true"},
+			wantSteps:  []string{"revert database_url to baseline", "revert max_retries to baseline", "revert timeout to baseline"},
+		},
+		{
+			name:       "baseline strategy without a baseline",
+			strategy:   ConfigFromBaseline,
+			wantConfig: map[string]string{"database_url": "invalid::url::format", "timeout": "30s", "max_retries": "0", "debug": "true"},
+			wantSteps:  []string{"Reset timeout to 30s", "Restart the service"},
+		},
+		{
+			name:       "steps strategy",
+			strategy:   ConfigFromSteps,
+			baseline:   baseline,
+			wantConfig: map[string]string{"database_url": "invalid::url::format", "timeout": "30s", "max_retries": "0", "debug": "true"},
+			wantSteps:  []string{"Reset timeout to 30s", "Restart the service"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// debug was added after the baseline was captured, so there is nothing to revert it to
+			ts := newTestService(t, map[string]string{
+				"database_url": "invalid::url::format",
+				"timeout":      "not-a-number",
+				"max_retries":  "0",
+				"debug":        "true",
+			})
+			executor := NewExecutor(ts, WithConfigStrategy(tt.strategy))
+
+			incident := &models.Incident{ID: "incident-1", Type: models.ConfigError, ConfigBaseline: tt.baseline}
+			resolution, err := executor.ExecuteFix(context.Background(), incident, fix)
+			if err != nil {
+				t.Fatalf("ExecuteFix: %v", err)
+			}
+
+			if got := config(t, ts); !maps.Equal(got, tt.wantConfig) {
+				t.Errorf("config = %v, want %v", got, tt.wantConfig)
+			}
+			var steps []string
+			for _, result := range resolution.StepResults {
+				steps = append(steps, result.Step)
+				if result.Error != "" {
+					t.Errorf("step %q failed: %s", result.Step, result.Error)
+				}
+			}
+			if !reflect.DeepEqual(steps, tt.wantSteps) {
+				t.Errorf("steps = %q, want %q", steps, tt.wantSteps)
+			}
+		})
+	}
+}
+
+func TestFixesForManagedAndUnmanagedService(t *testing.T) {
+	fixes := []*models.AIResponse{
+		{FixType: "restart", FixSteps: []string{"Restart the service"}},
+		{FixType: "config", FixSteps: []string{"Reset timeout to 30s"}},
+		{FixType: "code", FixSteps: []string{"Guard against the nil config"}, Code: "if cfg == nil { return }"},
+	}
+	incident := &models.Incident{ID: "incident-1", Type: models.ConfigError}
+
+	// A managed service is fixed in-process
+	ts := newTestService(t, map[string]string{"timeout": "not-a-number"})
+	managed := NewExecutor(ts)
+	for _, fix := range fixes {
+		if _, err := managed.ExecuteFix(context.Background(), incident, fix); err != nil {
+			t.Errorf("%s fix of a managed service: %v", fix.FixType, err)
+		}
+	}
+	if !ts.IsRunning() || config(t, ts)["timeout"] != "30s" {
+		t.Errorf("managed service running = %v with config %v, want restarted with the timeout reset", ts.IsRunning(), config(t, ts))
+	}
+	if status := managed.GetStatus(); status["configuration"] == nil {
+		t.Errorf("managed status = %v, want the service's config", status)
+	}
+
+	// An external one can't be changed in-process, and fixes fail rather than touching anything
+	unmanaged := NewExecutor(nil)
+	for _, fix := range fixes {
+		if _, err := unmanaged.ExecuteFix(context.Background(), incident, fix); !errors.Is(err, ErrUnmanagedService) {
+			t.Errorf("%s fix of an unmanaged service = %v, want ErrUnmanagedService", fix.FixType, err)
+		}
+		cached := &models.Resolution{FixType: fix.FixType, Steps: fix.FixSteps, Success: true}
+		if err := unmanaged.ApplyCachedFix(context.Background(), incident, cached); !errors.Is(err, ErrUnmanagedService) {
+			t.Errorf("cached %s fix of an unmanaged service = %v, want ErrUnmanagedService", fix.FixType, err)
+		}
+	}
+	if status := unmanaged.GetStatus(); !maps.Equal(status, map[string]interface{}{"managed": false}) {
+		t.Errorf("unmanaged status = %v, want only managed=false", status)
+	}
+}
@@ -0,0 +1,77 @@
+package remediation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"incident-ai/telemetry"
+	"slices"
+	"strings"
+)
+
+// ErrForbiddenAction is returned when a guardrail blocks a fix
+var ErrForbiddenAction = errors.New("action forbidden by guardrails")
+
+// Guardrails restricts what fixes may do, whoever suggested them. The zero value allows
+// everything.
+type Guardrails struct {
+	AllowedFixTypes     []string // fix types that may be applied (empty = all)
+	AllowedConfigKeys   []string // config keys fixes may change (empty = any key not forbidden)
+	ForbiddenConfigKeys []string // config keys fixes may never change
+}
+
+// ParseGuardrails builds guardrails from comma-separated lists, e.g. "restart,config" fix
+// types and "database_url" forbidden keys. Empty lists impose no restriction.
+func ParseGuardrails(allowedFixTypes, allowedConfigKeys, forbiddenConfigKeys string) (Guardrails, error) {
+	g := Guardrails{
+		AllowedFixTypes:     splitList(allowedFixTypes),
+		AllowedConfigKeys:   splitList(allowedConfigKeys),
+		ForbiddenConfigKeys: splitList(forbiddenConfigKeys),
+	}
+
+	for _, fixType := range g.AllowedFixTypes {
+		switch fixType {
+		case "restart", "config", "code":
+		default:
+			return Guardrails{}, fmt.Errorf("unknown fix type %q (valid: restart, config, code)", fixType)
+		}
+	}
+
+	for _, key := range g.ForbiddenConfigKeys {
+		if slices.Contains(g.AllowedConfigKeys, key) {
+			return Guardrails{}, fmt.Errorf("config key %q is both allowed and forbidden", key)
+		}
+	}
+
+	return g, nil
+}
+
+// splitList splits a comma-separated list, dropping blank entries
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// checkFixType returns an ErrForbiddenAction error, and logs it, if fixes of this type
+// may not be applied
+func (g Guardrails) checkFixType(ctx context.Context, fixType string) error {
+	if len(g.AllowedFixTypes) == 0 || slices.Contains(g.AllowedFixTypes, fixType) {
+		return nil
+	}
+
+	telemetry.Logf(ctx, "[REMEDIATION] 🛑 Guardrail blocked %s fix (allowed: %s)\n", fixType, strings.Join(g.AllowedFixTypes, ", "))
+	return fmt.Errorf("%w: %s fixes are not allowed", ErrForbiddenAction, fixType)
+}
+
+// allowsConfigKey reports whether fixes may change a config key
+func (g Guardrails) allowsConfigKey(key string) bool {
+	if slices.Contains(g.ForbiddenConfigKeys, key) {
+		return false
+	}
+	return len(g.AllowedConfigKeys) == 0 || slices.Contains(g.AllowedConfigKeys, key)
+}
@@ -0,0 +1,85 @@
+package remediation
+
+import (
+	"fmt"
+	"time"
+)
+
+// Option configures an Executor
+type Option func(*Executor)
+
+// CommandMode controls how an external restart command relates to the in-process restart
+type CommandMode string
+
+const (
+	// CommandReplace runs the command instead of restarting the in-process service
+	CommandReplace CommandMode = "replace"
+	// CommandAppend restarts the in-process service, then runs the command
+	CommandAppend CommandMode = "append"
+)
+
+// ParseCommandMode validates a command mode name
+func ParseCommandMode(s string) (CommandMode, error) {
+	switch CommandMode(s) {
+	case CommandReplace, CommandAppend:
+		return CommandMode(s), nil
+	default:
+		return "", fmt.Errorf("unknown command mode %q (valid: %s, %s)", s, CommandReplace, CommandAppend)
+	}
+}
+
+// CommandConfig describes an external command used for restart fixes,
+// e.g. "systemctl restart my-service" or "kubectl rollout restart deploy/my-service"
+type CommandConfig struct {
+	Path    string
+	Args    []string
+	Dir     string        // working directory; empty means the current directory
+	Timeout time.Duration // zero means no timeout beyond the caller's context
+	Mode    CommandMode
+}
+
+// WithRestartCommand runs an external command for restart fixes
+func WithRestartCommand(cmd CommandConfig) Option {
+	return func(e *Executor) {
+		if cmd.Mode == "" {
+			cmd.Mode = CommandReplace
+		}
+		e.restartCommand = &cmd
+	}
+}
+
+// ConfigStrategy controls how config fixes decide which values to set
+type ConfigStrategy string
+
+const (
+	// ConfigFromSteps sets the changes a fix asks for, or ones parsed from its steps
+	ConfigFromSteps ConfigStrategy = "steps"
+	// ConfigFromBaseline reverts every key that drifted from the incident's pre-incident
+	// baseline, whatever the fix's steps say
+	ConfigFromBaseline ConfigStrategy = "baseline"
+)
+
+// ParseConfigStrategy validates a config strategy name
+func ParseConfigStrategy(s string) (ConfigStrategy, error) {
+	switch ConfigStrategy(s) {
+	case ConfigFromSteps, ConfigFromBaseline:
+		return ConfigStrategy(s), nil
+	default:
+		return "", fmt.Errorf("unknown config strategy %q (valid: %s, %s)", s, ConfigFromSteps, ConfigFromBaseline)
+	}
+}
+
+// WithConfigStrategy sets how config fixes decide which values to set. With
+// ConfigFromBaseline, incidents without a captured baseline still use the fix's steps.
+func WithConfigStrategy(strategy ConfigStrategy) Option {
+	return func(e *Executor) {
+		e.configStrategy = strategy
+	}
+}
+
+// WithGuardrails restricts the fix types fixes may use and the config keys they may change
+func WithGuardrails(g Guardrails) Option {
+	return func(e *Executor) {
+		e.guardrails = g
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"incident-ai/memory"
+	"incident-ai/models"
+	"incident-ai/notify"
+	"incident-ai/trace"
+	"log"
+	"strings"
+)
+
+// runReplay feeds a recorded trace back through the orchestrator's incident handling,
+// with the recorded analyses, fix results and health checks standing in for the live
+// AI and service, and checks that every incident ends in its recorded final state
+func runReplay(path string) error {
+	events, err := trace.Load(path)
+	if err != nil {
+		return fmt.Errorf("failed to load trace: %w", err)
+	}
+
+	replayer := trace.NewReplayer(events)
+	store, err := memory.NewStore("") // in-memory only, never touches incident_memory.json
+	if err != nil {
+		return err
+	}
+
+	orch := &Orchestrator{
+		analyzer: replayer,
+		executor: replayer,
+		verifier: replayer,
+		store:    store,
+		notifier: notify.NewLogNotifier(),
+		useAI:    replayer.UsedAI(),
+	}
+
+	detections := replayer.Detections()
+	log.Printf("[REPLAY] Replaying %d incident(s) from %s\n", len(detections), path)
+
+	mismatches := 0
+	for _, detection := range detections {
+		incident := detection.Incident
+
+		// Recreate the learned fix that was available when the incident was detected
+		if detection.LearnedFix != nil {
+			if err := store.SetLearnedFix(incident.Type, detection.LearnedFix); err != nil {
+				return fmt.Errorf("failed to seed learned fix: %w", err)
+			}
+		}
+
+		if err := orch.processIncident(context.Background(), incident); err != nil {
+			log.Printf("[REPLAY] Incident %s returned error: %v\n", incident.ID, err)
+		}
+
+		recorded, exists := replayer.Outcome(incident.ID)
+		if !exists {
+			log.Printf("[REPLAY] ⚠️  No recorded outcome for incident %s\n", incident.ID)
+			continue
+		}
+
+		if diffs := compareOutcome(recorded, incident); len(diffs) > 0 {
+			mismatches++
+			log.Printf("[REPLAY] ❌ Incident %s diverged from the trace:\n", incident.ID)
+			for _, diff := range diffs {
+				log.Printf("[REPLAY]   %s\n", diff)
+			}
+		} else {
+			log.Printf("[REPLAY] ✓ Incident %s matches recorded outcome (%s)\n", incident.ID, incident.Status)
+		}
+	}
+
+	store.PrintSummary()
+
+	if mismatches > 0 {
+		return fmt.Errorf("%d incident(s) diverged from the recorded trace", mismatches)
+	}
+	return nil
+}
+
+// compareOutcome lists the differences between a recorded and a replayed final incident state
+func compareOutcome(recorded, replayed *models.Incident) []string {
+	diffs := []string{}
+
+	check := func(field string, want, got interface{}) {
+		if fmt.Sprint(want) != fmt.Sprint(got) {
+			diffs = append(diffs, fmt.Sprintf("%s: recorded %v, replayed %v", field, want, got))
+		}
+	}
+
+	check("status", recorded.Status, replayed.Status)
+	check("type", recorded.Type, replayed.Type)
+	check("diagnosis", recorded.Diagnosis, replayed.Diagnosis)
+	check("used_cached_fix", recorded.UsedCachedFix, replayed.UsedCachedFix)
+	check("resolution", describeResolution(recorded.Resolution), describeResolution(replayed.Resolution))
+
+	return diffs
+}
+
+func describeResolution(resolution *models.Resolution) string {
+	if resolution == nil {
+		return "none"
+	}
+	return fmt.Sprintf("%s success=%v steps=[%s]", resolution.FixType, resolution.Success, strings.Join(resolution.Steps, "; "))
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"incident-ai/models"
+	"incident-ai/trace"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// recordSession handles a fresh incident, then one whose learned fix fails verification,
+// recording the session to a trace file whose path it returns
+func recordSession(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	recorder, err := trace.NewRecorder(path)
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+
+	o := newTestOrchestrator(t)
+	o.recorder = recorder
+	var unhealthy atomic.Bool
+	o.verifier = &fakeVerifier{healthy: func(int) bool { return !unhealthy.Load() }}
+
+	for _, id := range []string{"first", "second"} {
+		unhealthy.Store(id == "second")
+		incident := newTestIncident(id, models.ServiceDown, "health check timed out")
+		if err := o.processIncident(context.Background(), incident); err != nil {
+			t.Fatalf("processIncident(%s): %v", id, err)
+		}
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("closing recorder: %v", err)
+	}
+	return path
+}
+
+func TestReplayReproducesRecordedSession(t *testing.T) {
+	path := recordSession(t)
+
+	events, err := trace.Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	kinds := map[trace.EventKind]int{}
+	for _, event := range events {
+		kinds[event.Kind]++
+	}
+	if kinds[trace.EventDetection] != 2 || kinds[trace.EventOutcome] != 2 || kinds[trace.EventAnalysis] == 0 || kinds[trace.EventCachedFix] != 1 || kinds[trace.EventVerification] == 0 {
+		t.Fatalf("recorded events %v, want two detections and outcomes, analysis, the cached fix and verification", kinds)
+	}
+
+	if err := runReplay(path); err != nil {
+		t.Errorf("replay diverged from the recording: %v", err)
+	}
+}
+
+func TestReplayReportsDivergence(t *testing.T) {
+	path := recordSession(t)
+
+	// Claim the resolved incident failed
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading trace: %v", err)
+	}
+	tampered := strings.ReplaceAll(string(raw), `"status":"RESOLVED"`, `"status":"FAILED"`)
+	if tampered == string(raw) {
+		t.Fatal("no resolved outcome in the trace")
+	}
+	if err := os.WriteFile(path, []byte(tampered), 0644); err != nil {
+		t.Fatalf("writing trace: %v", err)
+	}
+
+	if err := runReplay(path); err == nil || !strings.Contains(err.Error(), "diverged") {
+		t.Errorf("replay of a tampered trace = %v, want a divergence", err)
+	}
+}
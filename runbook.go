@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"incident-ai/models"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Runbook is an operator-written remediation for one incident type, applied instead of
+// learned and AI-generated fixes
+type Runbook struct {
+	Description string   `yaml:"description"`
+	FixType     string   `yaml:"fix_type"` // restart, config or code
+	Steps       []string `yaml:"steps"`    // applied in order
+	Code        string   `yaml:"code"`     // required for code fixes
+
+	ConfigChanges map[string]string `yaml:"config_changes"` // config key -> value, set exactly by config fixes
+}
+
+// validate checks a runbook can be executed
+func (rb *Runbook) validate() error {
+	switch rb.FixType {
+	case "restart", "config", "code":
+	case "":
+		return fmt.Errorf("missing fix_type")
+	default:
+		return fmt.Errorf("fix_type %q must be one of restart, config, code", rb.FixType)
+	}
+
+	if len(rb.Steps) == 0 {
+		return fmt.Errorf("missing steps")
+	}
+	for i, step := range rb.Steps {
+		if strings.TrimSpace(step) == "" {
+			return fmt.Errorf("step %d is empty", i+1)
+		}
+	}
+
+	if rb.FixType == "code" && strings.TrimSpace(rb.Code) == "" {
+		return fmt.Errorf("code fix has no code")
+	}
+	if rb.FixType != "config" && len(rb.ConfigChanges) > 0 {
+		return fmt.Errorf("config_changes are only allowed on config fixes")
+	}
+
+	return nil
+}
+
+// response returns the runbook as the fix to execute. diagnosis, when given, supplies the
+// diagnosis and root cause; its fix and type correction are discarded.
+func (rb *Runbook) response(diagnosis *models.AIResponse) *models.AIResponse {
+	response := &models.AIResponse{
+		Diagnosis:     rb.Description,
+		FixType:       rb.FixType,
+		FixSteps:      append([]string(nil), rb.Steps...),
+		Code:          rb.Code,
+		ConfigChanges: rb.ConfigChanges,
+		Confidence:    1,
+	}
+
+	if diagnosis != nil {
+		if diagnosis.Diagnosis != "" {
+			response.Diagnosis = diagnosis.Diagnosis
+		}
+		response.RootCauseCategory = diagnosis.RootCauseCategory
+		response.Recommendations = diagnosis.Recommendations
+		response.SystemFingerprint = diagnosis.SystemFingerprint
+	}
+	if response.Diagnosis == "" {
+		response.Diagnosis = fmt.Sprintf("Handled by the %s runbook", rb.FixType)
+	}
+
+	return response
+}
+
+// RunbookRegistry holds runbooks keyed by incident type. It is filled at startup and only
+// read afterwards.
+type RunbookRegistry struct {
+	runbooks map[models.IncidentType]*Runbook
+}
+
+// NewRunbookRegistry creates an empty runbook registry
+func NewRunbookRegistry() *RunbookRegistry {
+	return &RunbookRegistry{runbooks: make(map[models.IncidentType]*Runbook)}
+}
+
+// Register adds the runbook for an incident type, replacing any previous one
+func (r *RunbookRegistry) Register(incidentType models.IncidentType, rb *Runbook) error {
+	if !incidentType.IsValid() {
+		return fmt.Errorf("unknown incident type %q", incidentType)
+	}
+	if err := rb.validate(); err != nil {
+		return err
+	}
+
+	r.runbooks[incidentType] = rb
+	return nil
+}
+
+// Lookup returns the runbook registered for an incident type. A nil registry has none.
+func (r *RunbookRegistry) Lookup(incidentType models.IncidentType) (*Runbook, bool) {
+	if r == nil {
+		return nil, false
+	}
+	rb, ok := r.runbooks[incidentType]
+	return rb, ok
+}
+
+// Types returns the incident types that have a runbook, sorted
+func (r *RunbookRegistry) Types() []string {
+	types := make([]string, 0, len(r.runbooks))
+	for t := range r.runbooks {
+		types = append(types, string(t))
+	}
+	sort.Strings(types)
+	return types
+}
+
+// LoadRunbooks reads runbooks keyed by incident type or alias from a YAML file, e.g.
+//
+//	crash:
+//	  fix_type: restart
+//	  description: Service crashed
+//	  steps:
+//	    - Restart the service process
+//
+// Every runbook is validated; the first malformed one fails the whole file.
+func LoadRunbooks(path string) (*RunbookRegistry, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var runbooks map[string]*Runbook
+	if err := yaml.Unmarshal(raw, &runbooks); err != nil {
+		return nil, fmt.Errorf("failed to parse runbooks file: %w", err)
+	}
+	if len(runbooks) == 0 {
+		return nil, fmt.Errorf("runbooks file has no runbooks")
+	}
+
+	keys := make([]string, 0, len(runbooks))
+	for key := range runbooks {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	registry := NewRunbookRegistry()
+	for _, key := range keys {
+		incidentType, ok := models.NormalizeIncidentType(key)
+		if !ok {
+			return nil, fmt.Errorf("invalid runbook for %s: unknown incident type", key)
+		}
+		if _, dup := registry.Lookup(incidentType); dup {
+			return nil, fmt.Errorf("more than one runbook for %s", incidentType)
+		}
+
+		rb := runbooks[key]
+		if rb == nil {
+			return nil, fmt.Errorf("invalid runbook for %s: empty", key)
+		}
+		if err := registry.Register(incidentType, rb); err != nil {
+			return nil, fmt.Errorf("invalid runbook for %s: %w", key, err)
+		}
+	}
+
+	return registry, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"incident-ai/models"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunbookPreferredOverAI(t *testing.T) {
+	runbook := &Runbook{
+		Description:   "Connection pool exhausted",
+		FixType:       "config",
+		Steps:         []string{"Set max_connections to 100", "Restart the service"},
+		ConfigChanges: map[string]string{"max_connections": "100"},
+	}
+
+	tests := []struct {
+		name          string
+		diagnosis     bool // still ask the AI to diagnose
+		wantAnalyzed  int
+		wantDiagnosis string
+	}{
+		{name: "runbook only", wantDiagnosis: "Connection pool exhausted"},
+		{name: "AI diagnosis", diagnosis: true, wantAnalyzed: 1, wantDiagnosis: "AI diagnosis"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOrchestrator(t)
+			o.runbooks = NewRunbookRegistry()
+			if err := o.runbooks.Register(models.ConfigError, runbook); err != nil {
+				t.Fatalf("Register: %v", err)
+			}
+			o.runbookDiagnosis = tt.diagnosis
+			analyzer := o.analyzer.(*fakeAnalyzer)
+			executor := o.executor.(*fakeExecutor)
+
+			// A learned fix for the type is passed over for the runbook
+			learned := newTestIncident("learned", models.ConfigError, "max_connections is 5")
+			learned.DetectedAt = time.Now().Add(-time.Hour)
+			learned.Status = models.StatusResolved
+			learned.Resolution = &models.Resolution{FixType: "restart", Steps: []string{"Restart the service"}, Success: true}
+			if err := o.store.StoreIncident(learned); err != nil {
+				t.Fatalf("StoreIncident: %v", err)
+			}
+
+			incident := newTestIncident("a", models.ConfigError, "max_connections is 0")
+			if err := o.processIncident(context.Background(), incident); err != nil {
+				t.Fatalf("processIncident: %v", err)
+			}
+
+			if len(analyzer.analyzed) != tt.wantAnalyzed {
+				t.Errorf("AI analyzed %d times, want %d", len(analyzer.analyzed), tt.wantAnalyzed)
+			}
+			if len(executor.cached) != 0 {
+				t.Error("learned fix applied instead of the runbook")
+			}
+			if len(executor.executed) != 1 {
+				t.Fatalf("%d fixes executed, want 1", len(executor.executed))
+			}
+			executed := executor.executed[0]
+			if executed.FixType != "config" || !reflect.DeepEqual(executed.FixSteps, runbook.Steps) {
+				t.Errorf("executed %s fix %q, want the runbook's config fix %q", executed.FixType, executed.FixSteps, runbook.Steps)
+			}
+			if !reflect.DeepEqual(executed.ConfigChanges, runbook.ConfigChanges) {
+				t.Errorf("executed config changes %v, want the runbook's %v", executed.ConfigChanges, runbook.ConfigChanges)
+			}
+
+			if incident.Status != models.StatusResolved || !incident.UsedRunbook {
+				t.Errorf("status = %s, used runbook = %v; want resolved by the runbook", incident.Status, incident.UsedRunbook)
+			}
+			if incident.Diagnosis != tt.wantDiagnosis {
+				t.Errorf("diagnosis = %q, want %q", incident.Diagnosis, tt.wantDiagnosis)
+			}
+		})
+	}
+}
+
+func TestLoadRunbooks(t *testing.T) {
+	tests := []struct {
+		name      string
+		yaml      string
+		wantTypes []string
+		wantErr   string
+	}{
+		{
+			name: "keyed by type and alias",
+			yaml: `crash:
+  fix_type: restart
+  steps: [Restart the service process]
+CONFIG_ERROR:
+  fix_type: config
+  steps: [Set max_connections to 100]
+  config_changes: {max_connections: "100"}
+`,
+			wantTypes: []string{string(models.ConfigError), string(models.ServiceDown)},
+		},
+		{name: "unknown type", yaml: "meltdown:\n  fix_type: restart\n  steps: [Restart]\n", wantErr: "unknown incident type"},
+		{name: "missing steps", yaml: "crash:\n  fix_type: restart\n", wantErr: "missing steps"},
+		{name: "code fix without code", yaml: "crash:\n  fix_type: code\n  steps: [Patch the handler]\n", wantErr: "no code"},
+		{
+			name:    "config changes on a restart",
+			yaml:    "crash:\n  fix_type: restart\n  steps: [Restart]\n  config_changes: {max_connections: \"100\"}\n",
+			wantErr: "only allowed on config fixes",
+		},
+		{name: "empty file", yaml: "", wantErr: "no runbooks"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "runbooks.yaml")
+			if err := os.WriteFile(path, []byte(tt.yaml), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			registry, err := LoadRunbooks(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadRunbooks error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadRunbooks: %v", err)
+			}
+			if types := registry.Types(); !reflect.DeepEqual(types, tt.wantTypes) {
+				t.Errorf("runbook types = %v, want %v", types, tt.wantTypes)
+			}
+		})
+	}
+}
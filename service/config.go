@@ -0,0 +1,182 @@
+package service
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// defaultConfig is the healthy configuration the service starts with
+func defaultConfig() map[string]string {
+	return map[string]string{
+		"database_url": "localhost:5432",
+		"timeout":      "30s",
+		"max_retries":  "3",
+	}
+}
+
+// configSource stores the service's configuration. Callers hold ts.mu.
+type configSource interface {
+	Read() (map[string]string, error)
+	Write(config map[string]string) error
+}
+
+// memoryConfig keeps the configuration in memory
+type memoryConfig struct {
+	values map[string]string
+}
+
+func newMemoryConfig() *memoryConfig {
+	return &memoryConfig{values: defaultConfig()}
+}
+
+// Read returns a copy of the configuration
+func (c *memoryConfig) Read() (map[string]string, error) {
+	return copyConfig(c.values), nil
+}
+
+// Write replaces the configuration
+func (c *memoryConfig) Write(config map[string]string) error {
+	c.values = copyConfig(config)
+	return nil
+}
+
+// fileConfig keeps the configuration in a file on disk: a JSON object of strings for
+// .json files, otherwise KEY=VALUE lines as in a .env file
+type fileConfig struct {
+	path string
+}
+
+// newFileConfig uses the file at path, creating it with the default configuration if
+// it does not exist yet. An existing file must parse.
+func newFileConfig(path string) (*fileConfig, error) {
+	c := &fileConfig{path: path}
+
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		if err := c.Write(defaultConfig()); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to stat config file: %w", err)
+	}
+
+	if _, err := c.Read(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Read parses the config file
+func (c *fileConfig) Read() (map[string]string, error) {
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if c.isJSON() {
+		var config map[string]string
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", c.path, err)
+		}
+		return config, nil
+	}
+	return parseEnv(c.path, string(data))
+}
+
+// Write replaces the config file's contents, via a temporary file so a crash never
+// leaves it half-written
+func (c *fileConfig) Write(config map[string]string) error {
+	var data []byte
+	if c.isJSON() {
+		encoded, err := json.MarshalIndent(config, "", "  ")
+		if err != nil {
+			return err
+		}
+		data = append(encoded, '\n')
+	} else {
+		data = []byte(formatEnv(config))
+	}
+
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return fmt.Errorf("failed to replace config file: %w", err)
+	}
+	return nil
+}
+
+func (c *fileConfig) isJSON() bool {
+	return strings.EqualFold(filepath.Ext(c.path), ".json")
+}
+
+// parseEnv parses KEY=VALUE lines, skipping blank lines and # comments
+func parseEnv(path, data string) (map[string]string, error) {
+	config := make(map[string]string)
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("failed to parse config file %s: line %d: expected KEY=VALUE", path, n)
+		}
+		config[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	return config, scanner.Err()
+}
+
+// formatEnv renders the config as KEY=VALUE lines sorted by key
+func formatEnv(config map[string]string) string {
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&sb, "%s=%s\n", key, config[key])
+	}
+	return sb.String()
+}
+
+func copyConfig(config map[string]string) map[string]string {
+	copied := make(map[string]string, len(config))
+	for k, v := range config {
+		copied[k] = v
+	}
+	return copied
+}
+
+// updateConfig sets config values and writes them back. The caller must hold ts.mu.
+func (ts *TargetService) updateConfig(values map[string]string) error {
+	config, err := ts.config.Read()
+	if err != nil {
+		return err
+	}
+	for key, value := range values {
+		config[key] = value
+	}
+	return ts.config.Write(config)
+}
+
+// corruptConfig writes bad values for an injected fault. The caller must hold ts.mu.
+func (ts *TargetService) corruptConfig(values map[string]string) {
+	if err := ts.updateConfig(values); err != nil {
+		log.Printf("[TARGET SERVICE] Failed to corrupt config: %v\n", err)
+	}
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileConfigRoundTrip(t *testing.T) {
+	for _, name := range []string{"service.json", "service.env"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+
+			// A missing file is created with the default configuration
+			ts := newTestService(t, "0", WithConfigFile(path), WithAccessLog(false))
+			if config, err := ts.GetConfig(); err != nil || !maps.Equal(config, defaultConfig()) {
+				t.Fatalf("GetConfig = %v, %v; want the default config", config, err)
+			}
+
+			// Writes go to the file, and reads come from it
+			if err := ts.SetConfig("timeout", "45s"); err != nil {
+				t.Fatalf("SetConfig: %v", err)
+			}
+			if raw := mustRead(t, path); !strings.Contains(raw, "45s") {
+				t.Errorf("config file doesn't hold the new timeout:\n%s", raw)
+			}
+			if err := os.WriteFile(path, []byte(strings.ReplaceAll(mustRead(t, path), "45s", "60s")), 0644); err != nil {
+				t.Fatalf("editing config file: %v", err)
+			}
+			if config, _ := ts.GetConfig(); config["timeout"] != "60s" {
+				t.Errorf("timeout = %q after editing the file, want 60s", config["timeout"])
+			}
+
+			// A config fault corrupts the file itself, so another service on it sees the bad values
+			if _, injected, ok := ts.injectFault("config", ""); !ok || !injected {
+				t.Fatal("config fault not injected")
+			}
+			reopened := newTestService(t, "0", WithConfigFile(path))
+			config, err := reopened.GetConfig()
+			if err != nil {
+				t.Fatalf("GetConfig on the corrupted file: %v", err)
+			}
+			if config["database_url"] != "invalid::url::format" || config["timeout"] != "not-a-number" || config["max_retries"] != "3" {
+				t.Errorf("config after the fault = %v, want corrupted database_url and timeout", config)
+			}
+		})
+	}
+}
+
+func TestNewTargetServiceRejectsUnusableConfigFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string // written unless empty
+		wantErr string
+	}{
+		{name: "malformed JSON", file: "service.json", content: `{"timeout": `, wantErr: "failed to parse config file"},
+		{name: "JSON of non-strings", file: "service.json", content: `{"max_retries": 3}`, wantErr: "failed to parse config file"},
+		{name: "env line without a value", file: "service.env", content: "timeout=30s\ndatabase_url\n", wantErr: "line 2: expected KEY=VALUE"},
+		{name: "directory that doesn't exist", file: filepath.Join("missing", "service.json"), wantErr: "failed to write config file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if tt.content != "" {
+				if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+					t.Fatalf("writing config file: %v", err)
+				}
+			}
+
+			ts, err := NewTargetService("0", WithConfigFile(path))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("NewTargetService = %v, %v; want an error containing %q", ts, err, tt.wantErr)
+			}
+			if tt.content != "" && mustRead(t, path) != tt.content {
+				t.Error("the unusable config file was changed")
+			}
+		})
+	}
+}
+
+// mustRead returns the contents of a file, failing the test if it can't be read
+func mustRead(t *testing.T, path string) string {
+	t.Helper()
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+	return string(raw)
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"fmt"
+	"incident-ai/models"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"time"
+)
+
+// statusRecorder captures the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// withMiddleware wraps a handler with panic recovery and, if enabled, request logging
+func (ts *TargetService) withMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		defer func() {
+			if p := recover(); p != nil {
+				log.Printf("[TARGET SERVICE] ❌ Panic serving %s %s: %v\n%s", r.Method, r.URL.Path, p, debug.Stack())
+				ts.logEvent(models.LogError, fmt.Sprintf("Panic serving %s: %v", r.URL.Path, p))
+
+				if !rec.wroteHeader {
+					http.Error(rec, "internal server error", http.StatusInternalServerError)
+				} else {
+					rec.status = http.StatusInternalServerError
+				}
+			}
+
+			if ts.accessLog {
+				log.Printf("[TARGET SERVICE] %s %s %d %v\n", r.Method, r.URL.Path, rec.status, time.Since(start))
+			}
+		}()
+
+		next.ServeHTTP(rec, r)
+	})
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"bytes"
+	"incident-ai/models"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMiddlewareRecoversPanics(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	ts := newTestService(t, "0")
+	mux := http.NewServeMux()
+	mux.HandleFunc("/boom", func(w http.ResponseWriter, r *http.Request) {
+		var config map[string]string
+		config["key"] = "value" // nil map write
+	})
+	mux.HandleFunc("/late-boom", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		panic("failed after responding")
+	})
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	server := httptest.NewServer(ts.withMiddleware(mux))
+	defer server.Close()
+
+	get := func(path string) int {
+		t.Helper()
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if status := get("/boom"); status != http.StatusInternalServerError {
+		t.Errorf("GET /boom = %d, want %d", status, http.StatusInternalServerError)
+	}
+	if status := get("/late-boom"); status != http.StatusAccepted {
+		t.Errorf("GET /late-boom = %d, want the status already sent", status)
+	}
+	// The server survives to serve the next request
+	if status := get("/ok"); status != http.StatusTeapot {
+		t.Errorf("GET /ok after the panics = %d, want %d", status, http.StatusTeapot)
+	}
+
+	output := logs.String()
+	for _, want := range []string{
+		"Panic serving GET /boom: assignment to entry in nil map",
+		"goroutine ", // the stack trace
+		"GET /boom 500",
+		"GET /late-boom 500",
+		"GET /ok 418",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("log output doesn't contain %q:\n%s", want, output)
+		}
+	}
+
+	var panics []string
+	for _, entry := range ts.GetLogsByLevel(models.LogError) {
+		panics = append(panics, entry.Message)
+	}
+	if len(panics) != 2 || !strings.HasPrefix(panics[0], "Panic serving /boom") {
+		t.Errorf("service error logs = %q, want both panics", panics)
+	}
+}
+
+func TestMiddlewareAccessLogDisabled(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	ts := newTestService(t, "0", WithAccessLog(false))
+	server := httptest.NewServer(ts.withMiddleware(http.NotFoundHandler()))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/missing")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	resp.Body.Close()
+
+	if strings.Contains(logs.String(), "/missing") {
+		t.Errorf("request logged with the access log disabled:\n%s", logs.String())
+	}
+}
@@ -0,0 +1,48 @@
+package service
+
+import "incident-ai/models"
+
+// Option configures a TargetService
+type Option func(*TargetService)
+
+// WithLogCapacity sets how many log entries the service keeps before evicting the oldest
+func WithLogCapacity(capacity int) Option {
+	return func(ts *TargetService) {
+		if capacity > 0 {
+			ts.maxLogs = capacity
+		}
+	}
+}
+
+// WithAccessLog enables or disables per-request logging
+func WithAccessLog(enabled bool) Option {
+	return func(ts *TargetService) {
+		ts.accessLog = enabled
+	}
+}
+
+// WithPprof exposes the Go runtime profiles under /debug/pprof/
+func WithPprof(enabled bool) Option {
+	return func(ts *TargetService) {
+		ts.pprof = enabled
+	}
+}
+
+// WithConfigFile backs the service's configuration with a file instead of memory: a JSON
+// object of strings for .json paths, otherwise KEY=VALUE lines. A missing file is created
+// with the default configuration; one that can't be created, read or parsed fails
+// NewTargetService.
+func WithConfigFile(path string) Option {
+	return func(ts *TargetService) {
+		ts.configFile = path
+	}
+}
+
+// WithIDGenerator sets how the IDs of injected faults are minted, e.g. predictably in tests
+func WithIDGenerator(ids models.IDGenerator) Option {
+	return func(ts *TargetService) {
+		if ids != nil {
+			ts.ids = ids
+		}
+	}
+}
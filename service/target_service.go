@@ -3,39 +3,66 @@ package service
 import (
 	"encoding/json"
 	"fmt"
+	"incident-ai/buildinfo"
 	"incident-ai/models"
 	"log"
+	"net"
 	"net/http"
+	"net/http/pprof"
+	"slices"
 	"sync"
 	"time"
 )
 
 // TargetService represents a service that can experience incidents
 type TargetService struct {
-	port          string
-	isHealthy     bool
-	isRunning     bool
-	config        map[string]string
-	mu            sync.RWMutex
-	server        *http.Server
-	errorLogs     []string
-	maxLogs       int
+	port       string
+	isHealthy  bool
+	isDegraded bool // serving, but reporting a degraded health state
+	isRunning  bool
+	isReady    bool
+	config     configSource
+	configFile string // backs config when set (see WithConfigFile)
+	mu         sync.RWMutex
+	server     *http.Server
+	errorLogs  []models.LogEntry
+	maxLogs    int
+	accessLog  bool               // log every request's method, path, status and duration
+	pprof      bool               // serve runtime profiles under /debug/pprof/
+	trigger    *triggeredIncident // last injected fault, cleared when the service starts healthy
+	ids        models.IDGenerator // mints the IDs of injected faults
 }
 
-// NewTargetService creates a new target service
-func NewTargetService(port string) *TargetService {
-	return &TargetService{
+// defaultLogCapacity is how many log entries are kept unless configured otherwise
+const defaultLogCapacity = 50
+
+// NewTargetService creates a new target service. It fails if a config file is set and
+// can't be used.
+func NewTargetService(port string, opts ...Option) (*TargetService, error) {
+	ts := &TargetService{
 		port:      port,
 		isHealthy: true,
 		isRunning: false,
-		config: map[string]string{
-			"database_url": "localhost:5432",
-			"timeout":      "30s",
-			"max_retries":  "3",
-		},
-		errorLogs: make([]string, 0),
-		maxLogs:   50,
+		config:    newMemoryConfig(),
+		errorLogs: make([]models.LogEntry, 0),
+		maxLogs:   defaultLogCapacity,
+		accessLog: true,
+		ids:       models.UUIDGenerator{},
+	}
+
+	for _, opt := range opts {
+		opt(ts)
+	}
+
+	if ts.configFile != "" {
+		source, err := newFileConfig(ts.configFile)
+		if err != nil {
+			return nil, err
+		}
+		ts.config = source
 	}
+
+	return ts, nil
 }
 
 // Start starts the target service
@@ -61,23 +88,51 @@ func (ts *TargetService) Start() error {
 	// Status endpoint
 	mux.HandleFunc("/status", ts.handleStatus)
 
-	ts.server = &http.Server{
-		Addr:    ":" + ts.port,
-		Handler: mux,
+	// Readiness and build info endpoints
+	mux.HandleFunc("/ready", ts.handleReady)
+	mux.HandleFunc("/version", ts.handleVersion)
+
+	// Runtime profiles, captured by the monitor for resource exhaustion incidents
+	if ts.pprof {
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	}
+
+	// Bind before reporting success, so a port that is already in use fails Start
+	// instead of only being logged by the serving goroutine
+	listener, err := net.Listen("tcp", ":"+ts.port)
+	if err != nil {
+		ts.addLog(models.LogError, fmt.Sprintf("Failed to start: %v", err))
+		return fmt.Errorf("failed to listen on port %s: %w", ts.port, err)
 	}
 
+	// The serving goroutine gets its own reference: ts.server is replaced on every
+	// restart and must only be touched under ts.mu
+	server := &http.Server{
+		Addr:    listener.Addr().String(),
+		Handler: ts.withMiddleware(mux),
+	}
+	ts.server = server
+
 	ts.isRunning = true
 	ts.isHealthy = true
+	ts.isDegraded = false
+	ts.trigger = nil
+	ts.addLog(models.LogInfo, "Service started")
 
+	log.Printf("[TARGET SERVICE] Starting on port %s\n", ts.port)
 	go func() {
-		log.Printf("[TARGET SERVICE] Starting on port %s\n", ts.port)
-		if err := ts.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			ts.addLog(fmt.Sprintf("Server error: %v", err))
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			ts.logEvent(models.LogError, fmt.Sprintf("Server error: %v", err))
 			log.Printf("[TARGET SERVICE] Error: %v\n", err)
 		}
 	}()
 
-	time.Sleep(500 * time.Millisecond) // Give server time to start
+	// The listener is bound, so requests queue until Serve picks them up
+	ts.isReady = true
 	return nil
 }
 
@@ -92,6 +147,8 @@ func (ts *TargetService) Stop() error {
 
 	ts.isRunning = false
 	ts.isHealthy = false
+	ts.isReady = false
+	ts.addLog(models.LogWarn, "Service stopped")
 
 	if ts.server != nil {
 		return ts.server.Close()
@@ -99,6 +156,13 @@ func (ts *TargetService) Stop() error {
 	return nil
 }
 
+// IsRunning returns whether the service is running
+func (ts *TargetService) IsRunning() bool {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	return ts.isRunning
+}
+
 // IsHealthy returns the health status
 func (ts *TargetService) IsHealthy() bool {
 	ts.mu.RLock()
@@ -106,40 +170,55 @@ func (ts *TargetService) IsHealthy() bool {
 	return ts.isHealthy && ts.isRunning
 }
 
-// GetLogs returns recent error logs
-func (ts *TargetService) GetLogs() []string {
+// GetLogs returns recent log entries
+func (ts *TargetService) GetLogs() []models.LogEntry {
 	ts.mu.RLock()
 	defer ts.mu.RUnlock()
 
-	logs := make([]string, len(ts.errorLogs))
+	logs := make([]models.LogEntry, len(ts.errorLogs))
 	copy(logs, ts.errorLogs)
 	return logs
 }
 
-// GetConfig returns current configuration
-func (ts *TargetService) GetConfig() map[string]string {
+// GetLogsByLevel returns recent log entries at or above the given level
+func (ts *TargetService) GetLogsByLevel(minLevel models.LogLevel) []models.LogEntry {
 	ts.mu.RLock()
 	defer ts.mu.RUnlock()
 
-	config := make(map[string]string)
-	for k, v := range ts.config {
-		config[k] = v
+	logs := make([]models.LogEntry, 0, len(ts.errorLogs))
+	for _, entry := range ts.errorLogs {
+		if entry.Level.Rank() >= minLevel.Rank() {
+			logs = append(logs, entry)
+		}
 	}
-	return config
+	return logs
 }
 
-// SetConfig updates configuration
-func (ts *TargetService) SetConfig(key, value string) {
+// GetConfig returns current configuration, or an error if it cannot be read
+func (ts *TargetService) GetConfig() (map[string]string, error) {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	return ts.config.Read()
+}
+
+// SetConfig updates a configuration value, returning an error if it cannot be written back
+func (ts *TargetService) SetConfig(key, value string) error {
 	ts.mu.Lock()
 	defer ts.mu.Unlock()
-	ts.config[key] = value
+
+	if err := ts.updateConfig(map[string]string{key: value}); err != nil {
+		return fmt.Errorf("failed to set %s: %w", key, err)
+	}
+	return nil
 }
 
 // Restart restarts the service
 func (ts *TargetService) Restart() error {
 	log.Println("[TARGET SERVICE] Restarting...")
 
-	if err := ts.Stop(); err != nil && ts.isRunning {
+	// Stopping an already-stopped service is fine; anything else is a real failure
+	if err := ts.Stop(); err != nil && ts.IsRunning() {
 		return err
 	}
 
@@ -148,31 +227,97 @@ func (ts *TargetService) Restart() error {
 	return ts.Start()
 }
 
-func (ts *TargetService) addLog(message string) {
-	ts.errorLogs = append(ts.errorLogs, fmt.Sprintf("[%s] %s", time.Now().Format("15:04:05"), message))
+// logEvent appends a log entry, taking the lock. Use it from goroutines that don't hold ts.mu.
+func (ts *TargetService) logEvent(level models.LogLevel, message string) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.addLog(level, message)
+}
+
+// addLog appends a log entry. The caller must hold ts.mu.
+func (ts *TargetService) addLog(level models.LogLevel, message string) {
+	ts.appendLog(models.LogEntry{
+		Timestamp: time.Now(),
+		Level:     level,
+		Message:   message,
+	})
+}
+
+// appendLog appends an entry, dropping the oldest beyond capacity. The caller must hold ts.mu.
+func (ts *TargetService) appendLog(entry models.LogEntry) {
+	ts.errorLogs = append(ts.errorLogs, entry)
 	if len(ts.errorLogs) > ts.maxLogs {
-		ts.errorLogs = ts.errorLogs[1:]
+		ts.errorLogs = ts.errorLogs[len(ts.errorLogs)-ts.maxLogs:]
 	}
 }
 
 // HTTP Handlers
 
-func (ts *TargetService) handleHealth(w http.ResponseWriter, r *http.Request) {
+// Handlers copy what they need through these helpers, which release ts.mu with defer, so
+// the lock is never held while a response is written and a panic can't leave it locked
+// for the middleware's recovery, which logs under ts.mu.
+
+// healthState returns whether the service is healthy and whether it is degraded
+func (ts *TargetService) healthState() (healthy, degraded bool) {
 	ts.mu.RLock()
 	defer ts.mu.RUnlock()
+	return ts.isHealthy, ts.isDegraded
+}
+
+// ready returns whether the service has finished initializing
+func (ts *TargetService) ready() bool {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	return ts.isReady
+}
+
+// statusSnapshot returns the /status body
+func (ts *TargetService) statusSnapshot() map[string]interface{} {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	status := map[string]interface{}{
+		"running":     ts.isRunning,
+		"healthy":     ts.isHealthy,
+		"degraded":    ts.isDegraded,
+		"recent_logs": slices.Clone(ts.errorLogs),
+	}
+	if config, err := ts.config.Read(); err != nil {
+		log.Printf("[TARGET SERVICE] Config error: %v\n", err)
+		status["config_error"] = err.Error()
+	} else {
+		status["config"] = config
+	}
+	if ts.trigger != nil && ts.faulted() {
+		trigger := *ts.trigger
+		status["triggered_incident"] = &trigger
+	}
+	return status
+}
+
+func (ts *TargetService) handleHealth(w http.ResponseWriter, r *http.Request) {
+	healthy, degraded := ts.healthState()
 
 	status := models.HealthStatus{
-		Healthy:   ts.isHealthy,
+		Healthy:   healthy,
+		Status:    models.HealthHealthy,
 		Timestamp: time.Now(),
 		Message:   "Service operational",
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 
-	if !ts.isHealthy {
+	if !healthy {
+		status.Status = models.HealthUnhealthy
 		status.Message = "Service unhealthy"
 		status.StatusCode = http.StatusServiceUnavailable
 		w.WriteHeader(http.StatusServiceUnavailable)
+	} else if degraded {
+		// Degraded services keep serving, so the probe still succeeds
+		status.Status = models.HealthDegraded
+		status.Message = "Service degraded - elevated latency and partial failures"
+		status.StatusCode = http.StatusOK
+		w.WriteHeader(http.StatusOK)
 	} else {
 		status.StatusCode = http.StatusOK
 		w.WriteHeader(http.StatusOK)
@@ -183,53 +328,82 @@ func (ts *TargetService) handleHealth(w http.ResponseWriter, r *http.Request) {
 
 func (ts *TargetService) handleTriggerIncident(w http.ResponseWriter, r *http.Request) {
 	incidentType := r.URL.Query().Get("type")
+	key := r.URL.Query().Get("key")
 
 	log.Printf("[TARGET SERVICE] Triggering incident: %s\n", incidentType)
 
+	// The response is written after the lock is released, so a slow client can't stall probes
+	trigger, injected, ok := ts.injectFault(incidentType, key)
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, "Unknown incident type: %s\n", incidentType)
+		fmt.Fprintf(w, "Valid types: crash, config, resource, dependency, degraded\n")
+		return
+	}
+
+	w.Header().Set("X-Incident-ID", trigger.ID)
+	w.WriteHeader(http.StatusOK)
+	if injected {
+		fmt.Fprintf(w, "Incident triggered: %s\n", trigger.Type)
+	} else {
+		log.Printf("[TARGET SERVICE] Incident %s for key %q still open, not triggering again\n", trigger.ID, key)
+		fmt.Fprintf(w, "Incident already open: %s\n", trigger.Type)
+	}
+	fmt.Fprintf(w, "Incident ID: %s\n", trigger.ID)
+}
+
+// injectFault injects the fault for an incident type and starts tracking it, taking the
+// lock. A repeated trigger for a still-open incident returns it with injected false instead
+// of injecting the fault again. ok is false for an unknown incident type.
+func (ts *TargetService) injectFault(incidentType, key string) (trigger triggeredIncident, injected, ok bool) {
 	ts.mu.Lock()
 	defer ts.mu.Unlock()
 
-	switch incidentType {
-	case "crash", "SERVICE_DOWN":
+	if existing, open := ts.openTrigger(key); open {
+		return *existing, false, true
+	}
+
+	var message string
+	level := models.LogError
+
+	normalized, _ := models.NormalizeIncidentType(incidentType)
+	switch normalized {
+	case models.ServiceDown:
 		ts.isHealthy = false
-		ts.addLog("Service crashed - simulated failure")
-		w.WriteHeader(http.StatusOK)
-		fmt.Fprintf(w, "Incident triggered: SERVICE_DOWN\n")
+		message = "Service crashed - simulated failure"
 
-	case "config", "CONFIG_ERROR":
-		ts.config["database_url"] = "invalid::url::format"
-		ts.config["timeout"] = "not-a-number"
+	case models.ConfigError:
+		ts.corruptConfig(map[string]string{"database_url": "invalid::url::format", "timeout": "not-a-number"})
 		ts.isHealthy = false
-		ts.addLog("Configuration corrupted - invalid values detected")
-		w.WriteHeader(http.StatusOK)
-		fmt.Fprintf(w, "Incident triggered: CONFIG_ERROR\n")
+		message = "Configuration corrupted - invalid values detected"
 
-	case "resource", "RESOURCE_EXHAUSTION":
+	case models.ResourceExhaustion:
 		ts.isHealthy = false
-		ts.addLog("Resource exhaustion - port blocked or memory full")
-		w.WriteHeader(http.StatusOK)
-		fmt.Fprintf(w, "Incident triggered: RESOURCE_EXHAUSTION\n")
+		message = "Resource exhaustion - port blocked or memory full"
 
-	case "dependency", "DEPENDENCY_FAILURE":
-		ts.config["database_url"] = "unreachable-host:9999"
+	case models.DependencyFailure:
+		ts.corruptConfig(map[string]string{"database_url": "unreachable-host:9999"})
 		ts.isHealthy = false
-		ts.addLog("Database connection failed - unable to reach host")
-		w.WriteHeader(http.StatusOK)
-		fmt.Fprintf(w, "Incident triggered: DEPENDENCY_FAILURE\n")
+		message = "Database connection failed - unable to reach host"
+
+	case models.Degraded:
+		ts.isDegraded = true
+		level = models.LogWarn
+		message = "Service degraded - elevated latency and partial failures"
 
 	default:
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintf(w, "Unknown incident type: %s\n", incidentType)
-		fmt.Fprintf(w, "Valid types: crash, config, resource, dependency\n")
-		return
+		return triggeredIncident{}, false, false
 	}
+
+	recorded := ts.recordTrigger(key, string(normalized))
+	ts.addTriggerLog(recorded, level, message)
+	return *recorded, true, true
 }
 
 func (ts *TargetService) handleAPI(w http.ResponseWriter, r *http.Request) {
-	ts.mu.RLock()
-	defer ts.mu.RUnlock()
+	healthy, _ := ts.healthState()
 
-	if !ts.isHealthy {
+	if !healthy {
 		w.WriteHeader(http.StatusServiceUnavailable)
 		json.NewEncoder(w).Encode(map[string]string{"error": "service unavailable"})
 		return
@@ -243,15 +417,29 @@ func (ts *TargetService) handleAPI(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (ts *TargetService) handleReady(w http.ResponseWriter, r *http.Request) {
+	ready := ts.ready()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if !ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]bool{"ready": false})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]bool{"ready": true})
+}
+
+func (ts *TargetService) handleVersion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(buildinfo.Get())
+}
+
 func (ts *TargetService) handleStatus(w http.ResponseWriter, r *http.Request) {
-	ts.mu.RLock()
-	defer ts.mu.RUnlock()
+	status := ts.statusSnapshot()
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"running":    ts.isRunning,
-		"healthy":    ts.isHealthy,
-		"config":     ts.config,
-		"recent_logs": ts.errorLogs,
-	})
+	json.NewEncoder(w).Encode(status)
 }
@@ -0,0 +1,388 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"incident-ai/buildinfo"
+	"incident-ai/models"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestService creates a target service on port, failing the test if it can't
+func newTestService(t *testing.T, port string, opts ...Option) *TargetService {
+	t.Helper()
+
+	ts, err := NewTargetService(port, opts...)
+	if err != nil {
+		t.Fatalf("NewTargetService: %v", err)
+	}
+	return ts
+}
+
+// startTestService starts a target service on a free port, stopped when the test ends,
+// and returns its base URL
+func startTestService(t *testing.T, opts ...Option) (*TargetService, string) {
+	t.Helper()
+
+	ts := newTestService(t, "0", opts...)
+	if err := ts.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { ts.Stop() })
+
+	ts.mu.RLock()
+	addr := ts.server.Addr
+	ts.mu.RUnlock()
+	return ts, "http://" + addr
+}
+
+// slowWriter is a ResponseWriter for a client that reads nothing until released
+type slowWriter struct {
+	header  http.Header
+	writing chan struct{} // closed once the handler starts writing the body
+	release chan struct{}
+	once    sync.Once
+}
+
+func newSlowWriter() *slowWriter {
+	return &slowWriter{header: make(http.Header), writing: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (w *slowWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *slowWriter) WriteHeader(statusCode int) {}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.writing) })
+	<-w.release
+	return len(p), nil
+}
+
+func TestSlowTriggerClientDoesNotBlockProbes(t *testing.T) {
+	ts := newTestService(t, "0")
+
+	w := newSlowWriter()
+	triggered := make(chan struct{})
+	go func() {
+		defer close(triggered)
+		ts.handleTriggerIncident(w, httptest.NewRequest(http.MethodGet, "/trigger-incident?type=crash", nil))
+	}()
+	defer func() {
+		close(w.release)
+		<-triggered
+	}()
+
+	select {
+	case <-w.writing:
+	case <-time.After(2 * time.Second):
+		t.Fatal("trigger handler didn't start writing its response")
+	}
+
+	// The fault is injected, and probes see it while the trigger response is still being written
+	probed := make(chan int)
+	go func() {
+		recorder := httptest.NewRecorder()
+		ts.handleHealth(recorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+		probed <- recorder.Code
+	}()
+
+	select {
+	case code := <-probed:
+		if code != http.StatusServiceUnavailable {
+			t.Errorf("health status = %d during a crash, want %d", code, http.StatusServiceUnavailable)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("health probe blocked behind a slow trigger client")
+	}
+}
+
+func TestConcurrentTriggersAndProbes(t *testing.T) {
+	ts, url := startTestService(t, WithAccessLog(false))
+	types := []string{"crash", "config", "resource", "dependency", "degraded"}
+
+	const workers, iterations = 8, 20
+	errs := make(chan error, workers*iterations*4)
+	var wg sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				switch (worker + i) % 4 {
+				case 0:
+					incidentType := types[(worker+i)%len(types)]
+					resp, err := http.Get(fmt.Sprintf("%s/trigger-incident?type=%s&key=k%d", url, incidentType, i%3))
+					if err != nil {
+						errs <- err
+						continue
+					}
+					resp.Body.Close()
+					if resp.StatusCode != http.StatusOK || resp.Header.Get("X-Incident-ID") == "" {
+						errs <- fmt.Errorf("trigger %s: status %d, incident ID %q", incidentType, resp.StatusCode, resp.Header.Get("X-Incident-ID"))
+					}
+				case 1:
+					resp, err := http.Get(url + "/health")
+					if err != nil {
+						errs <- err
+						continue
+					}
+					var status models.HealthStatus
+					err = json.NewDecoder(resp.Body).Decode(&status)
+					resp.Body.Close()
+					if err != nil || status.StatusCode != resp.StatusCode {
+						errs <- fmt.Errorf("health: status %d, body %+v, decode error %v", resp.StatusCode, status, err)
+					}
+				case 2:
+					resp, err := http.Get(url + "/status")
+					if err != nil {
+						errs <- err
+						continue
+					}
+					var status map[string]interface{}
+					err = json.NewDecoder(resp.Body).Decode(&status)
+					resp.Body.Close()
+					if err != nil {
+						errs <- fmt.Errorf("status: %v", err)
+					}
+				case 3:
+					if err := ts.SetConfig("timeout", fmt.Sprintf("%ds", i)); err != nil {
+						errs <- err
+					}
+					if _, err := ts.GetConfig(); err != nil {
+						errs <- err
+					}
+					ts.GetLogs()
+				}
+			}
+		}(worker)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+	if logs := ts.GetLogs(); len(logs) > defaultLogCapacity {
+		t.Errorf("%d log entries kept, want at most %d", len(logs), defaultLogCapacity)
+	}
+}
+
+func TestStartReportsBindErrors(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	defer listener.Close()
+	port := strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+
+	ts := newTestService(t, port, WithAccessLog(false))
+	if err := ts.Start(); err == nil {
+		ts.Stop()
+		t.Fatalf("Start on port %s in use succeeded, want an error", port)
+	}
+	if ts.IsRunning() {
+		t.Error("service reports running after failing to bind")
+	}
+
+	// Once the port is free, the same service starts, and starting it again is an error
+	listener.Close()
+	if err := ts.Start(); err != nil {
+		t.Fatalf("Start on a free port: %v", err)
+	}
+	defer ts.Stop()
+	if err := ts.Start(); err == nil {
+		t.Error("second Start succeeded, want an error")
+	}
+	if resp, err := http.Get("http://localhost:" + port + "/health"); err != nil {
+		t.Errorf("health check after a duplicate Start: %v", err)
+	} else {
+		resp.Body.Close()
+	}
+}
+
+func TestConcurrentStartStopRestart(t *testing.T) {
+	ts := newTestService(t, "0", WithAccessLog(false))
+	defer ts.Stop()
+
+	const workers, iterations = 6, 25
+	var wg sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				// Starting a running service and stopping a stopped one fail by design; this
+				// only checks that interleaving them is race-free and leaves a consistent state
+				switch (worker + i) % 3 {
+				case 0:
+					ts.Start()
+				case 1:
+					ts.Stop()
+				case 2:
+					ts.IsRunning()
+					ts.IsHealthy()
+					ts.GetLogs()
+				}
+			}
+		}(worker)
+	}
+
+	// Restart sleeps between stopping and starting, so a couple overlap the loops above
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ts.Restart()
+		}()
+	}
+	wg.Wait()
+
+	// Whatever state the interleaving left, the service can be stopped and started again
+	ts.Stop()
+	if ts.IsRunning() {
+		t.Fatal("service still running after Stop")
+	}
+	if err := ts.Start(); err != nil {
+		t.Fatalf("Start after concurrent start/stop/restart: %v", err)
+	}
+
+	ts.mu.RLock()
+	addr := ts.server.Addr
+	ts.mu.RUnlock()
+	resp, err := http.Get("http://" + addr + "/health")
+	if err != nil {
+		t.Fatalf("health check: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("health status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if logs := ts.GetLogs(); len(logs) > defaultLogCapacity {
+		t.Errorf("%d log entries kept, want at most %d", len(logs), defaultLogCapacity)
+	}
+}
+
+func TestLogCapacityAndLevels(t *testing.T) {
+	ts, url := startTestService(t, WithLogCapacity(3), WithAccessLog(false))
+
+	// Starting logged an INFO line; these evict it and keep the newest 3
+	ts.logEvent(models.LogWarn, "pool at 80%")
+	ts.logEvent(models.LogInfo, "cache warmed")
+	ts.logEvent(models.LogError, "too many connections")
+
+	var messages []string
+	for _, entry := range ts.GetLogs() {
+		messages = append(messages, string(entry.Level)+" "+entry.Message)
+	}
+	if want := "WARN pool at 80%,INFO cache warmed,ERROR too many connections"; strings.Join(messages, ",") != want {
+		t.Errorf("logs = %v, want %s", messages, want)
+	}
+
+	if warnings := ts.GetLogsByLevel(models.LogWarn); len(warnings) != 2 || warnings[0].Level != models.LogWarn || warnings[1].Level != models.LogError {
+		t.Errorf("logs at WARN or above = %v, want the WARN and ERROR lines", warnings)
+	}
+	if errorLogs := ts.GetLogsByLevel(models.LogError); len(errorLogs) != 1 || errorLogs[0].Message != "too many connections" {
+		t.Errorf("logs at ERROR = %v, want the ERROR line", errorLogs)
+	}
+
+	// /status reports the structured entries
+	resp, err := http.Get(url + "/status")
+	if err != nil {
+		t.Fatalf("GET /status: %v", err)
+	}
+	defer resp.Body.Close()
+	var status struct {
+		RecentLogs []models.LogEntry `json:"recent_logs"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatalf("decoding /status: %v", err)
+	}
+	if len(status.RecentLogs) != 3 || status.RecentLogs[2].Level != models.LogError || status.RecentLogs[2].Timestamp.IsZero() {
+		t.Errorf("/status recent_logs = %+v, want the 3 leveled, timestamped entries", status.RecentLogs)
+	}
+}
+
+func TestReadinessTransition(t *testing.T) {
+	// readyStatus serves /ready from the handler, so it works while the server is down
+	readyStatus := func(ts *TargetService) int {
+		recorder := httptest.NewRecorder()
+		ts.handleReady(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		return recorder.Code
+	}
+
+	ts := newTestService(t, "0")
+	if status := readyStatus(ts); status != http.StatusServiceUnavailable {
+		t.Errorf("/ready before Start = %d, want %d", status, http.StatusServiceUnavailable)
+	}
+
+	if err := ts.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { ts.Stop() })
+	ts.mu.RLock()
+	url := "http://" + ts.server.Addr
+	ts.mu.RUnlock()
+
+	resp, err := http.Get(url + "/ready")
+	if err != nil {
+		t.Fatalf("GET /ready: %v", err)
+	}
+	var body map[string]bool
+	json.NewDecoder(resp.Body).Decode(&body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !body["ready"] {
+		t.Errorf("/ready after Start = %d %v, want %d and ready", resp.StatusCode, body, http.StatusOK)
+	}
+
+	resp, err = http.Get(url + "/version")
+	if err != nil {
+		t.Fatalf("GET /version: %v", err)
+	}
+	var info buildinfo.Info
+	json.NewDecoder(resp.Body).Decode(&info)
+	resp.Body.Close()
+	if info != buildinfo.Get() {
+		t.Errorf("/version = %+v, want %+v", info, buildinfo.Get())
+	}
+
+	if err := ts.Stop(); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if status := readyStatus(ts); status != http.StatusServiceUnavailable {
+		t.Errorf("/ready after Stop = %d, want %d", status, http.StatusServiceUnavailable)
+	}
+}
+
+func TestPprofEndpoints(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		t.Run(fmt.Sprintf("pprof %t", enabled), func(t *testing.T) {
+			_, url := startTestService(t, WithAccessLog(false), WithPprof(enabled))
+
+			resp, err := http.Get(url + "/debug/pprof/goroutine?debug=1")
+			if err != nil {
+				t.Fatalf("GET goroutine profile: %v", err)
+			}
+			body := new(strings.Builder)
+			_, err = io.Copy(body, resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				t.Fatalf("reading goroutine profile: %v", err)
+			}
+
+			served := resp.StatusCode == http.StatusOK && strings.HasPrefix(body.String(), "goroutine profile: total ")
+			if served != enabled {
+				t.Errorf("goroutine profile served = %v (status %d), want %v", served, resp.StatusCode, enabled)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"fmt"
+	"incident-ai/models"
+	"time"
+)
+
+// triggeredIncident is a fault injected through /trigger-incident that has not yet been cleared
+type triggeredIncident struct {
+	ID          string    `json:"id"`
+	Key         string    `json:"idempotency_key,omitempty"`
+	Type        string    `json:"type"`
+	TriggeredAt time.Time `json:"triggered_at"`
+}
+
+// openTrigger returns the open triggered incident for an idempotency key, if any.
+// The caller must hold ts.mu.
+func (ts *TargetService) openTrigger(key string) (*triggeredIncident, bool) {
+	if key == "" || ts.trigger == nil || ts.trigger.Key != key || !ts.faulted() {
+		return nil, false
+	}
+	return ts.trigger, true
+}
+
+// recordTrigger starts tracking a newly injected fault. The caller must hold ts.mu.
+func (ts *TargetService) recordTrigger(key, incidentType string) *triggeredIncident {
+	ts.trigger = &triggeredIncident{
+		ID:          ts.ids.NewID(),
+		Key:         key,
+		Type:        incidentType,
+		TriggeredAt: time.Now(),
+	}
+	return ts.trigger
+}
+
+// addTriggerLog logs the message reporting an injected fault, marked with the trigger's ID
+// so the detector can tell which log line belongs to the incident. The caller must hold ts.mu.
+func (ts *TargetService) addTriggerLog(trigger *triggeredIncident, level models.LogLevel, message string) {
+	ts.appendLog(models.LogEntry{
+		Timestamp: trigger.TriggeredAt,
+		Level:     level,
+		Message:   fmt.Sprintf("%s [incident %s]", message, trigger.ID),
+		TriggerID: trigger.ID,
+	})
+}
+
+// faulted reports whether an injected fault is still in effect. The caller must hold ts.mu.
+func (ts *TargetService) faulted() bool {
+	return !ts.isHealthy || ts.isDegraded
+}
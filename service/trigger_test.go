@@ -0,0 +1,143 @@
+package service
+
+import (
+	"encoding/json"
+	"incident-ai/models"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// trigger sends a trigger request to the service and returns the incident ID it answered
+// with and whether it reported a new incident
+func trigger(t *testing.T, ts *TargetService, query string) (string, bool) {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	ts.handleTriggerIncident(recorder, httptest.NewRequest(http.MethodGet, "/trigger-incident?"+query, nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("trigger %s: status %d: %s", query, recorder.Code, recorder.Body)
+	}
+
+	id := recorder.Header().Get("X-Incident-ID")
+	if id == "" || !strings.Contains(recorder.Body.String(), "Incident ID: "+id) {
+		t.Fatalf("trigger %s: incident ID %q missing from the response:\n%s", query, id, recorder.Body)
+	}
+	return id, strings.Contains(recorder.Body.String(), "Incident triggered")
+}
+
+func TestTriggerIdempotencyKeys(t *testing.T) {
+	ts, _ := startTestService(t, WithAccessLog(false))
+
+	first, created := trigger(t, ts, "type=crash&key=nightly")
+	if !created {
+		t.Fatal("first trigger for a key didn't create an incident")
+	}
+
+	// Repeats with the same key return the open incident, whatever type they ask for
+	for _, query := range []string{"type=crash&key=nightly", "type=config&key=nightly"} {
+		if id, created := trigger(t, ts, query); id != first || created {
+			t.Errorf("repeated trigger %s = %s (new %v), want the open incident %s", query, id, created, first)
+		}
+	}
+	if config, _ := ts.GetConfig(); config["database_url"] == "invalid::url::format" {
+		t.Error("repeated trigger injected its fault anyway")
+	}
+
+	// The open incident is reported to the detector with its ID
+	recorder := httptest.NewRecorder()
+	ts.handleStatus(recorder, httptest.NewRequest(http.MethodGet, "/status", nil))
+	var status struct {
+		Trigger triggeredIncident `json:"triggered_incident"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&status); err != nil {
+		t.Fatalf("decoding status: %v", err)
+	}
+	if status.Trigger.ID != first || status.Trigger.Key != "nightly" || status.Trigger.Type != "SERVICE_DOWN" {
+		t.Errorf("triggered incident = %+v, want %s for key nightly", status.Trigger, first)
+	}
+
+	// A distinct key, or none, creates a new incident
+	second, created := trigger(t, ts, "type=crash&key=smoke")
+	if second == first || !created {
+		t.Errorf("trigger with a distinct key = %s (new %v), want a new incident", second, created)
+	}
+	unkeyed, _ := trigger(t, ts, "type=crash")
+	if again, created := trigger(t, ts, "type=crash"); again == unkeyed || !created {
+		t.Errorf("unkeyed triggers both returned %s, want a new incident each", unkeyed)
+	}
+
+	// Once the service is fixed the incident is closed, and the key triggers a new one
+	if err := ts.Restart(); err != nil {
+		t.Fatalf("Restart: %v", err)
+	}
+	if id, created := trigger(t, ts, "type=crash&key=smoke"); id == second || !created || ts.IsHealthy() {
+		t.Errorf("trigger after the fix = %s (new %v), want a new incident instead of the closed %s", id, created, second)
+	}
+}
+
+func TestTriggerMarksItsLogLine(t *testing.T) {
+	ts := newTestService(t, "0", WithAccessLog(false))
+
+	crash, _ := trigger(t, ts, "type=crash&key=a")
+	config, _ := trigger(t, ts, "type=config&key=b")
+
+	marked := make(map[string]string)
+	for _, entry := range ts.GetLogs() {
+		if entry.TriggerID != "" {
+			marked[entry.TriggerID] = entry.Message
+		}
+	}
+	want := map[string]string{
+		crash:  "Service crashed - simulated failure [incident " + crash + "]",
+		config: "Configuration corrupted - invalid values detected [incident " + config + "]",
+	}
+	if !maps.Equal(marked, want) {
+		t.Errorf("marked log lines = %q, want %q", marked, want)
+	}
+}
+
+func TestDegradedTrigger(t *testing.T) {
+	ts := newTestService(t, "0", WithAccessLog(false))
+	trigger(t, ts, "type=degraded")
+
+	// A degraded service still passes its probe, reporting the finer-grained state
+	recorder := httptest.NewRecorder()
+	ts.handleHealth(recorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+	var health models.HealthStatus
+	if err := json.NewDecoder(recorder.Body).Decode(&health); err != nil {
+		t.Fatalf("decoding health: %v", err)
+	}
+	if recorder.Code != http.StatusOK || !health.Healthy || health.State() != models.HealthDegraded {
+		t.Errorf("health = %d %+v, want a 200 reporting a healthy but degraded service", recorder.Code, health)
+	}
+}
+
+func TestTriggerAcceptsTypeAliases(t *testing.T) {
+	for query, want := range map[string]models.IncidentType{
+		"type=crash":         models.ServiceDown,
+		"type=service-down":  models.ServiceDown,
+		"type=OOM":           models.ResourceExhaustion,
+		"type=Bad%20Config":  models.ConfigError,
+		"type=db":            models.DependencyFailure,
+		"type=DEGRADED":      models.Degraded,
+		"type=SERVICE_DOWN":  models.ServiceDown,
+		"type=out-of-memory": models.ResourceExhaustion,
+	} {
+		ts := newTestService(t, "0", WithAccessLog(false))
+		recorder := httptest.NewRecorder()
+		ts.handleTriggerIncident(recorder, httptest.NewRequest(http.MethodGet, "/trigger-incident?"+query, nil))
+		if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), "Incident triggered: "+string(want)) {
+			t.Errorf("trigger %s: status %d: %s; want a %s incident", query, recorder.Code, recorder.Body, want)
+		}
+	}
+
+	ts := newTestService(t, "0", WithAccessLog(false))
+	recorder := httptest.NewRecorder()
+	ts.handleTriggerIncident(recorder, httptest.NewRequest(http.MethodGet, "/trigger-incident?type=meltdown", nil))
+	if recorder.Code != http.StatusBadRequest || recorder.Header().Get("X-Incident-ID") != "" {
+		t.Errorf("unknown type: status %d: %s; want %d and no incident", recorder.Code, recorder.Body, http.StatusBadRequest)
+	}
+}
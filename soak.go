@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"incident-ai/models"
+	"incident-ai/telemetry"
+	"incident-ai/trace"
+	"time"
+)
+
+// maxSoakReopens caps how often one incident's remediation is reopened after relapsing
+const maxSoakReopens = 2
+
+// soak keeps probing the service for the soak period after a fix passed verification,
+// and reports whether it stayed healthy the whole time. Without a soak period it
+// passes immediately.
+func (o *Orchestrator) soak(ctx context.Context, incident *models.Incident) bool {
+	if o.soakDuration <= 0 {
+		return true
+	}
+
+	telemetry.Logf(ctx, "[VERIFICATION] 🛁 Soaking for %v (probing every %v)...\n", o.soakDuration, o.soakInterval)
+
+	// The soak probes watch the service now: a relapse belongs to this incident and is
+	// reopened, so the detector mustn't raise it as a new one
+	if o.detector != nil {
+		endDetection := o.detector.BeginRemediation()
+		defer endDetection()
+	}
+
+	start := time.Now()
+	probes := 0
+	for time.Since(start) < o.soakDuration {
+		if !sleepContext(ctx, o.soakInterval) {
+			telemetry.Logln(ctx, "[VERIFICATION] Soak interrupted by shutdown")
+			return false
+		}
+
+		probes++
+		healthy := o.verifier.VerifyResolution()
+		o.record(trace.Event{Kind: trace.EventVerification, IncidentID: incident.ID, Healthy: healthy})
+
+		if !healthy {
+			telemetry.Logf(ctx, "[VERIFICATION] ✗ Service failed soak probe %d, %v after verification\n",
+				probes, time.Since(start).Round(time.Millisecond))
+			if o.detector != nil {
+				o.detector.FailureHandled()
+			}
+			return false
+		}
+	}
+
+	telemetry.Logf(ctx, "[VERIFICATION] ✓ Service stayed healthy through the %v soak (%d probes)\n", o.soakDuration, probes)
+	return true
+}
+
+// reopen re-enqueues an incident whose fix relapsed during the soak period so remediation
+// starts over, with the relapsed fix passed to the AI as a failed attempt. It reports
+// false once the incident has relapsed too often or cannot be re-enqueued.
+func (o *Orchestrator) reopen(ctx context.Context, incident *models.Incident) bool {
+	incident.Relapses++
+	if incident.Relapses > maxSoakReopens {
+		telemetry.Logf(ctx, "[SYSTEM] Fix relapsed during soak %d times, giving up\n", incident.Relapses)
+		return false
+	}
+
+	incident.Status = models.StatusDetected
+	incident.UsedCachedFix = false
+	if err := o.store.StoreIncident(incident); err != nil {
+		telemetry.Logf(ctx, "[MEMORY] Warning: failed to store incident: %v\n", err)
+	}
+
+	// The requeue is drained by the goroutine running this, so never block on it
+	select {
+	case o.requeue <- incident:
+		telemetry.Logf(ctx, "[SYSTEM] 🔁 Service relapsed during soak - reopening remediation (%d/%d)\n", incident.Relapses, maxSoakReopens)
+		return true
+	default:
+		telemetry.Logln(ctx, "[SYSTEM] Warning: requeue is full, cannot reopen remediation")
+		return false
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"incident-ai/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSoakOutcome(t *testing.T) {
+	tests := []struct {
+		name         string
+		relapses     int                  // times the incident already relapsed
+		healthy      func(check int) bool // checks 1-3 are verification, the rest soak probes
+		wantStatus   models.IncidentStatus
+		wantRequeued bool
+	}{
+		{
+			name:       "healthy throughout",
+			wantStatus: models.StatusResolved,
+		},
+		{
+			name:         "fails during soak",
+			healthy:      func(check int) bool { return check != 5 },
+			wantStatus:   models.StatusDetected,
+			wantRequeued: true,
+		},
+		{
+			name:       "fails during soak too often",
+			relapses:   maxSoakReopens,
+			healthy:    func(check int) bool { return check != 5 },
+			wantStatus: models.StatusFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOrchestrator(t)
+			o.soakDuration = 50 * time.Millisecond
+			o.soakInterval = 5 * time.Millisecond
+			verifier := &fakeVerifier{healthy: tt.healthy}
+			o.verifier = verifier
+
+			incident := newTestIncident("a", models.ServiceDown, "health check timed out")
+			incident.Relapses = tt.relapses
+			if err := o.processIncident(context.Background(), incident); err != nil {
+				t.Fatalf("processIncident: %v", err)
+			}
+
+			if incident.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", incident.Status, tt.wantStatus)
+			}
+			if checks := verifier.checks.Load(); checks < 5 {
+				t.Errorf("%d health checks made, want verification and soak probes", checks)
+			}
+			if tt.wantStatus == models.StatusFailed && !strings.Contains(incident.FailureReason, "soak") {
+				t.Errorf("failure reason %q doesn't mention the soak", incident.FailureReason)
+			}
+
+			select {
+			case reopened := <-o.requeue:
+				if !tt.wantRequeued {
+					t.Errorf("incident %s requeued, want it closed", reopened.ID)
+				}
+			default:
+				if tt.wantRequeued {
+					t.Error("incident not requeued after relapsing")
+				}
+			}
+		})
+	}
+}
+
+func TestSoakRelapseReopensRemediation(t *testing.T) {
+	o := newTestOrchestrator(t)
+	o.soakDuration = 50 * time.Millisecond
+	o.soakInterval = 5 * time.Millisecond
+	o.verifier = &fakeVerifier{healthy: func(check int) bool { return check != 5 }}
+	analyzer := o.analyzer.(*fakeAnalyzer)
+
+	incident := newTestIncident("a", models.ServiceDown, "health check timed out")
+	if err := o.processIncident(context.Background(), incident); err != nil {
+		t.Fatalf("processIncident: %v", err)
+	}
+	if incident.Relapses != 1 {
+		t.Fatalf("relapses = %d after failing the soak, want 1", incident.Relapses)
+	}
+
+	reopened := <-o.requeue
+	if err := o.processIncident(context.Background(), reopened); err != nil {
+		t.Fatalf("processIncident of the reopened incident: %v", err)
+	}
+	if reopened.Status != models.StatusResolved {
+		t.Errorf("reopened incident status = %s, want %s", reopened.Status, models.StatusResolved)
+	}
+
+	// The fix that relapsed is passed to the AI as a failed attempt
+	if len(analyzer.previous) != 2 {
+		t.Fatalf("analyzed %d times, want 2", len(analyzer.previous))
+	}
+	if attempts := analyzer.previous[1]; len(attempts) != 1 || attempts[0].FixType != "restart" {
+		t.Errorf("previous attempts on reopening = %+v, want the relapsed restart", attempts)
+	}
+}
@@ -0,0 +1,88 @@
+package telemetry
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// CorrelationHeader carries an incident's correlation ID on outbound requests
+const CorrelationHeader = "X-Correlation-ID"
+
+type correlationKey struct{}
+
+// NewCorrelationID returns a random ID for correlating one incident's log lines and requests
+func NewCorrelationID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// WithCorrelationID returns a context carrying a correlation ID. An empty ID leaves ctx as is.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, correlationKey{}, id)
+}
+
+// CorrelationID returns the correlation ID carried by ctx, or "" without one
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationKey{}).(string)
+	return id
+}
+
+// Logf logs like log.Printf, prefixing the line with the context's correlation ID so every
+// line about one incident can be found with a single search
+func Logf(ctx context.Context, format string, v ...interface{}) {
+	log.Print(withCorrelation(ctx, fmt.Sprintf(format, v...)))
+}
+
+// Logln logs like log.Println, prefixing the line with the context's correlation ID
+func Logln(ctx context.Context, v ...interface{}) {
+	log.Print(withCorrelation(ctx, fmt.Sprintln(v...)))
+}
+
+// withCorrelation prefixes a log message with the context's correlation ID, after any
+// leading blank lines used to set the message apart
+func withCorrelation(ctx context.Context, message string) string {
+	id := CorrelationID(ctx)
+	if id == "" {
+		return message
+	}
+
+	body := strings.TrimLeft(message, "\n")
+	return message[:len(message)-len(body)] + "[" + id + "] " + body
+}
+
+// correlationTransport sets the correlation header on requests whose context carries an ID
+type correlationTransport struct {
+	base http.RoundTripper
+}
+
+// NewCorrelationTransport wraps base so outbound requests carry their context's correlation
+// ID in the X-Correlation-ID header. A nil base uses http.DefaultTransport.
+func NewCorrelationTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &correlationTransport{base: base}
+}
+
+func (t *correlationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	id := CorrelationID(req.Context())
+	if id == "" || req.Header.Get(CorrelationHeader) != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	// A RoundTripper must not modify the caller's request
+	req = req.Clone(req.Context())
+	req.Header.Set(CorrelationHeader, id)
+	return t.base.RoundTrip(req)
+}
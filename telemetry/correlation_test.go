@@ -0,0 +1,74 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestLogsCarryCorrelationID(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+	})
+
+	ctx := WithCorrelationID(context.Background(), "abc123")
+	Logf(ctx, "[AI] Analyzing incident %s\n", "incident-1")
+	Logln(ctx, "\n[REMEDIATION] Executing fix")
+	Logf(context.Background(), "[MONITOR] Probe failed\n")
+	Logf(WithCorrelationID(context.Background(), ""), "[MONITOR] Still failing\n")
+
+	want := "[abc123] [AI] Analyzing incident incident-1\n" +
+		"\n[abc123] [REMEDIATION] Executing fix\n" +
+		"[MONITOR] Probe failed\n" +
+		"[MONITOR] Still failing\n"
+	if got := buf.String(); got != want {
+		t.Errorf("logs:\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestCorrelationTransport(t *testing.T) {
+	received := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Get(CorrelationHeader)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewCorrelationTransport(nil)}
+	tests := []struct {
+		name   string
+		id     string
+		header string // set on the request by the caller
+		want   string
+	}{
+		{name: "context ID", id: "abc123", want: "abc123"},
+		{name: "no ID"},
+		{name: "caller's header kept", id: "abc123", header: "upstream-id", want: "upstream-id"},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequestWithContext(WithCorrelationID(context.Background(), tt.id), http.MethodGet, server.URL, nil)
+		if tt.header != "" {
+			req.Header.Set(CorrelationHeader, tt.header)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("%s: request: %v", tt.name, err)
+		}
+		resp.Body.Close()
+
+		if got := <-received; got != tt.want {
+			t.Errorf("%s: header = %q, want %q", tt.name, got, tt.want)
+		}
+		if tt.header == "" && req.Header.Get(CorrelationHeader) != "" {
+			t.Errorf("%s: transport modified the caller's request", tt.name)
+		}
+	}
+}
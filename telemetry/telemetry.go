@@ -0,0 +1,59 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies spans created by this system
+const instrumentationName = "incident-ai"
+
+// Tracer returns the tracer used for incident spans. Until Setup is called it is a
+// no-op tracer, so instrumented code costs nothing when tracing is disabled.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Setup exports spans over OTLP/HTTP to endpoint (host:port, e.g. "localhost:4318")
+// and installs the exporting provider globally. The returned function flushes
+// pending spans and shuts the exporter down.
+func Setup(ctx context.Context, endpoint string, insecure bool) (func(context.Context) error, error) {
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpoint)}
+	if insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", instrumentationName),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+
+	log.Printf("[TELEMETRY] Exporting incident traces to %s\n", endpoint)
+	return provider.Shutdown, nil
+}
+
+// End marks the span as failed if err is non-nil, then ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
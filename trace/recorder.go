@@ -0,0 +1,136 @@
+package trace
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"incident-ai/models"
+	"os"
+	"sync"
+	"time"
+)
+
+// EventKind identifies a step of incident handling
+type EventKind string
+
+const (
+	EventDetection    EventKind = "detection"    // incident as detected
+	EventCachedFix    EventKind = "cached_fix"   // result of applying a learned fix
+	EventAnalysis     EventKind = "analysis"     // AI or rule-based analysis result
+	EventRemediation  EventKind = "remediation"  // result of executing a fix
+	EventVerification EventKind = "verification" // single post-fix health check
+	EventOutcome      EventKind = "outcome"      // incident's final state
+)
+
+// Analysis sources recorded on EventAnalysis
+const (
+	SourceAI       = "ai"
+	SourceFallback = "fallback"
+)
+
+// Event is a single recorded step of incident handling
+type Event struct {
+	Kind       EventKind          `json:"kind"`
+	Time       time.Time          `json:"time"`
+	IncidentID string             `json:"incident_id"`
+	Incident   *models.Incident   `json:"incident,omitempty"`
+	LearnedFix *models.Resolution `json:"learned_fix,omitempty"` // fix available at detection time
+	Source     string             `json:"source,omitempty"`
+	AIResponse *models.AIResponse `json:"ai_response,omitempty"`
+	Resolution *models.Resolution `json:"resolution,omitempty"`
+	Healthy    bool               `json:"healthy,omitempty"`
+	Error      string             `json:"error,omitempty"`
+}
+
+// Recorder writes incident handling events to a JSON-lines trace file.
+// A nil *Recorder is valid and records nothing.
+type Recorder struct {
+	mu     sync.Mutex
+	file   *os.File
+	writer *bufio.Writer
+}
+
+// NewRecorder creates a recorder writing to the given trace file
+func NewRecorder(path string) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace file: %w", err)
+	}
+
+	return &Recorder{
+		file:   file,
+		writer: bufio.NewWriter(file),
+	}, nil
+}
+
+// Record appends an event to the trace. Events are serialized immediately,
+// so later mutations of the incident don't affect what was recorded.
+func (r *Recorder) Record(event Event) error {
+	if r == nil {
+		return nil
+	}
+
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode trace event: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.writer.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write trace event: %w", err)
+	}
+	return r.writer.Flush()
+}
+
+// Close flushes and closes the trace file
+func (r *Recorder) Close() error {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.writer.Flush(); err != nil {
+		r.file.Close()
+		return err
+	}
+	return r.file.Close()
+}
+
+// Load reads all events from a trace file
+func Load(path string) ([]Event, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	events := []Event{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("invalid trace event on line %d: %w", lineNum, err)
+		}
+		events = append(events, event)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read trace file: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,147 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"incident-ai/models"
+	"sync"
+)
+
+// Replayer feeds recorded events back in place of the live analyzer, executor
+// and health checks. Calls are answered per incident, in recorded order.
+type Replayer struct {
+	mu         sync.Mutex
+	detections []Event
+	outcomes   map[string]*models.Incident
+	queues     map[string]map[EventKind][]Event
+	current    string // incident whose verification results are being replayed
+}
+
+// NewReplayer creates a replayer from recorded events
+func NewReplayer(events []Event) *Replayer {
+	r := &Replayer{
+		outcomes: make(map[string]*models.Incident),
+		queues:   make(map[string]map[EventKind][]Event),
+	}
+
+	for _, event := range events {
+		switch event.Kind {
+		case EventDetection:
+			r.detections = append(r.detections, event)
+		case EventOutcome:
+			r.outcomes[event.IncidentID] = event.Incident
+		default:
+			if r.queues[event.IncidentID] == nil {
+				r.queues[event.IncidentID] = make(map[EventKind][]Event)
+			}
+			r.queues[event.IncidentID][event.Kind] = append(r.queues[event.IncidentID][event.Kind], event)
+		}
+	}
+
+	return r
+}
+
+// Detections returns the recorded detection events in order
+func (r *Replayer) Detections() []Event {
+	return r.detections
+}
+
+// Outcome returns the recorded final state of an incident
+func (r *Replayer) Outcome(incidentID string) (*models.Incident, bool) {
+	incident, exists := r.outcomes[incidentID]
+	return incident, exists
+}
+
+func (r *Replayer) next(incidentID string, kind EventKind) (Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.current = incidentID
+
+	queue := r.queues[incidentID][kind]
+	if len(queue) == 0 {
+		return Event{}, fmt.Errorf("trace has no more %s events for incident %s", kind, incidentID)
+	}
+
+	r.queues[incidentID][kind] = queue[1:]
+	return queue[0], nil
+}
+
+func eventError(event Event) error {
+	if event.Error == "" {
+		return nil
+	}
+	return errors.New(event.Error)
+}
+
+// UsedAI reports whether any recorded analysis came from the AI
+func (r *Replayer) UsedAI() bool {
+	for _, queues := range r.queues {
+		for _, event := range queues[EventAnalysis] {
+			if event.Source == SourceAI {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AnalyzeIncident replays a recorded AI analysis. If the next recorded analysis
+// was rule-based it is left in place for GetQuickAnalysis and an error is returned.
+func (r *Replayer) AnalyzeIncident(ctx context.Context, incident *models.Incident, previousAttempts []models.Resolution) (*models.AIResponse, error) {
+	r.mu.Lock()
+	queue := r.queues[incident.ID][EventAnalysis]
+	r.mu.Unlock()
+
+	if len(queue) > 0 && queue[0].Source != SourceAI {
+		return nil, fmt.Errorf("trace recorded %s analysis for incident %s", queue[0].Source, incident.ID)
+	}
+
+	event, err := r.next(incident.ID, EventAnalysis)
+	if err != nil {
+		return nil, err
+	}
+	return event.AIResponse, eventError(event)
+}
+
+// GetQuickAnalysis replays a recorded rule-based analysis. The recorded analysis already
+// reflects any last fix and confidence it was based on, so those are ignored.
+func (r *Replayer) GetQuickAnalysis(incident *models.Incident, lastFix *models.Resolution, confidence float64) *models.AIResponse {
+	event, err := r.next(incident.ID, EventAnalysis)
+	if err != nil {
+		return &models.AIResponse{Diagnosis: err.Error(), FixType: "restart", FixSteps: []string{"Replay trace exhausted"}}
+	}
+	return event.AIResponse
+}
+
+// ExecuteFix replays a recorded fix execution
+func (r *Replayer) ExecuteFix(ctx context.Context, incident *models.Incident, aiResponse *models.AIResponse) (*models.Resolution, error) {
+	event, err := r.next(incident.ID, EventRemediation)
+	if err != nil {
+		return nil, err
+	}
+	return event.Resolution, eventError(event)
+}
+
+// ApplyCachedFix replays a recorded learned-fix application
+func (r *Replayer) ApplyCachedFix(ctx context.Context, incident *models.Incident, cachedResolution *models.Resolution) error {
+	event, err := r.next(incident.ID, EventCachedFix)
+	if err != nil {
+		return err
+	}
+	return eventError(event)
+}
+
+// VerifyResolution replays the next recorded health check for the current incident
+func (r *Replayer) VerifyResolution() bool {
+	r.mu.Lock()
+	current := r.current
+	r.mu.Unlock()
+
+	event, err := r.next(current, EventVerification)
+	if err != nil {
+		return false
+	}
+	return event.Healthy
+}
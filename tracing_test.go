@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"incident-ai/ai"
+	"incident-ai/models"
+	"incident-ai/remediation"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestIncidentSpanTree(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	o := newTestOrchestrator(t)
+	o.analyzer = ai.NewAnalyzer("test-key", ai.WithCompletionFunc(func(ctx context.Context, req openai.ChatCompletionRequest) (string, error) {
+		return `{"diagnosis": "Process crashed", "fix_type": "restart", "fix_steps": ["Restart the service"], "confidence": 0.9}`, nil
+	}))
+	o.executor = remediation.NewExecutor(nil, remediation.WithRestartCommand(remediation.CommandConfig{Path: "true"}))
+
+	incident := newTestIncident("traced", models.ServiceDown, "health check timed out")
+	if err := o.processIncident(context.Background(), incident); err != nil {
+		t.Fatalf("processIncident: %v", err)
+	}
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		if _, seen := spans[span.Name()]; seen {
+			t.Errorf("more than one %s span", span.Name())
+		}
+		spans[span.Name()] = span
+	}
+
+	root, ok := spans["incident"]
+	if !ok {
+		t.Fatalf("no incident span among %v", recorder.Ended())
+	}
+	if root.Parent().IsValid() {
+		t.Error("incident span has a parent, want it to be the trace's root")
+	}
+
+	wantAttributes := map[string][]attribute.KeyValue{
+		"incident":     {attribute.String("incident.status", string(models.StatusResolved)), attribute.String("incident.type", string(models.ServiceDown))},
+		"detection":    nil,
+		"analysis":     {attribute.String("ai.model", openai.GPT3Dot5Turbo), attribute.Int("ai.total_tokens", 0)},
+		"remediation":  {attribute.String("fix.type", "restart")},
+		"verification": {attribute.String("verification.result", "passed")},
+	}
+	for name, want := range wantAttributes {
+		span, ok := spans[name]
+		if !ok {
+			t.Errorf("no %s span", name)
+			continue
+		}
+		if name != "incident" && span.Parent().SpanID() != root.SpanContext().SpanID() {
+			t.Errorf("%s span isn't a child of the incident span", name)
+		}
+		if span.SpanContext().TraceID() != root.SpanContext().TraceID() {
+			t.Errorf("%s span is in another trace", name)
+		}
+
+		attributes := make(map[attribute.Key]attribute.Value)
+		for _, kv := range span.Attributes() {
+			attributes[kv.Key] = kv.Value
+		}
+		for _, kv := range want {
+			if got, ok := attributes[kv.Key]; !ok || got != kv.Value {
+				t.Errorf("%s span %s = %v, want %v", name, kv.Key, got.Emit(), kv.Value.Emit())
+			}
+		}
+	}
+}
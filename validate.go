@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"incident-ai/ai"
+	"incident-ai/inventory"
+	"incident-ai/memory"
+	"log"
+	"net"
+	"strconv"
+	"time"
+)
+
+// errCheckSkipped marks a validation check that does not apply to this configuration
+var errCheckSkipped = errors.New("skipped")
+
+// validationCheck is one startup configuration check
+type validationCheck struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// namedDuration is a duration flag checked for sanity
+type namedDuration struct {
+	flag     string
+	value    time.Duration
+	positive bool // zero is not allowed either
+}
+
+// namedPort is a port checked for validity and availability
+type namedPort struct {
+	name string
+	port string
+}
+
+// validationConfig is everything the -validate checks inspect
+type validationConfig struct {
+	analyzer      *ai.Analyzer // nil when AI analysis is disabled
+	memoryFile    string
+	fixesFile     string
+	demoFile      string
+	runbooksFile  string
+	inventoryFile string
+	ports         []namedPort
+	durations     []namedDuration
+}
+
+// configChecks builds the checks run by -validate
+func configChecks(cfg validationConfig) []validationCheck {
+	checks := []validationCheck{
+		{"API key format", func(ctx context.Context) error {
+			if cfg.analyzer == nil {
+				return fmt.Errorf("%w: AI analysis disabled", errCheckSkipped)
+			}
+			return cfg.analyzer.ValidateAPIKey()
+		}},
+		{"AI endpoint reachable", func(ctx context.Context) error {
+			if cfg.analyzer == nil {
+				return fmt.Errorf("%w: AI analysis disabled", errCheckSkipped)
+			}
+			return cfg.analyzer.CheckProvider(ctx)
+		}},
+		{"memory file writable", func(ctx context.Context) error {
+			return memory.CheckWritable(cfg.memoryFile)
+		}},
+		{"fixes file", func(ctx context.Context) error {
+			if cfg.fixesFile == "" {
+				return fmt.Errorf("%w: no -fixes-file given", errCheckSkipped)
+			}
+			_, err := memory.LoadFixesFile(cfg.fixesFile)
+			return err
+		}},
+		{"demo file", func(ctx context.Context) error {
+			if cfg.demoFile == "" {
+				return fmt.Errorf("%w: no -demo-file given", errCheckSkipped)
+			}
+			_, err := LoadDemoScenarios(cfg.demoFile)
+			return err
+		}},
+		{"runbooks file", func(ctx context.Context) error {
+			if cfg.runbooksFile == "" {
+				return fmt.Errorf("%w: no -runbooks-file given", errCheckSkipped)
+			}
+			_, err := LoadRunbooks(cfg.runbooksFile)
+			return err
+		}},
+		{"inventory file", func(ctx context.Context) error {
+			if cfg.inventoryFile == "" {
+				return fmt.Errorf("%w: no -inventory-file given", errCheckSkipped)
+			}
+			_, err := inventory.LoadFile(cfg.inventoryFile)
+			return err
+		}},
+	}
+
+	for _, p := range cfg.ports {
+		port := p.port
+		checks = append(checks, validationCheck{fmt.Sprintf("%s %s", p.name, port), func(ctx context.Context) error {
+			return validatePort(port)
+		}})
+	}
+
+	checks = append(checks, validationCheck{"intervals", func(ctx context.Context) error {
+		return validateDurations(cfg.durations)
+	}})
+
+	return checks
+}
+
+// runValidation runs every check, logs a pass/fail report and returns whether all passed
+func runValidation(ctx context.Context, checks []validationCheck) bool {
+	log.Println("[SYSTEM] Validating configuration...")
+
+	passed := true
+	for _, check := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		err := check.run(checkCtx)
+		cancel()
+
+		switch {
+		case err == nil:
+			log.Printf("[SYSTEM]   ✓ %s\n", check.name)
+		case errors.Is(err, errCheckSkipped):
+			log.Printf("[SYSTEM]   - %s (%v)\n", check.name, err)
+		default:
+			log.Printf("[SYSTEM]   ✗ %s: %v\n", check.name, err)
+			passed = false
+		}
+	}
+
+	if passed {
+		log.Println("[SYSTEM] ✓ Configuration is valid")
+	} else {
+		log.Println("[SYSTEM] ❌ Configuration has errors")
+	}
+	return passed
+}
+
+// validatePort checks port is a valid TCP port that is free to listen on
+func validatePort(port string) error {
+	n, err := strconv.Atoi(port)
+	if err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("invalid port %q: must be a number between 1 and 65535", port)
+	}
+
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return fmt.Errorf("port %s is not available: %w", port, err)
+	}
+	return listener.Close()
+}
+
+// validateDurations checks no duration is negative and required ones are positive
+func validateDurations(durations []namedDuration) error {
+	var errs []error
+	for _, d := range durations {
+		switch {
+		case d.value < 0:
+			errs = append(errs, fmt.Errorf("-%s must not be negative (got %v)", d.flag, d.value))
+		case d.positive && d.value == 0:
+			errs = append(errs, fmt.Errorf("-%s must be greater than zero", d.flag))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"incident-ai/ai"
+	"net"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// freePort returns a port nothing listens on, and one held open until the test ends
+func freePort(t *testing.T) (free, used string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	free = strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+	listener.Close()
+
+	held, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { held.Close() })
+	return free, strconv.Itoa(held.Addr().(*net.TCPAddr).Port)
+}
+
+func TestValidatePort(t *testing.T) {
+	free, used := freePort(t)
+
+	tests := []struct {
+		port    string
+		wantErr string
+	}{
+		{port: free},
+		{port: used, wantErr: "is not available"},
+		{port: "0", wantErr: "must be a number between 1 and 65535"},
+		{port: "65536", wantErr: "must be a number between 1 and 65535"},
+		{port: "http", wantErr: "must be a number between 1 and 65535"},
+	}
+
+	for _, tt := range tests {
+		err := validatePort(tt.port)
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("validatePort(%s) = %v, want no error", tt.port, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("validatePort(%s) = %v, want an error containing %q", tt.port, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateDurations(t *testing.T) {
+	err := validateDurations([]namedDuration{
+		{flag: "check-interval", value: 0, positive: true},
+		{flag: "soak", value: -time.Second},
+		{flag: "ack-expiry", value: 0},
+		{flag: "verify-timeout", value: time.Minute, positive: true},
+	})
+	if err == nil {
+		t.Fatal("validateDurations accepted a zero interval and a negative duration")
+	}
+	for _, want := range []string{"-check-interval must be greater than zero", "-soak must not be negative"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q doesn't report %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "ack-expiry") || strings.Contains(err.Error(), "verify-timeout") {
+		t.Errorf("error %q reports valid durations", err)
+	}
+}
+
+func TestRunValidation(t *testing.T) {
+	free, used := freePort(t)
+	dir := t.TempDir()
+
+	tests := []struct {
+		name string
+		cfg  validationConfig
+		want bool
+	}{
+		{
+			name: "valid",
+			cfg: validationConfig{
+				analyzer:   ai.NewAnalyzer("sk-test"),
+				memoryFile: filepath.Join(dir, "incident_memory.json"),
+				ports:      []namedPort{{name: "service port", port: free}},
+				durations:  []namedDuration{{flag: "check-interval", value: time.Second, positive: true}},
+			},
+			want: true,
+		},
+		{
+			name: "port in use",
+			cfg: validationConfig{
+				memoryFile: filepath.Join(dir, "incident_memory.json"),
+				ports:      []namedPort{{name: "service port", port: used}},
+			},
+		},
+		{
+			name: "unwritable memory file",
+			cfg:  validationConfig{memoryFile: filepath.Join(dir, "missing", "incident_memory.json")},
+		},
+		{
+			name: "malformed API key",
+			cfg:  validationConfig{analyzer: ai.NewAnalyzer("test-key"), memoryFile: filepath.Join(dir, "incident_memory.json")},
+		},
+		{
+			name: "missing fixes file",
+			cfg:  validationConfig{memoryFile: filepath.Join(dir, "incident_memory.json"), fixesFile: filepath.Join(dir, "fixes.yaml")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks := configChecks(tt.cfg)
+			if tt.cfg.analyzer != nil {
+				// Leave out the network check, which needs a real provider
+				checks = slices.DeleteFunc(checks, func(check validationCheck) bool { return check.name == "AI endpoint reachable" })
+			}
+			if got := runValidation(context.Background(), checks); got != tt.want {
+				t.Errorf("runValidation = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
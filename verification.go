@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"incident-ai/models"
+	"incident-ai/telemetry"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// VerificationStrategy determines how a fix is confirmed to have worked
+type VerificationStrategy string
+
+const (
+	VerifyHealth          VerificationStrategy = "health"        // repeated health checks
+	VerifyHealthAndConfig VerificationStrategy = "health+config" // health checks plus config-value assertion
+	VerifyManual          VerificationStrategy = "manual"        // a human confirms the fix
+)
+
+// verificationResult is the outcome of verifying a fix
+type verificationResult int
+
+const (
+	verificationFailed verificationResult = iota
+	verificationPassed
+	verificationManual
+	verificationRelapsed // passed, then failed again during the soak period
+)
+
+func (r verificationResult) String() string {
+	switch r {
+	case verificationPassed:
+		return "passed"
+	case verificationManual:
+		return "manual"
+	case verificationRelapsed:
+		return "relapsed"
+	default:
+		return "failed"
+	}
+}
+
+// configReader exposes the monitored service's live configuration
+type configReader interface {
+	GetConfig() (map[string]string, error)
+}
+
+// DefaultVerificationStrategies returns the strategy used for each fix type by default
+func DefaultVerificationStrategies() map[string]VerificationStrategy {
+	return map[string]VerificationStrategy{
+		"restart": VerifyHealth,
+		"config":  VerifyHealthAndConfig,
+		"code":    VerifyManual,
+	}
+}
+
+// ParseVerificationStrategies parses "fixtype=strategy" pairs, e.g. "restart=health,code=manual",
+// overriding the defaults for the listed fix types
+func ParseVerificationStrategies(s string) (map[string]VerificationStrategy, error) {
+	strategies := DefaultVerificationStrategies()
+
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		fixType, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid verification strategy %q: expected fixtype=strategy", pair)
+		}
+
+		strategy := VerificationStrategy(strings.TrimSpace(value))
+		switch strategy {
+		case VerifyHealth, VerifyHealthAndConfig, VerifyManual:
+		default:
+			return nil, fmt.Errorf("unknown verification strategy %q for fix type %s", value, fixType)
+		}
+
+		strategies[strings.TrimSpace(fixType)] = strategy
+	}
+
+	return strategies, nil
+}
+
+// verificationStrategy returns the strategy for a fix type, falling back to a health check
+func (o *Orchestrator) verificationStrategy(fixType string) VerificationStrategy {
+	strategies := o.verifyStrategies
+	if strategies == nil {
+		strategies = DefaultVerificationStrategies()
+	}
+
+	if strategy, ok := strategies[fixType]; ok {
+		return strategy
+	}
+	return VerifyHealth
+}
+
+// verifyFix verifies a fix using the strategy configured for its fix type
+func (o *Orchestrator) verifyFix(ctx context.Context, incident *models.Incident, resolution *models.Resolution) (result verificationResult) {
+	strategy := o.verificationStrategy(resolution.FixType)
+	telemetry.Logf(ctx, "[VERIFICATION] Verifying %s fix using %s strategy\n", resolution.FixType, strategy)
+
+	_, span := telemetry.Tracer().Start(ctx, "verification", trace.WithAttributes(
+		attribute.String("fix.type", resolution.FixType),
+		attribute.String("verification.strategy", string(strategy)),
+	))
+	defer func() {
+		span.SetAttributes(attribute.String("verification.result", result.String()))
+		span.End()
+	}()
+
+	if strategy == VerifyManual {
+		return verificationManual
+	}
+
+	passed := o.verifyStrategy(ctx, incident, resolution, strategy)
+
+	// A service that only needed a moment longer often comes good after one more restart
+	restarts := 0
+	for !passed && restarts < o.verifyRestarts && o.restarter != nil && ctx.Err() == nil {
+		restarts++
+		telemetry.Logf(ctx, "[VERIFICATION] 🔁 Verification failed, restarting service and re-verifying (%d/%d)\n", restarts, o.verifyRestarts)
+		endRemediation := o.beginRemediation()
+		err := o.restarter.Restart(ctx)
+		endRemediation()
+		if err != nil {
+			telemetry.Logf(ctx, "[VERIFICATION] ✗ Restart failed: %v\n", err)
+			continue
+		}
+		sleepContext(ctx, o.stabilizeDelay)
+		passed = o.verifyStrategy(ctx, incident, resolution, strategy)
+	}
+	span.SetAttributes(attribute.Int("verification.restarts", restarts))
+
+	if !passed {
+		return verificationFailed
+	}
+	if restarts > 0 {
+		telemetry.Logf(ctx, "[VERIFICATION] ✓ Verification passed after %d extra restart(s)\n", restarts)
+	}
+
+	if !o.soak(ctx, incident) {
+		return verificationRelapsed
+	}
+	return verificationPassed
+}
+
+// verifyStrategy runs the checks of a non-manual strategy once
+func (o *Orchestrator) verifyStrategy(ctx context.Context, incident *models.Incident, resolution *models.Resolution, strategy VerificationStrategy) bool {
+	if !o.verifyResolution(ctx, incident) {
+		return false
+	}
+	if strategy == VerifyHealthAndConfig {
+		return o.verifyConfig(ctx, resolution.TargetConfig)
+	}
+	return true
+}
+
+// verifyConfig asserts the service's live config holds the values the fix set out to write.
+// They are what the fix decided on, not what it recorded as written, so a write that
+// failed or didn't survive the restart fails the check.
+func (o *Orchestrator) verifyConfig(ctx context.Context, expected map[string]string) bool {
+	if o.config == nil {
+		telemetry.Logln(ctx, "[VERIFICATION] No config source available, skipping config assertion")
+		return true
+	}
+
+	if len(expected) == 0 {
+		telemetry.Logln(ctx, "[VERIFICATION] Fix set no config values, nothing to assert")
+		return true
+	}
+
+	actual, err := o.config.GetConfig()
+	if err != nil {
+		telemetry.Logf(ctx, "[VERIFICATION] ✗ Can't read the config: %v\n", err)
+		return false
+	}
+
+	keys := make([]string, 0, len(expected))
+	for key := range expected {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	ok := true
+	for _, key := range keys {
+		if actual[key] != expected[key] {
+			telemetry.Logf(ctx, "[VERIFICATION] ✗ Config %s = %q, expected %q\n", key, actual[key], expected[key])
+			ok = false
+		}
+	}
+
+	if ok {
+		telemetry.Logf(ctx, "[VERIFICATION] ✓ All %d config value(s) match\n", len(keys))
+	}
+	return ok
+}
+
+// requestManualVerification marks an applied fix as awaiting human confirmation and notifies
+func (o *Orchestrator) requestManualVerification(ctx context.Context, incident *models.Incident, resolution *models.Resolution) {
+	incident.Resolution = resolution
+	incident.Status = models.StatusManualReview
+	o.store.StoreIncident(incident)
+
+	o.notify(ctx, incident,
+		fmt.Sprintf("%s incident fixed - manual verification required", incident.Type),
+		fmt.Sprintf("A %s fix was applied and must be verified by hand.\nDiagnosis: %s\n", resolution.FixType, resolution.Description))
+
+	log.Println("\n" + strings.Repeat("=", 70))
+	telemetry.Logf(ctx, "[SYSTEM] 👀 FIX APPLIED, AWAITING MANUAL VERIFICATION (applied in %v)\n", time.Since(incident.DetectedAt))
+	log.Println(strings.Repeat("=", 70) + "\n")
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"incident-ai/models"
+	"sync/atomic"
+	"testing"
+)
+
+// fakeConfig is a service config source
+type fakeConfig struct {
+	values map[string]string
+	err    error
+}
+
+func (c fakeConfig) GetConfig() (map[string]string, error) {
+	return c.values, c.err
+}
+
+func TestParseVerificationStrategies(t *testing.T) {
+	strategies, err := ParseVerificationStrategies("restart=manual, code = health")
+	if err != nil {
+		t.Fatalf("ParseVerificationStrategies: %v", err)
+	}
+	want := map[string]VerificationStrategy{"restart": VerifyManual, "config": VerifyHealthAndConfig, "code": VerifyHealth}
+	for fixType, strategy := range want {
+		if strategies[fixType] != strategy {
+			t.Errorf("%s strategy = %q, want %q", fixType, strategies[fixType], strategy)
+		}
+	}
+
+	for _, s := range []string{"restart", "restart=ping"} {
+		if _, err := ParseVerificationStrategies(s); err == nil {
+			t.Errorf("ParseVerificationStrategies(%q) accepted an invalid strategy", s)
+		}
+	}
+}
+
+func TestVerificationStrategies(t *testing.T) {
+	live := map[string]string{"max_connections": "200", "timeout": "30s"}
+
+	tests := []struct {
+		name    string
+		fix     models.Resolution
+		config  configReader
+		healthy bool
+		want    verificationResult
+	}{
+		{
+			name:    "config fix with the values it set",
+			fix:     models.Resolution{FixType: "config", TargetConfig: map[string]string{"max_connections": "200"}},
+			config:  fakeConfig{values: live},
+			healthy: true,
+			want:    verificationPassed,
+		},
+		{
+			name:    "config fix passing health with wrong values",
+			fix:     models.Resolution{FixType: "config", TargetConfig: map[string]string{"max_connections": "500", "timeout": "30s"}},
+			config:  fakeConfig{values: live},
+			healthy: true,
+			want:    verificationFailed,
+		},
+		{
+			name:    "config fix whose config can't be read",
+			fix:     models.Resolution{FixType: "config", TargetConfig: map[string]string{"max_connections": "200"}},
+			config:  fakeConfig{err: errors.New("config file unreadable")},
+			healthy: true,
+			want:    verificationFailed,
+		},
+		{
+			name:   "config fix with the values it set but unhealthy",
+			fix:    models.Resolution{FixType: "config", TargetConfig: map[string]string{"max_connections": "200"}},
+			config: fakeConfig{values: live},
+			want:   verificationFailed,
+		},
+		{
+			name:    "restart fix ignores config",
+			fix:     models.Resolution{FixType: "restart", TargetConfig: map[string]string{"max_connections": "500"}},
+			config:  fakeConfig{values: live},
+			healthy: true,
+			want:    verificationPassed,
+		},
+		{
+			name: "code fix",
+			fix:  models.Resolution{FixType: "code"},
+			want: verificationManual,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOrchestrator(t)
+			o.config = tt.config
+			o.verifier = &fakeVerifier{healthy: func(int) bool { return tt.healthy }}
+
+			incident := newTestIncident("verify", models.ConfigError)
+			if got := o.verifyFix(context.Background(), incident, &tt.fix); got != tt.want {
+				t.Errorf("verifyFix = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeRestarter counts restarts, failing them with err if set
+type fakeRestarter struct {
+	restarts atomic.Int32
+	err      error
+}
+
+func (r *fakeRestarter) Restart(ctx context.Context) error {
+	r.restarts.Add(1)
+	return r.err
+}
+
+func TestVerificationRestartRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		retries      int
+		restartErr   error
+		needRestarts int32 // restarts after which the service is healthy
+		want         verificationResult
+		wantRestarts int32
+	}{
+		{name: "disabled", retries: 0, needRestarts: 1, want: verificationFailed},
+		{name: "healthy after one restart", retries: 2, needRestarts: 1, want: verificationPassed, wantRestarts: 1},
+		{name: "healthy without a restart", retries: 2, want: verificationPassed},
+		{name: "retries exhausted", retries: 1, needRestarts: 2, want: verificationFailed, wantRestarts: 1},
+		{name: "restarts failing", retries: 2, restartErr: errors.New("restart command failed"), needRestarts: 1, want: verificationFailed, wantRestarts: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restarter := &fakeRestarter{err: tt.restartErr}
+			o := newTestOrchestrator(t)
+			o.verifyRestarts = tt.retries
+			o.restarter = restarter
+			o.verifier = &fakeVerifier{healthy: func(int) bool {
+				return tt.restartErr == nil && restarter.restarts.Load() >= tt.needRestarts
+			}}
+
+			incident := newTestIncident("verify", models.ServiceDown)
+			fix := &models.Resolution{FixType: "restart", Steps: []string{"Restart the service"}}
+			if got := o.verifyFix(context.Background(), incident, fix); got != tt.want {
+				t.Errorf("verifyFix = %s, want %s", got, tt.want)
+			}
+			if got := restarter.restarts.Load(); got != tt.wantRestarts {
+				t.Errorf("%d restarts, want %d", got, tt.wantRestarts)
+			}
+		})
+	}
+}
+
+func TestIncidentResolvedAfterExtraRestart(t *testing.T) {
+	restarter := &fakeRestarter{}
+	o := newTestOrchestrator(t)
+	o.verifyRestarts = 1
+	o.restarter = restarter
+	o.verifier = &fakeVerifier{healthy: func(int) bool { return restarter.restarts.Load() > 0 }}
+
+	incident := newTestIncident("a", models.ServiceDown, "health check timed out")
+	if err := o.processIncident(context.Background(), incident); err != nil {
+		t.Fatalf("processIncident: %v", err)
+	}
+	if incident.Status != models.StatusResolved || restarter.restarts.Load() != 1 {
+		t.Errorf("incident %s after %d extra restart(s), want RESOLVED after 1", incident.Status, restarter.restarts.Load())
+	}
+}